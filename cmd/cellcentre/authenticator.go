@@ -0,0 +1,52 @@
+package main
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+
+	"github.com/migotom/cell-centre-services/db"
+	authDelivery "github.com/migotom/cell-centre-services/pkg/components/auth/delivery/grpc"
+	employeeRepository "github.com/migotom/cell-centre-services/pkg/components/employee/repository"
+	"github.com/migotom/cell-centre-services/pkg/components/health"
+	healthDelivery "github.com/migotom/cell-centre-services/pkg/components/health/delivery/grpc"
+	"github.com/migotom/cell-centre-services/pkg/helpers"
+	"github.com/migotom/cell-centre-services/pkg/services/authenticator"
+)
+
+var authenticatorService = service{
+	name:      "authenticator",
+	newConfig: func() helpers.Config { return &authenticator.Config{} },
+	telemetry: func(c helpers.Config) telemetry {
+		config := c.(*authenticator.Config)
+		return telemetry{config.Tracing, config.MetricsAddress, config.ShutdownTimeout.Duration}
+	},
+	serve: func(ctx context.Context, log *zap.Logger, config helpers.Config) {
+		serveAuthenticator(ctx, log, config.(*authenticator.Config))
+	},
+}
+
+func serveAuthenticator(ctx context.Context, log *zap.Logger, config *authenticator.Config) {
+	dbClient, db, err := db.ConnectMongoDB(context.Background(), config.DatabaseAddress, config.DatabaseName)
+	if err != nil {
+		log.Fatal("Can't connect to database", zap.Error(err))
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), config.ShutdownTimeout.Duration)
+		defer cancel()
+		if err := dbClient.Disconnect(ctx); err != nil {
+			log.Fatal("Can't safely disconnect from database", zap.Error(err))
+		}
+	}()
+
+	authenticator := authenticator.NewAuthenticator(
+		log,
+		config,
+		authDelivery.NewAuthenticateDelivery(
+			log,
+			employeeRepository.NewEmployeeRepository(db),
+		),
+		healthDelivery.NewHealthDelivery(log, config.HealthCheckInterval.Duration, health.MongoDB(dbClient)),
+	)
+	authenticator.Listen(ctx)
+}
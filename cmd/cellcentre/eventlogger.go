@@ -0,0 +1,592 @@
+package main
+
+import (
+	"context"
+	"crypto/ed25519"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/golang/protobuf/ptypes"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.uber.org/zap"
+
+	"github.com/migotom/cell-centre-services/db"
+	"github.com/migotom/cell-centre-services/pkg/components/employee"
+	"github.com/migotom/cell-centre-services/pkg/components/event"
+	"github.com/migotom/cell-centre-services/pkg/components/event/archive"
+	"github.com/migotom/cell-centre-services/pkg/components/event/chain"
+	"github.com/migotom/cell-centre-services/pkg/components/event/encoding"
+	"github.com/migotom/cell-centre-services/pkg/components/event/repository"
+	"github.com/migotom/cell-centre-services/pkg/components/event/streaming"
+	"github.com/migotom/cell-centre-services/pkg/components/role"
+	"github.com/migotom/cell-centre-services/pkg/helpers"
+	"github.com/migotom/cell-centre-services/pkg/services/eventlogger"
+)
+
+const eventLoggerUsage = `Usage: cellcentre [options] eventlogger command
+
+Commands:
+  deadletters list [options]      list messages moved into dead letter channel
+  deadletters replay [options]    publish dead lettered messages back into their channels
+  replay [options]                feed history of channel into handler, e.g. to rebuild event log
+  snapshots create [options]      save snapshots of aggregates rebuilt from event log
+  snapshots prune [options]       remove all but the latest snapshots of aggregates
+  archive run [options]           move events older than archive_after into archive
+  archive restore [options]       store archived events back into event log
+  archive verify [options]        verify checksums of archive files
+  archive list [options]          list archive files
+  chain verify [options]          verify hash chains of channels against their signed checkpoints
+  chain checkpoint [options]      export signed checkpoints of heads of hash chains
+
+Replay handlers:
+  logger                          store events in event log, already stored events are skipped
+
+Aggregates:
+  employees                       employees rebuilt from employee events
+`
+
+var eventLoggerService = service{
+	name:      "eventlogger",
+	newConfig: func() helpers.Config { return &eventlogger.Config{} },
+	telemetry: func(c helpers.Config) telemetry {
+		config := c.(*eventlogger.Config)
+		return telemetry{config.Tracing, config.MetricsAddress, config.ShutdownTimeout.Duration}
+	},
+	serve: func(ctx context.Context, log *zap.Logger, config helpers.Config) {
+		serveEventLogger(ctx, log, config.(*eventlogger.Config))
+	},
+}
+
+// maintainEventLog runs maintenance command of event log given by arguments.
+func maintainEventLog(log *zap.Logger, config *eventlogger.Config, args []string) {
+	switch args[0] {
+	case "deadletters":
+		deadLetters(log, config, args[1:])
+	case "replay":
+		replay(log, config, args[1:])
+	case "snapshots":
+		snapshots(log, config, args[1:])
+	case "archive":
+		archiveEvents(log, config, args[1:])
+	case "chain":
+		chainEvents(log, config, args[1:])
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+}
+
+func serveEventLogger(ctx context.Context, log *zap.Logger, config *eventlogger.Config) {
+	dbClient, db, err := db.ConnectMongoDB(context.Background(), config.DatabaseAddress, config.DatabaseName)
+	if err != nil {
+		log.Fatal("Can't connect to database", zap.Error(err))
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), config.ShutdownTimeout.Duration)
+		defer cancel()
+		if err := dbClient.Disconnect(ctx); err != nil {
+			log.Fatal("Can't safely disconnect from database", zap.Error(err))
+		}
+	}()
+
+	if err := repository.CreateIndexes(context.Background(), db); err != nil {
+		log.Fatal("Can't create indexes of events", zap.Error(err))
+	}
+
+	var stopped sync.WaitGroup
+	defer stopped.Wait()
+
+	eventsStreaming, err := streaming.Connect(context.Background(), config.NATSConfig)
+	if err != nil {
+		log.Fatal("Failed to connect to NATS service", zap.Error(err))
+	}
+	defer eventsStreaming.Close()
+
+	// stored events are linked into hash chains of their channels
+	stopped.Add(1)
+	go func() {
+		defer stopped.Done()
+		newChainer(log, config, db, false).Run(ctx)
+	}()
+
+	eventLogger := eventlogger.NewEventLogger(
+		log,
+		config,
+		eventsStreaming,
+		repository.NewMongoEventRepository(db),
+		newEventRegistry(log),
+	)
+	eventLogger.Listen(ctx)
+}
+
+func deadLetters(log *zap.Logger, config *eventlogger.Config, args []string) {
+	if len(args) == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	flags := flag.NewFlagSet("deadletters "+args[0], flag.ExitOnError)
+	channel := flags.String("channel", "", "only dead letters of given channel")
+	sequences := flags.String("sequences", "", "only dead letters of given comma separated sequences of original messages")
+	idle := flags.Duration("idle", 2*time.Second, "stop reading dead letter channel after given time without new messages")
+	flags.Parse(args[1:])
+
+	filter := eventlogger.DeadLetterFilter{Channel: *channel}
+	for _, sequence := range strings.Split(*sequences, ",") {
+		if sequence == "" {
+			continue
+		}
+		parsed, err := strconv.ParseUint(sequence, 10, 64)
+		if err != nil {
+			log.Fatal("Invalid sequence", zap.String("sequence", sequence), zap.Error(err))
+		}
+		filter.Sequences = append(filter.Sequences, parsed)
+	}
+
+	eventsStreaming, err := streaming.Connect(context.Background(), config.NATSConfig)
+	if err != nil {
+		log.Fatal("Failed to connect to NATS service", zap.Error(err))
+	}
+	defer eventsStreaming.Close()
+
+	deadLetters := eventlogger.NewDeadLetters(log, config, eventsStreaming)
+	letters, err := deadLetters.Fetch(filter, *idle)
+	if err != nil {
+		log.Fatal("Can't fetch dead letters", zap.Error(err))
+	}
+
+	switch args[0] {
+	case "list":
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "CHANNEL\tSEQUENCE\tDELIVERIES\tFAILED AT\tSUBSCRIBER\tERROR")
+		for _, deadLetter := range letters {
+			failedAt, _ := ptypes.Timestamp(deadLetter.FailedAt)
+			fmt.Fprintf(w, "%s\t%d\t%d\t%s\t%s\t%s\n",
+				deadLetter.Channel,
+				deadLetter.Sequence,
+				deadLetter.Deliveries,
+				failedAt.Format(time.RFC3339),
+				deadLetter.Subscriber,
+				deadLetter.Error,
+			)
+		}
+		w.Flush()
+	case "replay":
+		if err := deadLetters.Replay(letters); err != nil {
+			log.Fatal("Can't replay dead letters", zap.Error(err))
+		}
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+}
+
+func replay(log *zap.Logger, config *eventlogger.Config, args []string) {
+	flags := flag.NewFlagSet("replay", flag.ExitOnError)
+	channel := flags.String("channel", "", "channel to replay (required)")
+	handler := flags.String("handler", "logger", "handler fed with replayed events")
+	sequence := flags.Uint64("sequence", 0, "start at message with given sequence number")
+	since := flags.String("since", "", "start at the first message stored at or after given RFC3339 time")
+	limit := flags.Float64("rate", 0, "maximum number of messages replayed per second, 0 disables limit")
+	dryRun := flags.Bool("dry-run", false, "only decode replayed messages without passing them to handler")
+	idle := flags.Duration("idle", 5*time.Second, "stop replay after given time without new messages")
+	progress := flags.Duration("progress", 10*time.Second, "interval of progress reports")
+	flags.Parse(args)
+
+	if *channel == "" {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	options := eventlogger.ReplayOptions{
+		Channel:       *channel,
+		Handler:       *handler,
+		StartSequence: *sequence,
+		Rate:          *limit,
+		DryRun:        *dryRun,
+		Idle:          *idle,
+		Progress:      *progress,
+	}
+	if *since != "" {
+		startTime, err := time.Parse(time.RFC3339, *since)
+		if err != nil {
+			log.Fatal("Invalid start time", zap.String("since", *since), zap.Error(err))
+		}
+		options.StartTime = startTime
+	}
+
+	dbClient, db, err := db.ConnectMongoDB(context.Background(), config.DatabaseAddress, config.DatabaseName)
+	if err != nil {
+		log.Fatal("Can't connect to database", zap.Error(err))
+	}
+	defer func() {
+		if err := dbClient.Disconnect(context.Background()); err != nil {
+			log.Fatal("Can't safely disconnect from database", zap.Error(err))
+		}
+	}()
+
+	eventsStreaming, err := streaming.Connect(context.Background(), config.NATSConfig)
+	if err != nil {
+		log.Fatal("Failed to connect to NATS service", zap.Error(err))
+	}
+	defer eventsStreaming.Close()
+
+	ctx, stop := helpers.SignalContext()
+	defer stop()
+
+	eventLogger := eventlogger.NewEventLogger(log, config, eventsStreaming, repository.NewMongoEventRepository(db), newEventRegistry(log))
+
+	replayer := eventlogger.NewReplayer(log, eventsStreaming)
+	replayer.Register("logger", eventLogger.LogEvent)
+
+	stats, err := replayer.Replay(ctx, options)
+	log.Info("Replay finished",
+		zap.String("channel", options.Channel),
+		zap.Int("replayed", stats.Replayed),
+		zap.Int("failed", stats.Failed),
+		zap.Uint64("lastSequence", stats.LastSequence),
+		zap.Bool("dryRun", options.DryRun),
+	)
+	if err != nil {
+		log.Fatal("Replay interrupted", zap.Error(err))
+	}
+}
+
+func snapshots(log *zap.Logger, config *eventlogger.Config, args []string) {
+	if len(args) == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	flags := flag.NewFlagSet("snapshots "+args[0], flag.ExitOnError)
+	aggregateType := flags.String("type", employee.AggregateType, "type of aggregates")
+	aggregateID := flags.String("id", "", "only aggregate of given ID, all aggregates of type when empty")
+	keep := flags.Int("keep", 1, "number of the latest snapshots kept of each aggregate by prune")
+	flags.Parse(args[1:])
+
+	newAggregate, ok := aggregates[*aggregateType]
+	if !ok {
+		log.Fatal("Unknown type of aggregates", zap.String("type", *aggregateType))
+	}
+
+	dbClient, db, err := db.ConnectMongoDB(context.Background(), config.DatabaseAddress, config.DatabaseName)
+	if err != nil {
+		log.Fatal("Can't connect to database", zap.Error(err))
+	}
+	defer func() {
+		if err := dbClient.Disconnect(context.Background()); err != nil {
+			log.Fatal("Can't safely disconnect from database", zap.Error(err))
+		}
+	}()
+
+	ctx := context.Background()
+	if err := repository.CreateSnapshotIndexes(ctx, db); err != nil {
+		log.Fatal("Can't create indexes of snapshots", zap.Error(err))
+	}
+	eventRepository := repository.NewMongoEventRepository(db)
+	if config.ArchiveDirectory != "" {
+		// aggregates are rebuilt including their archived events
+		eventRepository = archive.NewRepository(eventRepository, openArchive(log, config), newEventRegistry(log))
+	}
+	snapshotRepository := repository.NewMongoSnapshotRepository(db)
+
+	switch args[0] {
+	case "create":
+		ids := []string{*aggregateID}
+		if *aggregateID == "" {
+			if ids, err = eventRepository.AggregateIDs(ctx, *aggregateType); err != nil {
+				log.Fatal("Can't list aggregates", zap.Error(err))
+			}
+		}
+
+		store := event.NewSnapshotStore(eventRepository, snapshotRepository, 0)
+
+		var created, failed int
+		for _, id := range ids {
+			aggregate := newAggregate(id)
+			err := store.Load(ctx, aggregate)
+			if err == nil && aggregate.Sequence() > 0 {
+				err = store.Save(ctx, aggregate)
+			}
+			if err != nil {
+				log.Error("Can't create snapshot", zap.String("type", *aggregateType), zap.String("id", id), zap.Error(err))
+				failed++
+				continue
+			}
+			created++
+		}
+		log.Info("Snapshots created", zap.String("type", *aggregateType), zap.Int("created", created), zap.Int("failed", failed))
+		if failed > 0 {
+			os.Exit(1)
+		}
+	case "prune":
+		pruned, err := snapshotRepository.Prune(ctx, *aggregateType, *keep)
+		if err != nil {
+			log.Fatal("Can't prune snapshots", zap.Error(err))
+		}
+		log.Info("Snapshots pruned", zap.String("type", *aggregateType), zap.Int64("pruned", pruned))
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+}
+
+func archiveEvents(log *zap.Logger, config *eventlogger.Config, args []string) {
+	if len(args) == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	flags := flag.NewFlagSet("archive "+args[0], flag.ExitOnError)
+	before := flags.String("before", "", "archive events created before given RFC3339 time instead of archive_after ago")
+	channel := flags.String("channel", "", "only archive files of given channel")
+	from := flags.String("from", "", "only archive files of given day (YYYY-MM-DD) and later")
+	to := flags.String("to", "", "only archive files of given day (YYYY-MM-DD) and earlier")
+	keep := flags.Bool("keep", false, "keep restored files in archive")
+	flags.Parse(args[1:])
+
+	eventArchive := openArchive(log, config)
+	filter := archive.Filter{Channel: *channel, From: *from, To: *to}
+
+	switch args[0] {
+	case "list", "verify":
+		files, err := eventArchive.Files(filter)
+		if err != nil {
+			log.Fatal("Can't read manifest of archive", zap.Error(err))
+		}
+
+		if args[0] == "verify" {
+			var failed int
+			for _, file := range files {
+				if err := eventArchive.Verify(file); err != nil {
+					log.Error("Archive file failed verification", zap.String("path", file.Path), zap.Error(err))
+					failed++
+				}
+			}
+			log.Info("Archive verified", zap.Int("files", len(files)), zap.Int("failed", failed))
+			if failed > 0 {
+				os.Exit(1)
+			}
+			return
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "CHANNEL\tDAY\tPART\tEVENTS\tFORMAT\tARCHIVED AT\tPATH")
+		for _, file := range files {
+			fmt.Fprintf(w, "%s\t%s\t%d\t%d\t%s\t%s\t%s\n",
+				file.Channel,
+				file.Day,
+				file.Part,
+				file.Events,
+				file.Format,
+				file.ArchivedAt.Format(time.RFC3339),
+				file.Path,
+			)
+		}
+		w.Flush()
+		return
+	case "run", "restore":
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	dbClient, db, err := db.ConnectMongoDB(context.Background(), config.DatabaseAddress, config.DatabaseName)
+	if err != nil {
+		log.Fatal("Can't connect to database", zap.Error(err))
+	}
+	defer func() {
+		if err := dbClient.Disconnect(context.Background()); err != nil {
+			log.Fatal("Can't safely disconnect from database", zap.Error(err))
+		}
+	}()
+
+	ctx, stop := helpers.SignalContext()
+	defer stop()
+
+	archiver := eventlogger.NewArchiver(log, config, repository.NewMongoEventRepository(db), eventArchive, newEventRegistry(log))
+
+	if args[0] == "restore" {
+		stats, err := archiver.Restore(ctx, filter, *keep)
+		log.Info("Archived events restored",
+			zap.Int("files", stats.Files),
+			zap.Int("restored", stats.Restored),
+			zap.Int("duplicates", stats.Duplicates),
+		)
+		if err != nil {
+			log.Fatal("Restore interrupted", zap.Error(err))
+		}
+		return
+	}
+
+	if err := repository.CreateIndexes(ctx, db); err != nil {
+		log.Fatal("Can't create indexes of events", zap.Error(err))
+	}
+
+	cutoff := time.Now().Add(-config.ArchiveAfter.Duration)
+	if *before != "" {
+		if cutoff, err = time.Parse(time.RFC3339, *before); err != nil {
+			log.Fatal("Invalid archival time", zap.String("before", *before), zap.Error(err))
+		}
+	} else if config.ArchiveAfter.Duration <= 0 {
+		log.Fatal("Age of archived events isn't configured")
+	}
+
+	stats, err := archiver.Archive(ctx, cutoff)
+	log.Info("Archival finished",
+		zap.Time("before", cutoff),
+		zap.Int("archived", stats.Archived),
+		zap.Int64("removed", stats.Removed),
+		zap.Int("files", stats.Files),
+	)
+	if err != nil {
+		log.Fatal("Archival interrupted", zap.Error(err))
+	}
+}
+
+func chainEvents(log *zap.Logger, config *eventlogger.Config, args []string) {
+	if len(args) == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	flags := flag.NewFlagSet("chain "+args[0], flag.ExitOnError)
+	channel := flags.String("channel", "", "only chain of given channel, all subscribed channels when empty")
+	flags.Parse(args[1:])
+
+	channels := config.Subscribes
+	if *channel != "" {
+		channels = []string{*channel}
+	}
+
+	dbClient, db, err := db.ConnectMongoDB(context.Background(), config.DatabaseAddress, config.DatabaseName)
+	if err != nil {
+		log.Fatal("Can't connect to database", zap.Error(err))
+	}
+	defer func() {
+		if err := dbClient.Disconnect(context.Background()); err != nil {
+			log.Fatal("Can't safely disconnect from database", zap.Error(err))
+		}
+	}()
+
+	ctx := context.Background()
+
+	switch args[0] {
+	case "verify":
+		chainer := newChainer(log, config, db, true)
+
+		var verifyKey ed25519.PublicKey
+		if config.CheckpointVerifyKey != "" {
+			if verifyKey, err = chain.LoadPublicKey(config.CheckpointVerifyKey); err != nil {
+				log.Fatal("Can't load key verifying checkpoints", zap.Error(err))
+			}
+		} else {
+			log.Warn("Key verifying checkpoints isn't configured, only links of chains are verified")
+		}
+
+		var broken int
+		for _, channel := range channels {
+			report, err := chainer.Verify(ctx, channel, verifyKey)
+			if err != nil {
+				log.Fatal("Can't verify chain", zap.String("channel", channel), zap.Error(err))
+			}
+			fields := []zap.Field{
+				zap.String("channel", report.Channel),
+				zap.Uint64("from", report.From),
+				zap.Uint64("to", report.To),
+				zap.Int("verified", report.Verified),
+				zap.Uint64("archived", report.Archived),
+				zap.Int("checkpoints", report.Checkpoints),
+			}
+			if report.Break != nil {
+				broken++
+				log.Error("Chain is broken", append(fields,
+					zap.Uint64("position", report.Break.Position),
+					zap.String("eventID", report.Break.EventID),
+					zap.String("reason", report.Break.Reason),
+				)...)
+				continue
+			}
+			log.Info("Chain verified", fields...)
+		}
+		if broken > 0 {
+			os.Exit(1)
+		}
+	case "checkpoint":
+		chainer := newChainer(log, config, db, false)
+		for _, channel := range channels {
+			if _, err := chainer.Chain(ctx, channel); err != nil {
+				log.Fatal("Can't chain events", zap.String("channel", channel), zap.Error(err))
+			}
+			if _, err := chainer.Checkpoint(ctx, channel, ""); err != nil {
+				log.Fatal("Can't export checkpoint of chain", zap.String("channel", channel), zap.Error(err))
+			}
+		}
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+}
+
+// newChainer returns chainer of event log, checkpoints are disabled unless checkpoint file is configured. Chainer
+// only verifying chains doesn't need signing key. Chains start in archive once archive directory is configured.
+func newChainer(log *zap.Logger, config *eventlogger.Config, db *mongo.Database, verifyOnly bool) *eventlogger.Chainer {
+	var eventArchive *archive.Archive
+	if config.ArchiveDirectory != "" {
+		eventArchive = openArchive(log, config)
+	}
+	if config.CheckpointFile == "" {
+		return eventlogger.NewChainer(log, config, repository.NewMongoChainRepository(db), eventArchive, nil, nil)
+	}
+
+	var signingKey ed25519.PrivateKey
+	if !verifyOnly {
+		if config.CheckpointKey == "" {
+			log.Fatal("Key signing checkpoints isn't configured")
+		}
+		var err error
+		if signingKey, err = chain.LoadPrivateKey(config.CheckpointKey); err != nil {
+			log.Fatal("Can't load key signing checkpoints", zap.Error(err))
+		}
+	}
+	return eventlogger.NewChainer(log, config, repository.NewMongoChainRepository(db), eventArchive, chain.NewCheckpointFile(config.CheckpointFile), signingKey)
+}
+
+// openArchive opens archive of configured directory.
+func openArchive(log *zap.Logger, config *eventlogger.Config) *archive.Archive {
+	if config.ArchiveDirectory == "" {
+		log.Fatal("Archive directory isn't configured")
+	}
+	format, err := encoding.ParseFormat(config.ArchiveFormat)
+	if err != nil {
+		log.Fatal("Invalid format of archive", zap.Error(err))
+	}
+	eventArchive, err := archive.NewArchive(config.ArchiveDirectory, format)
+	if err != nil {
+		log.Fatal("Can't open archive", zap.Error(err))
+	}
+	return eventArchive
+}
+
+// aggregates are constructors of aggregates with snapshots, by their type.
+var aggregates = map[string]func(id string) event.Aggregate{
+	employee.AggregateType: func(id string) event.Aggregate { return employee.NewAggregate(id) },
+}
+
+// newEventRegistry returns registry of events decoded by logger.
+func newEventRegistry(log *zap.Logger) *event.Registry {
+	registry := event.NewRegistry()
+	if err := employee.RegisterEvents(registry); err != nil {
+		log.Fatal("Can't register events", zap.Error(err))
+	}
+	if err := role.RegisterEvents(registry); err != nil {
+		log.Fatal("Can't register events", zap.Error(err))
+	}
+	return registry
+}
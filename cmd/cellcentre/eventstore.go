@@ -0,0 +1,170 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/golang/protobuf/jsonpb"
+	"go.uber.org/zap"
+
+	"github.com/migotom/cell-centre-services/db"
+	authDelivery "github.com/migotom/cell-centre-services/pkg/components/auth/delivery/grpc"
+	directoryRepository "github.com/migotom/cell-centre-services/pkg/components/directory/repository"
+	"github.com/migotom/cell-centre-services/pkg/components/employee"
+	employeeDelivery "github.com/migotom/cell-centre-services/pkg/components/employee/delivery/grpc"
+	employeeRepository "github.com/migotom/cell-centre-services/pkg/components/employee/repository"
+	"github.com/migotom/cell-centre-services/pkg/components/event"
+	"github.com/migotom/cell-centre-services/pkg/components/event/archive"
+	"github.com/migotom/cell-centre-services/pkg/components/event/encoding"
+	eventRepository "github.com/migotom/cell-centre-services/pkg/components/event/repository"
+	"github.com/migotom/cell-centre-services/pkg/components/event/streaming"
+	"github.com/migotom/cell-centre-services/pkg/components/health"
+	healthDelivery "github.com/migotom/cell-centre-services/pkg/components/health/delivery/grpc"
+	"github.com/migotom/cell-centre-services/pkg/components/keystore"
+	keystoreRepository "github.com/migotom/cell-centre-services/pkg/components/keystore/repository"
+	"github.com/migotom/cell-centre-services/pkg/components/role"
+	roleRepository "github.com/migotom/cell-centre-services/pkg/components/role/repository"
+	"github.com/migotom/cell-centre-services/pkg/helpers"
+	"github.com/migotom/cell-centre-services/pkg/pb"
+	"github.com/migotom/cell-centre-services/pkg/services/eventstore"
+)
+
+var eventStoreService = service{
+	name:      "eventstore",
+	newConfig: func() helpers.Config { return &eventstore.Config{} },
+	telemetry: func(c helpers.Config) telemetry {
+		config := c.(*eventstore.Config)
+		return telemetry{config.Tracing, config.MetricsAddress, config.ShutdownTimeout.Duration}
+	},
+	serve: func(ctx context.Context, log *zap.Logger, config helpers.Config) {
+		withEventStore(log, config.(*eventstore.Config), true, func(eventStore *eventstore.EventStore) {
+			eventStore.Listen(ctx)
+		})
+	},
+}
+
+// exportEmployeeData writes JSON bundle of data held about employee selected by given arguments.
+func exportEmployeeData(log *zap.Logger, config *eventstore.Config, args []string) {
+	flags := flag.NewFlagSet("export", flag.ExitOnError)
+	id := flags.String("id", "", "ID of employee")
+	email := flags.String("email", "", "email of employee")
+	output := flags.String("output", "", "file of bundle")
+	flags.Parse(args)
+
+	if *id == "" && *email == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	withEventStore(log, config, false, func(eventStore *eventstore.EventStore) {
+		export, err := eventStore.ExportEmployeeData(context.Background(), &pb.EmployeeFilter{Id: *id, Email: *email})
+		if err != nil {
+			log.Fatal("Can't export employee", zap.Error(err))
+		}
+
+		out := os.Stdout
+		if *output != "" {
+			if out, err = os.OpenFile(*output, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600); err != nil {
+				log.Fatal("Can't create bundle", zap.Error(err))
+			}
+			defer out.Close()
+		}
+
+		marshaler := jsonpb.Marshaler{OrigName: true, Indent: "  "}
+		if err := marshaler.Marshal(out, export); err != nil {
+			log.Fatal("Can't write bundle", zap.Error(err))
+		}
+		fmt.Fprintln(out)
+		log.Info("Employee data exported", zap.String("employeeID", export.EmployeeId), zap.Int("events", len(export.Events)))
+	})
+}
+
+// withEventStore calls given function with event store, connections are closed once it returns. Event store
+// which isn't streamed doesn't connect to NATS and can't publish events.
+func withEventStore(log *zap.Logger, config *eventstore.Config, streamed bool, f func(eventStore *eventstore.EventStore)) {
+	dbClient, db, err := db.ConnectMongoDB(context.Background(), config.DatabaseAddress, config.DatabaseName)
+	if err != nil {
+		log.Fatal("Can't connect to database", zap.Error(err))
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), config.ShutdownTimeout.Duration)
+		defer cancel()
+		if err := dbClient.Disconnect(ctx); err != nil {
+			log.Fatal("Can't safely disconnect from database", zap.Error(err))
+		}
+	}()
+
+	dependencies := []health.Dependency{health.MongoDB(dbClient)}
+
+	var eventsStreaming event.Streaming
+	if streamed {
+		eventsStreaming, err = streaming.Connect(context.Background(), config.NATSConfig)
+		if err != nil {
+			log.Fatal("Failed to connect to NATS service", zap.Error(err))
+		}
+		// closed once pending publishes are drained
+		defer eventsStreaming.Close()
+		dependencies = append(dependencies, health.Streaming(eventsStreaming))
+
+		// events are numbered per aggregate to let consumers detect gaps and reordering
+		eventsStreaming = streaming.NewSequencedStreaming(eventsStreaming, eventRepository.NewMongoSequencer(db))
+	}
+
+	// events of roles are registered to be exported with data of employees who originated them
+	eventRegistry := event.NewRegistry()
+	if err := employee.RegisterEvents(eventRegistry); err != nil {
+		log.Fatal("Can't register events", zap.Error(err))
+	}
+	if err := role.RegisterEvents(eventRegistry); err != nil {
+		log.Fatal("Can't register events", zap.Error(err))
+	}
+
+	employeeRepository := employeeRepository.NewEmployeeRepository(db)
+	roleRepository := roleRepository.NewRoleRepository(db)
+	authDelivery := authDelivery.NewAuthenticateDelivery(log, employeeRepository)
+
+	events := eventRepository.NewMongoEventRepository(db)
+	if config.ArchiveDirectory != "" {
+		// aggregates are loaded and employees exported including their archived events
+		eventArchive, err := archive.NewArchive(config.ArchiveDirectory, encoding.JSON)
+		if err != nil {
+			log.Fatal("Can't open archive", zap.Error(err))
+		}
+		events = archive.NewRepository(events, eventArchive, eventRegistry)
+	}
+
+	var aggregates *event.SnapshotStore
+	if config.WriteModel == eventstore.EventsWriteModel {
+		if err := eventRepository.CreateIndexes(context.Background(), db); err != nil {
+			log.Fatal("Can't create indexes of events", zap.Error(err))
+		}
+		if err := eventRepository.CreateSnapshotIndexes(context.Background(), db); err != nil {
+			log.Fatal("Can't create indexes of snapshots", zap.Error(err))
+		}
+		aggregates = event.NewSnapshotStore(
+			events,
+			eventRepository.NewMongoSnapshotRepository(db),
+			uint64(config.SnapshotEvery),
+		)
+	}
+
+	f(eventstore.NewEventStore(
+		log,
+		config,
+		eventsStreaming,
+		eventRegistry,
+		authDelivery,
+		employeeRepository,
+		roleRepository,
+		employeeDelivery.EmployeeDeliveryOptions{
+			Aggregates: aggregates,
+			// employees are listed from directory read model kept by projector
+			Directory:     directoryRepository.NewDirectoryRepository(db),
+			PayloadCipher: employee.NewPayloadCipher(keystore.NewShredder(keystoreRepository.NewMongoKeyRepository(db))),
+			Events:        events,
+		},
+		healthDelivery.NewHealthDelivery(log, config.HealthCheckInterval.Duration, dependencies...),
+	))
+}
@@ -0,0 +1,199 @@
+// Command cellcentre serves services of cell centre and runs their maintenance commands.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/migotom/cell-centre-services/pkg/helpers"
+	"github.com/migotom/cell-centre-services/pkg/services/eventlogger"
+	"github.com/migotom/cell-centre-services/pkg/services/eventstore"
+	"github.com/migotom/cell-centre-services/pkg/services/projector"
+)
+
+const usage = `Usage: cellcentre [options] command
+
+Commands:
+  serve <service>|all [options]    serve given service, or all services in one process
+  config print <service> [options] print effective config of service, passwords of URLs are redacted
+  eventstore export [options]      write JSON bundle of data held about employee, for subject access requests
+    -id string                     ID of employee
+    -email string                  email of employee, deleted employees are found while directory lists them
+    -output string                 file of bundle (default standard output)
+  projector rebuild                drop employee directory and project whole history of subscribed channels,
+                                   projector shouldn't be served during rebuild
+  eventlogger <command>            maintain event log, "cellcentre eventlogger" lists its commands
+
+Services:
+  authenticator, eventstore, eventlogger, projector, restapi, webhooks
+
+Options:
+  --config string        config file of service (default <config-dir>/<service>/config.toml), can't be used by
+                         "serve all"
+  --config-dir string    directory of configs of services (default "/etc/cell-centre")
+
+Environment:
+  Options of config file are overridden by CELLCENTRE_<SERVICE>_<KEY> variables, where KEY is upper-cased key
+  of option and keys of tables are joined by underscore, e.g. CELLCENTRE_EVENTSTORE_NATS_URL or
+  CELLCENTRE_EVENTLOGGER_TRACING_SAMPLE_RATIO. Lists of strings are comma separated, arrays of tables can't
+  be overridden.
+`
+
+const (
+	defaultConfigDir = "/etc/cell-centre"
+	// envPrefix of variables overriding options of configs, followed by upper-cased name of service.
+	envPrefix = "CELLCENTRE_"
+)
+
+var (
+	configPath string
+	configDir  string
+)
+
+// services served by cellcentre, in order of their start by "serve all".
+var services = []service{
+	authenticatorService,
+	eventStoreService,
+	eventLoggerService,
+	projectorService,
+	webhooksService,
+	restAPIService,
+}
+
+func main() {
+	flag.StringVar(&configPath, "config", "", "config file of service")
+	flag.StringVar(&configDir, "config-dir", defaultConfigDir, "directory of configs of services")
+	flag.Usage = func() { fmt.Fprint(os.Stderr, usage) }
+	flag.Parse()
+
+	log, _ := zap.NewProduction()
+	defer log.Sync()
+
+	args := flag.Args()
+	if len(args) == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	switch args[0] {
+	case "serve":
+		selected := selectServices(args[1:])
+		configs := make([]helpers.Config, len(selected))
+		for i, service := range selected {
+			configs[i] = loadConfig(service)
+		}
+		serve(log, selected, configs)
+	case "config":
+		if len(args) < 2 || args[1] != "print" {
+			flag.Usage()
+			os.Exit(2)
+		}
+		printConfig(selectService(args[2:]))
+	case "eventstore":
+		if len(args) < 2 || args[1] != "export" {
+			flag.Usage()
+			os.Exit(2)
+		}
+		exportEmployeeData(log, loadConfig(eventStoreService).(*eventstore.Config), args[2:])
+	case "projector":
+		if len(args) != 2 || args[1] != "rebuild" {
+			flag.Usage()
+			os.Exit(2)
+		}
+		rebuildDirectory(log, loadConfig(projectorService).(*projector.Config))
+	case "eventlogger":
+		flag.Usage = func() { fmt.Fprint(os.Stderr, eventLoggerUsage) }
+		if len(args) < 2 {
+			flag.Usage()
+			os.Exit(2)
+		}
+		maintainEventLog(log, loadConfig(eventLoggerService).(*eventlogger.Config), args[1:])
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+}
+
+// selectServices returns services named by arguments, options of configs may follow name of service.
+func selectServices(args []string) []service {
+	if len(args) > 0 && args[0] == "all" {
+		parseOptions(args[1:])
+		if configPath != "" {
+			fail("--config selects config of single service, use --config-dir to serve all services")
+		}
+		return services
+	}
+	return []service{selectService(args)}
+}
+
+// selectService returns service named by arguments, options of configs may follow name of service.
+func selectService(args []string) service {
+	if len(args) == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	parseOptions(args[1:])
+
+	for _, service := range services {
+		if service.name == args[0] {
+			return service
+		}
+	}
+	fail("unknown service %q", args[0])
+	return service{}
+}
+
+func parseOptions(args []string) {
+	flag.CommandLine.Parse(args)
+	if flag.NArg() > 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+}
+
+// loadConfig returns config of service, process exits when config can't be read or is invalid.
+func loadConfig(service service) helpers.Config {
+	config, path := readConfig(service)
+	validateConfig(service, config, path)
+	return config
+}
+
+// printConfig writes effective config of service to standard output, process exits with error when config
+// is invalid.
+func printConfig(service service) {
+	config, path := readConfig(service)
+	if err := helpers.PrintConfig(os.Stdout, config); err != nil {
+		fail("can't print config of %s: %v", service.name, err)
+	}
+	validateConfig(service, config, path)
+}
+
+func readConfig(service service) (helpers.Config, string) {
+	path := configPath
+	if path == "" {
+		path = filepath.Join(configDir, service.name, "config.toml")
+	}
+
+	config := service.newConfig()
+	if err := helpers.LoadConfig(context.Background(), path, envPrefix+strings.ToUpper(service.name)+"_", config); err != nil {
+		fail("can't load config of %s: %v", service.name, err)
+	}
+	return config, path
+}
+
+func validateConfig(service service, config helpers.Config, path string) {
+	if err := config.Validate(); err != nil {
+		fail("invalid config of %s (%s):\n  %s", service.name, path, strings.ReplaceAll(err.Error(), "\n", "\n  "))
+	}
+}
+
+func fail(format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, "cellcentre: "+format+"\n", args...)
+	os.Exit(1)
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.uber.org/zap"
+
+	"github.com/migotom/cell-centre-services/db"
+	directoryRepository "github.com/migotom/cell-centre-services/pkg/components/directory/repository"
+	"github.com/migotom/cell-centre-services/pkg/components/employee"
+	"github.com/migotom/cell-centre-services/pkg/components/event"
+	"github.com/migotom/cell-centre-services/pkg/components/event/streaming"
+	"github.com/migotom/cell-centre-services/pkg/components/keystore"
+	keystoreRepository "github.com/migotom/cell-centre-services/pkg/components/keystore/repository"
+	"github.com/migotom/cell-centre-services/pkg/components/role"
+	roleRepository "github.com/migotom/cell-centre-services/pkg/components/role/repository"
+	"github.com/migotom/cell-centre-services/pkg/helpers"
+	"github.com/migotom/cell-centre-services/pkg/services/projector"
+)
+
+var projectorService = service{
+	name:      "projector",
+	newConfig: func() helpers.Config { return &projector.Config{} },
+	telemetry: func(c helpers.Config) telemetry {
+		config := c.(*projector.Config)
+		return telemetry{config.Tracing, config.MetricsAddress, config.ShutdownTimeout.Duration}
+	},
+	serve: func(ctx context.Context, log *zap.Logger, config helpers.Config) {
+		withProjector(log, config.(*projector.Config), func(directoryProjector *projector.Projector) {
+			directoryProjector.Listen(ctx)
+		})
+	},
+}
+
+// rebuildDirectory drops employee directory and projects whole history of subscribed channels.
+func rebuildDirectory(log *zap.Logger, config *projector.Config) {
+	ctx, stop := helpers.SignalContext()
+	defer stop()
+
+	withProjector(log, config, func(directoryProjector *projector.Projector) {
+		if err := directoryProjector.Rebuild(ctx); err != nil {
+			log.Fatal("Rebuild of employee directory failed", zap.Error(err))
+		}
+		log.Info("Employee directory rebuilt")
+	})
+}
+
+// withProjector calls given function with projector of employee directory, connections are closed once it returns.
+func withProjector(log *zap.Logger, config *projector.Config, f func(directoryProjector *projector.Projector)) {
+	dbClient, db, err := db.ConnectMongoDB(context.Background(), config.DatabaseAddress, config.DatabaseName)
+	if err != nil {
+		log.Fatal("Can't connect to database", zap.Error(err))
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), config.ShutdownTimeout.Duration)
+		defer cancel()
+		if err := dbClient.Disconnect(ctx); err != nil {
+			log.Fatal("Can't safely disconnect from database", zap.Error(err))
+		}
+	}()
+
+	if err := directoryRepository.CreateIndexes(context.Background(), db); err != nil {
+		log.Fatal("Can't create indexes of employee directory", zap.Error(err))
+	}
+
+	eventsStreaming, err := streaming.Connect(context.Background(), config.NATSConfig)
+	if err != nil {
+		log.Fatal("Failed to connect to NATS service", zap.Error(err))
+	}
+	defer eventsStreaming.Close()
+
+	f(newProjector(log, config, eventsStreaming, db))
+}
+
+func newProjector(log *zap.Logger, config *projector.Config, eventsStreaming event.Streaming, db *mongo.Database) *projector.Projector {
+	registry := event.NewRegistry()
+	if err := employee.RegisterEvents(registry); err != nil {
+		log.Fatal("Can't register events", zap.Error(err))
+	}
+	if err := role.RegisterEvents(registry); err != nil {
+		log.Fatal("Can't register events", zap.Error(err))
+	}
+
+	return projector.NewProjector(
+		log,
+		config,
+		eventsStreaming,
+		directoryRepository.NewDirectoryRepository(db),
+		roleRepository.NewRoleRepository(db),
+		registry,
+		employee.NewPayloadCipher(keystore.NewShredder(keystoreRepository.NewMongoKeyRepository(db))),
+	)
+}
@@ -0,0 +1,22 @@
+package main
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+
+	"github.com/migotom/cell-centre-services/pkg/helpers"
+	"github.com/migotom/cell-centre-services/pkg/services/restapi"
+)
+
+var restAPIService = service{
+	name:      "restapi",
+	newConfig: func() helpers.Config { return &restapi.Config{} },
+	telemetry: func(c helpers.Config) telemetry {
+		config := c.(*restapi.Config)
+		return telemetry{config.Tracing, config.MetricsAddress, config.ShutdownTimeout.Duration}
+	},
+	serve: func(ctx context.Context, log *zap.Logger, config helpers.Config) {
+		restapi.NewRESTAPI(log, config.(*restapi.Config)).Listen(ctx)
+	},
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/migotom/cell-centre-services/pkg/helpers"
+	"github.com/migotom/cell-centre-services/pkg/helpers/metrics"
+	"github.com/migotom/cell-centre-services/pkg/helpers/tracing"
+)
+
+// service served by cellcentre.
+type service struct {
+	name string
+	// newConfig returns empty config of service.
+	newConfig func() helpers.Config
+	// telemetry returns options of tracing and metrics of given config.
+	telemetry func(config helpers.Config) telemetry
+	// serve runs service until given context is done.
+	serve func(ctx context.Context, log *zap.Logger, config helpers.Config)
+}
+
+// telemetry options shared by configs of services.
+type telemetry struct {
+	tracing         tracing.Config
+	metricsAddress  string
+	shutdownTimeout time.Duration
+}
+
+// serve runs given services configured by given configs until process is asked to stop or any of them stops.
+// Services served together are traced as "cellcentre" by tracing of the first service configuring exporter.
+func serve(log *zap.Logger, services []service, configs []helpers.Config) {
+	ctx, stop := helpers.SignalContext()
+	defer stop()
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	name, traced := services[0].name, services[0].telemetry(configs[0])
+	if len(services) > 1 {
+		name = "cellcentre"
+		for i, service := range services {
+			if telemetry := service.telemetry(configs[i]); telemetry.tracing.Exporter != "" {
+				traced = telemetry
+				break
+			}
+		}
+	}
+
+	shutdownTracing, err := tracing.Setup(ctx, name, traced.tracing)
+	if err != nil {
+		log.Fatal("Can't set up tracing", zap.Error(err))
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), traced.shutdownTimeout)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			log.Error("Spans weren't exported", zap.Error(err))
+		}
+	}()
+
+	var stopped sync.WaitGroup
+	defer stopped.Wait()
+
+	// services share registry of metrics, it's served once at each configured address
+	servedMetrics := make(map[string]bool)
+	for i, service := range services {
+		telemetry := service.telemetry(configs[i])
+		if telemetry.metricsAddress == "" || servedMetrics[telemetry.metricsAddress] {
+			continue
+		}
+		servedMetrics[telemetry.metricsAddress] = true
+
+		stopped.Add(1)
+		go func() {
+			defer stopped.Done()
+			if err := metrics.Serve(ctx, telemetry.metricsAddress, telemetry.shutdownTimeout); err != nil {
+				log.Error("Metrics server stopped", zap.Error(err))
+			}
+		}()
+	}
+
+	for i := range services {
+		service, config := services[i], configs[i]
+		stopped.Add(1)
+		go func() {
+			defer stopped.Done()
+			// services are stopped together
+			defer cancel()
+			service.serve(ctx, log.With(zap.String("service", service.name)), config)
+		}()
+	}
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+
+	"github.com/migotom/cell-centre-services/db"
+	authDelivery "github.com/migotom/cell-centre-services/pkg/components/auth/delivery/grpc"
+	"github.com/migotom/cell-centre-services/pkg/components/employee"
+	employeeRepository "github.com/migotom/cell-centre-services/pkg/components/employee/repository"
+	"github.com/migotom/cell-centre-services/pkg/components/event/streaming"
+	"github.com/migotom/cell-centre-services/pkg/components/health"
+	healthDelivery "github.com/migotom/cell-centre-services/pkg/components/health/delivery/grpc"
+	"github.com/migotom/cell-centre-services/pkg/components/keystore"
+	keystoreRepository "github.com/migotom/cell-centre-services/pkg/components/keystore/repository"
+	webhookRepository "github.com/migotom/cell-centre-services/pkg/components/webhook/repository"
+	"github.com/migotom/cell-centre-services/pkg/helpers"
+	"github.com/migotom/cell-centre-services/pkg/services/webhooks"
+)
+
+var webhooksService = service{
+	name:      "webhooks",
+	newConfig: func() helpers.Config { return &webhooks.Config{} },
+	telemetry: func(c helpers.Config) telemetry {
+		config := c.(*webhooks.Config)
+		return telemetry{config.Tracing, config.MetricsAddress, config.ShutdownTimeout.Duration}
+	},
+	serve: func(ctx context.Context, log *zap.Logger, config helpers.Config) {
+		serveWebhooks(ctx, log, config.(*webhooks.Config))
+	},
+}
+
+func serveWebhooks(ctx context.Context, log *zap.Logger, config *webhooks.Config) {
+	dbClient, db, err := db.ConnectMongoDB(context.Background(), config.DatabaseAddress, config.DatabaseName)
+	if err != nil {
+		log.Fatal("Can't connect to database", zap.Error(err))
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), config.ShutdownTimeout.Duration)
+		defer cancel()
+		if err := dbClient.Disconnect(ctx); err != nil {
+			log.Fatal("Can't safely disconnect from database", zap.Error(err))
+		}
+	}()
+
+	if err := webhookRepository.CreateIndexes(context.Background(), db); err != nil {
+		log.Fatal("Can't create indexes of webhooks", zap.Error(err))
+	}
+
+	eventsStreaming, err := streaming.Connect(context.Background(), config.NATSConfig)
+	if err != nil {
+		log.Fatal("Failed to connect to NATS service", zap.Error(err))
+	}
+	defer eventsStreaming.Close()
+
+	authDelivery := authDelivery.NewAuthenticateDelivery(log, employeeRepository.NewEmployeeRepository(db))
+
+	webhooks := webhooks.NewWebhooks(
+		log,
+		config,
+		eventsStreaming,
+		authDelivery,
+		webhookRepository.NewWebhookRepository(db),
+		webhookRepository.NewAttemptRepository(db),
+		webhookRepository.NewParkingRepository(db),
+		employee.NewPayloadCipher(keystore.NewShredder(keystoreRepository.NewMongoKeyRepository(db))),
+		healthDelivery.NewHealthDelivery(log, config.HealthCheckInterval.Duration, health.MongoDB(dbClient), health.Streaming(eventsStreaming)),
+	)
+	webhooks.Listen(ctx)
+}
@@ -5,11 +5,15 @@ import (
 
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/migotom/cell-centre-services/pkg/helpers/metrics"
+	"github.com/migotom/cell-centre-services/pkg/helpers/tracing"
 )
 
-// ConnectMongoDB connects into MongoDB by given URI and database name.
+// ConnectMongoDB connects into MongoDB by given URI and database name, latency of commands is measured
+// and commands run within traced context are traced.
 func ConnectMongoDB(ctx context.Context, uri, dbname string) (*mongo.Client, *mongo.Database, error) {
-	dbClient, err := mongo.Connect(ctx, options.Client().ApplyURI(uri))
+	dbClient, err := mongo.Connect(ctx, options.Client().ApplyURI(uri).SetMonitor(tracing.MongoCommandMonitor(metrics.MongoCommandMonitor())))
 	if err != nil {
 		return nil, nil, err
 	}
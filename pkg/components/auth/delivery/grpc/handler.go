@@ -11,6 +11,7 @@ import (
 	"github.com/migotom/cell-centre-services/pkg/components/auth"
 	"github.com/migotom/cell-centre-services/pkg/components/employee"
 	"github.com/migotom/cell-centre-services/pkg/entities"
+	"github.com/migotom/cell-centre-services/pkg/helpers/correlation"
 	"github.com/migotom/cell-centre-services/pkg/pb"
 )
 
@@ -46,10 +47,19 @@ func (delivery *AuthenticateDelivery) DefaultInterceptor(ctx context.Context) (c
 
 // Authenticate gRPC handler that checks credentials and generates AuthResponse with token and claims.
 func (delivery *AuthenticateDelivery) Authenticate(ctx context.Context, request *pb.AuthRequest) (*pb.AuthResponse, error) {
+	token, err := delivery.authenticate(ctx, request)
+	observeAuth(operationAuthenticate, err)
+	if err != nil {
+		return &pb.AuthResponse{}, status.Errorf(codes.Unauthenticated, "Can't authenticate: %v", err)
+	}
+	return &pb.AuthResponse{Token: token}, nil
+}
+
+func (delivery *AuthenticateDelivery) authenticate(ctx context.Context, request *pb.AuthRequest) (string, error) {
 	if request == nil {
-		return &pb.AuthResponse{}, status.Errorf(codes.Unauthenticated, "Can't authenticate: %v", auth.AuthError{Reason: auth.ErrInvalidParameters})
+		return "", auth.AuthError{Reason: auth.ErrInvalidParameters}
 	}
-	delivery.log.Info("Authenticate request", zap.String("request by", request.GetLogin()))
+	correlation.Logger(ctx, delivery.log).Info("Authenticate request", zap.String("request by", request.GetLogin()))
 
 	var TokenClaimer entities.TokenClaimer
 
@@ -57,28 +67,30 @@ func (delivery *AuthenticateDelivery) Authenticate(ctx context.Context, request
 	case pb.AuthRequest_EMPLOYEE:
 		employee, err := delivery.repository.Get(context.Background(), &pb.EmployeeFilter{Email: request.GetLogin()})
 		if err != nil || !auth.ValidPassword(employee.Password, request.GetPassword()) {
-			return &pb.AuthResponse{}, status.Errorf(codes.Unauthenticated, "Can't authenticate: %v", auth.AuthError{Reason: auth.ErrInvalidCredentials})
+			return "", auth.AuthError{Reason: auth.ErrInvalidCredentials}
 		}
 		TokenClaimer = employee
 
 	default:
-		return &pb.AuthResponse{}, status.Errorf(codes.Unauthenticated, "Can't authenticate: %v", auth.AuthError{Reason: auth.ErrInvalidParameters})
+		return "", auth.AuthError{Reason: auth.ErrInvalidParameters}
 	}
 
 	token, err := auth.NewToken(TokenClaimer)
 	if err != nil {
-		return &pb.AuthResponse{}, status.Errorf(codes.Unauthenticated, "Can't authenticate: %v", auth.AuthError{Reason: auth.ErrDecryptionToken, Err: err})
+		return "", auth.AuthError{Reason: auth.ErrDecryptionToken, Err: err}
 	}
-	return &pb.AuthResponse{Token: token}, nil
+	return token, nil
 }
 
 func (delivery *AuthenticateDelivery) Validate(ctx context.Context, request *pb.ValidateRequest) (*pb.AuthResponse, error) {
-	delivery.log.Debug("Validate request", zap.Any("request", request))
+	correlation.Logger(ctx, delivery.log).Debug("Validate request", zap.Any("request", request))
 	return nil, nil
 }
 
 // ObtainClaimsFromMetadata obtains token claims from given context with gRPC metadata.
 func ObtainClaimsFromMetadata(ctx context.Context) (claims entities.TokenClaims, err error) {
+	defer func() { observeAuth(operationToken, err) }()
+
 	var authenticate string
 	if authenticate, err = fromMetadata(ctx); err != nil {
 		return entities.TokenClaims{}, err
@@ -91,6 +103,15 @@ func ObtainClaimsFromMetadata(ctx context.Context) (claims entities.TokenClaims,
 	return
 }
 
+// Authorize verifies that given claims have at least one of given roles.
+func Authorize(claims entities.TokenClaims, roles []string) (err error) {
+	if !claims.HasRole(roles) {
+		err = auth.AuthError{Reason: auth.ErrInsufficientRights}
+	}
+	observeAuth(operationAuthorize, err)
+	return err
+}
+
 // ObtainClaimsFromContext obtains token claims from given context with value.
 func ObtainClaimsFromContext(ctx context.Context) entities.TokenClaims {
 	claims, ok := ctx.Value(ContextKeyClaims).(entities.TokenClaims)
@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/dgrijalva/jwt-go"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"go.uber.org/zap"
@@ -142,3 +143,34 @@ func TestAuthenticate(t *testing.T) {
 		})
 	}
 }
+
+func TestAuthorize(t *testing.T) {
+	cases := []struct {
+		Name           string
+		Claims         entities.TokenClaims
+		ExpectedErr    string
+		ExpectedResult []string
+	}{
+		{
+			Name:           "Allowed role",
+			Claims:         entities.TokenClaims{Roles: []string{"admin"}},
+			ExpectedResult: []string{operationAuthorize, "success", ""},
+		},
+		{
+			Name:           "Missing role",
+			Claims:         entities.TokenClaims{Roles: []string{"serviceman"}},
+			ExpectedErr:    "Insufficient rights",
+			ExpectedResult: []string{operationAuthorize, "failure", "insufficient_rights"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			counter := authResults.WithLabelValues(tc.ExpectedResult...)
+			counted := testutil.ToFloat64(counter)
+
+			helpers.AssertErrors(t, tc.ExpectedErr, Authorize(tc.Claims, []string{"admin"}))
+			assert.Equal(t, counted+1, testutil.ToFloat64(counter))
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package grpc
+
+import (
+	"errors"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/migotom/cell-centre-services/pkg/components/auth"
+	"github.com/migotom/cell-centre-services/pkg/helpers/metrics"
+)
+
+// Operations of authentication counted by authResults.
+const (
+	operationAuthenticate = "authenticate"
+	operationToken        = "token"
+	operationAuthorize    = "authorize"
+)
+
+var authResults = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: metrics.Namespace,
+	Subsystem: "auth",
+	Name:      "results_total",
+	Help:      "Results of credentials authentication, token validation and roles authorization, failures by reason.",
+}, []string{"operation", "result", "reason"})
+
+// observeAuth counts result of authentication operation, reason of failure is taken from auth.AuthError.
+func observeAuth(operation string, err error) {
+	if err == nil {
+		authResults.WithLabelValues(operation, "success", "").Inc()
+		return
+	}
+
+	reason := "unknown"
+	var authErr auth.AuthError
+	if errors.As(err, &authErr) {
+		reason = authErr.Reason.String()
+	}
+	authResults.WithLabelValues(operation, "failure", reason).Inc()
+}
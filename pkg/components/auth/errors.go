@@ -15,6 +15,25 @@ const (
 	ErrInsufficientRights
 )
 
+// String returns short name of reason used to label metrics.
+func (reason AuthErrorReason) String() string {
+	switch reason {
+	case ErrInvalidParameters:
+		return "invalid_parameters"
+	case ErrInvalidCredentials:
+		return "invalid_credentials"
+	case ErrMissingToken:
+		return "missing_token"
+	case ErrInvalidToken:
+		return "invalid_token"
+	case ErrDecryptionToken:
+		return "decryption_token"
+	case ErrInsufficientRights:
+		return "insufficient_rights"
+	}
+	return "unknown"
+}
+
 type AuthError struct {
 	Reason AuthErrorReason
 	Err    error
@@ -0,0 +1,40 @@
+package directory
+
+import (
+	"context"
+	"errors"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/migotom/cell-centre-services/pkg/entities"
+)
+
+// ReadModel is name of employee directory read model.
+const ReadModel = "employee_directory"
+
+// ErrEntryNotFound is returned by repository when employee isn't listed by directory.
+var ErrEntryNotFound = errors.New("employee not found in directory")
+
+// Query of employee directory, empty fields don't filter entries. Entries are listed in order of their IDs
+// starting after given one.
+type Query struct {
+	Search string
+	Role   string
+	Team   string
+	Status string
+	After  primitive.ObjectID
+	Limit  int
+}
+
+// Repository of employee directory read model and checkpoints of its projection.
+type Repository interface {
+	Save(ctx context.Context, entry *entities.DirectoryEntry) error
+	Get(ctx context.Context, id primitive.ObjectID) (*entities.DirectoryEntry, error)
+	GetByEmail(ctx context.Context, email string) (*entities.DirectoryEntry, error)
+	RenameRole(ctx context.Context, role entities.Role) error
+	RemoveRole(ctx context.Context, role entities.Role) error
+	List(ctx context.Context, query Query) ([]*entities.DirectoryEntry, error)
+	Checkpoint(ctx context.Context, channel string) (uint64, error)
+	SetCheckpoint(ctx context.Context, channel string, sequence uint64) error
+	Drop(ctx context.Context) error
+}
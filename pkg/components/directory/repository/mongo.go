@@ -0,0 +1,186 @@
+package repository
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/migotom/cell-centre-services/pkg/components/directory"
+	"github.com/migotom/cell-centre-services/pkg/entities"
+)
+
+const (
+	collectionName            = directory.ReadModel
+	checkpointsCollectionName = "projection_checkpoints"
+)
+
+type directoryRepository struct {
+	DB *mongo.Database
+}
+
+// NewDirectoryRepository returns new employee directory MongoDB repository.
+func NewDirectoryRepository(db *mongo.Database) directory.Repository {
+	return &directoryRepository{
+		DB: db,
+	}
+}
+
+// CreateIndexes creates indexes of employee directory, text index covers names, emails and teams of employees.
+func CreateIndexes(ctx context.Context, db *mongo.Database) error {
+	_, err := db.Collection(collectionName).Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "name", Value: "text"},
+				{Key: "email", Value: "text"},
+				{Key: "team", Value: "text"},
+			},
+		},
+		{Keys: bson.D{{Key: "email", Value: 1}}},
+		{Keys: bson.D{{Key: "roles.name", Value: 1}}},
+		{Keys: bson.D{{Key: "team", Value: 1}}},
+		{Keys: bson.D{{Key: "status", Value: 1}}},
+	})
+	return err
+}
+
+// Save stores entry of employee, entry of the same ID is replaced.
+func (repository *directoryRepository) Save(ctx context.Context, entry *entities.DirectoryEntry) error {
+	collection := repository.DB.Collection(collectionName)
+
+	_, err := collection.ReplaceOne(ctx, bson.M{"_id": entry.ID}, entry, options.Replace().SetUpsert(true))
+	return err
+}
+
+// Get returns entry of employee by ID.
+func (repository *directoryRepository) Get(ctx context.Context, id primitive.ObjectID) (*entities.DirectoryEntry, error) {
+	return repository.fetchOne(ctx, bson.M{"_id": id})
+}
+
+// GetByEmail returns entry of active employee by email.
+func (repository *directoryRepository) GetByEmail(ctx context.Context, email string) (*entities.DirectoryEntry, error) {
+	return repository.fetchOne(ctx, bson.M{"email": email, "status": entities.ActiveEmployee})
+}
+
+func (repository *directoryRepository) fetchOne(ctx context.Context, filter bson.M) (*entities.DirectoryEntry, error) {
+	collection := repository.DB.Collection(collectionName)
+
+	var entry entities.DirectoryEntry
+	if err := collection.FindOne(ctx, filter).Decode(&entry); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, directory.ErrEntryNotFound
+		}
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// RenameRole sets new name of role held by employees.
+func (repository *directoryRepository) RenameRole(ctx context.Context, role entities.Role) error {
+	collection := repository.DB.Collection(collectionName)
+
+	_, err := collection.UpdateMany(ctx,
+		bson.M{"roles._id": role.ID},
+		bson.M{"$set": bson.M{"roles.$[role].name": role.Name}},
+		options.Update().SetArrayFilters(options.ArrayFilters{Filters: []interface{}{bson.M{"role._id": role.ID}}}),
+	)
+	return err
+}
+
+// RemoveRole removes role from employees holding it.
+func (repository *directoryRepository) RemoveRole(ctx context.Context, role entities.Role) error {
+	collection := repository.DB.Collection(collectionName)
+
+	_, err := collection.UpdateMany(ctx,
+		bson.M{"roles._id": role.ID},
+		bson.M{"$pull": bson.M{"roles": bson.M{"_id": role.ID}}},
+	)
+	return err
+}
+
+// List returns entries matching query in order of their IDs.
+func (repository *directoryRepository) List(ctx context.Context, query directory.Query) ([]*entities.DirectoryEntry, error) {
+	collection := repository.DB.Collection(collectionName)
+
+	filter := bson.M{}
+	if query.Search != "" {
+		filter["$text"] = bson.M{"$search": query.Search}
+	}
+	if query.Role != "" {
+		filter["roles.name"] = query.Role
+	}
+	if query.Team != "" {
+		filter["team"] = query.Team
+	}
+	if query.Status != "" {
+		filter["status"] = query.Status
+	}
+	if !query.After.IsZero() {
+		filter["_id"] = bson.M{"$gt": query.After}
+	}
+
+	findOptions := options.Find().SetSort(bson.M{"_id": 1})
+	if query.Limit > 0 {
+		findOptions.SetLimit(int64(query.Limit))
+	}
+
+	cursor, err := collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var entries []*entities.DirectoryEntry
+	for cursor.Next(ctx) {
+		var entry entities.DirectoryEntry
+		if err := cursor.Decode(&entry); err != nil {
+			return nil, err
+		}
+		entries = append(entries, &entry)
+	}
+	return entries, cursor.Err()
+}
+
+// Checkpoint returns sequence of the last message of channel projected into directory, zero when channel wasn't
+// projected yet.
+func (repository *directoryRepository) Checkpoint(ctx context.Context, channel string) (uint64, error) {
+	collection := repository.DB.Collection(checkpointsCollectionName)
+
+	var checkpoint struct {
+		Sequence uint64 `bson:"sequence"`
+	}
+	if err := collection.FindOne(ctx, bson.M{"_id": checkpointID(channel)}).Decode(&checkpoint); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return 0, nil
+		}
+		return 0, err
+	}
+	return checkpoint.Sequence, nil
+}
+
+// SetCheckpoint stores sequence of the last message of channel projected into directory.
+func (repository *directoryRepository) SetCheckpoint(ctx context.Context, channel string, sequence uint64) error {
+	collection := repository.DB.Collection(checkpointsCollectionName)
+
+	_, err := collection.UpdateOne(ctx,
+		bson.M{"_id": checkpointID(channel)},
+		bson.M{"$set": bson.M{"sequence": sequence}},
+		options.Update().SetUpsert(true),
+	)
+	return err
+}
+
+// Drop removes all entries of directory and checkpoints of its projection.
+func (repository *directoryRepository) Drop(ctx context.Context) error {
+	if _, err := repository.DB.Collection(collectionName).DeleteMany(ctx, bson.M{}); err != nil {
+		return err
+	}
+	_, err := repository.DB.Collection(checkpointsCollectionName).DeleteMany(ctx, bson.M{"_id": bson.M{"$regex": "^" + directory.ReadModel + "/"}})
+	return err
+}
+
+func checkpointID(channel string) string {
+	return directory.ReadModel + "/" + channel
+}
@@ -0,0 +1,200 @@
+package employee
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/migotom/cell-centre-services/pkg/components/event"
+	"github.com/migotom/cell-centre-services/pkg/entities"
+)
+
+// snapshotVersion is version of layout of employee snapshots.
+const snapshotVersion = 1
+
+var (
+	// ErrEmployeeExists is returned when employee is created again.
+	ErrEmployeeExists = errors.New("employee already exists")
+	// ErrEmployeeNotFound is returned when employee doesn't exist or was deleted.
+	ErrEmployeeNotFound = errors.New("employee doesn't exist")
+	// ErrEmployeeNotDeleted is returned when personal data of employee is erased before employee is deleted.
+	ErrEmployeeNotDeleted = errors.New("employee has to be deleted before erasure")
+)
+
+// Aggregate is employee state rebuilt from its events. Password of employee is not carried by events, so it
+// stays empty.
+type Aggregate struct {
+	id       string
+	sequence uint64
+
+	// Employee is current state, nil until employee is created and after it's deleted.
+	Employee *entities.Employee
+}
+
+type aggregateState struct {
+	Employee *entities.Employee `bson:"employee,omitempty"`
+}
+
+// NewAggregate returns aggregate of employee with given ID without applied events.
+func NewAggregate(id string) *Aggregate {
+	return &Aggregate{id: id}
+}
+
+// AggregateType returns type of employee aggregate.
+func (aggregate *Aggregate) AggregateType() string {
+	return AggregateType
+}
+
+// AggregateID returns ID of employee.
+func (aggregate *Aggregate) AggregateID() string {
+	return aggregate.id
+}
+
+// Sequence returns sequence of the last applied event.
+func (aggregate *Aggregate) Sequence() uint64 {
+	return aggregate.sequence
+}
+
+// SnapshotVersion returns version of layout of employee snapshots.
+func (aggregate *Aggregate) SnapshotVersion() uint32 {
+	return snapshotVersion
+}
+
+// Apply folds employee event into state.
+func (aggregate *Aggregate) Apply(e *entities.Event) error {
+	switch e.Type {
+	case entities.NewEmployeeEvent:
+		var employee entities.Employee
+		if err := event.DecodeData(e.Data, &employee); err != nil {
+			return err
+		}
+		aggregate.Employee = &employee
+	case entities.UpdateEmployeeEvent:
+		if aggregate.Employee == nil {
+			return fmt.Errorf("%v: %s", ErrEmployeeNotFound, aggregate.id)
+		}
+		var changes entities.EmployeeChanges
+		if err := event.DecodeData(e.Data, &changes); err != nil {
+			return err
+		}
+		aggregate.applyChanges(changes.Changes)
+		if !e.CreatedAt.IsZero() {
+			updatedAt := e.CreatedAt
+			aggregate.Employee.UpdatedAt = &updatedAt
+		}
+	case entities.DeleteEmployeeEvent:
+		aggregate.Employee = nil
+	case entities.EraseEmployeeEvent:
+		// personal data of deleted employee was erased, state stays empty
+	default:
+		return fmt.Errorf("unknown event %s", e.Type)
+	}
+
+	aggregate.sequence = e.Sequence
+	return nil
+}
+
+// ValidateNew checks invariants of employee created as aggregate, employee of the same ID can't be created
+// again and it needs email and at least one role.
+func (aggregate *Aggregate) ValidateNew(employee *entities.Employee) error {
+	if aggregate.sequence != 0 {
+		return ErrEmployeeExists
+	}
+	if employee.Email == "" {
+		return errors.New("missing email")
+	}
+	if len(employee.Roles) == 0 {
+		return errors.New("missing roles")
+	}
+	return nil
+}
+
+// ValidateUpdate checks that employee can be updated with given state.
+func (aggregate *Aggregate) ValidateUpdate(employee *entities.Employee) error {
+	if aggregate.Employee == nil {
+		return ErrEmployeeNotFound
+	}
+	if employee.Email == "" {
+		return errors.New("missing email")
+	}
+	return nil
+}
+
+// ValidateDelete checks that employee can be deleted.
+func (aggregate *Aggregate) ValidateDelete() error {
+	if aggregate.Employee == nil {
+		return ErrEmployeeNotFound
+	}
+	return nil
+}
+
+// ValidateErase checks that personal data of employee can be erased, only deleted employees are erased.
+func (aggregate *Aggregate) ValidateErase() error {
+	if aggregate.sequence == 0 {
+		return ErrEmployeeNotFound
+	}
+	if aggregate.Employee != nil {
+		return ErrEmployeeNotDeleted
+	}
+	return nil
+}
+
+// applyChanges sets new values of changed fields, redacted fields are not carried by events and are skipped.
+func (aggregate *Aggregate) applyChanges(changes []entities.FieldChange) {
+	for _, change := range changes {
+		if change.Redacted {
+			continue
+		}
+		switch change.Path {
+		case "email":
+			aggregate.Employee.Email = change.NewValue
+		case "name":
+			aggregate.Employee.Name = change.NewValue
+		case "phone":
+			aggregate.Employee.Phone = change.NewValue
+		case "team":
+			aggregate.Employee.Team = change.NewValue
+		case "roles":
+			aggregate.Employee.Roles = changedRoles(aggregate.Employee.Roles, change.NewValue)
+		}
+	}
+}
+
+// changedRoles returns roles of given comma separated names, roles already held keep their IDs.
+func changedRoles(roles []entities.Role, names string) []entities.Role {
+	held := make(map[string]entities.Role, len(roles))
+	for _, role := range roles {
+		held[role.Name] = role
+	}
+
+	var changed []entities.Role
+	for _, name := range strings.Split(names, ",") {
+		if name == "" {
+			continue
+		}
+		role, ok := held[name]
+		if !ok {
+			role = entities.Role{Name: name}
+		}
+		changed = append(changed, role)
+	}
+	return changed
+}
+
+// MarshalState encodes state of employee for snapshot.
+func (aggregate *Aggregate) MarshalState() (bson.Raw, error) {
+	return bson.Marshal(aggregateState{Employee: aggregate.Employee})
+}
+
+// UnmarshalState restores state of employee from snapshot covering events up to given sequence.
+func (aggregate *Aggregate) UnmarshalState(state bson.Raw, sequence uint64) error {
+	var decoded aggregateState
+	if err := bson.Unmarshal(state, &decoded); err != nil {
+		return err
+	}
+	aggregate.Employee = decoded.Employee
+	aggregate.sequence = sequence
+	return nil
+}
@@ -0,0 +1,178 @@
+package employee
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/migotom/cell-centre-services/pkg/entities"
+	"github.com/migotom/cell-centre-services/pkg/helpers"
+)
+
+// storedData returns data of event as read back from event log.
+func storedData(t *testing.T, data interface{}) interface{} {
+	raw, err := bson.Marshal(bson.M{"data": data})
+	assert.NoError(t, err)
+
+	var document struct {
+		Data interface{}
+	}
+	assert.NoError(t, bson.Unmarshal(raw, &document))
+	return document.Data
+}
+
+func TestAggregateApply(t *testing.T) {
+	id, _ := primitive.ObjectIDFromHex("5d3783ee28ae9468bc528907")
+	adminRole := entities.Role{ID: primitive.NewObjectID(), Name: "admin"}
+	updatedAt := time.Date(2019, 7, 24, 20, 26, 40, 0, time.UTC)
+
+	created := &entities.Event{
+		Type:     entities.NewEmployeeEvent,
+		Sequence: 1,
+		Data:     &entities.Employee{ID: id, Email: "john@page.com", Name: "John", Roles: []entities.Role{adminRole}},
+	}
+
+	cases := []struct {
+		Name             string
+		Events           []*entities.Event
+		ExpectedEmployee *entities.Employee
+		ExpectedSequence uint64
+		ExpectedErr      string
+	}{
+		{
+			Name:             "Created employee",
+			Events:           []*entities.Event{created},
+			ExpectedEmployee: &entities.Employee{ID: id, Email: "john@page.com", Name: "John", Roles: []entities.Role{adminRole}},
+			ExpectedSequence: 1,
+		},
+		{
+			Name: "Updated employee read from event log",
+			Events: []*entities.Event{
+				created,
+				{
+					Type:      entities.UpdateEmployeeEvent,
+					Sequence:  2,
+					CreatedAt: updatedAt,
+					Data: storedData(t, &entities.EmployeeChanges{ID: id, Changes: []entities.FieldChange{
+						{Path: "name", OldValue: "John", NewValue: "John Page"},
+						{Path: "roles", OldValue: "admin", NewValue: "admin,serviceman"},
+						{Path: "password", Redacted: true},
+					}}),
+				},
+			},
+			ExpectedEmployee: &entities.Employee{
+				ID:        id,
+				Email:     "john@page.com",
+				Name:      "John Page",
+				UpdatedAt: &updatedAt,
+				Roles:     []entities.Role{adminRole, {Name: "serviceman"}},
+			},
+			ExpectedSequence: 2,
+		},
+		{
+			Name: "Deleted employee",
+			Events: []*entities.Event{
+				created,
+				{Type: entities.DeleteEmployeeEvent, Sequence: 2, Data: &entities.Employee{ID: id}},
+			},
+			ExpectedSequence: 2,
+		},
+		{
+			Name: "Erased employee",
+			Events: []*entities.Event{
+				created,
+				{Type: entities.DeleteEmployeeEvent, Sequence: 2, Data: &entities.Employee{ID: id}},
+				{Type: entities.EraseEmployeeEvent, Sequence: 3, Data: &entities.Employee{ID: id}},
+			},
+			ExpectedSequence: 3,
+		},
+		{
+			Name: "Update of not existing employee",
+			Events: []*entities.Event{
+				{Type: entities.UpdateEmployeeEvent, Sequence: 1, Data: &entities.EmployeeChanges{ID: id}},
+			},
+			ExpectedErr: "employee doesn't exist: 5d3783ee28ae9468bc528907",
+		},
+		{
+			Name:        "Unknown event",
+			Events:      []*entities.Event{{Type: "RenameEmployee", Sequence: 1}},
+			ExpectedErr: "unknown event RenameEmployee",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			aggregate := NewAggregate(id.Hex())
+
+			var err error
+			for _, e := range tc.Events {
+				if err = aggregate.Apply(e); err != nil {
+					break
+				}
+			}
+
+			helpers.AssertErrors(t, tc.ExpectedErr, err)
+			if err != nil {
+				return
+			}
+			assert.Equal(t, tc.ExpectedEmployee, aggregate.Employee)
+			assert.Equal(t, tc.ExpectedSequence, aggregate.Sequence())
+		})
+	}
+}
+
+func TestAggregateValidateErase(t *testing.T) {
+	id := primitive.NewObjectID()
+	created := &entities.Event{Type: entities.NewEmployeeEvent, Sequence: 1, Data: &entities.Employee{ID: id, Email: "john@page.com"}}
+	deleted := &entities.Event{Type: entities.DeleteEmployeeEvent, Sequence: 2, Data: &entities.Employee{ID: id}}
+
+	cases := []struct {
+		Name        string
+		Events      []*entities.Event
+		ExpectedErr string
+	}{
+		{
+			Name:   "Deleted employee",
+			Events: []*entities.Event{created, deleted},
+		},
+		{
+			Name:        "Existing employee",
+			Events:      []*entities.Event{created},
+			ExpectedErr: "employee has to be deleted before erasure",
+		},
+		{
+			Name:        "Unknown employee",
+			ExpectedErr: "employee doesn't exist",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			aggregate := NewAggregate(id.Hex())
+			for _, e := range tc.Events {
+				assert.NoError(t, aggregate.Apply(e))
+			}
+
+			helpers.AssertErrors(t, tc.ExpectedErr, aggregate.ValidateErase())
+		})
+	}
+}
+
+func TestAggregateState(t *testing.T) {
+	id := primitive.NewObjectID()
+
+	aggregate := NewAggregate(id.Hex())
+	assert.NoError(t, aggregate.Apply(&entities.Event{
+		Type:     entities.NewEmployeeEvent,
+		Sequence: 3,
+		Data:     &entities.Employee{ID: id, Email: "john@page.com", Name: "John"},
+	}))
+
+	state, err := aggregate.MarshalState()
+	assert.NoError(t, err)
+
+	restored := NewAggregate(id.Hex())
+	assert.NoError(t, restored.UnmarshalState(state, aggregate.Sequence()))
+	assert.Equal(t, aggregate, restored)
+}
@@ -0,0 +1,86 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/migotom/cell-centre-services/pkg/components/directory"
+	"github.com/migotom/cell-centre-services/pkg/entities"
+	"github.com/migotom/cell-centre-services/pkg/pb"
+)
+
+const (
+	defaultPageSize = 50
+	maxPageSize     = 500
+)
+
+// ListEmployees gRPC handler lists employees matching request from employee directory read model. Active employees
+// are listed unless other status is requested, next page starts after ID returned as page token.
+func (delivery *EmployeeDelivery) ListEmployees(ctx context.Context, request *pb.ListEmployeesRequest) (*pb.ListEmployeesResponse, error) {
+	if delivery.directory == nil {
+		return nil, status.Errorf(codes.Unimplemented, "Can't list employees: employee directory is not available")
+	}
+
+	query, err := newDirectoryQuery(request)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "Can't list employees: %v", EmployeeDeliveryError{Reason: ErrInvalidListQuery, Err: err})
+	}
+
+	entries, err := delivery.directory.List(ctx, query)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "Can't list employees: %v", EmployeeDeliveryError{Reason: ErrInternal, Err: err})
+	}
+
+	var response pb.ListEmployeesResponse
+	for _, entry := range entries {
+		employee, err := delivery.employeePbFactory.NewFromDirectoryEntry(entry)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "%v", EmployeeDeliveryError{Reason: ErrInternal, Err: err})
+		}
+		response.Employees = append(response.Employees, employee)
+	}
+	if len(entries) == query.Limit {
+		response.NextPageToken = entries[len(entries)-1].ID.Hex()
+	}
+	return &response, nil
+}
+
+func newDirectoryQuery(request *pb.ListEmployeesRequest) (directory.Query, error) {
+	query := directory.Query{
+		Search: request.GetQuery(),
+		Role:   request.GetRole(),
+		Team:   request.GetTeam(),
+		Status: request.GetStatus(),
+		Limit:  int(request.GetPageSize()),
+	}
+
+	switch query.Status {
+	case "":
+		query.Status = entities.ActiveEmployee
+	case entities.ActiveEmployee, entities.DeletedEmployee:
+	default:
+		return query, errors.New("unknown status " + query.Status)
+	}
+
+	switch {
+	case query.Limit < 0:
+		return query, errors.New("negative page size")
+	case query.Limit == 0:
+		query.Limit = defaultPageSize
+	case query.Limit > maxPageSize:
+		query.Limit = maxPageSize
+	}
+
+	if request.GetPageToken() != "" {
+		after, err := primitive.ObjectIDFromHex(request.GetPageToken())
+		if err != nil {
+			return query, errors.New("invalid page token")
+		}
+		query.After = after
+	}
+	return query, nil
+}
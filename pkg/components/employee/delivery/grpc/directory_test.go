@@ -0,0 +1,124 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/zap"
+
+	"github.com/migotom/cell-centre-services/pkg/components/directory"
+	"github.com/migotom/cell-centre-services/pkg/entities"
+	"github.com/migotom/cell-centre-services/pkg/helpers"
+	"github.com/migotom/cell-centre-services/pkg/helpers/mocks"
+	"github.com/migotom/cell-centre-services/pkg/pb"
+)
+
+func TestListEmployees(t *testing.T) {
+	first, _ := primitive.ObjectIDFromHex("5d3783ee28ae9468bc528906")
+	second, _ := primitive.ObjectIDFromHex("5d3783ee28ae9468bc528907")
+	adminRole := entities.Role{ID: first, Name: "admin"}
+
+	cases := []struct {
+		Name              string
+		Request           pb.ListEmployeesRequest
+		ExpectedMockCalls func(*mocks.DirectoryRepositoryMock)
+		ExpectedResponse  *pb.ListEmployeesResponse
+		ExpectedErr       string
+	}{
+		{
+			Name:    "Active employees are listed by default",
+			Request: pb.ListEmployeesRequest{Query: "john", Team: "field"},
+			ExpectedMockCalls: func(d *mocks.DirectoryRepositoryMock) {
+				d.On("List", mock.Anything, directory.Query{Search: "john", Team: "field", Status: "active", Limit: 50}).
+					Return([]*entities.DirectoryEntry{
+						{ID: first, Email: "john@page.com", Team: "field", Status: "active", Roles: []entities.Role{adminRole}},
+					}, nil)
+			},
+			ExpectedResponse: &pb.ListEmployeesResponse{
+				Employees: []*pb.DirectoryEntry{
+					{Id: first.Hex(), Email: "john@page.com", Team: "field", Status: "active", Roles: []string{"admin"}},
+				},
+			},
+		},
+		{
+			Name:    "Full page returns token of next page",
+			Request: pb.ListEmployeesRequest{Role: "admin", PageSize: 2, PageToken: first.Hex()},
+			ExpectedMockCalls: func(d *mocks.DirectoryRepositoryMock) {
+				d.On("List", mock.Anything, directory.Query{Role: "admin", Status: "active", Limit: 2, After: first}).
+					Return([]*entities.DirectoryEntry{
+						{ID: first, Email: "john@page.com", Status: "active"},
+						{ID: second, Email: "jane@page.com", Status: "active"},
+					}, nil)
+			},
+			ExpectedResponse: &pb.ListEmployeesResponse{
+				Employees: []*pb.DirectoryEntry{
+					{Id: first.Hex(), Email: "john@page.com", Status: "active", Roles: []string{}},
+					{Id: second.Hex(), Email: "jane@page.com", Status: "active", Roles: []string{}},
+				},
+				NextPageToken: second.Hex(),
+			},
+		},
+		{
+			Name:    "Page size is limited",
+			Request: pb.ListEmployeesRequest{Status: "deleted", PageSize: 10000},
+			ExpectedMockCalls: func(d *mocks.DirectoryRepositoryMock) {
+				d.On("List", mock.Anything, directory.Query{Status: "deleted", Limit: 500}).Return([]*entities.DirectoryEntry(nil), nil)
+			},
+			ExpectedResponse: &pb.ListEmployeesResponse{},
+		},
+		{
+			Name:              "Unknown status",
+			Request:           pb.ListEmployeesRequest{Status: "retired"},
+			ExpectedMockCalls: func(d *mocks.DirectoryRepositoryMock) {},
+			ExpectedErr:       "rpc error: code = InvalidArgument desc = Can't list employees: Invalid list query (unknown status retired)",
+		},
+		{
+			Name:              "Invalid page token",
+			Request:           pb.ListEmployeesRequest{PageToken: "next"},
+			ExpectedMockCalls: func(d *mocks.DirectoryRepositoryMock) {},
+			ExpectedErr:       "rpc error: code = InvalidArgument desc = Can't list employees: Invalid list query (invalid page token)",
+		},
+		{
+			Name: "Read model failure",
+			ExpectedMockCalls: func(d *mocks.DirectoryRepositoryMock) {
+				d.On("List", mock.Anything, mock.Anything).Return([]*entities.DirectoryEntry(nil), errors.New("database down"))
+			},
+			ExpectedErr: "rpc error: code = Internal desc = Can't list employees: Internal error (database down)",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			log := zap.NewNop()
+			directoryRepositoryMock := mocks.DirectoryRepositoryMock{}
+
+			tc.ExpectedMockCalls(&directoryRepositoryMock)
+
+			delivery := NewEmployeeDelivery(
+				log,
+				&mocks.EmployeRepositoryMock{},
+				&mocks.RoleRepositoryMock{},
+				nil,
+				nil,
+				EmployeeDeliveryOptions{
+					Directory: &directoryRepositoryMock,
+				},
+			)
+			response, err := delivery.ListEmployees(context.Background(), &tc.Request)
+			helpers.AssertErrors(t, tc.ExpectedErr, err)
+
+			assert.Equal(t, tc.ExpectedResponse, response)
+			directoryRepositoryMock.AssertExpectations(t)
+		})
+	}
+}
+
+func TestListEmployeesWithoutDirectory(t *testing.T) {
+	delivery := NewEmployeeDelivery(zap.NewNop(), &mocks.EmployeRepositoryMock{}, &mocks.RoleRepositoryMock{}, nil, nil, EmployeeDeliveryOptions{})
+
+	_, err := delivery.ListEmployees(context.Background(), &pb.ListEmployeesRequest{})
+	helpers.AssertErrors(t, "rpc error: code = Unimplemented desc = Can't list employees: employee directory is not available", err)
+}
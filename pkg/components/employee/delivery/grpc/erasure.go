@@ -0,0 +1,77 @@
+package grpc
+
+import (
+	"context"
+
+	empty "github.com/golang/protobuf/ptypes/empty"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/migotom/cell-centre-services/pkg/components/employee"
+	"github.com/migotom/cell-centre-services/pkg/entities"
+	"github.com/migotom/cell-centre-services/pkg/helpers/correlation"
+	"github.com/migotom/cell-centre-services/pkg/pb"
+)
+
+// EraseEmployee gRPC handler erases personal data of deleted employee by destroying its data key, events of employee
+// keep their structure while its personal data reads as erased. Erasure is announced by event, so read models can
+// drop their copies of personal data.
+//
+// Event sourced erasure stores its event before data key is destroyed, erasure of already erased employee is
+// accepted, so failed request may be retried. Event of state write model is published once data key is destroyed,
+// its failed publish is logged and leaves personal data in employee directory until it's rebuilt with
+// `cellcentre projector rebuild`.
+func (delivery *EmployeeDelivery) EraseEmployee(ctx context.Context, filter *pb.EmployeeFilter) (*empty.Empty, error) {
+	if delivery.payloadCipher == nil {
+		return nil, status.Errorf(codes.Unimplemented, "Can't erase employee: personal data of events is not encrypted")
+	}
+	if _, err := primitive.ObjectIDFromHex(filter.GetId()); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "Can't erase employee: %v", EmployeeDeliveryError{Reason: ErrInvalidEmployeeData, Err: err})
+	}
+	id := filter.GetId()
+
+	if delivery.aggregates != nil {
+		aggregate, err := delivery.loadAggregate(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		switch err := aggregate.ValidateErase(); err {
+		case nil:
+		case employee.ErrEmployeeNotDeleted:
+			return nil, status.Errorf(codes.FailedPrecondition, "Can't erase employee: %v", EmployeeDeliveryError{Reason: ErrInvalidEmployeeData, Err: err})
+		default:
+			return nil, status.Errorf(codes.NotFound, "Can't erase employee: %v", EmployeeDeliveryError{Reason: ErrInvalidEmployeeData, Err: err})
+		}
+
+		e, err := delivery.appendEvent(ctx, aggregate, entities.EraseEmployeeEvent, &pb.EmployeeFilter{Id: id})
+		if err != nil {
+			return nil, err
+		}
+		delivery.publish(ctx, e)
+		if err := delivery.erase(ctx, id); err != nil {
+			return nil, err
+		}
+		return &empty.Empty{}, nil
+	}
+
+	if _, err := delivery.repository.Get(ctx, &pb.EmployeeFilter{Id: id}); err == nil {
+		return nil, status.Errorf(codes.FailedPrecondition, "Can't erase employee: %v", EmployeeDeliveryError{Reason: ErrInvalidEmployeeData, Err: employee.ErrEmployeeNotDeleted})
+	}
+	if err := delivery.erase(ctx, id); err != nil {
+		return nil, err
+	}
+
+	delivery.publishNew(ctx, entities.EraseEmployeeEvent, id, &pb.EmployeeFilter{Id: id})
+	return &empty.Empty{}, nil
+}
+
+// erase destroys data key of employee.
+func (delivery *EmployeeDelivery) erase(ctx context.Context, id string) error {
+	if err := delivery.payloadCipher.Erase(ctx, id); err != nil {
+		return status.Errorf(codes.Internal, "Can't erase employee: %v", EmployeeDeliveryError{Reason: ErrInternal, Err: err})
+	}
+	correlation.Logger(ctx, delivery.log).Info("Personal data of employee erased", zap.String("employeeID", id))
+	return nil
+}
@@ -0,0 +1,203 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	empty "github.com/golang/protobuf/ptypes/empty"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/zap"
+
+	"github.com/migotom/cell-centre-services/pkg/components/employee"
+	"github.com/migotom/cell-centre-services/pkg/components/event"
+	"github.com/migotom/cell-centre-services/pkg/components/keystore"
+	"github.com/migotom/cell-centre-services/pkg/entities"
+	"github.com/migotom/cell-centre-services/pkg/helpers"
+	"github.com/migotom/cell-centre-services/pkg/helpers/mocks"
+	"github.com/migotom/cell-centre-services/pkg/pb"
+)
+
+func TestEraseEmployee(t *testing.T) {
+	id, _ := primitive.ObjectIDFromHex("5d3783ee28ae9468bc528906")
+	created := &entities.Event{
+		Type:     entities.NewEmployeeEvent,
+		Sequence: 1,
+		Data:     &entities.Employee{ID: id, Email: "admin@page.com"},
+	}
+	deleted := &entities.Event{
+		Type:     entities.DeleteEmployeeEvent,
+		Sequence: 2,
+		Data:     &entities.Employee{ID: id},
+	}
+
+	erased := &entities.Event{
+		Type:     entities.EraseEmployeeEvent,
+		Sequence: 3,
+		Data:     &entities.Employee{ID: id},
+	}
+
+	history := func(s *mocks.SnapshotRepositoryMock, ev *mocks.EventRepositoryMock, events ...*entities.Event) {
+		s.On("Latest", mock.Anything, employee.AggregateType, id.Hex(), uint32(1)).Return((*entities.Snapshot)(nil), event.ErrSnapshotNotFound)
+		ev.On("Events", mock.Anything, employee.AggregateType, id.Hex(), uint64(0)).Return(events, nil)
+	}
+
+	cases := []struct {
+		Name              string
+		EventSourced      bool
+		Filter            *pb.EmployeeFilter
+		ExpectedMockCalls func(*mocks.EmployeRepositoryMock, *mocks.EventRepositoryMock, *mocks.SnapshotRepositoryMock, *mocks.KeyRepositoryMock)
+		ExpectedResult    *empty.Empty
+		ExpectedErr       string
+	}{
+		{
+			Name:         "Deleted employee is erased",
+			EventSourced: true,
+			Filter:       &pb.EmployeeFilter{Id: id.Hex()},
+			ExpectedMockCalls: func(e *mocks.EmployeRepositoryMock, ev *mocks.EventRepositoryMock, s *mocks.SnapshotRepositoryMock, k *mocks.KeyRepositoryMock) {
+				history(s, ev, created, deleted)
+				k.On("Destroy", mock.Anything, id.Hex()).Return(nil)
+				ev.On("New", mock.Anything, mock.MatchedBy(func(e *entities.Event) bool {
+					return e.Type == entities.EraseEmployeeEvent && e.Sequence == 3 && e.AggregateID == id.Hex()
+				})).Return(nil)
+			},
+			ExpectedResult: &empty.Empty{},
+		},
+		{
+			Name:         "Data key is kept when event isn't stored",
+			EventSourced: true,
+			Filter:       &pb.EmployeeFilter{Id: id.Hex()},
+			ExpectedMockCalls: func(e *mocks.EmployeRepositoryMock, ev *mocks.EventRepositoryMock, s *mocks.SnapshotRepositoryMock, k *mocks.KeyRepositoryMock) {
+				history(s, ev, created, deleted)
+				ev.On("New", mock.Anything, mock.Anything).Return(event.ErrSequenceConflict)
+			},
+			ExpectedErr: "rpc error: code = Aborted desc = Can't store event: Employee modified concurrently (other event of aggregate already stored at the same sequence)",
+		},
+		{
+			Name:         "Failed destroy of data key after event is stored",
+			EventSourced: true,
+			Filter:       &pb.EmployeeFilter{Id: id.Hex()},
+			ExpectedMockCalls: func(e *mocks.EmployeRepositoryMock, ev *mocks.EventRepositoryMock, s *mocks.SnapshotRepositoryMock, k *mocks.KeyRepositoryMock) {
+				history(s, ev, created, deleted)
+				ev.On("New", mock.Anything, mock.Anything).Return(nil)
+				k.On("Destroy", mock.Anything, id.Hex()).Return(errors.New("database down"))
+			},
+			ExpectedErr: "rpc error: code = Internal desc = Can't erase employee: Internal error (can't destroy data key: database down)",
+		},
+		{
+			Name:         "Erasure is retried",
+			EventSourced: true,
+			Filter:       &pb.EmployeeFilter{Id: id.Hex()},
+			ExpectedMockCalls: func(e *mocks.EmployeRepositoryMock, ev *mocks.EventRepositoryMock, s *mocks.SnapshotRepositoryMock, k *mocks.KeyRepositoryMock) {
+				history(s, ev, created, deleted, erased)
+				ev.On("New", mock.Anything, mock.MatchedBy(func(e *entities.Event) bool {
+					return e.Type == entities.EraseEmployeeEvent && e.Sequence == 4
+				})).Return(nil)
+				k.On("Destroy", mock.Anything, id.Hex()).Return(nil)
+			},
+			ExpectedResult: &empty.Empty{},
+		},
+		{
+			Name:         "Existing employee isn't erased",
+			EventSourced: true,
+			Filter:       &pb.EmployeeFilter{Id: id.Hex()},
+			ExpectedMockCalls: func(e *mocks.EmployeRepositoryMock, ev *mocks.EventRepositoryMock, s *mocks.SnapshotRepositoryMock, k *mocks.KeyRepositoryMock) {
+				history(s, ev, created)
+				k.On("Get", mock.Anything, id.Hex()).Return((*entities.DataKey)(nil), keystore.ErrKeyNotFound)
+			},
+			ExpectedErr: "rpc error: code = FailedPrecondition desc = Can't erase employee: Invalid employee data (employee has to be deleted before erasure)",
+		},
+		{
+			Name:         "Unknown employee",
+			EventSourced: true,
+			Filter:       &pb.EmployeeFilter{Id: id.Hex()},
+			ExpectedMockCalls: func(e *mocks.EmployeRepositoryMock, ev *mocks.EventRepositoryMock, s *mocks.SnapshotRepositoryMock, k *mocks.KeyRepositoryMock) {
+				history(s, ev)
+			},
+			ExpectedErr: "rpc error: code = NotFound desc = Can't erase employee: Invalid employee data (employee doesn't exist)",
+		},
+		{
+			Name:         "Invalid ID",
+			EventSourced: true,
+			Filter:       &pb.EmployeeFilter{Email: "admin@page.com"},
+			ExpectedMockCalls: func(*mocks.EmployeRepositoryMock, *mocks.EventRepositoryMock, *mocks.SnapshotRepositoryMock, *mocks.KeyRepositoryMock) {
+			},
+			ExpectedErr: "rpc error: code = InvalidArgument desc = Can't erase employee: Invalid employee data (the provided hex string is not a valid ObjectID)",
+		},
+		{
+			Name:   "Deleted employee is erased in state write model",
+			Filter: &pb.EmployeeFilter{Id: id.Hex()},
+			ExpectedMockCalls: func(e *mocks.EmployeRepositoryMock, ev *mocks.EventRepositoryMock, s *mocks.SnapshotRepositoryMock, k *mocks.KeyRepositoryMock) {
+				e.On("Get", mock.Anything, &pb.EmployeeFilter{Id: id.Hex()}).Return((*entities.Employee)(nil), errors.New("not found"))
+				k.On("Destroy", mock.Anything, id.Hex()).Return(nil)
+			},
+			ExpectedResult: &empty.Empty{},
+		},
+		{
+			Name:   "Existing employee isn't erased in state write model",
+			Filter: &pb.EmployeeFilter{Id: id.Hex()},
+			ExpectedMockCalls: func(e *mocks.EmployeRepositoryMock, ev *mocks.EventRepositoryMock, s *mocks.SnapshotRepositoryMock, k *mocks.KeyRepositoryMock) {
+				e.On("Get", mock.Anything, &pb.EmployeeFilter{Id: id.Hex()}).Return(&entities.Employee{ID: id}, nil)
+			},
+			ExpectedErr: "rpc error: code = FailedPrecondition desc = Can't erase employee: Invalid employee data (employee has to be deleted before erasure)",
+		},
+		{
+			Name:   "Failed destroy of data key",
+			Filter: &pb.EmployeeFilter{Id: id.Hex()},
+			ExpectedMockCalls: func(e *mocks.EmployeRepositoryMock, ev *mocks.EventRepositoryMock, s *mocks.SnapshotRepositoryMock, k *mocks.KeyRepositoryMock) {
+				e.On("Get", mock.Anything, &pb.EmployeeFilter{Id: id.Hex()}).Return((*entities.Employee)(nil), errors.New("not found"))
+				k.On("Destroy", mock.Anything, id.Hex()).Return(errors.New("database down"))
+			},
+			ExpectedErr: "rpc error: code = Internal desc = Can't erase employee: Internal error (can't destroy data key: database down)",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			log, _ := zap.NewProduction()
+			defer log.Sync()
+
+			employeeRepositoryMock := mocks.EmployeRepositoryMock{}
+			eventRepositoryMock := mocks.EventRepositoryMock{}
+			snapshotRepositoryMock := mocks.SnapshotRepositoryMock{}
+			keyRepositoryMock := mocks.KeyRepositoryMock{}
+
+			tc.ExpectedMockCalls(&employeeRepositoryMock, &eventRepositoryMock, &snapshotRepositoryMock, &keyRepositoryMock)
+
+			registry := event.NewRegistry()
+			assert.NoError(t, employee.RegisterEvents(registry))
+
+			var aggregates *event.SnapshotStore
+			if tc.EventSourced {
+				aggregates = event.NewSnapshotStore(&eventRepositoryMock, &snapshotRepositoryMock, 0)
+			}
+
+			delivery := NewEmployeeDelivery(
+				log,
+				&employeeRepositoryMock,
+				&mocks.RoleRepositoryMock{},
+				nil,
+				registry,
+				EmployeeDeliveryOptions{
+					Aggregates:    aggregates,
+					PayloadCipher: employee.NewPayloadCipher(keystore.NewShredder(&keyRepositoryMock)),
+				},
+			)
+			result, err := delivery.EraseEmployee(context.Background(), tc.Filter)
+			helpers.AssertErrors(t, tc.ExpectedErr, err)
+			assert.Equal(t, tc.ExpectedResult, result)
+
+			employeeRepositoryMock.AssertExpectations(t)
+			eventRepositoryMock.AssertExpectations(t)
+			keyRepositoryMock.AssertExpectations(t)
+		})
+	}
+}
+
+func TestEraseEmployeeWithoutEncryption(t *testing.T) {
+	delivery := NewEmployeeDelivery(zap.NewNop(), &mocks.EmployeRepositoryMock{}, &mocks.RoleRepositoryMock{}, nil, event.NewRegistry(), EmployeeDeliveryOptions{})
+
+	_, err := delivery.EraseEmployee(context.Background(), &pb.EmployeeFilter{Id: primitive.NewObjectID().Hex()})
+	helpers.AssertErrors(t, "rpc error: code = Unimplemented desc = Can't erase employee: personal data of events is not encrypted", err)
+}
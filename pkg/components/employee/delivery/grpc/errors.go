@@ -8,6 +8,8 @@ const (
 	ErrInvalidEmployeeData = iota
 	ErrInvalidEmployeeRoles
 	ErrInternal
+	ErrConcurrentModification
+	ErrInvalidListQuery
 )
 
 type EmployeeDeliveryError struct {
@@ -33,6 +35,12 @@ func (err EmployeeDeliveryError) description() string {
 	case ErrInternal:
 		return "Internal error"
 
+	case ErrConcurrentModification:
+		return "Employee modified concurrently"
+
+	case ErrInvalidListQuery:
+		return "Invalid list query"
+
 	default:
 		return "Unknown error"
 	}
@@ -0,0 +1,205 @@
+package grpc
+
+import (
+	"context"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	empty "github.com/golang/protobuf/ptypes/empty"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/migotom/cell-centre-services/pkg/components/employee"
+	"github.com/migotom/cell-centre-services/pkg/components/event"
+	"github.com/migotom/cell-centre-services/pkg/entities"
+	"github.com/migotom/cell-centre-services/pkg/helpers/correlation"
+	"github.com/migotom/cell-centre-services/pkg/pb"
+)
+
+// In event sourced write model commands are validated by employee aggregate rebuilt from event log, their events
+// are appended with expected version of aggregate and employee repository is updated as projection of appended
+// events. Password hashes aren't carried by events, they are kept by projection only.
+
+func (delivery *EmployeeDelivery) newEventSourcedEmployee(ctx context.Context, employeeEntity *entities.Employee) (*pb.Employee, error) {
+	aggregate, err := delivery.loadAggregate(ctx, employeeEntity.ID.Hex())
+	if err != nil {
+		return nil, err
+	}
+	if err := aggregate.ValidateNew(employeeEntity); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "Can't create new employee: %v", EmployeeDeliveryError{Reason: ErrInvalidEmployeeData, Err: err})
+	}
+	if _, err := delivery.repository.Get(ctx, &pb.EmployeeFilter{Email: employeeEntity.Email}); err == nil {
+		return nil, status.Errorf(codes.InvalidArgument, "Can't create new employee: %v", EmployeeDeliveryError{Reason: ErrInvalidEmployeeData, Err: employee.ErrEmployeeExists})
+	}
+
+	now := time.Now()
+	created := *employeeEntity
+	created.Password = ""
+	created.CreatedAt = &now
+	created.UpdatedAt = &now
+
+	payload, err := delivery.employeePbFactory.NewFromEmployee(&created)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "%v", EmployeeDeliveryError{Reason: ErrInternal, Err: err})
+	}
+	e, err := delivery.appendEvent(ctx, aggregate, entities.NewEmployeeEvent, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	employee, err := delivery.repository.New(ctx, employeeEntity)
+	if err != nil {
+		return nil, delivery.projectionError(ctx, e, err)
+	}
+	delivery.publish(ctx, e)
+
+	employee.Password = ""
+	return delivery.employeePbFactory.NewFromEmployee(employee)
+}
+
+func (delivery *EmployeeDelivery) updateEventSourcedEmployee(ctx context.Context, previous, employeeEntity *entities.Employee) (*pb.Employee, error) {
+	aggregate, err := delivery.loadAggregate(ctx, employeeEntity.ID.Hex())
+	if err != nil {
+		return nil, err
+	}
+	if aggregate.Employee == nil {
+		return nil, status.Errorf(codes.NotFound, "Can't update employee: %v", EmployeeDeliveryError{Reason: ErrInvalidEmployeeData, Err: employee.ErrEmployeeNotFound})
+	}
+
+	current := *aggregate.Employee
+	current.Password = previous.Password
+	updated := mergeEmployee(&current, employeeEntity)
+	if err := aggregate.ValidateUpdate(updated); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "Can't update employee: %v", EmployeeDeliveryError{Reason: ErrInvalidEmployeeData, Err: err})
+	}
+
+	changes := delivery.employeePbFactory.NewChanges(&current, updated)
+	if len(changes.Changes) == 0 {
+		return delivery.employeePbFactory.NewFromEmployee(previous)
+	}
+
+	e, err := delivery.appendEvent(ctx, aggregate, entities.UpdateEmployeeEvent, changes)
+	if err != nil {
+		return nil, err
+	}
+
+	employee, err := delivery.repository.Update(ctx, employeeEntity)
+	if err != nil {
+		return nil, delivery.projectionError(ctx, e, err)
+	}
+	delivery.publish(ctx, e)
+
+	return delivery.employeePbFactory.NewFromEmployee(employee)
+}
+
+func (delivery *EmployeeDelivery) deleteEventSourcedEmployee(ctx context.Context, filter *pb.EmployeeFilter) (*empty.Empty, error) {
+	id := filter.GetId()
+	if id == "" {
+		existing, err := delivery.repository.Get(ctx, filter)
+		if err != nil {
+			return nil, status.Errorf(codes.NotFound, "Can't delete employee: %v", EmployeeDeliveryError{Reason: ErrInvalidEmployeeData, Err: err})
+		}
+		id = existing.ID.Hex()
+	}
+
+	aggregate, err := delivery.loadAggregate(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := aggregate.ValidateDelete(); err != nil {
+		return nil, status.Errorf(codes.NotFound, "Can't delete employee: %v", EmployeeDeliveryError{Reason: ErrInvalidEmployeeData, Err: err})
+	}
+
+	e, err := delivery.appendEvent(ctx, aggregate, entities.DeleteEmployeeEvent, &pb.EmployeeFilter{Id: id, Email: filter.GetEmail()})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := delivery.repository.Delete(ctx, &pb.EmployeeFilter{Id: id}); err != nil {
+		return nil, delivery.projectionError(ctx, e, err)
+	}
+	delivery.publish(ctx, e)
+
+	return &empty.Empty{}, nil
+}
+
+// loadAggregate rebuilds employee aggregate of given ID.
+func (delivery *EmployeeDelivery) loadAggregate(ctx context.Context, id string) (*employee.Aggregate, error) {
+	aggregate := employee.NewAggregate(id)
+	if err := delivery.aggregates.Load(ctx, aggregate); err != nil {
+		return nil, status.Errorf(codes.Internal, "Can't load employee: %v", EmployeeDeliveryError{Reason: ErrInternal, Err: err})
+	}
+	if delivery.payloadCipher != nil {
+		// changes of employee are compared with its decrypted state
+		if err := delivery.payloadCipher.DecryptEmployee(ctx, aggregate.Employee); err != nil {
+			return nil, status.Errorf(codes.Internal, "Can't load employee: %v", EmployeeDeliveryError{Reason: ErrInternal, Err: err})
+		}
+	}
+	return aggregate, nil
+}
+
+// appendEvent appends event of aggregate expecting aggregate wasn't changed since it was loaded.
+func (delivery *EmployeeDelivery) appendEvent(ctx context.Context, aggregate *employee.Aggregate, eventType entities.EventType, payload proto.Message) (*pb.Event, error) {
+	e, err := delivery.newEvent(ctx, eventType, aggregate.AggregateID(), payload)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "Can't create event: %v", EmployeeDeliveryError{Reason: ErrInternal, Err: err})
+	}
+	// sequence of event is its expected version of aggregate, it's kept by published event
+	e.Sequence = aggregate.Sequence() + 1
+
+	entity, err := delivery.eventFactory.NewFromEvent(*e)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "Can't create event: %v", EmployeeDeliveryError{Reason: ErrInternal, Err: err})
+	}
+
+	switch err := delivery.aggregates.Append(ctx, aggregate, entity); err {
+	case nil:
+		return e, nil
+	case event.ErrSequenceConflict:
+		return nil, status.Errorf(codes.Aborted, "Can't store event: %v", EmployeeDeliveryError{Reason: ErrConcurrentModification, Err: err})
+	default:
+		return nil, status.Errorf(codes.Internal, "Can't store event: %v", EmployeeDeliveryError{Reason: ErrInternal, Err: err})
+	}
+}
+
+// publish publishes appended event, event that failed to be published stays in event log.
+func (delivery *EmployeeDelivery) publish(ctx context.Context, e *pb.Event) {
+	delivery.async(ctx, func(ctx context.Context) {
+		if delivery.eventsStreaming == nil {
+			return
+		}
+		delivery.send(ctx, e)
+	})
+}
+
+// projectionError reports failed update of employee repository after event was appended.
+func (delivery *EmployeeDelivery) projectionError(ctx context.Context, e *pb.Event, err error) error {
+	correlation.Logger(ctx, delivery.log).Error("Can't project stored event", zap.String("eventID", e.EventId), zap.Error(err))
+	delivery.publish(ctx, e)
+	return status.Errorf(codes.Internal, "Can't update employees: %v", EmployeeDeliveryError{Reason: ErrInternal, Err: err})
+}
+
+// mergeEmployee returns current employee with fields set by update request, empty fields aren't changed.
+func mergeEmployee(current, request *entities.Employee) *entities.Employee {
+	merged := *current
+	if request.Email != "" {
+		merged.Email = request.Email
+	}
+	if request.Password != "" {
+		merged.Password = request.Password
+	}
+	if request.Name != "" {
+		merged.Name = request.Name
+	}
+	if request.Phone != "" {
+		merged.Phone = request.Phone
+	}
+	if request.Team != "" {
+		merged.Team = request.Team
+	}
+	if len(request.Roles) > 0 {
+		merged.Roles = request.Roles
+	}
+	return &merged
+}
@@ -0,0 +1,247 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	empty "github.com/golang/protobuf/ptypes/empty"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/zap"
+
+	"github.com/migotom/cell-centre-services/pkg/components/employee"
+	"github.com/migotom/cell-centre-services/pkg/components/event"
+	"github.com/migotom/cell-centre-services/pkg/entities"
+	"github.com/migotom/cell-centre-services/pkg/helpers"
+	"github.com/migotom/cell-centre-services/pkg/helpers/mocks"
+	"github.com/migotom/cell-centre-services/pkg/pb"
+)
+
+func TestEventSourcedEmployee(t *testing.T) {
+	id, _ := primitive.ObjectIDFromHex("5d3783ee28ae9468bc528906")
+	previous := &entities.Employee{
+		ID:    id,
+		Email: "admin@page.com",
+		Roles: []entities.Role{{ID: id, Name: "admin"}},
+	}
+	created := &entities.Event{
+		Type:     entities.NewEmployeeEvent,
+		Sequence: 1,
+		Data:     &entities.Employee{ID: id, Email: "admin@page.com", Roles: []entities.Role{{ID: id, Name: "admin"}}},
+	}
+	deleted := &entities.Event{
+		Type:     entities.DeleteEmployeeEvent,
+		Sequence: 2,
+		Data:     &entities.Employee{ID: id},
+	}
+
+	appended := func(eventType entities.EventType, sequence uint64) interface{} {
+		return mock.MatchedBy(func(e *entities.Event) bool {
+			return e.Type == eventType && e.Sequence == sequence && e.AggregateID == id.Hex()
+		})
+	}
+	history := func(s *mocks.SnapshotRepositoryMock, ev *mocks.EventRepositoryMock, events ...*entities.Event) {
+		s.On("Latest", mock.Anything, employee.AggregateType, id.Hex(), uint32(1)).Return((*entities.Snapshot)(nil), event.ErrSnapshotNotFound)
+		ev.On("Events", mock.Anything, employee.AggregateType, id.Hex(), uint64(0)).Return(events, nil)
+	}
+
+	cases := []struct {
+		Name              string
+		Command           func(context.Context, *EmployeeDelivery) (interface{}, error)
+		ExpectedMockCalls func(*mocks.EmployeRepositoryMock, *mocks.EventRepositoryMock, *mocks.SnapshotRepositoryMock)
+		ExpectedResult    interface{}
+		ExpectedErr       string
+	}{
+		{
+			Name: "Update appends changes of employee",
+			Command: func(ctx context.Context, delivery *EmployeeDelivery) (interface{}, error) {
+				return delivery.UpdateEmployee(ctx, &pb.UpdateEmployeeRequest{Id: id.Hex(), Email: "root@page.com"})
+			},
+			ExpectedMockCalls: func(e *mocks.EmployeRepositoryMock, ev *mocks.EventRepositoryMock, s *mocks.SnapshotRepositoryMock) {
+				e.On("Get", mock.Anything, &pb.EmployeeFilter{Id: id.Hex()}).Return(previous, nil)
+				history(s, ev, created)
+				ev.On("New", mock.Anything, appended(entities.UpdateEmployeeEvent, 2)).Return(nil)
+				e.On("Update", mock.Anything, &entities.Employee{ID: id, Email: "root@page.com"}).
+					Return(&entities.Employee{ID: id, Email: "root@page.com"}, nil)
+			},
+			ExpectedResult: &pb.Employee{Id: id.Hex(), Email: "root@page.com"},
+		},
+		{
+			Name: "Concurrent update is rejected",
+			Command: func(ctx context.Context, delivery *EmployeeDelivery) (interface{}, error) {
+				return delivery.UpdateEmployee(ctx, &pb.UpdateEmployeeRequest{Id: id.Hex(), Email: "root@page.com"})
+			},
+			ExpectedMockCalls: func(e *mocks.EmployeRepositoryMock, ev *mocks.EventRepositoryMock, s *mocks.SnapshotRepositoryMock) {
+				e.On("Get", mock.Anything, &pb.EmployeeFilter{Id: id.Hex()}).Return(previous, nil)
+				history(s, ev, created)
+				ev.On("New", mock.Anything, appended(entities.UpdateEmployeeEvent, 2)).Return(event.ErrSequenceConflict)
+			},
+			ExpectedResult: (*pb.Employee)(nil),
+			ExpectedErr:    "rpc error: code = Aborted desc = Can't store event: Employee modified concurrently (other event of aggregate already stored at the same sequence)",
+		},
+		{
+			Name: "Update without changes isn't appended",
+			Command: func(ctx context.Context, delivery *EmployeeDelivery) (interface{}, error) {
+				return delivery.UpdateEmployee(ctx, &pb.UpdateEmployeeRequest{Id: id.Hex(), Email: "admin@page.com"})
+			},
+			ExpectedMockCalls: func(e *mocks.EmployeRepositoryMock, ev *mocks.EventRepositoryMock, s *mocks.SnapshotRepositoryMock) {
+				e.On("Get", mock.Anything, &pb.EmployeeFilter{Id: id.Hex()}).Return(previous, nil)
+				history(s, ev, created)
+			},
+			ExpectedResult: &pb.Employee{Id: id.Hex(), Email: "admin@page.com", Roles: []*pb.Role{{Id: id.Hex(), Name: "admin"}}},
+		},
+		{
+			Name: "Update of deleted employee",
+			Command: func(ctx context.Context, delivery *EmployeeDelivery) (interface{}, error) {
+				return delivery.UpdateEmployee(ctx, &pb.UpdateEmployeeRequest{Id: id.Hex(), Email: "root@page.com"})
+			},
+			ExpectedMockCalls: func(e *mocks.EmployeRepositoryMock, ev *mocks.EventRepositoryMock, s *mocks.SnapshotRepositoryMock) {
+				e.On("Get", mock.Anything, &pb.EmployeeFilter{Id: id.Hex()}).Return(previous, nil)
+				history(s, ev, created, deleted)
+			},
+			ExpectedResult: (*pb.Employee)(nil),
+			ExpectedErr:    "rpc error: code = NotFound desc = Can't update employee: Invalid employee data (employee doesn't exist)",
+		},
+		{
+			Name: "Delete by email appends event of employee",
+			Command: func(ctx context.Context, delivery *EmployeeDelivery) (interface{}, error) {
+				return delivery.DeleteEmployee(ctx, &pb.EmployeeFilter{Email: "admin@page.com"})
+			},
+			ExpectedMockCalls: func(e *mocks.EmployeRepositoryMock, ev *mocks.EventRepositoryMock, s *mocks.SnapshotRepositoryMock) {
+				e.On("Get", mock.Anything, &pb.EmployeeFilter{Email: "admin@page.com"}).Return(previous, nil)
+				history(s, ev, created)
+				ev.On("New", mock.Anything, appended(entities.DeleteEmployeeEvent, 2)).Return(nil)
+				e.On("Delete", mock.Anything, &pb.EmployeeFilter{Id: id.Hex()}).Return(nil)
+			},
+			ExpectedResult: &empty.Empty{},
+		},
+		{
+			Name: "Delete of deleted employee",
+			Command: func(ctx context.Context, delivery *EmployeeDelivery) (interface{}, error) {
+				return delivery.DeleteEmployee(ctx, &pb.EmployeeFilter{Id: id.Hex()})
+			},
+			ExpectedMockCalls: func(e *mocks.EmployeRepositoryMock, ev *mocks.EventRepositoryMock, s *mocks.SnapshotRepositoryMock) {
+				history(s, ev, created, deleted)
+			},
+			ExpectedErr: "rpc error: code = NotFound desc = Can't delete employee: Invalid employee data (employee doesn't exist)",
+		},
+		{
+			Name: "Failed projection is reported",
+			Command: func(ctx context.Context, delivery *EmployeeDelivery) (interface{}, error) {
+				return delivery.DeleteEmployee(ctx, &pb.EmployeeFilter{Id: id.Hex()})
+			},
+			ExpectedMockCalls: func(e *mocks.EmployeRepositoryMock, ev *mocks.EventRepositoryMock, s *mocks.SnapshotRepositoryMock) {
+				history(s, ev, created)
+				ev.On("New", mock.Anything, appended(entities.DeleteEmployeeEvent, 2)).Return(nil)
+				e.On("Delete", mock.Anything, &pb.EmployeeFilter{Id: id.Hex()}).Return(errors.New("database down"))
+			},
+			ExpectedErr: "rpc error: code = Internal desc = Can't update employees: Internal error (database down)",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			log, _ := zap.NewProduction()
+			defer log.Sync()
+
+			employeeRepositoryMock := mocks.EmployeRepositoryMock{}
+			eventRepositoryMock := mocks.EventRepositoryMock{}
+			snapshotRepositoryMock := mocks.SnapshotRepositoryMock{}
+
+			tc.ExpectedMockCalls(&employeeRepositoryMock, &eventRepositoryMock, &snapshotRepositoryMock)
+
+			registry := event.NewRegistry()
+			assert.NoError(t, employee.RegisterEvents(registry))
+
+			delivery := NewEmployeeDelivery(
+				log,
+				&employeeRepositoryMock,
+				&mocks.RoleRepositoryMock{},
+				nil,
+				registry,
+				EmployeeDeliveryOptions{
+					Aggregates: event.NewSnapshotStore(&eventRepositoryMock, &snapshotRepositoryMock, 0),
+				},
+			)
+			result, err := tc.Command(context.Background(), delivery)
+			helpers.AssertErrors(t, tc.ExpectedErr, err)
+			if tc.ExpectedResult != nil {
+				assert.Equal(t, tc.ExpectedResult, result)
+			}
+
+			employeeRepositoryMock.AssertExpectations(t)
+			eventRepositoryMock.AssertExpectations(t)
+		})
+	}
+}
+
+func TestNewEventSourcedEmployee(t *testing.T) {
+	cases := []struct {
+		Name              string
+		ExpectedMockCalls func(*mocks.EmployeRepositoryMock, *mocks.EventRepositoryMock, *mocks.SnapshotRepositoryMock)
+		ExpectedErr       string
+	}{
+		{
+			Name: "New employee is appended at the first sequence",
+			ExpectedMockCalls: func(e *mocks.EmployeRepositoryMock, ev *mocks.EventRepositoryMock, s *mocks.SnapshotRepositoryMock) {
+				s.On("Latest", mock.Anything, employee.AggregateType, mock.Anything, uint32(1)).Return((*entities.Snapshot)(nil), event.ErrSnapshotNotFound)
+				ev.On("Events", mock.Anything, employee.AggregateType, mock.Anything, uint64(0)).Return([]*entities.Event(nil), nil)
+				e.On("Get", mock.Anything, &pb.EmployeeFilter{Email: "admin@page.com"}).Return((*entities.Employee)(nil), errors.New("not found"))
+				ev.On("New", mock.Anything, mock.MatchedBy(func(e *entities.Event) bool {
+					employee, ok := e.Data.(*entities.Employee)
+					return e.Type == entities.NewEmployeeEvent && e.Sequence == 1 && ok && employee.Password == ""
+				})).Return(nil)
+				e.On("New", mock.Anything, mock.MatchedBy(func(employee *entities.Employee) bool {
+					return employee.Password != ""
+				})).Return(&entities.Employee{Email: "admin@page.com"}, nil)
+			},
+		},
+		{
+			Name: "Email of other employee",
+			ExpectedMockCalls: func(e *mocks.EmployeRepositoryMock, ev *mocks.EventRepositoryMock, s *mocks.SnapshotRepositoryMock) {
+				s.On("Latest", mock.Anything, employee.AggregateType, mock.Anything, uint32(1)).Return((*entities.Snapshot)(nil), event.ErrSnapshotNotFound)
+				ev.On("Events", mock.Anything, employee.AggregateType, mock.Anything, uint64(0)).Return([]*entities.Event(nil), nil)
+				e.On("Get", mock.Anything, &pb.EmployeeFilter{Email: "admin@page.com"}).Return(&entities.Employee{Email: "admin@page.com"}, nil)
+			},
+			ExpectedErr: "rpc error: code = InvalidArgument desc = Can't create new employee: Invalid employee data (employee already exists)",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			log, _ := zap.NewProduction()
+			defer log.Sync()
+
+			employeeRepositoryMock := mocks.EmployeRepositoryMock{}
+			roleRepositoryMock := mocks.RoleRepositoryMock{}
+			eventRepositoryMock := mocks.EventRepositoryMock{}
+			snapshotRepositoryMock := mocks.SnapshotRepositoryMock{}
+
+			roleRepositoryMock.On("Get", mock.Anything, &pb.RoleFilter{Name: "admin"}).Return(&entities.Role{Name: "admin"}, nil)
+			tc.ExpectedMockCalls(&employeeRepositoryMock, &eventRepositoryMock, &snapshotRepositoryMock)
+
+			registry := event.NewRegistry()
+			assert.NoError(t, employee.RegisterEvents(registry))
+
+			delivery := NewEmployeeDelivery(
+				log,
+				&employeeRepositoryMock,
+				&roleRepositoryMock,
+				nil,
+				registry,
+				EmployeeDeliveryOptions{
+					Aggregates: event.NewSnapshotStore(&eventRepositoryMock, &snapshotRepositoryMock, 0),
+				},
+			)
+			_, err := delivery.NewEmployee(context.Background(), &pb.NewEmployeeRequest{
+				Email:    "admin@page.com",
+				Password: "secret",
+				Roles:    []*pb.Role{{Name: "admin"}},
+			})
+			helpers.AssertErrors(t, tc.ExpectedErr, err)
+
+			employeeRepositoryMock.AssertExpectations(t)
+			eventRepositoryMock.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,133 @@
+package grpc
+
+import (
+	"context"
+
+	"github.com/golang/protobuf/ptypes"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/migotom/cell-centre-services/pkg/components/directory"
+	"github.com/migotom/cell-centre-services/pkg/components/employee"
+	"github.com/migotom/cell-centre-services/pkg/entities"
+	"github.com/migotom/cell-centre-services/pkg/pb"
+)
+
+// ExportEmployeeData gRPC handler gathers data held about employee: its profile, roles, directory entry and events
+// of employee or originated by employee. Personal data of events is decrypted and password hashes are excluded.
+// Deleted employees are exported by ID, or by email while directory still lists them.
+func (delivery *EmployeeDelivery) ExportEmployeeData(ctx context.Context, filter *pb.EmployeeFilter) (*pb.EmployeeDataExport, error) {
+	if delivery.events == nil {
+		return nil, status.Errorf(codes.Unimplemented, "Can't export employee: events are not available")
+	}
+
+	profile, err := delivery.repository.Get(ctx, filter)
+	if err != nil {
+		// deleted employees have no profile
+		profile = nil
+	}
+	id, err := delivery.exportedID(ctx, filter, profile)
+	if err != nil {
+		return nil, err
+	}
+
+	export := pb.EmployeeDataExport{
+		EmployeeId: id.Hex(),
+		ExportedAt: ptypes.TimestampNow(),
+	}
+	if profile != nil {
+		profile.Password = ""
+		if export.Profile, err = delivery.employeePbFactory.NewFromEmployee(profile); err != nil {
+			return nil, status.Errorf(codes.Internal, "Can't export employee: %v", EmployeeDeliveryError{Reason: ErrInternal, Err: err})
+		}
+		export.Roles = delivery.exportedRoles(ctx, profile.Roles)
+	}
+
+	if delivery.directory != nil {
+		entry, err := delivery.directory.Get(ctx, id)
+		switch err {
+		case nil:
+			if export.DirectoryEntry, err = delivery.employeePbFactory.NewFromDirectoryEntry(entry); err != nil {
+				return nil, status.Errorf(codes.Internal, "Can't export employee: %v", EmployeeDeliveryError{Reason: ErrInternal, Err: err})
+			}
+		case directory.ErrEntryNotFound:
+		default:
+			return nil, status.Errorf(codes.Internal, "Can't export employee: %v", EmployeeDeliveryError{Reason: ErrInternal, Err: err})
+		}
+	}
+
+	events, err := delivery.events.SubjectEvents(ctx, employee.AggregateType, id.Hex(), id)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "Can't export employee: %v", EmployeeDeliveryError{Reason: ErrInternal, Err: err})
+	}
+	for _, e := range events {
+		exported, err := delivery.exportedEvent(ctx, e)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "Can't export employee: %v", EmployeeDeliveryError{Reason: ErrInternal, Err: err})
+		}
+		export.Events = append(export.Events, exported)
+	}
+
+	if export.Profile == nil && export.DirectoryEntry == nil && len(export.Events) == 0 {
+		return nil, status.Errorf(codes.NotFound, "Can't export employee: %v", EmployeeDeliveryError{Reason: ErrInvalidEmployeeData, Err: employee.ErrEmployeeNotFound})
+	}
+	return &export, nil
+}
+
+// exportedID returns ID of exported employee, deleted employees filtered by email are looked up in directory.
+func (delivery *EmployeeDelivery) exportedID(ctx context.Context, filter *pb.EmployeeFilter, profile *entities.Employee) (primitive.ObjectID, error) {
+	switch {
+	case profile != nil:
+		return profile.ID, nil
+	case filter.GetId() != "":
+		id, err := primitive.ObjectIDFromHex(filter.GetId())
+		if err != nil {
+			return id, status.Errorf(codes.InvalidArgument, "Can't export employee: %v", EmployeeDeliveryError{Reason: ErrInvalidEmployeeData, Err: err})
+		}
+		return id, nil
+	case filter.GetEmail() != "" && delivery.directory != nil:
+		if entry, err := delivery.directory.GetByEmail(ctx, filter.GetEmail()); err == nil {
+			return entry.ID, nil
+		}
+	}
+	return primitive.NilObjectID, status.Errorf(codes.NotFound, "Can't export employee: %v", EmployeeDeliveryError{Reason: ErrInvalidEmployeeData, Err: employee.ErrEmployeeNotFound})
+}
+
+// exportedRoles returns current roles of employee, roles missing in role repository are exported as assigned.
+func (delivery *EmployeeDelivery) exportedRoles(ctx context.Context, assigned []entities.Role) []*pb.Role {
+	roles := make([]*pb.Role, 0, len(assigned))
+	for _, role := range assigned {
+		if current, err := delivery.roles.Get(ctx, &pb.RoleFilter{Id: role.ID.Hex()}); err == nil {
+			role = *current
+		}
+		roles = append(roles, &pb.Role{Id: role.ID.Hex(), Name: role.Name})
+	}
+	return roles
+}
+
+// exportedEvent returns stored event with decrypted personal data and without password hash.
+func (delivery *EmployeeDelivery) exportedEvent(ctx context.Context, e *entities.Event) (*pb.Event, error) {
+	exported, err := delivery.eventPbFactory.NewFromEvent(e)
+	if err != nil {
+		return nil, err
+	}
+	if delivery.payloadCipher != nil {
+		if err := delivery.payloadCipher.Decrypt(ctx, exported); err != nil {
+			return nil, err
+		}
+	}
+
+	var payload ptypes.DynamicAny
+	if err := ptypes.UnmarshalAny(exported.GetPayload(), &payload); err != nil {
+		return nil, err
+	}
+	if employee, ok := payload.Message.(*pb.Employee); ok && employee.Password != "" {
+		// events of schema version 1 carried password hashes
+		employee.Password = ""
+		if exported.Payload, err = ptypes.MarshalAny(employee); err != nil {
+			return nil, err
+		}
+	}
+	return exported, nil
+}
@@ -0,0 +1,183 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/ptypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/zap"
+
+	"github.com/migotom/cell-centre-services/pkg/components/directory"
+	"github.com/migotom/cell-centre-services/pkg/components/employee"
+	"github.com/migotom/cell-centre-services/pkg/components/event"
+	"github.com/migotom/cell-centre-services/pkg/entities"
+	"github.com/migotom/cell-centre-services/pkg/helpers"
+	"github.com/migotom/cell-centre-services/pkg/helpers/mocks"
+	"github.com/migotom/cell-centre-services/pkg/pb"
+)
+
+func TestExportEmployeeData(t *testing.T) {
+	id, _ := primitive.ObjectIDFromHex("5d3783ee28ae9468bc528906")
+	adminRole := entities.Role{ID: primitive.NewObjectID(), Name: "admin"}
+	createdAt := time.Date(2019, 7, 24, 20, 26, 40, 0, time.UTC)
+
+	// event of schema version 1 carrying password hash
+	created := &entities.Event{
+		EventID:       "e1",
+		Type:          entities.NewEmployeeEvent,
+		AggregateID:   id.Hex(),
+		AggregateType: employee.AggregateType,
+		Sequence:      1,
+		Data:          &entities.Employee{ID: id, Email: "john@page.com", Password: "$2a$04$hash"},
+		CreatedAt:     createdAt,
+	}
+	deleted := &entities.Event{
+		EventID:       "e2",
+		Type:          entities.DeleteEmployeeEvent,
+		AggregateID:   id.Hex(),
+		AggregateType: employee.AggregateType,
+		Sequence:      2,
+		Data:          &entities.Employee{ID: id},
+		Originator:    entities.EventOriginator{EntityID: id, Login: "john@page.com"},
+		CreatedAt:     createdAt,
+	}
+
+	cases := []struct {
+		Name              string
+		Filter            *pb.EmployeeFilter
+		ExpectedMockCalls func(*mocks.EmployeRepositoryMock, *mocks.RoleRepositoryMock, *mocks.DirectoryRepositoryMock, *mocks.EventRepositoryMock)
+		ExpectedExport    func(*testing.T, *pb.EmployeeDataExport)
+		ExpectedErr       string
+	}{
+		{
+			Name:   "Employee is exported with password hashes excluded",
+			Filter: &pb.EmployeeFilter{Email: "john@page.com"},
+			ExpectedMockCalls: func(e *mocks.EmployeRepositoryMock, r *mocks.RoleRepositoryMock, d *mocks.DirectoryRepositoryMock, ev *mocks.EventRepositoryMock) {
+				e.On("Get", mock.Anything, &pb.EmployeeFilter{Email: "john@page.com"}).
+					Return(&entities.Employee{ID: id, Email: "john@page.com", Password: "$2a$04$hash", Roles: []entities.Role{{ID: adminRole.ID}}}, nil)
+				r.On("Get", mock.Anything, &pb.RoleFilter{Id: adminRole.ID.Hex()}).Return(&adminRole, nil)
+				d.On("Get", mock.Anything, id).Return(&entities.DirectoryEntry{ID: id, Email: "john@page.com", Status: entities.ActiveEmployee}, nil)
+				ev.On("SubjectEvents", mock.Anything, employee.AggregateType, id.Hex(), id).Return([]*entities.Event{created}, nil)
+			},
+			ExpectedExport: func(t *testing.T, export *pb.EmployeeDataExport) {
+				assert.Equal(t, id.Hex(), export.EmployeeId)
+				assert.Equal(t, "john@page.com", export.Profile.Email)
+				assert.Empty(t, export.Profile.Password)
+				assert.Equal(t, []*pb.Role{{Id: adminRole.ID.Hex(), Name: "admin"}}, export.Roles)
+				assert.Equal(t, "john@page.com", export.DirectoryEntry.Email)
+				assert.Len(t, export.Events, 1)
+
+				var payload pb.Employee
+				assert.NoError(t, ptypes.UnmarshalAny(export.Events[0].Payload, &payload))
+				assert.Equal(t, "john@page.com", payload.Email)
+				assert.Empty(t, payload.Password)
+			},
+		},
+		{
+			Name:   "Deleted employee is exported from events",
+			Filter: &pb.EmployeeFilter{Id: id.Hex()},
+			ExpectedMockCalls: func(e *mocks.EmployeRepositoryMock, r *mocks.RoleRepositoryMock, d *mocks.DirectoryRepositoryMock, ev *mocks.EventRepositoryMock) {
+				e.On("Get", mock.Anything, &pb.EmployeeFilter{Id: id.Hex()}).Return((*entities.Employee)(nil), errors.New("not found"))
+				d.On("Get", mock.Anything, id).Return((*entities.DirectoryEntry)(nil), directory.ErrEntryNotFound)
+				ev.On("SubjectEvents", mock.Anything, employee.AggregateType, id.Hex(), id).Return([]*entities.Event{created, deleted}, nil)
+			},
+			ExpectedExport: func(t *testing.T, export *pb.EmployeeDataExport) {
+				assert.Nil(t, export.Profile)
+				assert.Nil(t, export.DirectoryEntry)
+				assert.Len(t, export.Events, 2)
+				assert.Equal(t, "john@page.com", export.Events[1].Originator.Login)
+			},
+		},
+		{
+			Name:   "Deleted employee is found by email in directory",
+			Filter: &pb.EmployeeFilter{Email: "john@page.com"},
+			ExpectedMockCalls: func(e *mocks.EmployeRepositoryMock, r *mocks.RoleRepositoryMock, d *mocks.DirectoryRepositoryMock, ev *mocks.EventRepositoryMock) {
+				e.On("Get", mock.Anything, &pb.EmployeeFilter{Email: "john@page.com"}).Return((*entities.Employee)(nil), errors.New("not found"))
+				entry := &entities.DirectoryEntry{ID: id, Email: "john@page.com", Status: entities.DeletedEmployee}
+				d.On("GetByEmail", mock.Anything, "john@page.com").Return(entry, nil)
+				d.On("Get", mock.Anything, id).Return(entry, nil)
+				ev.On("SubjectEvents", mock.Anything, employee.AggregateType, id.Hex(), id).Return([]*entities.Event{created, deleted}, nil)
+			},
+			ExpectedExport: func(t *testing.T, export *pb.EmployeeDataExport) {
+				assert.Equal(t, id.Hex(), export.EmployeeId)
+				assert.Equal(t, string(entities.DeletedEmployee), export.DirectoryEntry.Status)
+				assert.Len(t, export.Events, 2)
+			},
+		},
+		{
+			Name:   "Unknown employee",
+			Filter: &pb.EmployeeFilter{Id: id.Hex()},
+			ExpectedMockCalls: func(e *mocks.EmployeRepositoryMock, r *mocks.RoleRepositoryMock, d *mocks.DirectoryRepositoryMock, ev *mocks.EventRepositoryMock) {
+				e.On("Get", mock.Anything, &pb.EmployeeFilter{Id: id.Hex()}).Return((*entities.Employee)(nil), errors.New("not found"))
+				d.On("Get", mock.Anything, id).Return((*entities.DirectoryEntry)(nil), directory.ErrEntryNotFound)
+				ev.On("SubjectEvents", mock.Anything, employee.AggregateType, id.Hex(), id).Return([]*entities.Event(nil), nil)
+			},
+			ExpectedErr: "rpc error: code = NotFound desc = Can't export employee: Invalid employee data (employee doesn't exist)",
+		},
+		{
+			Name:   "Invalid ID",
+			Filter: &pb.EmployeeFilter{Id: "john"},
+			ExpectedMockCalls: func(e *mocks.EmployeRepositoryMock, r *mocks.RoleRepositoryMock, d *mocks.DirectoryRepositoryMock, ev *mocks.EventRepositoryMock) {
+				e.On("Get", mock.Anything, &pb.EmployeeFilter{Id: "john"}).Return((*entities.Employee)(nil), errors.New("invalid ID"))
+			},
+			ExpectedErr: "rpc error: code = InvalidArgument desc = Can't export employee: Invalid employee data (encoding/hex: invalid byte: U+006A 'j')",
+		},
+		{
+			Name:   "Failed read of events",
+			Filter: &pb.EmployeeFilter{Id: id.Hex()},
+			ExpectedMockCalls: func(e *mocks.EmployeRepositoryMock, r *mocks.RoleRepositoryMock, d *mocks.DirectoryRepositoryMock, ev *mocks.EventRepositoryMock) {
+				e.On("Get", mock.Anything, &pb.EmployeeFilter{Id: id.Hex()}).Return((*entities.Employee)(nil), errors.New("not found"))
+				d.On("Get", mock.Anything, id).Return((*entities.DirectoryEntry)(nil), directory.ErrEntryNotFound)
+				ev.On("SubjectEvents", mock.Anything, employee.AggregateType, id.Hex(), id).Return([]*entities.Event(nil), errors.New("database down"))
+			},
+			ExpectedErr: "rpc error: code = Internal desc = Can't export employee: Internal error (database down)",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			employeeRepositoryMock := mocks.EmployeRepositoryMock{}
+			roleRepositoryMock := mocks.RoleRepositoryMock{}
+			directoryRepositoryMock := mocks.DirectoryRepositoryMock{}
+			eventRepositoryMock := mocks.EventRepositoryMock{}
+
+			tc.ExpectedMockCalls(&employeeRepositoryMock, &roleRepositoryMock, &directoryRepositoryMock, &eventRepositoryMock)
+
+			registry := event.NewRegistry()
+			assert.NoError(t, employee.RegisterEvents(registry))
+
+			delivery := NewEmployeeDelivery(
+				zap.NewNop(),
+				&employeeRepositoryMock,
+				&roleRepositoryMock,
+				nil,
+				registry,
+				EmployeeDeliveryOptions{
+					Directory: &directoryRepositoryMock,
+					Events:    &eventRepositoryMock,
+				},
+			)
+			export, err := delivery.ExportEmployeeData(context.Background(), tc.Filter)
+			helpers.AssertErrors(t, tc.ExpectedErr, err)
+			if tc.ExpectedExport != nil {
+				tc.ExpectedExport(t, export)
+			}
+
+			employeeRepositoryMock.AssertExpectations(t)
+			roleRepositoryMock.AssertExpectations(t)
+			directoryRepositoryMock.AssertExpectations(t)
+			eventRepositoryMock.AssertExpectations(t)
+		})
+	}
+}
+
+func TestExportEmployeeDataWithoutEvents(t *testing.T) {
+	delivery := NewEmployeeDelivery(zap.NewNop(), &mocks.EmployeRepositoryMock{}, &mocks.RoleRepositoryMock{}, nil, event.NewRegistry(), EmployeeDeliveryOptions{})
+
+	_, err := delivery.ExportEmployeeData(context.Background(), &pb.EmployeeFilter{Id: primitive.NewObjectID().Hex()})
+	helpers.AssertErrors(t, "rpc error: code = Unimplemented desc = Can't export employee: events are not available", err)
+}
@@ -2,7 +2,9 @@ package grpc
 
 import (
 	"context"
+	"sync"
 
+	"github.com/golang/protobuf/proto"
 	empty "github.com/golang/protobuf/ptypes/empty"
 	"go.uber.org/zap"
 	"google.golang.org/grpc/codes"
@@ -10,13 +12,15 @@ import (
 
 	"github.com/migotom/cell-centre-services/pkg/components/auth"
 	authDelivery "github.com/migotom/cell-centre-services/pkg/components/auth/delivery/grpc"
+	"github.com/migotom/cell-centre-services/pkg/components/directory"
 	"github.com/migotom/cell-centre-services/pkg/components/employee"
 	employeeFactory "github.com/migotom/cell-centre-services/pkg/components/employee/factory"
 	"github.com/migotom/cell-centre-services/pkg/components/event"
-
+	eventFactory "github.com/migotom/cell-centre-services/pkg/components/event/factory"
 	"github.com/migotom/cell-centre-services/pkg/components/role"
 	"github.com/migotom/cell-centre-services/pkg/entities"
 	"github.com/migotom/cell-centre-services/pkg/helpers"
+	"github.com/migotom/cell-centre-services/pkg/helpers/correlation"
 	"github.com/migotom/cell-centre-services/pkg/pb"
 	pbFactory "github.com/migotom/cell-centre-services/pkg/pb/factory"
 )
@@ -24,22 +28,52 @@ import (
 // EmployeeDelivery is gRPC handler delivery of employee.
 type EmployeeDelivery struct {
 	log               *zap.Logger
-	eventsStreaming   *event.EventsStreaming
+	eventsStreaming   event.Streaming
 	employeeFactory   *employeeFactory.EmployeeEntityFactory
 	employeePbFactory *pbFactory.EmployeePbFactory
 	eventPbFactory    *pbFactory.EventPbFactory
+	eventFactory      *eventFactory.EventEntityFactory
 	repository        employee.Repository
+	roles             role.Repository
+	events            event.Repository
+	aggregates        *event.SnapshotStore
+	directory         directory.Repository
+	payloadCipher     *employee.PayloadCipher
+
+	// pending publishes of events
+	pending sync.WaitGroup
+}
+
+// EmployeeDeliveryOptions are optional dependencies of EmployeeDelivery, features using them are unavailable
+// when they aren't set.
+type EmployeeDeliveryOptions struct {
+	// Aggregates enables event sourced write model, without it employee repository is the source of truth and
+	// events are published after its changes.
+	Aggregates *event.SnapshotStore
+	// Directory read model lists employees.
+	Directory directory.Repository
+	// PayloadCipher encrypts personal data carried by events, without it events carry plain data and erasure
+	// is unavailable.
+	PayloadCipher *employee.PayloadCipher
+	// Events are read by export of employee data.
+	Events event.Repository
 }
 
 // NewEmployeeDelivery returns new Employee gRPC delivery.
-func NewEmployeeDelivery(log *zap.Logger, employeeRepository employee.Repository, roleRepository role.Repository, eventsStreaming *event.EventsStreaming) *EmployeeDelivery {
+func NewEmployeeDelivery(log *zap.Logger, employeeRepository employee.Repository, roleRepository role.Repository, eventsStreaming event.Streaming, eventRegistry *event.Registry, options EmployeeDeliveryOptions) *EmployeeDelivery {
 	return &EmployeeDelivery{
 		log:               log,
 		eventsStreaming:   eventsStreaming,
 		employeeFactory:   employeeFactory.NewEmployeeEntityFactory(roleRepository),
 		employeePbFactory: pbFactory.NewEmployeePbFactory(),
-		eventPbFactory:    pbFactory.NewEventPbFactory(),
+		eventPbFactory:    pbFactory.NewEventPbFactory(eventRegistry),
+		eventFactory:      eventFactory.NewEventEntityFactory(eventRegistry),
 		repository:        employeeRepository,
+		roles:             roleRepository,
+		events:            options.Events,
+		aggregates:        options.Aggregates,
+		directory:         options.Directory,
+		payloadCipher:     options.PayloadCipher,
 	}
 }
 
@@ -53,8 +87,8 @@ func (delivery *EmployeeDelivery) AuthFuncOverride(ctx context.Context, fullMeth
 	// TODO method name based auth..
 	// fmt.Println("method", fullMethodName)
 
-	if !claims.HasRole([]string{"admin", "serviceman"}) {
-		return ctx, status.Errorf(codes.Unauthenticated, "Request unauthenticated with error: %v", auth.AuthError{Reason: auth.ErrInsufficientRights})
+	if err := authDelivery.Authorize(claims, []string{"admin", "serviceman"}); err != nil {
+		return ctx, status.Errorf(codes.Unauthenticated, "Request unauthenticated with error: %v", err)
 	}
 
 	return context.WithValue(ctx, authDelivery.ContextKeyClaims, claims), nil
@@ -62,7 +96,7 @@ func (delivery *EmployeeDelivery) AuthFuncOverride(ctx context.Context, fullMeth
 
 // GetEmployee gRPC handler gets employee by given filer options.
 func (delivery *EmployeeDelivery) GetEmployee(ctx context.Context, filter *pb.EmployeeFilter) (*pb.Employee, error) {
-	employee, err := delivery.repository.Get(context.Background(), filter)
+	employee, err := delivery.repository.Get(ctx, filter)
 	if err != nil {
 		return nil, status.Errorf(codes.NotFound, "Can't get employee: %v", err)
 	}
@@ -85,7 +119,11 @@ func (delivery *EmployeeDelivery) NewEmployee(ctx context.Context, request *pb.N
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "Can't create new employee: %v", EmployeeDeliveryError{Reason: ErrInternal, Err: err})
 	}
-	employee, err := delivery.repository.New(context.Background(), employeeEntity)
+	if delivery.aggregates != nil {
+		return delivery.newEventSourcedEmployee(ctx, employeeEntity)
+	}
+
+	employee, err := delivery.repository.New(ctx, employeeEntity)
 	if err != nil {
 		return nil, status.Errorf(codes.InvalidArgument, "Can't create new employee: %v", EmployeeDeliveryError{Reason: ErrInvalidEmployeeData, Err: err})
 	}
@@ -97,77 +135,135 @@ func (delivery *EmployeeDelivery) NewEmployee(ctx context.Context, request *pb.N
 		return nil, status.Errorf(codes.Internal, "%v", EmployeeDeliveryError{Reason: ErrInternal, Err: err})
 	}
 
-	go func() {
-		if delivery.eventsStreaming == nil {
-			return
-		}
-		event, _ := delivery.eventPbFactory.NewFromEmployeeMessage(
-			authDelivery.ObtainClaimsFromContext(ctx),
-			entities.NewEmployeeEvent,
-			employeePb,
-		)
-		delivery.eventsStreaming.Publish(event)
-	}()
+	delivery.publishNew(ctx, entities.NewEmployeeEvent, employeePb.Id, employeePb)
 	return employeePb, nil
 }
 
 // UpdateEmployee gRPC handler updates employee data based on UpdateEmployeeRequest message and returns updated Employee message.
+// Event with changes of employee fields is published only if update changed anything.
 func (delivery *EmployeeDelivery) UpdateEmployee(ctx context.Context, request *pb.UpdateEmployeeRequest) (*pb.Employee, error) {
 	if request == nil {
 		return &pb.Employee{}, EmployeeDeliveryError{Reason: ErrInvalidEmployeeData}
 	}
 
+	previous, err := delivery.repository.Get(ctx, &pb.EmployeeFilter{Id: request.GetId()})
+	if err != nil {
+		return nil, status.Errorf(codes.NotFound, "Can't update employee: %v", EmployeeDeliveryError{Reason: ErrInvalidEmployeeData, Err: err})
+	}
+
 	if request.GetPassword() != "" {
-		request.Password = helpers.HashPassword(request.Password)
+		if auth.ValidPassword(previous.Password, request.Password) {
+			// password is not changed, keep its current hash
+			request.Password = ""
+		} else {
+			request.Password = helpers.HashPassword(request.Password)
+		}
 	}
 
 	employeeEntity, err := delivery.employeeFactory.NewFromUpdateEmployeeRequest(request)
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "%v", EmployeeDeliveryError{Reason: ErrInternal, Err: err})
 	}
-	employee, err := delivery.repository.Update(context.Background(), employeeEntity)
+	if delivery.aggregates != nil {
+		return delivery.updateEventSourcedEmployee(ctx, previous, employeeEntity)
+	}
+
+	employee, err := delivery.repository.Update(ctx, employeeEntity)
 	if err != nil {
 		return nil, status.Errorf(codes.InvalidArgument, "Can't update employee: %v", EmployeeDeliveryError{Reason: ErrInvalidEmployeeData, Err: err})
 	}
 
+	changes := delivery.employeePbFactory.NewChanges(previous, employee)
+
 	employeePb, err := delivery.employeePbFactory.NewFromEmployee(employee)
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "%v", EmployeeDeliveryError{Reason: ErrInternal, Err: err})
 	}
 
-	go func() {
-		if delivery.eventsStreaming == nil {
-			return
-		}
-		event, _ := delivery.eventPbFactory.NewFromUpdateEmployeeRequest(
-			authDelivery.ObtainClaimsFromContext(ctx),
-			entities.UpdateEmployeeEvent,
-			request,
-		)
-		delivery.eventsStreaming.Publish(event)
-	}()
+	if len(changes.Changes) == 0 {
+		return employeePb, nil
+	}
+
+	delivery.publishNew(ctx, entities.UpdateEmployeeEvent, changes.Id, changes)
 
 	return employeePb, nil
 }
 
 // DeleteEmployee gRPC handler deletes employee based on given filter.
 func (delivery *EmployeeDelivery) DeleteEmployee(ctx context.Context, filter *pb.EmployeeFilter) (*empty.Empty, error) {
-	err := delivery.repository.Delete(context.Background(), filter)
+	if delivery.aggregates != nil {
+		return delivery.deleteEventSourcedEmployee(ctx, filter)
+	}
+
+	// event is aggregated by ID of employee, so its email is encrypted with data key of employee
+	id := filter.GetId()
+	if id == "" {
+		existing, err := delivery.repository.Get(ctx, filter)
+		if err != nil {
+			return nil, status.Errorf(codes.NotFound, "Can't delete employee: %v", EmployeeDeliveryError{Reason: ErrInvalidEmployeeData, Err: err})
+		}
+		id = existing.ID.Hex()
+	}
+
+	err := delivery.repository.Delete(ctx, &pb.EmployeeFilter{Id: id})
 	if err != nil {
 		return nil, status.Errorf(codes.NotFound, "Can't delete employee: %v", EmployeeDeliveryError{Reason: ErrInvalidEmployeeData, Err: err})
 	}
 
-	go func() {
+	delivery.publishNew(ctx, entities.DeleteEmployeeEvent, id, &pb.EmployeeFilter{Id: id, Email: filter.GetEmail()})
+
+	return &empty.Empty{}, nil
+}
+
+// newEvent returns event of employee caused by request, personal data carried by event is encrypted.
+func (delivery *EmployeeDelivery) newEvent(ctx context.Context, eventType entities.EventType, aggregateID string, payload proto.Message) (*pb.Event, error) {
+	e, err := delivery.eventPbFactory.New(ctx, authDelivery.ObtainClaimsFromContext(ctx), eventType, aggregateID, payload)
+	if err != nil {
+		return nil, err
+	}
+	if delivery.payloadCipher != nil {
+		if err := delivery.payloadCipher.Encrypt(ctx, e); err != nil {
+			return nil, err
+		}
+	}
+	return e, nil
+}
+
+// publishNew creates event of state write model and publishes it in background. Events of state write model
+// aren't stored, so event that failed to be created or published is lost and only logged.
+func (delivery *EmployeeDelivery) publishNew(ctx context.Context, eventType entities.EventType, aggregateID string, payload proto.Message) {
+	delivery.async(ctx, func(ctx context.Context) {
 		if delivery.eventsStreaming == nil {
 			return
 		}
-		event, _ := delivery.eventPbFactory.NewFromEmployeeFilter(
-			authDelivery.ObtainClaimsFromContext(ctx),
-			entities.DeleteEmployeeEvent,
-			filter,
-		)
-		delivery.eventsStreaming.Publish(event)
+		e, err := delivery.newEvent(ctx, eventType, aggregateID, payload)
+		if err != nil {
+			correlation.Logger(ctx, delivery.log).Error("Can't create event", zap.String("eventType", string(eventType)), zap.String("aggregateID", aggregateID), zap.Error(err))
+			return
+		}
+		delivery.send(ctx, e)
+	})
+}
+
+// send publishes event, failed publish is logged.
+func (delivery *EmployeeDelivery) send(ctx context.Context, e *pb.Event) {
+	if err := delivery.eventsStreaming.Publish(e); err != nil {
+		correlation.Logger(ctx, delivery.log).Error("Can't publish event", zap.String("eventID", e.EventId), zap.String("eventType", e.Type), zap.Error(err))
+	}
+}
+
+// async runs publish of event in background, pending publishes are awaited by Drain. Publish is given context
+// of request which isn't cancelled once request is finished.
+func (delivery *EmployeeDelivery) async(ctx context.Context, publish func(ctx context.Context)) {
+	ctx = context.WithoutCancel(ctx)
+	delivery.pending.Add(1)
+	go func() {
+		defer delivery.pending.Done()
+		publish(ctx)
 	}()
+}
 
-	return &empty.Empty{}, nil
+// Drain waits for pending publishes of events until given context is done.
+func (delivery *EmployeeDelivery) Drain(ctx context.Context) error {
+	return helpers.Wait(ctx, &delivery.pending)
 }
@@ -4,12 +4,19 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
+	"github.com/golang/protobuf/ptypes"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
 
+	"github.com/migotom/cell-centre-services/pkg/components/employee"
+	"github.com/migotom/cell-centre-services/pkg/components/event"
 	"github.com/migotom/cell-centre-services/pkg/entities"
 	"github.com/migotom/cell-centre-services/pkg/helpers"
 	"github.com/migotom/cell-centre-services/pkg/helpers/mocks"
@@ -96,6 +103,8 @@ func TestGetEmployee(t *testing.T) {
 				&employeeRepositoryMock,
 				&roleRepositoryMock,
 				nil,
+				nil,
+				EmployeeDeliveryOptions{},
 			)
 			employee, err := delivery.GetEmployee(context.Background(), &tc.Filter)
 			helpers.AssertErrors(t, tc.ExpectedErr, err)
@@ -160,6 +169,8 @@ func TestNewEmployee(t *testing.T) {
 				&employeeRepositoryMock,
 				&roleRepositoryMock,
 				nil,
+				nil,
+				EmployeeDeliveryOptions{},
 			)
 			employee, err := delivery.NewEmployee(context.Background(), &tc.Request)
 			helpers.AssertErrors(t, tc.ExpectedErr, err)
@@ -170,6 +181,13 @@ func TestNewEmployee(t *testing.T) {
 }
 
 func TestUpdateEmployee(t *testing.T) {
+	id, _ := primitive.ObjectIDFromHex("5d3783ee28ae9468bc528906")
+	previous := &entities.Employee{
+		ID:       id,
+		Email:    "admin@page.com",
+		Password: helpers.HashPassword("secret"),
+	}
+
 	cases := []struct {
 		Name              string
 		Request           pb.UpdateEmployeeRequest
@@ -181,14 +199,33 @@ func TestUpdateEmployee(t *testing.T) {
 			Name: "Valid request",
 			Request: pb.UpdateEmployeeRequest{
 				Id:    "5d3783ee28ae9468bc528906",
-				Email: "admin@page.com",
+				Email: "root@page.com",
 			},
 			ExpectedMockCalls: func(e *mocks.EmployeRepositoryMock, r *mocks.RoleRepositoryMock) {
-				id, _ := primitive.ObjectIDFromHex("5d3783ee28ae9468bc528906")
-				r.On("Get", mock.Anything, &pb.RoleFilter{Name: "admin"}).Return(&entities.Role{Name: "admin"}, nil)
+				e.On("Get", mock.Anything, &pb.EmployeeFilter{Id: "5d3783ee28ae9468bc528906"}).Return(previous, nil)
 				e.On("Update", mock.Anything, &entities.Employee{
 					ID:    id,
-					Email: "admin@page.com",
+					Email: "root@page.com",
+				}).Return(&entities.Employee{
+					ID:    id,
+					Email: "root@page.com",
+				}, nil)
+			},
+			ExpectedEmployee: &pb.Employee{
+				Id:    "5d3783ee28ae9468bc528906",
+				Email: "root@page.com",
+			},
+		},
+		{
+			Name: "Unchanged password keeps its hash",
+			Request: pb.UpdateEmployeeRequest{
+				Id:       "5d3783ee28ae9468bc528906",
+				Password: "secret",
+			},
+			ExpectedMockCalls: func(e *mocks.EmployeRepositoryMock, r *mocks.RoleRepositoryMock) {
+				e.On("Get", mock.Anything, &pb.EmployeeFilter{Id: "5d3783ee28ae9468bc528906"}).Return(previous, nil)
+				e.On("Update", mock.Anything, &entities.Employee{
+					ID: id,
 				}).Return(&entities.Employee{
 					ID:    id,
 					Email: "admin@page.com",
@@ -200,21 +237,32 @@ func TestUpdateEmployee(t *testing.T) {
 			},
 		},
 		{
-			Name: "Invalid request",
+			Name: "Not existing employee",
 			Request: pb.UpdateEmployeeRequest{
 				Id:    "5d3783ee28ae9468bc520101",
 				Email: "admin@page.com",
 			},
 			ExpectedMockCalls: func(e *mocks.EmployeRepositoryMock, r *mocks.RoleRepositoryMock) {
-				id, _ := primitive.ObjectIDFromHex("5d3783ee28ae9468bc520101")
-				r.On("Get", mock.Anything, &pb.RoleFilter{Name: "admin"}).Return(&entities.Role{Name: "admin"}, nil)
+				e.On("Get", mock.Anything, &pb.EmployeeFilter{Id: "5d3783ee28ae9468bc520101"}).Return((*entities.Employee)(nil), errors.New("missing"))
+			},
+			ExpectedEmployee: nil,
+			ExpectedErr:      "rpc error: code = NotFound desc = Can't update employee: Invalid employee data (missing)",
+		},
+		{
+			Name: "Invalid request",
+			Request: pb.UpdateEmployeeRequest{
+				Id:    "5d3783ee28ae9468bc528906",
+				Email: "admin@page.com",
+			},
+			ExpectedMockCalls: func(e *mocks.EmployeRepositoryMock, r *mocks.RoleRepositoryMock) {
+				e.On("Get", mock.Anything, &pb.EmployeeFilter{Id: "5d3783ee28ae9468bc528906"}).Return(previous, nil)
 				e.On("Update", mock.Anything, &entities.Employee{
 					ID:    id,
 					Email: "admin@page.com",
-				}).Return(&entities.Employee{}, errors.New("missing"))
+				}).Return(&entities.Employee{}, errors.New("duplicated email"))
 			},
 			ExpectedEmployee: nil,
-			ExpectedErr:      "rpc error: code = InvalidArgument desc = Can't update employee: Invalid employee data (missing)",
+			ExpectedErr:      "rpc error: code = InvalidArgument desc = Can't update employee: Invalid employee data (duplicated email)",
 		},
 	}
 	for _, tc := range cases {
@@ -232,16 +280,21 @@ func TestUpdateEmployee(t *testing.T) {
 				&employeeRepositoryMock,
 				&roleRepositoryMock,
 				nil,
+				nil,
+				EmployeeDeliveryOptions{},
 			)
 			employee, err := delivery.UpdateEmployee(context.Background(), &tc.Request)
 			helpers.AssertErrors(t, tc.ExpectedErr, err)
 
 			assert.Equal(t, tc.ExpectedEmployee, employee)
+			employeeRepositoryMock.AssertExpectations(t)
 		})
 	}
 }
 
 func TestDeleteEmployee(t *testing.T) {
+	id := primitive.NewObjectID()
+
 	cases := []struct {
 		Name              string
 		Filter            pb.EmployeeFilter
@@ -257,14 +310,24 @@ func TestDeleteEmployee(t *testing.T) {
 				e.On("Delete", mock.Anything, &pb.EmployeeFilter{Id: "1"}).Return(nil)
 			},
 		},
+		{
+			Name: "Valid request by email",
+			Filter: pb.EmployeeFilter{
+				Email: "john@page.com",
+			},
+			ExpectedMockCalls: func(e *mocks.EmployeRepositoryMock, r *mocks.RoleRepositoryMock) {
+				e.On("Get", mock.Anything, &pb.EmployeeFilter{Email: "john@page.com"}).Return(&entities.Employee{ID: id}, nil)
+				e.On("Delete", mock.Anything, &pb.EmployeeFilter{Id: id.Hex()}).Return(nil)
+			},
+		},
 		{
 			Name: "Invalid request (not found)",
 			Filter: pb.EmployeeFilter{
 				Email: "nobody@page.com",
 			},
 			ExpectedMockCalls: func(e *mocks.EmployeRepositoryMock, r *mocks.RoleRepositoryMock) {
-				e.On("Delete", mock.Anything, &pb.EmployeeFilter{Email: "nobody@page.com"}).
-					Return(errors.New("not found"))
+				e.On("Get", mock.Anything, &pb.EmployeeFilter{Email: "nobody@page.com"}).
+					Return((*entities.Employee)(nil), errors.New("not found"))
 			},
 			ExpectedErr: "rpc error: code = NotFound desc = Can't delete employee: Invalid employee data (not found)",
 		},
@@ -284,9 +347,89 @@ func TestDeleteEmployee(t *testing.T) {
 				&employeeRepositoryMock,
 				&roleRepositoryMock,
 				nil,
+				nil,
+				EmployeeDeliveryOptions{},
 			)
 			_, err := delivery.DeleteEmployee(context.Background(), &tc.Filter)
 			helpers.AssertErrors(t, tc.ExpectedErr, err)
+			employeeRepositoryMock.AssertExpectations(t)
 		})
 	}
 }
+
+func TestDeleteEmployeeEvent(t *testing.T) {
+	id := primitive.NewObjectID()
+	employeeRepositoryMock := mocks.EmployeRepositoryMock{}
+	employeeRepositoryMock.On("Get", mock.Anything, &pb.EmployeeFilter{Email: "john@page.com"}).Return(&entities.Employee{ID: id}, nil)
+	employeeRepositoryMock.On("Delete", mock.Anything, &pb.EmployeeFilter{Id: id.Hex()}).Return(nil)
+
+	var published *pb.Event
+	streamingMock := mocks.StreamingMock{}
+	streamingMock.On("Publish", mock.Anything).Run(func(args mock.Arguments) {
+		published = args.Get(0).(*pb.Event)
+	}).Return(nil)
+
+	registry := event.NewRegistry()
+	assert.NoError(t, employee.RegisterEvents(registry))
+
+	delivery := NewEmployeeDelivery(zap.NewNop(), &employeeRepositoryMock, &mocks.RoleRepositoryMock{}, &streamingMock, registry, EmployeeDeliveryOptions{})
+	_, err := delivery.DeleteEmployee(context.Background(), &pb.EmployeeFilter{Email: "john@page.com"})
+	assert.NoError(t, err)
+	assert.NoError(t, delivery.Drain(context.Background()))
+
+	// email is encrypted only in payloads identifying employee
+	require.NotNil(t, published)
+	assert.Equal(t, id.Hex(), published.AggregateId)
+	var payload pb.EmployeeFilter
+	assert.NoError(t, ptypes.UnmarshalAny(published.Payload, &payload))
+	assert.Equal(t, id.Hex(), payload.Id)
+}
+
+func TestDrain(t *testing.T) {
+	employeeRepositoryMock := mocks.EmployeRepositoryMock{}
+	employeeRepositoryMock.On("Delete", mock.Anything, &pb.EmployeeFilter{Id: "1"}).Return(nil)
+
+	release := make(chan struct{})
+	streamingMock := mocks.StreamingMock{}
+	streamingMock.On("Publish", mock.Anything).Run(func(mock.Arguments) {
+		<-release
+	}).Return(nil)
+
+	registry := event.NewRegistry()
+	assert.NoError(t, employee.RegisterEvents(registry))
+
+	delivery := NewEmployeeDelivery(zap.NewNop(), &employeeRepositoryMock, &mocks.RoleRepositoryMock{}, &streamingMock, registry, EmployeeDeliveryOptions{})
+	_, err := delivery.DeleteEmployee(context.Background(), &pb.EmployeeFilter{Id: "1"})
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.Equal(t, context.DeadlineExceeded, delivery.Drain(ctx), "pending publish isn't awaited")
+
+	close(release)
+	assert.NoError(t, delivery.Drain(context.Background()))
+	streamingMock.AssertExpectations(t)
+}
+
+func TestDrainFailedPublish(t *testing.T) {
+	employeeRepositoryMock := mocks.EmployeRepositoryMock{}
+	employeeRepositoryMock.On("Delete", mock.Anything, &pb.EmployeeFilter{Id: "1"}).Return(nil)
+
+	streamingMock := mocks.StreamingMock{}
+	streamingMock.On("Publish", mock.Anything).Return(errors.New("connection closed"))
+
+	registry := event.NewRegistry()
+	assert.NoError(t, employee.RegisterEvents(registry))
+
+	core, logs := observer.New(zapcore.ErrorLevel)
+	delivery := NewEmployeeDelivery(zap.New(core), &employeeRepositoryMock, &mocks.RoleRepositoryMock{}, &streamingMock, registry, EmployeeDeliveryOptions{})
+	_, err := delivery.DeleteEmployee(context.Background(), &pb.EmployeeFilter{Id: "1"})
+	assert.NoError(t, err)
+	assert.NoError(t, delivery.Drain(context.Background()))
+
+	require.Equal(t, 1, logs.Len(), "failed publish isn't logged")
+	entry := logs.All()[0]
+	assert.Equal(t, "Can't publish event", entry.Message)
+	assert.Equal(t, "connection closed", entry.ContextMap()["error"])
+	assert.Equal(t, string(entities.DeleteEmployeeEvent), entry.ContextMap()["eventType"])
+}
@@ -0,0 +1,133 @@
+package employee
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+
+	employeeFactory "github.com/migotom/cell-centre-services/pkg/components/employee/factory"
+	"github.com/migotom/cell-centre-services/pkg/components/event"
+	"github.com/migotom/cell-centre-services/pkg/entities"
+	"github.com/migotom/cell-centre-services/pkg/pb"
+	pbFactory "github.com/migotom/cell-centre-services/pkg/pb/factory"
+)
+
+const (
+	// EventChannel is channel of employee events.
+	EventChannel = "employees"
+	// AggregateType of employee events.
+	AggregateType = "employees"
+)
+
+// RegisterEvents registers employee event types and decoders of their payloads.
+func RegisterEvents(registry *event.Registry) error {
+	definitions := []event.Definition{
+		{Type: entities.NewEmployeeEvent, Channel: EventChannel, AggregateType: AggregateType, Payload: &pb.Employee{}, Encode: encodeEmployee},
+		{Type: entities.UpdateEmployeeEvent, Channel: EventChannel, AggregateType: AggregateType, Payload: &pb.EmployeeChanges{}, Encode: encodeEmployeeChanges},
+		{Type: entities.DeleteEmployeeEvent, Channel: EventChannel, AggregateType: AggregateType, Payload: &pb.EmployeeFilter{}, Encode: encodeEmployeeFilter},
+		{Type: entities.EraseEmployeeEvent, Channel: EventChannel, AggregateType: AggregateType, Payload: &pb.EmployeeFilter{}, Encode: encodeEmployeeFilter},
+	}
+	for _, definition := range definitions {
+		if err := registry.RegisterEvent(definition); err != nil {
+			return err
+		}
+	}
+
+	factory := employeeFactory.NewEmployeeEntityFactory(nil)
+	payloads := []struct {
+		prototype proto.Message
+		decode    event.PayloadDecoder
+	}{
+		{&pb.Employee{}, func(m proto.Message) (interface{}, error) {
+			return factory.NewFromEmployee(m.(*pb.Employee))
+		}},
+		{&pb.EmployeeChanges{}, func(m proto.Message) (interface{}, error) {
+			return factory.NewFromEmployeeChanges(m.(*pb.EmployeeChanges))
+		}},
+		{&pb.EmployeeFilter{}, func(m proto.Message) (interface{}, error) {
+			return factory.NewFromEmployeeFilter(m.(*pb.EmployeeFilter))
+		}},
+	}
+	for _, payload := range payloads {
+		if err := registry.RegisterPayload(payload.prototype, payload.decode); err != nil {
+			return err
+		}
+	}
+
+	return registry.RegisterUpcaster(1, upcastUpdateRequest)
+}
+
+func encodeEmployee(data interface{}) (proto.Message, error) {
+	var employee entities.Employee
+	if err := event.DecodeData(data, &employee); err != nil {
+		return nil, err
+	}
+	return pbFactory.NewEmployeePbFactory().NewFromEmployee(&employee)
+}
+
+func encodeEmployeeChanges(data interface{}) (proto.Message, error) {
+	var changes entities.EmployeeChanges
+	if err := event.DecodeData(data, &changes); err != nil {
+		return nil, err
+	}
+	return pbFactory.NewEmployeePbFactory().NewFromEmployeeChanges(&changes), nil
+}
+
+func encodeEmployeeFilter(data interface{}) (proto.Message, error) {
+	var employee entities.Employee
+	if err := event.DecodeData(data, &employee); err != nil {
+		return nil, err
+	}
+	filter := pb.EmployeeFilter{Email: employee.Email}
+	if !employee.ID.IsZero() {
+		filter.Id = employee.ID.Hex()
+	}
+	return &filter, nil
+}
+
+// upcastUpdateRequest replaces update request carried by update events of schema version 1 with change set,
+// previous values of fields are unknown.
+func upcastUpdateRequest(e *pb.Event) error {
+	if e.GetType() != string(entities.UpdateEmployeeEvent) || !ptypes.Is(e.GetPayload(), &pb.UpdateEmployeeRequest{}) {
+		return nil
+	}
+
+	var request pb.UpdateEmployeeRequest
+	if err := ptypes.UnmarshalAny(e.GetPayload(), &request); err != nil {
+		return err
+	}
+
+	roles := make([]string, 0, len(request.GetRoles()))
+	for _, role := range request.GetRoles() {
+		roles = append(roles, role.GetName())
+	}
+	sort.Strings(roles)
+
+	changes := pb.EmployeeChanges{Id: request.GetId()}
+	fields := []struct {
+		path  string
+		value string
+	}{
+		{"email", request.GetEmail()},
+		{"name", request.GetName()},
+		{"phone", request.GetPhone()},
+		{"roles", strings.Join(roles, ",")},
+	}
+	for _, field := range fields {
+		if field.value != "" {
+			changes.Changes = append(changes.Changes, &pb.FieldChange{Path: field.path, NewValue: field.value})
+		}
+	}
+	if request.GetPassword() != "" {
+		changes.Changes = append(changes.Changes, &pb.FieldChange{Path: "password", Redacted: true})
+	}
+
+	payload, err := ptypes.MarshalAny(&changes)
+	if err != nil {
+		return err
+	}
+	e.Payload = payload
+	return nil
+}
@@ -0,0 +1,153 @@
+package employee
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/migotom/cell-centre-services/pkg/components/event"
+	eventFactory "github.com/migotom/cell-centre-services/pkg/components/event/factory"
+	"github.com/migotom/cell-centre-services/pkg/entities"
+	"github.com/migotom/cell-centre-services/pkg/helpers"
+	"github.com/migotom/cell-centre-services/pkg/pb"
+)
+
+// TestUpcastV1Fixtures decodes frozen events of schema version 1, fixtures must never be regenerated.
+func TestUpcastV1Fixtures(t *testing.T) {
+	id, _ := primitive.ObjectIDFromHex("5d3783ee28ae9468bc528907")
+	roleID, _ := primitive.ObjectIDFromHex("5d3783ee28ae9468bc528901")
+
+	cases := []struct {
+		Name            string
+		Fixture         string
+		ExpectedType    entities.EventType
+		ExpectedPayload proto.Message
+		ExpectedData    interface{}
+	}{
+		{
+			Name:         "New employee",
+			Fixture:      "new_employee.pb",
+			ExpectedType: entities.NewEmployeeEvent,
+			ExpectedPayload: &pb.Employee{Id: id.Hex(), Email: "john@page.com", Name: "John", Phone: "+48 600 100 200", Password: "$2a$04$hash",
+				Roles: []*pb.Role{{Id: roleID.Hex(), Name: "serviceman"}}},
+			ExpectedData: &entities.Employee{ID: id, Email: "john@page.com", Name: "John", Phone: "+48 600 100 200", Password: "$2a$04$hash",
+				Roles: []entities.Role{{ID: roleID, Name: "serviceman"}}},
+		},
+		{
+			Name:         "Update employee request becomes change set",
+			Fixture:      "update_employee.pb",
+			ExpectedType: entities.UpdateEmployeeEvent,
+			ExpectedPayload: &pb.EmployeeChanges{Id: id.Hex(), Changes: []*pb.FieldChange{
+				{Path: "name", NewValue: "John Smith"},
+				{Path: "roles", NewValue: "admin,serviceman"},
+				{Path: "password", Redacted: true},
+			}},
+			ExpectedData: &entities.EmployeeChanges{ID: id, Changes: []entities.FieldChange{
+				{Path: "name", NewValue: "John Smith"},
+				{Path: "roles", NewValue: "admin,serviceman"},
+				{Path: "password", Redacted: true},
+			}},
+		},
+		{
+			Name:         "Update employee change set",
+			Fixture:      "update_employee_changes.pb",
+			ExpectedType: entities.UpdateEmployeeEvent,
+			ExpectedPayload: &pb.EmployeeChanges{Id: id.Hex(), Changes: []*pb.FieldChange{
+				{Path: "phone", OldValue: "+48 600 100 200", NewValue: "+48 600 100 300"},
+			}},
+			ExpectedData: &entities.EmployeeChanges{ID: id, Changes: []entities.FieldChange{
+				{Path: "phone", OldValue: "+48 600 100 200", NewValue: "+48 600 100 300"},
+			}},
+		},
+		{
+			Name:            "Delete employee",
+			Fixture:         "delete_employee.pb",
+			ExpectedType:    entities.DeleteEmployeeEvent,
+			ExpectedPayload: &pb.EmployeeFilter{Email: "john@page.com"},
+			ExpectedData:    &entities.Employee{Email: "john@page.com"},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			registry := event.NewRegistry()
+			require.NoError(t, RegisterEvents(registry))
+
+			data, err := ioutil.ReadFile(filepath.Join("testdata", "v1", tc.Fixture))
+			require.NoError(t, err)
+
+			var e pb.Event
+			require.NoError(t, proto.Unmarshal(data, &e))
+			assert.Equal(t, uint32(0), e.SchemaVersion)
+
+			entity, err := eventFactory.NewEventEntityFactory(registry).NewFromEvent(e)
+			require.NoError(t, err)
+			assert.Equal(t, tc.ExpectedType, entity.Type)
+			assert.Equal(t, uint32(event.SchemaVersion), entity.SchemaVersion)
+			assert.Equal(t, tc.ExpectedData, entity.Data)
+
+			payload, err := registry.Payload(&e)
+			require.NoError(t, err)
+			assert.True(t, proto.Equal(tc.ExpectedPayload, payload), "unexpected payload %v", payload)
+			assert.Nil(t, e.Data)
+			assert.Equal(t, uint32(event.SchemaVersion), e.SchemaVersion)
+		})
+	}
+}
+
+func TestEncodeStoredData(t *testing.T) {
+	id, _ := primitive.ObjectIDFromHex("5d3783ee28ae9468bc528907")
+
+	registry := event.NewRegistry()
+	require.NoError(t, RegisterEvents(registry))
+
+	cases := []struct {
+		Name            string
+		Event           *entities.Event
+		ExpectedPayload proto.Message
+		ExpectedErr     string
+	}{
+		{
+			Name: "New employee",
+			Event: &entities.Event{Type: entities.NewEmployeeEvent, Data: storedData(t, &entities.Employee{
+				ID:    id,
+				Email: "john@page.com",
+				Team:  "field",
+				Roles: []entities.Role{{ID: id, Name: "admin"}},
+			})},
+			ExpectedPayload: &pb.Employee{Id: id.Hex(), Email: "john@page.com", Team: "field", Roles: []*pb.Role{{Id: id.Hex(), Name: "admin"}}},
+		},
+		{
+			Name: "Changes of employee",
+			Event: &entities.Event{Type: entities.UpdateEmployeeEvent, Data: storedData(t, &entities.EmployeeChanges{
+				ID:      id,
+				Changes: []entities.FieldChange{{Path: "name", OldValue: "John", NewValue: "John Page"}, {Path: "password", Redacted: true}},
+			})},
+			ExpectedPayload: &pb.EmployeeChanges{Id: id.Hex(), Changes: []*pb.FieldChange{
+				{Path: "name", OldValue: "John", NewValue: "John Page"},
+				{Path: "password", Redacted: true},
+			}},
+		},
+		{
+			Name:            "Deleted employee",
+			Event:           &entities.Event{Type: entities.DeleteEmployeeEvent, Data: storedData(t, &entities.Employee{Email: "john@page.com"})},
+			ExpectedPayload: &pb.EmployeeFilter{Email: "john@page.com"},
+		},
+		{
+			Name:        "Event without data",
+			Event:       &entities.Event{Type: entities.DeleteEmployeeEvent},
+			ExpectedErr: "missing data",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			payload, err := registry.Encode(tc.Event)
+			helpers.AssertErrors(t, tc.ExpectedErr, err)
+			assert.Equal(t, tc.ExpectedPayload, payload)
+		})
+	}
+}
@@ -89,12 +89,32 @@ func (factory *EmployeeEntityFactory) NewFromEmployee(e *pb.Employee) (employee
 	return
 }
 
+// NewFromEmployeeChanges creates EmployeeChanges entity from EmployeeChanges message.
+func (factory *EmployeeEntityFactory) NewFromEmployeeChanges(e *pb.EmployeeChanges) (*entities.EmployeeChanges, error) {
+	id, err := primitive.ObjectIDFromHex(e.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	changes := entities.EmployeeChanges{ID: id}
+	for _, change := range e.GetChanges() {
+		changes.Changes = append(changes.Changes, entities.FieldChange{
+			Path:     change.GetPath(),
+			OldValue: change.GetOldValue(),
+			NewValue: change.GetNewValue(),
+			Redacted: change.GetRedacted(),
+		})
+	}
+	return &changes, nil
+}
+
 func (factory *EmployeeEntityFactory) newEntityFromBase(e baseEmployeeInterface) (*entities.Employee, error) {
 	employee := entities.Employee{
 		Email:    e.GetEmail(),
 		Password: e.GetPassword(),
 		Name:     e.GetName(),
 		Phone:    e.GetPhone(),
+		Team:     e.GetTeam(),
 	}
 
 	for _, role := range e.GetRoles() {
@@ -128,5 +148,6 @@ type baseEmployeeInterface interface {
 	GetName() string
 	GetPassword() string
 	GetPhone() string
+	GetTeam() string
 	GetRoles() []*pb.Role
 }
@@ -0,0 +1,126 @@
+package employee
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+
+	"github.com/migotom/cell-centre-services/pkg/components/keystore"
+	"github.com/migotom/cell-centre-services/pkg/entities"
+	"github.com/migotom/cell-centre-services/pkg/pb"
+)
+
+// personalFields are paths of employee fields holding personal data.
+var personalFields = map[string]bool{
+	"email": true,
+	"name":  true,
+	"phone": true,
+}
+
+// PayloadCipher encrypts personal data of employees carried by payloads of employee events with data key of
+// employee. Payloads filtering employees only by email aren't encrypted, as they lack ID of employee.
+type PayloadCipher struct {
+	shredder *keystore.Shredder
+}
+
+// NewPayloadCipher returns new cipher of employee events.
+func NewPayloadCipher(shredder *keystore.Shredder) *PayloadCipher {
+	return &PayloadCipher{
+		shredder: shredder,
+	}
+}
+
+// Encrypt encrypts personal data in payload of event, data key of employee is created by its first event.
+func (payloadCipher *PayloadCipher) Encrypt(ctx context.Context, e *pb.Event) error {
+	return payloadCipher.transform(ctx, e, true, (*keystore.SubjectCipher).Encrypt)
+}
+
+// Decrypt decrypts personal data in payload of event, data of erased employees is replaced by keystore.Erased.
+func (payloadCipher *PayloadCipher) Decrypt(ctx context.Context, e *pb.Event) error {
+	return payloadCipher.transform(ctx, e, false, (*keystore.SubjectCipher).Decrypt)
+}
+
+// DecryptEmployee decrypts personal data of employee rebuilt from events.
+func (payloadCipher *PayloadCipher) DecryptEmployee(ctx context.Context, employee *entities.Employee) error {
+	if employee == nil {
+		return nil
+	}
+	subject, err := payloadCipher.shredder.Subject(ctx, employee.ID.Hex(), false)
+	if err != nil {
+		return err
+	}
+	return apply([]*string{&employee.Email, &employee.Name, &employee.Phone}, subject.Decrypt)
+}
+
+// Erase destroys data key of employee of given ID.
+func (payloadCipher *PayloadCipher) Erase(ctx context.Context, id string) error {
+	return payloadCipher.shredder.Erase(ctx, id)
+}
+
+func (payloadCipher *PayloadCipher) transform(ctx context.Context, e *pb.Event, create bool, transform func(*keystore.SubjectCipher, string) (string, error)) error {
+	if e.GetPayload() == nil {
+		return nil
+	}
+
+	var payload ptypes.DynamicAny
+	if err := ptypes.UnmarshalAny(e.GetPayload(), &payload); err != nil {
+		// payloads of unknown types don't carry data of employees
+		return nil
+	}
+	id, fields := personalData(payload.Message)
+	if id == "" || len(fields) == 0 {
+		return nil
+	}
+
+	subject, err := payloadCipher.shredder.Subject(ctx, id, create)
+	if err != nil {
+		return fmt.Errorf("can't protect personal data of employee %s: %v", id, err)
+	}
+	if err := apply(fields, func(value string) (string, error) { return transform(subject, value) }); err != nil {
+		return fmt.Errorf("can't protect personal data of employee %s: %v", id, err)
+	}
+
+	e.Payload, err = ptypes.MarshalAny(payload.Message)
+	return err
+}
+
+// personalData returns ID of employee and not empty fields of payload holding its personal data.
+func personalData(payload proto.Message) (string, []*string) {
+	var id string
+	var fields []*string
+	switch payload := payload.(type) {
+	case *pb.Employee:
+		id, fields = payload.GetId(), []*string{&payload.Email, &payload.Name, &payload.Phone}
+	case *pb.EmployeeChanges:
+		id = payload.GetId()
+		for _, change := range payload.GetChanges() {
+			if personalFields[change.GetPath()] {
+				fields = append(fields, &change.OldValue, &change.NewValue)
+			}
+		}
+	case *pb.EmployeeFilter:
+		id, fields = payload.GetId(), []*string{&payload.Email}
+	}
+
+	// payloads without personal data don't need data key of employee, e.g. events of erased employees
+	set := fields[:0]
+	for _, field := range fields {
+		if *field != "" {
+			set = append(set, field)
+		}
+	}
+	return id, set
+}
+
+func apply(fields []*string, transform func(string) (string, error)) error {
+	for _, field := range fields {
+		value, err := transform(*field)
+		if err != nil {
+			return err
+		}
+		*field = value
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package employee
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/migotom/cell-centre-services/pkg/components/keystore"
+	"github.com/migotom/cell-centre-services/pkg/entities"
+	"github.com/migotom/cell-centre-services/pkg/helpers/mocks"
+	"github.com/migotom/cell-centre-services/pkg/pb"
+)
+
+func TestPayloadCipher(t *testing.T) {
+	key := &entities.DataKey{SubjectID: "5d3783ee28ae9468bc528907", Key: make([]byte, 32)}
+
+	cases := []struct {
+		Name            string
+		Payload         proto.Message
+		ExpectedPayload proto.Message
+		ExpectedErased  proto.Message
+	}{
+		{
+			Name:            "Employee",
+			Payload:         &pb.Employee{Id: key.SubjectID, Email: "john@page.com", Name: "John", Phone: "+48 600 100 200", Team: "field"},
+			ExpectedPayload: &pb.Employee{Id: key.SubjectID, Team: "field"},
+			ExpectedErased:  &pb.Employee{Id: key.SubjectID, Email: keystore.Erased, Name: keystore.Erased, Phone: keystore.Erased, Team: "field"},
+		},
+		{
+			Name: "Employee changes",
+			Payload: &pb.EmployeeChanges{Id: key.SubjectID, Changes: []*pb.FieldChange{
+				{Path: "name", OldValue: "John", NewValue: "John Page"},
+				{Path: "team", OldValue: "field", NewValue: "office"},
+			}},
+			ExpectedPayload: &pb.EmployeeChanges{Id: key.SubjectID, Changes: []*pb.FieldChange{
+				{Path: "name"},
+				{Path: "team", OldValue: "field", NewValue: "office"},
+			}},
+			ExpectedErased: &pb.EmployeeChanges{Id: key.SubjectID, Changes: []*pb.FieldChange{
+				{Path: "name", OldValue: keystore.Erased, NewValue: keystore.Erased},
+				{Path: "team", OldValue: "field", NewValue: "office"},
+			}},
+		},
+		{
+			Name:            "Filter without ID isn't encrypted",
+			Payload:         &pb.EmployeeFilter{Email: "john@page.com"},
+			ExpectedPayload: &pb.EmployeeFilter{Email: "john@page.com"},
+			ExpectedErased:  &pb.EmployeeFilter{Email: "john@page.com"},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			keyRepositoryMock := mocks.KeyRepositoryMock{}
+			keyRepositoryMock.On("Get", mock.Anything, key.SubjectID).Return(key, nil)
+			payloadCipher := NewPayloadCipher(keystore.NewShredder(&keyRepositoryMock))
+
+			payload, err := ptypes.MarshalAny(tc.Payload)
+			require.NoError(t, err)
+			e := &pb.Event{Type: "NewEmployee", Payload: payload}
+
+			require.NoError(t, payloadCipher.Encrypt(context.Background(), e))
+			encrypted := proto.Clone(tc.Payload)
+			require.NoError(t, ptypes.UnmarshalAny(e.Payload, encrypted))
+			assert.Equal(t, tc.ExpectedPayload, withoutEncrypted(encrypted))
+
+			decrypted := proto.Clone(e).(*pb.Event)
+			require.NoError(t, payloadCipher.Decrypt(context.Background(), decrypted))
+			assert.Equal(t, payload, decrypted.Payload)
+
+			erasedRepositoryMock := mocks.KeyRepositoryMock{}
+			erasedRepositoryMock.On("Get", mock.Anything, key.SubjectID).Return((*entities.DataKey)(nil), keystore.ErrKeyNotFound)
+			require.NoError(t, NewPayloadCipher(keystore.NewShredder(&erasedRepositoryMock)).Decrypt(context.Background(), e))
+			erased := proto.Clone(tc.Payload)
+			require.NoError(t, ptypes.UnmarshalAny(e.Payload, erased))
+			assert.Equal(t, tc.ExpectedErased, erased)
+		})
+	}
+}
+
+// withoutEncrypted clears encrypted fields of payload, so it can be compared with expected one.
+func withoutEncrypted(payload proto.Message) proto.Message {
+	clear := func(fields ...*string) {
+		for _, field := range fields {
+			if keystore.Encrypted(*field) {
+				*field = ""
+			}
+		}
+	}
+	switch payload := payload.(type) {
+	case *pb.Employee:
+		clear(&payload.Email, &payload.Name, &payload.Phone)
+	case *pb.EmployeeChanges:
+		for _, change := range payload.Changes {
+			clear(&change.OldValue, &change.NewValue)
+		}
+	}
+	return payload
+}
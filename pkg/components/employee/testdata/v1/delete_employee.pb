@@ -0,0 +1,3 @@
+
+$0b9d3c0e-3f4e-4b8c-9d59-7c0f0f6f5a04	employeesDeleteEmployee"john@page.com*	employeesJ4
+5d3783ee28ae9468bc528906employeeadmin@page.comRBjohn@page.com
\ No newline at end of file
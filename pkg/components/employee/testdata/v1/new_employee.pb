@@ -0,0 +1,6 @@
+
+$0b9d3c0e-3f4e-4b8c-9d59-7c0f0f6f5a01	employeesNewEmployee"5d3783ee28ae9468bc528907*	employeesJ4
+5d3783ee28ae9468bc528906employeeadmin@page.comR2u
+5d3783ee28ae9468bc528907john@page.comJohn"$2a$04$hash*+48 600 100 2002&
+5d3783ee28ae9468bc528901
+serviceman
\ No newline at end of file
@@ -0,0 +1,6 @@
+
+$0b9d3c0e-3f4e-4b8c-9d59-7c0f0f6f5a02	employeesUpdateEmployee"5d3783ee28ae9468bc528907*	employeesJ4
+5d3783ee28ae9468bc528906employeeadmin@page.comR:E
+5d3783ee28ae9468bc528907
+John Smith"secret2
+serviceman2admin
\ No newline at end of file
@@ -0,0 +1,5 @@
+
+$0b9d3c0e-3f4e-4b8c-9d59-7c0f0f6f5a03	employeesUpdateEmployee"5d3783ee28ae9468bc528907*	employeesJ4
+5d3783ee28ae9468bc528906employeeadmin@page.comRXbE
+5d3783ee28ae9468bc528907)
+phone+48 600 100 200+48 600 100 300
\ No newline at end of file
@@ -0,0 +1,529 @@
+// Package archive keeps events moved out of event log in gzip compressed JSON Lines files, partitioned by channel
+// and day and listed by manifest with their checksums. Links of archived events in hash chains of their channels
+// are kept in chain files next to them, along with canonical documents links were hashed from.
+package archive
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/golang/protobuf/ptypes"
+
+	"github.com/migotom/cell-centre-services/pkg/components/event/chain"
+	"github.com/migotom/cell-centre-services/pkg/components/event/encoding"
+	"github.com/migotom/cell-centre-services/pkg/entities"
+	"github.com/migotom/cell-centre-services/pkg/pb"
+)
+
+const (
+	manifestName = "manifest.json"
+	// DayLayout is layout of days partitioning archive.
+	DayLayout = "2006-01-02"
+)
+
+// ErrChecksumMismatch is returned when archive file differs from file listed by manifest.
+var ErrChecksumMismatch = errors.New("checksum of archive file doesn't match manifest")
+
+// Filter of archive files, empty fields don't filter files. Days are in DayLayout and bound range inclusively.
+// AggregateID narrows files of AggregateType to those holding events of aggregate, files which aren't indexed by
+// their aggregates match by type.
+type Filter struct {
+	Channel       string
+	AggregateType string
+	AggregateID   string
+	From          string
+	To            string
+}
+
+func (filter Filter) matches(file entities.ArchiveFile) bool {
+	if filter.Channel != "" && filter.Channel != file.Channel {
+		return false
+	}
+	if filter.From != "" && file.Day < filter.From {
+		return false
+	}
+	if filter.To != "" && file.Day > filter.To {
+		return false
+	}
+	if filter.AggregateID != "" && Indexed(file) {
+		return Aggregate(file, filter.AggregateType, filter.AggregateID) != nil
+	}
+	if filter.AggregateType != "" {
+		for _, aggregateType := range file.AggregateTypes {
+			if aggregateType == filter.AggregateType {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// Indexed reports whether archive file lists its aggregates, files archived before the index was introduced have to
+// be read to find events of aggregate.
+func Indexed(file entities.ArchiveFile) bool {
+	return len(file.AggregateTypes) == 0 || len(file.Aggregates) > 0
+}
+
+// Aggregate returns index entry of aggregate in archive file, nil when file doesn't hold its events or isn't indexed.
+func Aggregate(file entities.ArchiveFile, aggregateType, aggregateID string) *entities.ArchiveAggregate {
+	i := sort.Search(len(file.Aggregates), func(i int) bool {
+		aggregate := file.Aggregates[i]
+		return aggregate.Type > aggregateType || aggregate.Type == aggregateType && aggregate.ID >= aggregateID
+	})
+	if i < len(file.Aggregates) && file.Aggregates[i].Type == aggregateType && file.Aggregates[i].ID == aggregateID {
+		return &file.Aggregates[i]
+	}
+	return nil
+}
+
+// Archive of events in directory.
+type Archive struct {
+	sync.Mutex
+
+	directory string
+	format    encoding.Format
+}
+
+// NewArchive returns archive kept in given directory, events are written in given format. Formats carrying part
+// of event in headers can't be archived.
+func NewArchive(directory string, format encoding.Format) (*Archive, error) {
+	if format.HasHeaders() {
+		return nil, fmt.Errorf("format %s carries events in headers and can't be archived", format)
+	}
+	if err := os.MkdirAll(directory, 0750); err != nil {
+		return nil, err
+	}
+	return &Archive{
+		directory: directory,
+		format:    format,
+	}, nil
+}
+
+// Write stores events of channel created at given day in new part of channel and day files and lists it in manifest.
+// Given chained events, by their IDs, are kept with their links in chain file of part and runs of links are listed
+// by manifest.
+func (archive *Archive) Write(channel, day string, events []*pb.Event, chained map[string]*entities.ChainedEvent) (*entities.ArchiveFile, error) {
+	if channel == "" || filepath.Base(channel) != channel {
+		return nil, fmt.Errorf("invalid channel %q", channel)
+	}
+	if _, err := time.Parse(DayLayout, day); err != nil {
+		return nil, fmt.Errorf("invalid day %q", day)
+	}
+
+	archive.Lock()
+	defer archive.Unlock()
+
+	manifest, err := archive.manifest()
+	if err != nil {
+		return nil, err
+	}
+
+	file := entities.ArchiveFile{
+		Channel:    channel,
+		Day:        day,
+		Part:       1,
+		Format:     string(archive.format),
+		Events:     len(events),
+		ArchivedAt: time.Now().UTC(),
+	}
+	for _, archived := range manifest.Files {
+		if archived.Channel == channel && archived.Day == day && archived.Part >= file.Part {
+			file.Part = archived.Part + 1
+		}
+	}
+	file.Path = filepath.Join(channel, day, fmt.Sprintf("%s-%s-%04d.jsonl.gz", channel, day, file.Part))
+
+	data, err := archive.compress(&file, events)
+	if err != nil {
+		return nil, err
+	}
+	checksum := sha256.Sum256(data)
+	file.SHA256 = hex.EncodeToString(checksum[:])
+
+	path := filepath.Join(archive.directory, file.Path)
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return nil, err
+	}
+	if err := writeFile(path, data); err != nil {
+		return nil, err
+	}
+
+	links, err := compressLinks(&file, events, chained)
+	if err != nil {
+		return nil, err
+	}
+	if links != nil {
+		file.ChainPath = filepath.Join(channel, day, fmt.Sprintf("%s-%s-%04d.chain.jsonl.gz", channel, day, file.Part))
+		checksum := sha256.Sum256(links)
+		file.ChainSHA256 = hex.EncodeToString(checksum[:])
+		if err := writeFile(filepath.Join(archive.directory, file.ChainPath), links); err != nil {
+			return nil, err
+		}
+	}
+
+	manifest.Files = append(manifest.Files, file)
+	if err := archive.saveManifest(manifest); err != nil {
+		return nil, err
+	}
+	return &file, nil
+}
+
+// compress encodes events one per line and describes them in archive file.
+func (archive *Archive) compress(file *entities.ArchiveFile, events []*pb.Event) ([]byte, error) {
+	var data bytes.Buffer
+	writer := gzip.NewWriter(&data)
+
+	aggregateTypes := make(map[string]bool)
+	aggregates := make(map[[2]string]int)
+	for _, e := range events {
+		message, err := encoding.Encode(archive.format, e)
+		if err != nil {
+			return nil, fmt.Errorf("can't encode event %s: %v", e.GetEventId(), err)
+		}
+		if _, err := writer.Write(append(message.Body, '\n')); err != nil {
+			return nil, err
+		}
+
+		if e.GetAggregateType() != "" && !aggregateTypes[e.GetAggregateType()] {
+			aggregateTypes[e.GetAggregateType()] = true
+			file.AggregateTypes = append(file.AggregateTypes, e.GetAggregateType())
+		}
+		if e.GetAggregateType() != "" {
+			key := [2]string{e.GetAggregateType(), e.GetAggregateId()}
+			if i, ok := aggregates[key]; ok {
+				if e.GetSequence() < file.Aggregates[i].FirstSequence {
+					file.Aggregates[i].FirstSequence = e.GetSequence()
+				}
+				if e.GetSequence() > file.Aggregates[i].LastSequence {
+					file.Aggregates[i].LastSequence = e.GetSequence()
+				}
+			} else {
+				aggregates[key] = len(file.Aggregates)
+				file.Aggregates = append(file.Aggregates, entities.ArchiveAggregate{
+					Type:          e.GetAggregateType(),
+					ID:            e.GetAggregateId(),
+					FirstSequence: e.GetSequence(),
+					LastSequence:  e.GetSequence(),
+				})
+			}
+		}
+		createdAt, err := ptypes.Timestamp(e.GetCreatedAt())
+		if err != nil {
+			return nil, fmt.Errorf("invalid creation time of event %s: %v", e.GetEventId(), err)
+		}
+		if file.From.IsZero() || createdAt.Before(file.From) {
+			file.From = createdAt
+		}
+		if createdAt.After(file.To) {
+			file.To = createdAt
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	sort.Strings(file.AggregateTypes)
+	// aggregates are sorted to be searched by Aggregate
+	sort.Slice(file.Aggregates, func(i, j int) bool {
+		if file.Aggregates[i].Type != file.Aggregates[j].Type {
+			return file.Aggregates[i].Type < file.Aggregates[j].Type
+		}
+		return file.Aggregates[i].ID < file.Aggregates[j].ID
+	})
+	return data.Bytes(), nil
+}
+
+// archivedLink is line of chain file, link of archived event with canonical document of event it was hashed from.
+// Links archived before documents were kept have no document.
+type archivedLink struct {
+	EventID string `json:"event_id"`
+	entities.ChainLink
+	Document []byte `json:"document,omitempty"`
+}
+
+// compressLinks encodes links of chained events one per line and lists their runs in archive file, returns nil
+// when none of events was chained.
+func compressLinks(file *entities.ArchiveFile, events []*pb.Event, chained map[string]*entities.ChainedEvent) ([]byte, error) {
+	var links []archivedLink
+	for _, e := range events {
+		linked := chained[e.GetEventId()]
+		if linked == nil || linked.Link == nil {
+			continue
+		}
+		link := archivedLink{EventID: e.GetEventId(), ChainLink: *linked.Link}
+		if linked.Document != nil {
+			document, err := chain.Canonical(linked.Document)
+			if err != nil {
+				return nil, fmt.Errorf("can't archive link of event %s: %v", e.GetEventId(), err)
+			}
+			link.Document = document
+		}
+		links = append(links, link)
+	}
+	if len(links) == 0 {
+		return nil, nil
+	}
+	sort.Slice(links, func(i, j int) bool { return links[i].Position < links[j].Position })
+
+	var data bytes.Buffer
+	writer := gzip.NewWriter(&data)
+	encoder := json.NewEncoder(writer)
+	for _, link := range links {
+		if err := encoder.Encode(&link); err != nil {
+			return nil, err
+		}
+
+		last := len(file.Chain) - 1
+		if last >= 0 && file.Chain[last].To+1 == link.Position && file.Chain[last].Hash == link.PreviousHash {
+			file.Chain[last].To, file.Chain[last].Hash = link.Position, link.Hash
+			continue
+		}
+		file.Chain = append(file.Chain, entities.ArchiveChainRun{
+			From:         link.Position,
+			PreviousHash: link.PreviousHash,
+			To:           link.Position,
+			Hash:         link.Hash,
+		})
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return data.Bytes(), nil
+}
+
+// Files returns files of archive matching filter in order of their channels, days and parts.
+func (archive *Archive) Files(filter Filter) ([]entities.ArchiveFile, error) {
+	archive.Lock()
+	manifest, err := archive.manifest()
+	archive.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	var files []entities.ArchiveFile
+	for _, file := range manifest.Files {
+		if filter.matches(file) {
+			files = append(files, file)
+		}
+	}
+	sort.Slice(files, func(i, j int) bool {
+		if files[i].Channel != files[j].Channel {
+			return files[i].Channel < files[j].Channel
+		}
+		if files[i].Day != files[j].Day {
+			return files[i].Day < files[j].Day
+		}
+		return files[i].Part < files[j].Part
+	})
+	return files, nil
+}
+
+// Read returns events of archive file in order they were written, file is verified first.
+func (archive *Archive) Read(file entities.ArchiveFile) ([]*pb.Event, error) {
+	data, err := archive.read(file)
+	if err != nil {
+		return nil, err
+	}
+
+	reader, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("can't read archive file %s: %v", file.Path, err)
+	}
+	defer reader.Close()
+
+	var events []*pb.Event
+	lines := bufio.NewReader(reader)
+	for {
+		line, err := lines.ReadBytes('\n')
+		if len(bytes.TrimSpace(line)) > 0 {
+			e, decodeErr := encoding.Decode(encoding.Format(file.Format), &encoding.Message{Body: line})
+			if decodeErr != nil {
+				return nil, fmt.Errorf("can't decode event %d of archive file %s: %v", len(events)+1, file.Path, decodeErr)
+			}
+			events = append(events, e)
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("can't read archive file %s: %v", file.Path, err)
+		}
+	}
+	if len(events) != file.Events {
+		return nil, fmt.Errorf("archive file %s holds %d events, manifest lists %d", file.Path, len(events), file.Events)
+	}
+	return events, nil
+}
+
+// Links returns chained events of archive file by their IDs with their links and canonical documents, chain file is
+// verified first.
+func (archive *Archive) Links(file entities.ArchiveFile) (map[string]*entities.ChainedEvent, error) {
+	links := make(map[string]*entities.ChainedEvent)
+	if file.ChainPath == "" {
+		return links, nil
+	}
+
+	data, err := archive.readFile(file.ChainPath, file.ChainSHA256)
+	if err != nil {
+		return nil, err
+	}
+	reader, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("can't read chain file %s: %v", file.ChainPath, err)
+	}
+	defer reader.Close()
+
+	decoder := json.NewDecoder(reader)
+	for {
+		var link archivedLink
+		if err := decoder.Decode(&link); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("can't decode link %d of chain file %s: %v", len(links)+1, file.ChainPath, err)
+		}
+		links[link.EventID] = &entities.ChainedEvent{EventID: link.EventID, Document: link.Document, Link: &link.ChainLink}
+	}
+	return links, nil
+}
+
+// Chain returns runs of archived links of channel in order of their positions, runs starting at the same position
+// are ordered from the longest.
+func (archive *Archive) Chain(channel string) ([]entities.ArchiveChainRun, error) {
+	files, err := archive.Files(Filter{Channel: channel})
+	if err != nil {
+		return nil, err
+	}
+
+	var runs []entities.ArchiveChainRun
+	for _, file := range files {
+		runs = append(runs, file.Chain...)
+	}
+	// runs of events archived again by interrupted archival are followed by the longest one
+	sort.Slice(runs, func(i, j int) bool {
+		if runs[i].From != runs[j].From {
+			return runs[i].From < runs[j].From
+		}
+		return runs[i].To > runs[j].To
+	})
+	return runs, nil
+}
+
+// Verify compares checksums of archive file and its chain file with manifest.
+func (archive *Archive) Verify(file entities.ArchiveFile) error {
+	if _, err := archive.read(file); err != nil {
+		return err
+	}
+	if file.ChainPath == "" {
+		return nil
+	}
+	_, err := archive.readFile(file.ChainPath, file.ChainSHA256)
+	return err
+}
+
+func (archive *Archive) read(file entities.ArchiveFile) ([]byte, error) {
+	return archive.readFile(file.Path, file.SHA256)
+}
+
+// readFile returns content of file of archive matching given checksum.
+func (archive *Archive) readFile(path, checksum string) ([]byte, error) {
+	data, err := ioutil.ReadFile(filepath.Join(archive.directory, path))
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(data)
+	if hex.EncodeToString(sum[:]) != checksum {
+		return nil, fmt.Errorf("%v: %s", ErrChecksumMismatch, path)
+	}
+	return data, nil
+}
+
+// Remove deletes archive file, its chain file and its manifest entry.
+func (archive *Archive) Remove(file entities.ArchiveFile) error {
+	archive.Lock()
+	defer archive.Unlock()
+
+	manifest, err := archive.manifest()
+	if err != nil {
+		return err
+	}
+
+	files := manifest.Files[:0]
+	for _, archived := range manifest.Files {
+		if archived.Path != file.Path {
+			files = append(files, archived)
+		}
+	}
+	manifest.Files = files
+	if err := archive.saveManifest(manifest); err != nil {
+		return err
+	}
+
+	for _, path := range []string{file.Path, file.ChainPath} {
+		if path == "" {
+			continue
+		}
+		if err := os.Remove(filepath.Join(archive.directory, path)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+func (archive *Archive) manifest() (*entities.ArchiveManifest, error) {
+	var manifest entities.ArchiveManifest
+
+	data, err := ioutil.ReadFile(filepath.Join(archive.directory, manifestName))
+	if os.IsNotExist(err) {
+		return &manifest, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("invalid manifest of archive: %v", err)
+	}
+	return &manifest, nil
+}
+
+func (archive *Archive) saveManifest(manifest *entities.ArchiveManifest) error {
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFile(filepath.Join(archive.directory, manifestName), data)
+}
+
+// writeFile replaces file with given data once data is synced to disk.
+func writeFile(path string, data []byte) error {
+	tmp, err := ioutil.TempFile(filepath.Dir(path), "."+filepath.Base(path))
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
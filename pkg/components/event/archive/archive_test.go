@@ -0,0 +1,335 @@
+package archive
+
+import (
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/ptypes"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/migotom/cell-centre-services/pkg/components/employee"
+	"github.com/migotom/cell-centre-services/pkg/components/event"
+	"github.com/migotom/cell-centre-services/pkg/components/event/encoding"
+	"github.com/migotom/cell-centre-services/pkg/entities"
+	"github.com/migotom/cell-centre-services/pkg/helpers"
+	"github.com/migotom/cell-centre-services/pkg/pb"
+)
+
+const aggregateID = "5d3783ee28ae9468bc528907"
+
+func deleteEvent(id string, sequence uint64, createdAt time.Time) *pb.Event {
+	payload, _ := ptypes.MarshalAny(&pb.EmployeeFilter{Id: aggregateID})
+	timestamp, _ := ptypes.TimestampProto(createdAt)
+	return &pb.Event{
+		EventId:       id,
+		Channel:       employee.EventChannel,
+		Type:          string(entities.DeleteEmployeeEvent),
+		AggregateId:   aggregateID,
+		AggregateType: employee.AggregateType,
+		Sequence:      sequence,
+		Payload:       payload,
+		SchemaVersion: event.SchemaVersion,
+		CreatedAt:     timestamp,
+	}
+}
+
+func TestArchive(t *testing.T) {
+	day := time.Date(2019, 7, 24, 20, 26, 40, 0, time.UTC)
+
+	cases := []struct {
+		Name        string
+		Format      encoding.Format
+		ExpectedErr string
+	}{
+		{Name: "JSON", Format: encoding.JSON},
+		{Name: "CloudEvents structured mode", Format: encoding.CloudEventsStructured},
+		{Name: "CloudEvents binary mode", Format: encoding.CloudEventsBinary, ExpectedErr: "format cloudevents-binary carries events in headers and can't be archived"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			archive, err := NewArchive(t.TempDir(), tc.Format)
+			helpers.AssertErrors(t, tc.ExpectedErr, err)
+			if err != nil {
+				return
+			}
+
+			events := []*pb.Event{deleteEvent("e1", 1, day), deleteEvent("e2", 2, day.Add(time.Hour))}
+			stored, _ := bson.Marshal(bson.D{{Key: "_id", Value: "5d2f0c8e"}, {Key: "event_id", Value: "e1"}, {Key: "chain", Value: bson.M{"position": 7}}})
+			canonical, _ := bson.Marshal(bson.D{{Key: "event_id", Value: "e1"}})
+			links := map[string]*entities.ChainedEvent{
+				"e1": {EventID: "e1", Document: stored, Link: &entities.ChainLink{Position: 7, PreviousHash: "h6", Hash: "h7"}},
+				// link archived without document
+				"e2": {EventID: "e2", Link: &entities.ChainLink{Position: 8, PreviousHash: "h7", Hash: "h8"}},
+			}
+			first, err := archive.Write("employees", "2019-07-24", events, links)
+			assert.NoError(t, err)
+			second, err := archive.Write("employees", "2019-07-24", events[1:], nil)
+			assert.NoError(t, err)
+
+			assert.Equal(t, filepath.Join("employees", "2019-07-24", "employees-2019-07-24-0001.jsonl.gz"), first.Path)
+			assert.Equal(t, 2, second.Part)
+			assert.Equal(t, []string{"employees"}, first.AggregateTypes)
+			assert.Equal(t, []entities.ArchiveAggregate{{Type: "employees", ID: aggregateID, FirstSequence: 1, LastSequence: 2}}, first.Aggregates)
+			assert.Equal(t, day, first.From)
+			assert.Equal(t, day.Add(time.Hour), first.To)
+			assert.Equal(t, []entities.ArchiveChainRun{{From: 7, PreviousHash: "h6", To: 8, Hash: "h8"}}, first.Chain)
+			assert.Empty(t, second.ChainPath)
+
+			files, err := archive.Files(Filter{Channel: "employees", From: "2019-07-24", To: "2019-07-24"})
+			assert.NoError(t, err)
+			assert.Equal(t, []entities.ArchiveFile{*first, *second}, files)
+
+			read, err := archive.Read(*first)
+			assert.NoError(t, err)
+			assert.Len(t, read, 2)
+			assert.Equal(t, "e2", read[1].GetEventId())
+			assert.Equal(t, uint64(2), read[1].GetSequence())
+
+			archived, err := archive.Links(*first)
+			assert.NoError(t, err)
+			assert.Equal(t, map[string]*entities.ChainedEvent{
+				"e1": {EventID: "e1", Document: canonical, Link: links["e1"].Link},
+				"e2": links["e2"],
+			}, archived)
+			runs, err := archive.Chain("employees")
+			assert.NoError(t, err)
+			assert.Equal(t, first.Chain, runs)
+
+			assert.NoError(t, archive.Remove(*first))
+			files, err = archive.Files(Filter{})
+			assert.NoError(t, err)
+			assert.Equal(t, []entities.ArchiveFile{*second}, files)
+			assert.NoFileExists(t, filepath.Join(archive.directory, first.ChainPath))
+		})
+	}
+}
+
+func TestArchiveVerify(t *testing.T) {
+	directory := t.TempDir()
+	archive, err := NewArchive(directory, encoding.JSON)
+	assert.NoError(t, err)
+
+	file, err := archive.Write("employees", "2019-07-24", []*pb.Event{deleteEvent("e1", 1, time.Now())}, map[string]*entities.ChainedEvent{
+		"e1": {EventID: "e1", Link: &entities.ChainLink{Position: 1, Hash: "h1"}},
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, archive.Verify(*file))
+
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(directory, file.Path), []byte("tampered"), 0640))
+	helpers.AssertErrors(t, "checksum of archive file doesn't match manifest: "+file.Path, archive.Verify(*file))
+
+	file, err = archive.Write("employees", "2019-07-25", []*pb.Event{deleteEvent("e2", 2, time.Now())}, map[string]*entities.ChainedEvent{
+		"e2": {EventID: "e2", Link: &entities.ChainLink{Position: 2, PreviousHash: "h1", Hash: "h2"}},
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(directory, file.ChainPath), []byte("tampered"), 0640))
+	helpers.AssertErrors(t, "checksum of archive file doesn't match manifest: "+file.ChainPath, archive.Verify(*file))
+
+	_, err = archive.Write("../employees", "2019-07-24", nil, nil)
+	helpers.AssertErrors(t, `invalid channel "../employees"`, err)
+}
+
+// storedRepository holds stored events of single aggregate.
+type storedRepository struct {
+	event.Repository
+
+	events []*entities.Event
+}
+
+func (repository *storedRepository) Events(ctx context.Context, aggregateType, aggregateID string, afterSequence uint64) ([]*entities.Event, error) {
+	var events []*entities.Event
+	for _, e := range repository.events {
+		if e.Sequence > afterSequence {
+			events = append(events, e)
+		}
+	}
+	return events, nil
+}
+
+func (repository *storedRepository) AggregateIDs(ctx context.Context, aggregateType string) ([]string, error) {
+	return nil, nil
+}
+
+func (repository *storedRepository) LastSequence(ctx context.Context, aggregateType, aggregateID string) (uint64, error) {
+	if len(repository.events) == 0 {
+		return 0, nil
+	}
+	return repository.events[len(repository.events)-1].Sequence, nil
+}
+
+func TestRepository(t *testing.T) {
+	registry := event.NewRegistry()
+	assert.NoError(t, employee.RegisterEvents(registry))
+
+	archive, err := NewArchive(t.TempDir(), encoding.JSON)
+	assert.NoError(t, err)
+	_, err = archive.Write("employees", "2019-07-24", []*pb.Event{
+		deleteEvent("e1", 1, time.Now()),
+		deleteEvent("e2", 2, time.Now()),
+	}, nil)
+	assert.NoError(t, err)
+
+	cases := []struct {
+		Name             string
+		Stored           []*entities.Event
+		AfterSequence    uint64
+		ExpectedEvents   []string
+		ExpectedSequence uint64
+	}{
+		{
+			Name:             "Archived and stored events are merged",
+			Stored:           []*entities.Event{{EventID: "e3", Sequence: 3}},
+			ExpectedEvents:   []string{"e1", "e2", "e3"},
+			ExpectedSequence: 3,
+		},
+		{
+			Name:             "Restored events are read once",
+			Stored:           []*entities.Event{{EventID: "e2", Sequence: 2}, {EventID: "e3", Sequence: 3}},
+			AfterSequence:    1,
+			ExpectedEvents:   []string{"e2", "e3"},
+			ExpectedSequence: 3,
+		},
+		{
+			Name:             "Aggregate with archived events only",
+			ExpectedEvents:   []string{"e1", "e2"},
+			ExpectedSequence: 2,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			repository := NewRepository(&storedRepository{events: tc.Stored}, archive, registry)
+
+			events, err := repository.Events(context.Background(), employee.AggregateType, aggregateID, tc.AfterSequence)
+			assert.NoError(t, err)
+
+			var ids []string
+			for _, e := range events {
+				ids = append(ids, e.EventID)
+			}
+			assert.Equal(t, tc.ExpectedEvents, ids)
+
+			sequence, err := repository.LastSequence(context.Background(), employee.AggregateType, aggregateID)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.ExpectedSequence, sequence)
+		})
+	}
+}
+
+func TestRepositoryIndex(t *testing.T) {
+	registry := event.NewRegistry()
+	assert.NoError(t, employee.RegisterEvents(registry))
+
+	directory := t.TempDir()
+	archive, err := NewArchive(directory, encoding.JSON)
+	assert.NoError(t, err)
+	other := deleteEvent("e3", 1, time.Now())
+	other.AggregateId = primitive.NewObjectID().Hex()
+	otherFile, err := archive.Write("employees", "2019-07-23", []*pb.Event{other}, nil)
+	assert.NoError(t, err)
+	file, err := archive.Write("employees", "2019-07-24", []*pb.Event{
+		deleteEvent("e1", 1, time.Now()),
+		deleteEvent("e2", 2, time.Now()),
+	}, nil)
+	assert.NoError(t, err)
+
+	repository := NewRepository(&storedRepository{}, archive, registry)
+
+	ids, err := repository.AggregateIDs(context.Background(), employee.AggregateType)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{aggregateID, other.AggregateId}, ids)
+
+	// files of other aggregates aren't read
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(directory, otherFile.Path), []byte("tampered"), 0640))
+	events, err := repository.Events(context.Background(), employee.AggregateType, aggregateID, 0)
+	assert.NoError(t, err)
+	assert.Len(t, events, 2)
+
+	// files with earlier events of aggregate only aren't read either
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(directory, file.Path), []byte("tampered"), 0640))
+	events, err = repository.Events(context.Background(), employee.AggregateType, aggregateID, 2)
+	assert.NoError(t, err)
+	assert.Empty(t, events)
+	sequence, err := repository.LastSequence(context.Background(), employee.AggregateType, aggregateID)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2), sequence)
+	_, err = repository.Events(context.Background(), employee.AggregateType, aggregateID, 1)
+	helpers.AssertErrors(t, "checksum of archive file doesn't match manifest: "+file.Path, err)
+}
+
+func TestRepositoryUnindexedFiles(t *testing.T) {
+	registry := event.NewRegistry()
+	assert.NoError(t, employee.RegisterEvents(registry))
+
+	archive, err := NewArchive(t.TempDir(), encoding.JSON)
+	assert.NoError(t, err)
+	_, err = archive.Write("employees", "2019-07-24", []*pb.Event{
+		deleteEvent("e1", 1, time.Now()),
+		deleteEvent("e2", 2, time.Now()),
+	}, nil)
+	assert.NoError(t, err)
+
+	// manifest written before aggregates were indexed
+	manifest, err := archive.manifest()
+	assert.NoError(t, err)
+	manifest.Files[0].Aggregates = nil
+	assert.NoError(t, archive.saveManifest(manifest))
+
+	repository := NewRepository(&storedRepository{}, archive, registry)
+	events, err := repository.Events(context.Background(), employee.AggregateType, aggregateID, 1)
+	assert.NoError(t, err)
+	assert.Len(t, events, 1)
+	sequence, err := repository.LastSequence(context.Background(), employee.AggregateType, aggregateID)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2), sequence)
+	ids, err := repository.AggregateIDs(context.Background(), employee.AggregateType)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{aggregateID}, ids)
+}
+
+func (repository *storedRepository) SubjectEvents(ctx context.Context, aggregateType, aggregateID string, originatorID primitive.ObjectID) ([]*entities.Event, error) {
+	return repository.events, nil
+}
+
+func TestRepositorySubjectEvents(t *testing.T) {
+	registry := event.NewRegistry()
+	assert.NoError(t, employee.RegisterEvents(registry))
+
+	originatorID := primitive.NewObjectID()
+	day := time.Date(2019, 7, 24, 0, 0, 0, 0, time.UTC)
+
+	originated := deleteEvent("e2", 1, day.Add(2*time.Hour))
+	originated.AggregateId = primitive.NewObjectID().Hex()
+	originated.Originator = &pb.Event_Claims{EntityId: originatorID.Hex()}
+	other := deleteEvent("e4", 1, day.Add(4*time.Hour))
+	other.AggregateId = primitive.NewObjectID().Hex()
+
+	archive, err := NewArchive(t.TempDir(), encoding.JSON)
+	assert.NoError(t, err)
+	_, err = archive.Write("employees", "2019-07-24", []*pb.Event{
+		deleteEvent("e1", 1, day.Add(time.Hour)),
+		originated,
+		deleteEvent("e3", 2, day.Add(3*time.Hour)),
+		other,
+	}, nil)
+	assert.NoError(t, err)
+
+	stored := []*entities.Event{
+		{EventID: "e3", Sequence: 2, CreatedAt: day.Add(3 * time.Hour)},
+		{EventID: "e5", Sequence: 3, CreatedAt: day.Add(5 * time.Hour)},
+	}
+	repository := NewRepository(&storedRepository{events: stored}, archive, registry)
+
+	events, err := repository.SubjectEvents(context.Background(), employee.AggregateType, aggregateID, originatorID)
+	assert.NoError(t, err)
+
+	var ids []string
+	for _, e := range events {
+		ids = append(ids, e.EventID)
+	}
+	assert.Equal(t, []string{"e1", "e2", "e3", "e5"}, ids)
+}
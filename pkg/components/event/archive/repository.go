@@ -0,0 +1,220 @@
+package archive
+
+import (
+	"context"
+	"sort"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/migotom/cell-centre-services/pkg/components/event"
+	eventFactory "github.com/migotom/cell-centre-services/pkg/components/event/factory"
+	"github.com/migotom/cell-centre-services/pkg/entities"
+)
+
+type archivedRepository struct {
+	event.Repository
+
+	archive      *Archive
+	eventFactory *eventFactory.EventEntityFactory
+}
+
+// NewRepository returns event repository reading events of aggregates both from given repository and archive, events
+// are stored in given repository only. Aggregates are looked up in manifest, so only archive files holding their
+// requested events are read.
+func NewRepository(events event.Repository, archive *Archive, eventRegistry *event.Registry) event.Repository {
+	return &archivedRepository{
+		Repository:   events,
+		archive:      archive,
+		eventFactory: eventFactory.NewEventEntityFactory(eventRegistry),
+	}
+}
+
+// LastSequence returns the highest sequence of stored or archived events of given aggregate.
+func (repository *archivedRepository) LastSequence(ctx context.Context, aggregateType, aggregateID string) (uint64, error) {
+	last, err := repository.Repository.LastSequence(ctx, aggregateType, aggregateID)
+	if err != nil {
+		return 0, err
+	}
+
+	files, err := repository.archive.Files(Filter{AggregateType: aggregateType, AggregateID: aggregateID})
+	if err != nil {
+		return 0, err
+	}
+	for _, file := range files {
+		if aggregate := Aggregate(file, aggregateType, aggregateID); aggregate != nil {
+			if aggregate.LastSequence > last {
+				last = aggregate.LastSequence
+			}
+			continue
+		}
+
+		// file isn't indexed by its aggregates
+		archived, err := repository.read(file, aggregateType, aggregateID, last)
+		if err != nil {
+			return 0, err
+		}
+		for _, e := range archived {
+			if e.Sequence > last {
+				last = e.Sequence
+			}
+		}
+	}
+	return last, nil
+}
+
+// Events returns stored and archived events of given aggregate following given sequence, in order of their sequence.
+func (repository *archivedRepository) Events(ctx context.Context, aggregateType, aggregateID string, afterSequence uint64) ([]*entities.Event, error) {
+	archived, err := repository.archived(aggregateType, aggregateID, afterSequence)
+	if err != nil {
+		return nil, err
+	}
+	stored, err := repository.Repository.Events(ctx, aggregateType, aggregateID, afterSequence)
+	if err != nil {
+		return nil, err
+	}
+	if len(archived) == 0 {
+		return stored, nil
+	}
+
+	// events restored from archive may be both stored and archived
+	seen := make(map[string]bool, len(stored))
+	for _, e := range stored {
+		seen[e.EventID] = true
+	}
+	events := stored
+	for _, e := range archived {
+		if !seen[e.EventID] {
+			events = append(events, e)
+		}
+	}
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Sequence < events[j].Sequence
+	})
+	return events, nil
+}
+
+// AggregateIDs returns IDs of aggregates of given type with stored or archived events.
+func (repository *archivedRepository) AggregateIDs(ctx context.Context, aggregateType string) ([]string, error) {
+	ids, err := repository.Repository.AggregateIDs(ctx, aggregateType)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		seen[id] = true
+	}
+
+	files, err := repository.archive.Files(Filter{AggregateType: aggregateType})
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range files {
+		if Indexed(file) {
+			for _, aggregate := range file.Aggregates {
+				if aggregate.Type == aggregateType && aggregate.ID != "" && !seen[aggregate.ID] {
+					seen[aggregate.ID] = true
+					ids = append(ids, aggregate.ID)
+				}
+			}
+			continue
+		}
+
+		events, err := repository.archive.Read(file)
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range events {
+			if e.GetAggregateType() == aggregateType && e.GetAggregateId() != "" && !seen[e.GetAggregateId()] {
+				seen[e.GetAggregateId()] = true
+				ids = append(ids, e.GetAggregateId())
+			}
+		}
+	}
+	return ids, nil
+}
+
+// SubjectEvents returns stored and archived events of given aggregate and events originated by given entity, in order
+// of their creation.
+func (repository *archivedRepository) SubjectEvents(ctx context.Context, aggregateType, aggregateID string, originatorID primitive.ObjectID) ([]*entities.Event, error) {
+	stored, err := repository.Repository.SubjectEvents(ctx, aggregateType, aggregateID, originatorID)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool, len(stored))
+	for _, e := range stored {
+		seen[e.EventID] = true
+	}
+	events := stored
+
+	files, err := repository.archive.Files(Filter{})
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range files {
+		archived, err := repository.archive.Read(file)
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range archived {
+			subject := e.GetAggregateType() == aggregateType && e.GetAggregateId() == aggregateID
+			if seen[e.GetEventId()] || !subject && e.GetOriginator().GetEntityId() != originatorID.Hex() {
+				continue
+			}
+			entity, err := repository.eventFactory.NewFromEvent(*e)
+			if err != nil {
+				return nil, err
+			}
+			seen[e.GetEventId()] = true
+			events = append(events, entity)
+		}
+	}
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].CreatedAt.Before(events[j].CreatedAt)
+	})
+	return events, nil
+}
+
+// archived returns archived events of aggregate following given sequence, files holding only earlier events of
+// aggregate aren't read.
+func (repository *archivedRepository) archived(aggregateType, aggregateID string, afterSequence uint64) ([]*entities.Event, error) {
+	files, err := repository.archive.Files(Filter{AggregateType: aggregateType, AggregateID: aggregateID})
+	if err != nil {
+		return nil, err
+	}
+
+	var events []*entities.Event
+	for _, file := range files {
+		if aggregate := Aggregate(file, aggregateType, aggregateID); aggregate != nil && aggregate.LastSequence <= afterSequence {
+			continue
+		}
+		archived, err := repository.read(file, aggregateType, aggregateID, afterSequence)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, archived...)
+	}
+	return events, nil
+}
+
+// read returns events of aggregate following given sequence kept by archive file.
+func (repository *archivedRepository) read(file entities.ArchiveFile, aggregateType, aggregateID string, afterSequence uint64) ([]*entities.Event, error) {
+	archived, err := repository.archive.Read(file)
+	if err != nil {
+		return nil, err
+	}
+
+	var events []*entities.Event
+	for _, e := range archived {
+		if e.GetAggregateType() != aggregateType || e.GetAggregateId() != aggregateID || e.GetSequence() <= afterSequence {
+			continue
+		}
+		entity, err := repository.eventFactory.NewFromEvent(*e)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, entity)
+	}
+	return events, nil
+}
@@ -0,0 +1,22 @@
+package event
+
+import (
+	"context"
+	"errors"
+
+	"github.com/migotom/cell-centre-services/pkg/entities"
+)
+
+// ErrChainConflict is returned by repository when event is already linked or other event holds its position in chain.
+var ErrChainConflict = errors.New("event already linked or position in chain already taken")
+
+// ChainRepository of hash chains linking stored events of channels.
+type ChainRepository interface {
+	// Head returns link of the last chained event of channel, nil when no event is chained.
+	Head(ctx context.Context, channel string) (*entities.ChainLink, error)
+	// Unchained returns up to limit events of channel not chained yet, in order they were stored.
+	Unchained(ctx context.Context, channel string, limit int) ([]*entities.ChainedEvent, error)
+	// Chained returns up to limit chained events of channel following given position, in order of chain.
+	Chained(ctx context.Context, channel string, afterPosition uint64, limit int) ([]*entities.ChainedEvent, error)
+	Link(ctx context.Context, eventID string, link *entities.ChainLink) error
+}
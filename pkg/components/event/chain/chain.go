@@ -0,0 +1,73 @@
+// Package chain links stored events of channel into tamper-evident hash chain and signs checkpoints of its head.
+package chain
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/x/bsonx/bsoncore"
+
+	"github.com/migotom/cell-centre-services/pkg/entities"
+)
+
+// unhashed are elements of stored event document not covered by its hash.
+var unhashed = map[string]bool{
+	"_id":   true,
+	"chain": true,
+}
+
+// Canonical returns canonical encoding of stored event document, BSON document of event without its database ID
+// and link in chain, elements are kept in stored order.
+func Canonical(document bson.Raw) ([]byte, error) {
+	elements, err := document.Elements()
+	if err != nil {
+		return nil, fmt.Errorf("invalid document of event: %v", err)
+	}
+
+	canonical := make([][]byte, 0, len(elements))
+	for _, element := range elements {
+		if !unhashed[element.Key()] {
+			canonical = append(canonical, element)
+		}
+	}
+	return bsoncore.BuildDocumentFromElements(nil, canonical...), nil
+}
+
+// Restored returns document of event restored from its canonical encoding followed by given link, canonical
+// encoding of restored document matches the one of document event was archived from.
+func Restored(document bson.Raw, link *entities.ChainLink) (bson.Raw, error) {
+	canonical, err := Canonical(document)
+	if err != nil {
+		return nil, err
+	}
+	if link == nil {
+		return canonical, nil
+	}
+
+	linkDocument, err := bson.Marshal(link)
+	if err != nil {
+		return nil, err
+	}
+	// elements of canonical document are taken without its length and terminating byte
+	elements := canonical[4 : len(canonical)-1]
+	return bsoncore.BuildDocumentFromElements(nil, elements, bsoncore.AppendDocumentElement(nil, "chain", linkDocument)), nil
+}
+
+// Hash returns hex encoded SHA-256 hash of event at given position of chain, following event of given hash.
+func Hash(previousHash string, position uint64, document bson.Raw) (string, error) {
+	canonical, err := Canonical(document)
+	if err != nil {
+		return "", err
+	}
+
+	hash := sha256.New()
+	hash.Write([]byte(previousHash))
+	hash.Write([]byte{'\n'})
+	hash.Write([]byte(strconv.FormatUint(position, 10)))
+	hash.Write([]byte{'\n'})
+	hash.Write(canonical)
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
@@ -0,0 +1,129 @@
+package chain
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/migotom/cell-centre-services/pkg/entities"
+	"github.com/migotom/cell-centre-services/pkg/helpers"
+)
+
+func TestHash(t *testing.T) {
+	document := func(elements bson.D) bson.Raw {
+		raw, _ := bson.Marshal(elements)
+		return raw
+	}
+	stored := document(bson.D{{Key: "event_id", Value: "e1"}, {Key: "data", Value: bson.M{"email": "admin@page.com"}}})
+	hash, err := Hash("", 1, stored)
+	assert.NoError(t, err)
+
+	cases := []struct {
+		Name         string
+		PreviousHash string
+		Position     uint64
+		Document     bson.Raw
+		ExpectedSame bool
+		ExpectedErr  string
+	}{
+		{
+			Name:         "Database ID and link of stored event aren't hashed",
+			Position:     1,
+			Document:     document(bson.D{{Key: "_id", Value: "5d2f0c8e"}, {Key: "event_id", Value: "e1"}, {Key: "data", Value: bson.M{"email": "admin@page.com"}}, {Key: "chain", Value: bson.M{"position": 1}}}),
+			ExpectedSame: true,
+		},
+		{
+			Name:     "Edited event",
+			Position: 1,
+			Document: document(bson.D{{Key: "event_id", Value: "e1"}, {Key: "data", Value: bson.M{"email": "root@page.com"}}}),
+		},
+		{
+			Name:     "Reordered elements",
+			Position: 1,
+			Document: document(bson.D{{Key: "data", Value: bson.M{"email": "admin@page.com"}}, {Key: "event_id", Value: "e1"}}),
+		},
+		{
+			Name:     "Event moved in chain",
+			Position: 2,
+			Document: stored,
+		},
+		{
+			Name:         "Event following other event",
+			PreviousHash: hash,
+			Position:     1,
+			Document:     stored,
+		},
+		{
+			Name:        "Invalid document",
+			Document:    bson.Raw{1, 2, 3},
+			ExpectedErr: "invalid document of event: too few bytes to read next component",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			got, err := Hash(tc.PreviousHash, tc.Position, tc.Document)
+			helpers.AssertErrors(t, tc.ExpectedErr, err)
+			if err != nil {
+				return
+			}
+			assert.Equal(t, tc.ExpectedSame, got == hash)
+		})
+	}
+}
+
+func TestRestored(t *testing.T) {
+	stored, _ := bson.Marshal(bson.D{{Key: "_id", Value: "5d2f0c8e"}, {Key: "event_id", Value: "e1"}, {Key: "data", Value: bson.M{"email": "admin@page.com"}}, {Key: "chain", Value: bson.M{"position": 1}}})
+	link := &entities.ChainLink{Position: 1, Hash: "h1"}
+
+	canonical, err := Canonical(stored)
+	assert.NoError(t, err)
+	restored, err := Restored(canonical, link)
+	assert.NoError(t, err)
+
+	restoredCanonical, err := Canonical(restored)
+	assert.NoError(t, err)
+	assert.Equal(t, canonical, restoredCanonical)
+
+	var document struct {
+		Chain *entities.ChainLink
+	}
+	assert.NoError(t, bson.Unmarshal(restored, &document))
+	assert.Equal(t, link, document.Chain)
+}
+
+func TestCheckpoint(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	otherKey, _, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+
+	file := NewCheckpointFile(filepath.Join(t.TempDir(), "checkpoints.jsonl"))
+	checkpoints, err := file.Checkpoints("")
+	assert.NoError(t, err)
+	assert.Empty(t, checkpoints)
+
+	createdAt := time.Date(2019, 7, 24, 20, 26, 40, 0, time.UTC)
+	for i, channel := range []string{"employees", "roles", "employees"} {
+		checkpoint := &entities.ChainCheckpoint{Channel: channel, Position: uint64(i + 1), Hash: "abc", CreatedAt: createdAt}
+		Sign(checkpoint, privateKey)
+		assert.NoError(t, file.Append(checkpoint))
+	}
+
+	checkpoints, err = file.Checkpoints("employees")
+	assert.NoError(t, err)
+	assert.Len(t, checkpoints, 2)
+	assert.Equal(t, uint64(3), checkpoints[1].Position)
+	assert.Equal(t, createdAt, checkpoints[1].CreatedAt)
+
+	checkpoint := checkpoints[0]
+	assert.NoError(t, Verify(checkpoint, publicKey))
+	helpers.AssertErrors(t, "invalid signature of checkpoint", Verify(checkpoint, otherKey))
+
+	checkpoint.Position = 10
+	helpers.AssertErrors(t, "invalid signature of checkpoint", Verify(checkpoint, publicKey))
+}
@@ -0,0 +1,162 @@
+package chain
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/migotom/cell-centre-services/pkg/entities"
+)
+
+// ErrInvalidSignature is returned when signature of checkpoint doesn't match its content.
+var ErrInvalidSignature = errors.New("invalid signature of checkpoint")
+
+// LoadPrivateKey reads Ed25519 private key from PEM encoded PKCS #8 file.
+func LoadPrivateKey(path string) (ed25519.PrivateKey, error) {
+	der, err := readPEM(path, "PRIVATE KEY")
+	if err != nil {
+		return nil, err
+	}
+	key, err := x509.ParsePKCS8PrivateKey(der)
+	if err != nil {
+		return nil, fmt.Errorf("can't parse private key %s: %v", path, err)
+	}
+	privateKey, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("private key %s isn't Ed25519 key", path)
+	}
+	return privateKey, nil
+}
+
+// LoadPublicKey reads Ed25519 public key from PEM encoded PKIX file, public key of private key file is accepted too.
+func LoadPublicKey(path string) (ed25519.PublicKey, error) {
+	if privateKey, err := LoadPrivateKey(path); err == nil {
+		return privateKey.Public().(ed25519.PublicKey), nil
+	}
+
+	der, err := readPEM(path, "PUBLIC KEY")
+	if err != nil {
+		return nil, err
+	}
+	key, err := x509.ParsePKIXPublicKey(der)
+	if err != nil {
+		return nil, fmt.Errorf("can't parse public key %s: %v", path, err)
+	}
+	publicKey, ok := key.(ed25519.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("public key %s isn't Ed25519 key", path)
+	}
+	return publicKey, nil
+}
+
+func readPEM(path, blockType string) ([]byte, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != blockType {
+		return nil, fmt.Errorf("%s doesn't hold PEM encoded %s", path, blockType)
+	}
+	return block.Bytes, nil
+}
+
+// signed returns content of checkpoint covered by its signature.
+func signed(checkpoint *entities.ChainCheckpoint) []byte {
+	return []byte(checkpoint.Channel + "\n" +
+		strconv.FormatUint(checkpoint.Position, 10) + "\n" +
+		checkpoint.Hash + "\n" +
+		checkpoint.CreatedAt.UTC().Format(time.RFC3339Nano))
+}
+
+// Sign signs checkpoint with given key.
+func Sign(checkpoint *entities.ChainCheckpoint, key ed25519.PrivateKey) {
+	checkpoint.Signature = base64.StdEncoding.EncodeToString(ed25519.Sign(key, signed(checkpoint)))
+}
+
+// Verify checks signature of checkpoint with given key.
+func Verify(checkpoint *entities.ChainCheckpoint, key ed25519.PublicKey) error {
+	signature, err := base64.StdEncoding.DecodeString(checkpoint.Signature)
+	if err != nil || !ed25519.Verify(key, signed(checkpoint), signature) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// CheckpointFile keeps checkpoints in JSON Lines file, checkpoints are only appended.
+type CheckpointFile struct {
+	sync.Mutex
+
+	path string
+}
+
+// NewCheckpointFile returns checkpoint file of given path, file is created by first appended checkpoint.
+func NewCheckpointFile(path string) *CheckpointFile {
+	return &CheckpointFile{path: path}
+}
+
+// Append writes checkpoint at the end of file once it's synced to disk.
+func (file *CheckpointFile) Append(checkpoint *entities.ChainCheckpoint) error {
+	line, err := json.Marshal(checkpoint)
+	if err != nil {
+		return err
+	}
+
+	file.Lock()
+	defer file.Unlock()
+
+	f, err := os.OpenFile(file.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0640)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Checkpoints returns checkpoints of channel in order they were appended, checkpoints of all channels when
+// channel is empty. Missing file holds no checkpoints.
+func (file *CheckpointFile) Checkpoints(channel string) ([]*entities.ChainCheckpoint, error) {
+	file.Lock()
+	data, err := ioutil.ReadFile(file.path)
+	file.Unlock()
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var checkpoints []*entities.ChainCheckpoint
+	lines := bufio.NewScanner(bytes.NewReader(data))
+	for number := 1; lines.Scan(); number++ {
+		if len(bytes.TrimSpace(lines.Bytes())) == 0 {
+			continue
+		}
+		var checkpoint entities.ChainCheckpoint
+		if err := json.Unmarshal(lines.Bytes(), &checkpoint); err != nil {
+			return nil, fmt.Errorf("invalid checkpoint at line %d of %s: %v", number, file.path, err)
+		}
+		if channel == "" || checkpoint.Channel == channel {
+			checkpoints = append(checkpoints, &checkpoint)
+		}
+	}
+	return checkpoints, lines.Err()
+}
@@ -0,0 +1,39 @@
+package grpc
+
+import "fmt"
+
+type EventDeliveryErrorReason int
+
+const (
+	ErrInvalidSubscription = iota
+	ErrInternal
+	ErrShutdown
+)
+
+type EventDeliveryError struct {
+	Reason EventDeliveryErrorReason
+	Err    error
+}
+
+func (err EventDeliveryError) Error() string {
+	if err.Err != nil {
+		return fmt.Sprintf("%s (%v)", err.description(), err.Err)
+	}
+	return err.description()
+}
+
+func (err EventDeliveryError) description() string {
+	switch err.Reason {
+	case ErrInvalidSubscription:
+		return "Invalid subscription"
+
+	case ErrInternal:
+		return "Internal error"
+
+	case ErrShutdown:
+		return "Service is shutting down"
+
+	default:
+		return "Unknown error"
+	}
+}
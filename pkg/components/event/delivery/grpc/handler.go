@@ -0,0 +1,187 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	authDelivery "github.com/migotom/cell-centre-services/pkg/components/auth/delivery/grpc"
+	"github.com/migotom/cell-centre-services/pkg/components/event"
+	"github.com/migotom/cell-centre-services/pkg/entities"
+	"github.com/migotom/cell-centre-services/pkg/helpers/correlation"
+	"github.com/migotom/cell-centre-services/pkg/pb"
+)
+
+const subscriptionBuffer = 64
+
+// privilegedRoles may see events of all aggregates, other entities see only events about themselves.
+var privilegedRoles = []string{"admin", "serviceman"}
+
+// EventDelivery is gRPC handler delivery of events subscriptions.
+type EventDelivery struct {
+	log             *zap.Logger
+	eventsStreaming event.Streaming
+	eventRegistry   *event.Registry
+	payloadCipher   event.PayloadCipher
+
+	closeOnce sync.Once
+	closing   chan struct{}
+}
+
+// NewEventDelivery returns new Event gRPC delivery, personal data of streamed events is decrypted by given cipher.
+// Nil cipher streams personal data as it was published.
+func NewEventDelivery(log *zap.Logger, eventsStreaming event.Streaming, eventRegistry *event.Registry, payloadCipher event.PayloadCipher) *EventDelivery {
+	return &EventDelivery{
+		log:             log,
+		eventsStreaming: eventsStreaming,
+		eventRegistry:   eventRegistry,
+		payloadCipher:   payloadCipher,
+		closing:         make(chan struct{}),
+	}
+}
+
+// Close ends open subscriptions, callers are expected to resubscribe from last received sequence.
+func (delivery *EventDelivery) Close() {
+	delivery.closeOnce.Do(func() {
+		close(delivery.closing)
+	})
+}
+
+// AuthFuncOverride is authorization accessor for EventDelivery gRPC, any authenticated entity may subscribe
+// while events are filtered by its claims.
+func (delivery *EventDelivery) AuthFuncOverride(ctx context.Context, fullMethodName string) (context.Context, error) {
+	claims, err := authDelivery.ObtainClaimsFromMetadata(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Unauthenticated, "Request unauthenticated with error: %v", err)
+	}
+
+	return context.WithValue(ctx, authDelivery.ContextKeyClaims, claims), nil
+}
+
+// SubscribeEvents gRPC handler streams events of channel matching request filters and visible to caller,
+// until caller cancels the call or delivery is closed. Only channels of registered event types may be subscribed.
+func (delivery *EventDelivery) SubscribeEvents(request *pb.SubscribeEventsRequest, stream pb.EventService_SubscribeEventsServer) error {
+	if request.GetChannel() == "" {
+		return status.Errorf(codes.InvalidArgument, "Invalid request: %v", EventDeliveryError{Reason: ErrInvalidSubscription})
+	}
+	if !delivery.eventRegistry.Channel(request.GetChannel()) {
+		return status.Errorf(codes.InvalidArgument, "Invalid request: %v", EventDeliveryError{Reason: ErrInvalidSubscription, Err: fmt.Errorf("unknown channel %s", request.GetChannel())})
+	}
+
+	ctx := stream.Context()
+	claims := authDelivery.ObtainClaimsFromContext(ctx)
+
+	var options []event.SubscribeOption
+	if request.GetFromSequence() != 0 {
+		options = append(options, event.StartAtSequence(request.GetFromSequence()))
+	}
+
+	messages := make(chan event.Message, subscriptionBuffer)
+	subscription, err := delivery.eventsStreaming.Subscribe(request.GetChannel(), func(msg event.Message) {
+		select {
+		case messages <- msg:
+		case <-ctx.Done():
+		}
+	}, options...)
+	if err != nil {
+		return status.Errorf(codes.Internal, "Can't subscribe events: %v", EventDeliveryError{Reason: ErrInternal, Err: err})
+	}
+	defer subscription.Close()
+
+	// headers confirm to caller that subscription is established
+	if err := stream.SendHeader(metadata.MD{}); err != nil {
+		return err
+	}
+
+	log := correlation.Logger(ctx, delivery.log).With(zap.String("channel", request.GetChannel()), zap.String("login", claims.Login))
+	log.Info("Events subscribed", zap.Uint64("fromSequence", request.GetFromSequence()))
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Info("Events unsubscribed")
+			return nil
+		case <-delivery.closing:
+			log.Info("Events subscription closed")
+			return status.Errorf(codes.Unavailable, "Events subscription closed: %v", EventDeliveryError{Reason: ErrShutdown})
+		case msg := <-messages:
+			var e pb.Event
+			if err := proto.Unmarshal(msg.Data(), &e); err != nil {
+				log.Error("Invalid event, skipped", zap.Uint64("sequence", msg.Sequence()), zap.Error(err))
+				continue
+			}
+			if !matches(request, &e) || !visible(claims, &e) {
+				continue
+			}
+
+			if err := delivery.redact(ctx, &e); err != nil {
+				log.Error("Can't redact event, skipped", zap.Uint64("sequence", msg.Sequence()), zap.Error(err))
+				continue
+			}
+			if err := stream.Send(&pb.StreamedEvent{Sequence: msg.Sequence(), Event: &e}); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// matches reports whether event passes filters of subscription.
+func matches(request *pb.SubscribeEventsRequest, e *pb.Event) bool {
+	if request.GetAggregateType() != "" && request.GetAggregateType() != e.GetAggregateType() {
+		return false
+	}
+	if request.GetAggregateId() != "" && request.GetAggregateId() != e.GetAggregateId() {
+		return false
+	}
+	if len(request.GetTypes()) == 0 {
+		return true
+	}
+	for _, eventType := range request.GetTypes() {
+		if eventType == e.GetType() {
+			return true
+		}
+	}
+	return false
+}
+
+// visible reports whether caller is authorized to see event.
+func visible(claims entities.TokenClaims, e *pb.Event) bool {
+	if claims.HasRole(privilegedRoles) {
+		return true
+	}
+	// employees deleted by email were aggregated by email before their events were aggregated by ID
+	return e.GetAggregateId() != "" &&
+		(e.GetAggregateId() == claims.EntityID.Hex() || e.GetAggregateId() == claims.Login)
+}
+
+// redact removes password hashes from payload of streamed event and decrypts its personal data, personal data
+// of erased employees is streamed as erased.
+func (delivery *EventDelivery) redact(ctx context.Context, e *pb.Event) error {
+	if delivery.payloadCipher != nil {
+		if err := delivery.payloadCipher.Decrypt(ctx, e); err != nil {
+			return err
+		}
+	}
+
+	payload, err := delivery.eventRegistry.Payload(e)
+	if err != nil {
+		return err
+	}
+
+	if employee, ok := payload.(*pb.Employee); ok {
+		employee.Password = ""
+	}
+
+	if e.Payload, err = ptypes.MarshalAny(payload); err != nil {
+		return err
+	}
+	e.Data = nil
+	return nil
+}
@@ -0,0 +1,207 @@
+package grpc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/golang/protobuf/ptypes/any"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	authDelivery "github.com/migotom/cell-centre-services/pkg/components/auth/delivery/grpc"
+	"github.com/migotom/cell-centre-services/pkg/components/employee"
+	"github.com/migotom/cell-centre-services/pkg/components/event"
+	"github.com/migotom/cell-centre-services/pkg/entities"
+	"github.com/migotom/cell-centre-services/pkg/helpers"
+	"github.com/migotom/cell-centre-services/pkg/helpers/mocks"
+	"github.com/migotom/cell-centre-services/pkg/pb"
+)
+
+type subscribeEventsServerMock struct {
+	grpc.ServerStream
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	limit  int
+	sent   []*pb.StreamedEvent
+}
+
+func (m *subscribeEventsServerMock) Context() context.Context {
+	return m.ctx
+}
+func (m *subscribeEventsServerMock) SendHeader(metadata.MD) error {
+	return nil
+}
+func (m *subscribeEventsServerMock) Send(streamed *pb.StreamedEvent) error {
+	m.sent = append(m.sent, streamed)
+	if len(m.sent) == m.limit {
+		m.cancel()
+	}
+	return nil
+}
+
+func eventMessage(sequence uint64, e *pb.Event) *mocks.MessageMock {
+	data, _ := proto.Marshal(e)
+	return &mocks.MessageMock{MsgData: data, MsgSequence: sequence}
+}
+
+func payload(message proto.Message) *any.Any {
+	payload, _ := ptypes.MarshalAny(message)
+	return payload
+}
+
+func TestSubscribeEvents(t *testing.T) {
+	employeeID := primitive.NewObjectID()
+	otherID := primitive.NewObjectID()
+
+	registry := event.NewRegistry()
+	if err := employee.RegisterEvents(registry); err != nil {
+		t.Fatal(err)
+	}
+
+	messages := []*mocks.MessageMock{
+		eventMessage(5, &pb.Event{EventId: "1", AggregateId: otherID.Hex(), Type: "NewEmployee",
+			Payload: payload(&pb.Employee{Id: otherID.Hex(), Password: "$2a$04$hash"})}),
+		// event of schema version 1 carrying update request
+		eventMessage(6, &pb.Event{EventId: "2", AggregateId: employeeID.Hex(), Type: "UpdateEmployee",
+			Data: &pb.Event_UpdateRequest{UpdateRequest: &pb.UpdateEmployeeRequest{Id: employeeID.Hex(), Password: "$2a$04$hash"}}}),
+		{MsgData: []byte("not an event"), MsgSequence: 7},
+		eventMessage(8, &pb.Event{EventId: "3", AggregateId: employeeID.Hex(), Type: "DeleteEmployee",
+			Payload: payload(&pb.EmployeeFilter{Id: employeeID.Hex()})}),
+		eventMessage(9, &pb.Event{EventId: "4", AggregateId: employeeID.Hex(), Type: "Unknown",
+			Payload: payload(&pb.Role{})}),
+	}
+
+	cases := []struct {
+		Name             string
+		Request          *pb.SubscribeEventsRequest
+		Claims           entities.TokenClaims
+		ExpectedOptions  event.SubscribeOptions
+		ExpectedSent     []string
+		ExpectedSequence []uint64
+		ExpectedErr      string
+	}{
+		{
+			Name:             "Privileged caller sees all events",
+			Request:          &pb.SubscribeEventsRequest{Channel: "employees"},
+			Claims:           entities.TokenClaims{EntityID: employeeID, Roles: []string{"admin"}},
+			ExpectedSent:     []string{"1", "2", "3"},
+			ExpectedSequence: []uint64{5, 6, 8},
+		},
+		{
+			Name:             "Caller sees only events about itself",
+			Request:          &pb.SubscribeEventsRequest{Channel: "employees"},
+			Claims:           entities.TokenClaims{EntityID: employeeID, Roles: []string{"employee"}},
+			ExpectedSent:     []string{"2", "3"},
+			ExpectedSequence: []uint64{6, 8},
+		},
+		{
+			Name:             "Events filtered by type and resumed from sequence",
+			Request:          &pb.SubscribeEventsRequest{Channel: "employees", Types: []string{"NewEmployee", "DeleteEmployee"}, FromSequence: 5},
+			Claims:           entities.TokenClaims{EntityID: employeeID, Roles: []string{"admin"}},
+			ExpectedOptions:  event.SubscribeOptions{StartAtSequence: 5},
+			ExpectedSent:     []string{"1", "3"},
+			ExpectedSequence: []uint64{5, 8},
+		},
+		{
+			Name:             "Events filtered by aggregate",
+			Request:          &pb.SubscribeEventsRequest{Channel: "employees", AggregateId: otherID.Hex()},
+			Claims:           entities.TokenClaims{EntityID: employeeID, Roles: []string{"serviceman"}},
+			ExpectedSent:     []string{"1"},
+			ExpectedSequence: []uint64{5},
+		},
+		{
+			Name:        "Missing channel",
+			Request:     &pb.SubscribeEventsRequest{},
+			ExpectedErr: "rpc error: code = InvalidArgument desc = Invalid request: Invalid subscription",
+		},
+		{
+			Name:        "Unregistered channel",
+			Request:     &pb.SubscribeEventsRequest{Channel: "deadletters"},
+			Claims:      entities.TokenClaims{EntityID: employeeID, Roles: []string{"employee"}},
+			ExpectedErr: "rpc error: code = InvalidArgument desc = Invalid request: Invalid subscription (unknown channel deadletters)",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			log, _ := zap.NewProduction()
+			defer log.Sync()
+
+			ctx, cancel := context.WithCancel(context.WithValue(context.Background(), authDelivery.ContextKeyClaims, tc.Claims))
+			defer cancel()
+			stream := &subscribeEventsServerMock{ctx: ctx, cancel: cancel, limit: len(tc.ExpectedSent)}
+
+			streamingMock := mocks.StreamingMock{}
+			subscriptionMock := mocks.SubscriptionMock{}
+			if tc.ExpectedErr == "" {
+				streamingMock.On("Subscribe", tc.Request.Channel, mock.Anything, mock.MatchedBy(func(options []event.SubscribeOption) bool {
+					return event.NewSubscribeOptions(options...) == tc.ExpectedOptions
+				})).Run(func(args mock.Arguments) {
+					handler := args.Get(1).(event.MessageHandler)
+					go func() {
+						for _, msg := range messages {
+							handler(msg)
+						}
+					}()
+				}).Return(&subscriptionMock, nil)
+				subscriptionMock.On("Close").Return(nil)
+			}
+
+			delivery := NewEventDelivery(log, &streamingMock, registry, nil)
+			err := delivery.SubscribeEvents(tc.Request, stream)
+			helpers.AssertErrors(t, tc.ExpectedErr, err)
+
+			var sent []string
+			var sequences []uint64
+			for _, streamed := range stream.sent {
+				sent = append(sent, streamed.Event.EventId)
+				sequences = append(sequences, streamed.Sequence)
+				assert.Nil(t, streamed.Event.Data)
+				payload, err := registry.Payload(streamed.Event)
+				assert.NoError(t, err)
+				switch data := payload.(type) {
+				case *pb.Employee:
+					assert.Empty(t, data.Password)
+				case *pb.EmployeeChanges:
+					for _, change := range data.Changes {
+						assert.Empty(t, change.NewValue, change.Path)
+					}
+				}
+			}
+			assert.Equal(t, tc.ExpectedSent, sent)
+			assert.Equal(t, tc.ExpectedSequence, sequences)
+
+			streamingMock.AssertExpectations(t)
+			subscriptionMock.AssertExpectations(t)
+		})
+	}
+}
+
+func TestSubscribeEventsClosed(t *testing.T) {
+	ctx := context.WithValue(context.Background(), authDelivery.ContextKeyClaims, entities.TokenClaims{Roles: []string{"admin"}})
+	stream := &subscribeEventsServerMock{ctx: ctx}
+
+	streamingMock := mocks.StreamingMock{}
+	subscriptionMock := mocks.SubscriptionMock{}
+	streamingMock.On("Subscribe", "employees", mock.Anything, mock.Anything).Return(&subscriptionMock, nil)
+	subscriptionMock.On("Close").Return(nil)
+
+	registry := event.NewRegistry()
+	if err := employee.RegisterEvents(registry); err != nil {
+		t.Fatal(err)
+	}
+	delivery := NewEventDelivery(zap.NewNop(), &streamingMock, registry, nil)
+	delivery.Close()
+
+	err := delivery.SubscribeEvents(&pb.SubscribeEventsRequest{Channel: "employees"}, stream)
+	helpers.AssertErrors(t, "rpc error: code = Unavailable desc = Events subscription closed: Service is shutting down", err)
+
+	streamingMock.AssertExpectations(t)
+	subscriptionMock.AssertExpectations(t)
+}
@@ -0,0 +1,259 @@
+package encoding
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/golang/protobuf/ptypes/any"
+
+	"github.com/migotom/cell-centre-services/pkg/pb"
+)
+
+const (
+	// SpecVersion of CloudEvents specification.
+	SpecVersion = "1.0"
+	// StructuredContentType is content type of events encoded in CloudEvents structured mode.
+	StructuredContentType = "application/cloudevents+json"
+	// SourcePrefix of CloudEvents source attribute, followed by channel of event.
+	SourcePrefix = "/cell-centre/"
+
+	binaryHeaderPrefix = "Ce-"
+	dataAttribute      = "data"
+	// dataSchemaScheme makes type URL of payload absolute URI required by dataschema attribute.
+	dataSchemaScheme = "https://"
+)
+
+// CloudEvents attributes of event, extension attributes carry fields without standard counterpart.
+const (
+	specVersionAttribute     = "specversion"
+	idAttribute              = "id"
+	sourceAttribute          = "source"
+	typeAttribute            = "type"
+	subjectAttribute         = "subject"
+	timeAttribute            = "time"
+	dataContentTypeAttribute = "datacontenttype"
+	dataSchemaAttribute      = "dataschema"
+
+	originatorIDAttribute     = "originatorid"
+	originatorEntityAttribute = "originatorentity"
+	originatorLoginAttribute  = "originatorlogin"
+	sequenceAttribute         = "sequence"
+	schemaVersionAttribute    = "schemaversion"
+	correlationIDAttribute    = "correlationid"
+	causationIDAttribute      = "causationid"
+)
+
+// attributes maps event onto CloudEvents attributes: event_id onto id, channel onto source, aggregate onto
+// subject, created_at onto time and type URL of payload onto dataschema as https URI. Returned data is JSON of payload.
+func attributes(e *pb.Event) (map[string]string, []byte, error) {
+	if e.GetPayload() == nil {
+		return nil, nil, fmt.Errorf("missing payload of event %s", e.GetEventId())
+	}
+
+	attributes := map[string]string{
+		specVersionAttribute:     SpecVersion,
+		idAttribute:              e.GetEventId(),
+		sourceAttribute:          SourcePrefix + e.GetChannel(),
+		typeAttribute:            e.GetType(),
+		dataContentTypeAttribute: jsonContentType,
+		dataSchemaAttribute:      dataSchemaScheme + e.GetPayload().GetTypeUrl(),
+	}
+	if e.GetAggregateType() != "" || e.GetAggregateId() != "" {
+		attributes[subjectAttribute] = e.GetAggregateType() + "/" + e.GetAggregateId()
+	}
+	if e.GetCreatedAt() != nil {
+		createdAt, err := ptypes.Timestamp(e.GetCreatedAt())
+		if err != nil {
+			return nil, nil, err
+		}
+		attributes[timeAttribute] = createdAt.UTC().Format(time.RFC3339Nano)
+	}
+
+	extensions := map[string]string{
+		originatorIDAttribute:     e.GetOriginator().GetEntityId(),
+		originatorEntityAttribute: e.GetOriginator().GetEntity(),
+		originatorLoginAttribute:  e.GetOriginator().GetLogin(),
+		correlationIDAttribute:    e.GetCorrelationId(),
+		causationIDAttribute:      e.GetCausationId(),
+	}
+	if e.GetSequence() != 0 {
+		extensions[sequenceAttribute] = strconv.FormatUint(e.GetSequence(), 10)
+	}
+	if e.GetSchemaVersion() != 0 {
+		extensions[schemaVersionAttribute] = strconv.FormatUint(uint64(e.GetSchemaVersion()), 10)
+	}
+	for name, value := range extensions {
+		if value != "" {
+			attributes[name] = value
+		}
+	}
+
+	payload, err := ptypes.Empty(e.GetPayload())
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := ptypes.UnmarshalAny(e.GetPayload(), payload); err != nil {
+		return nil, nil, err
+	}
+
+	var data bytes.Buffer
+	marshaler := jsonpb.Marshaler{OrigName: true}
+	if err := marshaler.Marshal(&data, payload); err != nil {
+		return nil, nil, err
+	}
+	return attributes, data.Bytes(), nil
+}
+
+// newEvent maps CloudEvents attributes and JSON data back onto event.
+func newEvent(attributes map[string]string, data []byte) (*pb.Event, error) {
+	if attributes[specVersionAttribute] != SpecVersion {
+		return nil, fmt.Errorf("unsupported specversion %q", attributes[specVersionAttribute])
+	}
+	contentType := strings.TrimSpace(strings.SplitN(attributes[dataContentTypeAttribute], ";", 2)[0])
+	if contentType != "" && contentType != jsonContentType {
+		return nil, fmt.Errorf("unsupported datacontenttype %q", contentType)
+	}
+	if !strings.HasPrefix(attributes[sourceAttribute], SourcePrefix) {
+		return nil, fmt.Errorf("unknown source %q", attributes[sourceAttribute])
+	}
+
+	e := pb.Event{
+		EventId:       attributes[idAttribute],
+		Channel:       strings.TrimPrefix(attributes[sourceAttribute], SourcePrefix),
+		Type:          attributes[typeAttribute],
+		CorrelationId: attributes[correlationIDAttribute],
+		CausationId:   attributes[causationIDAttribute],
+	}
+	if e.EventId == "" || e.Type == "" {
+		return nil, errors.New("missing id or type")
+	}
+
+	if subject := attributes[subjectAttribute]; subject != "" {
+		parts := strings.SplitN(subject, "/", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid subject %q", subject)
+		}
+		e.AggregateType, e.AggregateId = parts[0], parts[1]
+	}
+
+	if value := attributes[timeAttribute]; value != "" {
+		createdAt, err := time.Parse(time.RFC3339Nano, value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid time: %v", err)
+		}
+		if e.CreatedAt, err = ptypes.TimestampProto(createdAt); err != nil {
+			return nil, err
+		}
+	}
+
+	if attributes[originatorIDAttribute] != "" || attributes[originatorEntityAttribute] != "" || attributes[originatorLoginAttribute] != "" {
+		e.Originator = &pb.Event_Claims{
+			EntityId: attributes[originatorIDAttribute],
+			Entity:   attributes[originatorEntityAttribute],
+			Login:    attributes[originatorLoginAttribute],
+		}
+	}
+
+	var err error
+	if value := attributes[sequenceAttribute]; value != "" {
+		if e.Sequence, err = strconv.ParseUint(value, 10, 64); err != nil {
+			return nil, fmt.Errorf("invalid sequence: %v", err)
+		}
+	}
+	if value := attributes[schemaVersionAttribute]; value != "" {
+		version, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid schemaversion: %v", err)
+		}
+		e.SchemaVersion = uint32(version)
+	}
+
+	// type URL of payload is kept in dataschema
+	payload, err := ptypes.Empty(&any.Any{TypeUrl: strings.TrimPrefix(attributes[dataSchemaAttribute], dataSchemaScheme)})
+	if err != nil {
+		return nil, fmt.Errorf("invalid dataschema: %v", err)
+	}
+	if err := jsonpb.Unmarshal(bytes.NewReader(data), payload); err != nil {
+		return nil, fmt.Errorf("invalid data: %v", err)
+	}
+	if e.Payload, err = ptypes.MarshalAny(payload); err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+func encodeStructured(e *pb.Event) (*Message, error) {
+	attributes, data, err := attributes(e)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := make(map[string]interface{}, len(attributes)+1)
+	for name, value := range attributes {
+		fields[name] = value
+	}
+	fields[dataAttribute] = json.RawMessage(data)
+
+	body, err := json.Marshal(fields)
+	if err != nil {
+		return nil, err
+	}
+	return &Message{Header: http.Header{contentTypeKey: {StructuredContentType}}, Body: body}, nil
+}
+
+func decodeStructured(message *Message) (*pb.Event, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(message.Body, &fields); err != nil {
+		return nil, err
+	}
+
+	attributes := make(map[string]string, len(fields))
+	for name, value := range fields {
+		if name == dataAttribute {
+			continue
+		}
+		var attribute string
+		if err := json.Unmarshal(value, &attribute); err != nil {
+			return nil, fmt.Errorf("invalid attribute %s: %v", name, err)
+		}
+		attributes[name] = attribute
+	}
+	return newEvent(attributes, fields[dataAttribute])
+}
+
+func encodeBinary(e *pb.Event) (*Message, error) {
+	attributes, data, err := attributes(e)
+	if err != nil {
+		return nil, err
+	}
+
+	header := http.Header{}
+	for name, value := range attributes {
+		if name == dataContentTypeAttribute {
+			header.Set(contentTypeKey, value)
+			continue
+		}
+		header.Set(binaryHeaderPrefix+name, value)
+	}
+	return &Message{Header: header, Body: data}, nil
+}
+
+func decodeBinary(message *Message) (*pb.Event, error) {
+	attributes := make(map[string]string)
+	for name, values := range message.Header {
+		name = http.CanonicalHeaderKey(name)
+		if strings.HasPrefix(name, binaryHeaderPrefix) && len(values) > 0 {
+			attributes[strings.ToLower(strings.TrimPrefix(name, binaryHeaderPrefix))] = values[0]
+		}
+	}
+	attributes[dataContentTypeAttribute] = message.Header.Get(contentTypeKey)
+	return newEvent(attributes, message.Body)
+}
@@ -0,0 +1,90 @@
+// Package encoding encodes events into wire formats of outputs delivering them outside of NATS.
+package encoding
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+
+	"github.com/golang/protobuf/jsonpb"
+
+	"github.com/migotom/cell-centre-services/pkg/pb"
+)
+
+// Format of encoded event.
+type Format string
+
+const (
+	// JSON is JSON encoding of event message.
+	JSON Format = "json"
+	// CloudEventsStructured is CloudEvents 1.0 structured mode, attributes and data carried in JSON body.
+	CloudEventsStructured Format = "cloudevents"
+	// CloudEventsBinary is CloudEvents 1.0 binary mode, attributes carried in headers and data in body.
+	CloudEventsBinary Format = "cloudevents-binary"
+)
+
+const (
+	jsonContentType = "application/json"
+	contentTypeKey  = "Content-Type"
+)
+
+// ParseFormat returns format of given name, empty name means JSON.
+func ParseFormat(name string) (Format, error) {
+	switch format := Format(name); format {
+	case "":
+		return JSON, nil
+	case JSON, CloudEventsStructured, CloudEventsBinary:
+		return format, nil
+	default:
+		return "", fmt.Errorf("unknown format %q", name)
+	}
+}
+
+// HasHeaders reports whether format carries part of event in headers, such format can't be used by outputs
+// storing bodies only.
+func (format Format) HasHeaders() bool {
+	return format == CloudEventsBinary
+}
+
+// Message is event encoded for transport.
+type Message struct {
+	Header http.Header
+	Body   []byte
+}
+
+// Encode encodes event in given format.
+func Encode(format Format, e *pb.Event) (*Message, error) {
+	switch format {
+	case "", JSON:
+		var body bytes.Buffer
+		marshaler := jsonpb.Marshaler{OrigName: true}
+		if err := marshaler.Marshal(&body, e); err != nil {
+			return nil, err
+		}
+		return &Message{Header: http.Header{contentTypeKey: {jsonContentType}}, Body: body.Bytes()}, nil
+	case CloudEventsStructured:
+		return encodeStructured(e)
+	case CloudEventsBinary:
+		return encodeBinary(e)
+	default:
+		return nil, fmt.Errorf("unknown format %q", format)
+	}
+}
+
+// Decode decodes event encoded in given format.
+func Decode(format Format, message *Message) (*pb.Event, error) {
+	switch format {
+	case "", JSON:
+		var e pb.Event
+		if err := jsonpb.Unmarshal(bytes.NewReader(message.Body), &e); err != nil {
+			return nil, err
+		}
+		return &e, nil
+	case CloudEventsStructured:
+		return decodeStructured(message)
+	case CloudEventsBinary:
+		return decodeBinary(message)
+	default:
+		return nil, fmt.Errorf("unknown format %q", format)
+	}
+}
@@ -0,0 +1,185 @@
+package encoding
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/golang/protobuf/ptypes/timestamp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/migotom/cell-centre-services/pkg/helpers"
+	"github.com/migotom/cell-centre-services/pkg/pb"
+)
+
+func testEvent(t *testing.T) *pb.Event {
+	payload, err := ptypes.MarshalAny(&pb.Employee{Id: "5d3783ee28ae9468bc528907", Email: "john@page.com", Name: "John"})
+	require.NoError(t, err)
+
+	return &pb.Event{
+		EventId:       "0b9d3c0e-3f4e-4b8c-9d59-7c0f0f6f5a01",
+		Channel:       "employees",
+		Type:          "NewEmployee",
+		AggregateId:   "5d3783ee28ae9468bc528907",
+		AggregateType: "employees",
+		Originator:    &pb.Event_Claims{EntityId: "5d3783ee28ae9468bc528906", Entity: "employee", Login: "admin@page.com"},
+		CreatedAt:     &timestamp.Timestamp{Seconds: 1564000000, Nanos: 500},
+		Sequence:      18446744073709551615,
+		Payload:       payload,
+		SchemaVersion: 2,
+		CorrelationId: "request-1",
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	for _, format := range []Format{JSON, CloudEventsStructured, CloudEventsBinary} {
+		t.Run(string(format), func(t *testing.T) {
+			e := testEvent(t)
+
+			message, err := Encode(format, e)
+			require.NoError(t, err)
+
+			decoded, err := Decode(format, message)
+			require.NoError(t, err)
+			assert.True(t, proto.Equal(e, decoded), "decoded event %v", decoded)
+		})
+	}
+}
+
+func TestEncodeCloudEvents(t *testing.T) {
+	expectedAttributes := map[string]string{
+		"specversion":      "1.0",
+		"id":               "0b9d3c0e-3f4e-4b8c-9d59-7c0f0f6f5a01",
+		"source":           "/cell-centre/employees",
+		"type":             "NewEmployee",
+		"subject":          "employees/5d3783ee28ae9468bc528907",
+		"time":             "2019-07-24T20:26:40.0000005Z",
+		"datacontenttype":  "application/json",
+		"dataschema":       "https://type.googleapis.com/pb.Employee",
+		"originatorid":     "5d3783ee28ae9468bc528906",
+		"originatorentity": "employee",
+		"originatorlogin":  "admin@page.com",
+		"sequence":         "18446744073709551615",
+		"schemaversion":    "2",
+		"correlationid":    "request-1",
+	}
+	expectedData := `{"id":"5d3783ee28ae9468bc528907","email":"john@page.com","name":"John"}`
+
+	t.Run("Structured", func(t *testing.T) {
+		message, err := Encode(CloudEventsStructured, testEvent(t))
+		require.NoError(t, err)
+		assert.Equal(t, StructuredContentType, message.Header.Get("Content-Type"))
+
+		var fields map[string]json.RawMessage
+		require.NoError(t, json.Unmarshal(message.Body, &fields))
+		assert.JSONEq(t, expectedData, string(fields["data"]))
+		delete(fields, "data")
+
+		attributes := make(map[string]string)
+		for name, value := range fields {
+			var attribute string
+			require.NoError(t, json.Unmarshal(value, &attribute))
+			attributes[name] = attribute
+		}
+		assert.Equal(t, expectedAttributes, attributes)
+	})
+
+	t.Run("Binary", func(t *testing.T) {
+		message, err := Encode(CloudEventsBinary, testEvent(t))
+		require.NoError(t, err)
+		assert.JSONEq(t, expectedData, string(message.Body))
+
+		for name, value := range expectedAttributes {
+			if name == "datacontenttype" {
+				assert.Equal(t, value, message.Header.Get("Content-Type"))
+				continue
+			}
+			assert.Equal(t, value, message.Header.Get("ce-"+name), name)
+		}
+	})
+}
+
+func TestDecodeCloudEvents(t *testing.T) {
+	cases := []struct {
+		Name        string
+		Format      Format
+		Message     *Message
+		ExpectedErr string
+	}{
+		{
+			Name:   "Structured event of other producer",
+			Format: CloudEventsStructured,
+			Message: &Message{Body: []byte(`{"specversion":"1.0","id":"1","source":"/cell-centre/employees","type":"DeleteEmployee",
+				"dataschema":"https://type.googleapis.com/pb.EmployeeFilter","data":{"email":"john@page.com"}}`)},
+		},
+		{
+			Name:   "Binary event with charset",
+			Format: CloudEventsBinary,
+			Message: &Message{
+				Header: http.Header{
+					"Ce-Specversion": {"1.0"}, "Ce-Id": {"1"}, "Ce-Source": {"/cell-centre/employees"}, "Ce-Type": {"DeleteEmployee"},
+					"Ce-Dataschema": {"type.googleapis.com/pb.EmployeeFilter"}, "Content-Type": {"application/json; charset=utf-8"},
+				},
+				Body: []byte(`{"email":"john@page.com"}`),
+			},
+		},
+		{
+			Name:        "Unsupported specversion",
+			Format:      CloudEventsStructured,
+			Message:     &Message{Body: []byte(`{"specversion":"0.3","id":"1","source":"/cell-centre/employees","type":"DeleteEmployee"}`)},
+			ExpectedErr: `unsupported specversion "0.3"`,
+		},
+		{
+			Name:        "Unknown source",
+			Format:      CloudEventsStructured,
+			Message:     &Message{Body: []byte(`{"specversion":"1.0","id":"1","source":"/payroll","type":"DeleteEmployee"}`)},
+			ExpectedErr: `unknown source "/payroll"`,
+		},
+		{
+			Name:   "Unknown dataschema",
+			Format: CloudEventsStructured,
+			Message: &Message{Body: []byte(`{"specversion":"1.0","id":"1","source":"/cell-centre/employees","type":"DeleteEmployee",
+				"dataschema":"https://type.googleapis.com/pb.Unknown","data":{}}`)},
+			ExpectedErr: "invalid dataschema: proto: not found",
+		},
+		{
+			Name:   "Unsupported data content type",
+			Format: CloudEventsBinary,
+			Message: &Message{
+				Header: http.Header{"Ce-Specversion": {"1.0"}, "Ce-Id": {"1"}, "Ce-Source": {"/cell-centre/employees"}, "Ce-Type": {"DeleteEmployee"}, "Content-Type": {"application/xml"}},
+				Body:   []byte(`<email>john@page.com</email>`),
+			},
+			ExpectedErr: `unsupported datacontenttype "application/xml"`,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			e, err := Decode(tc.Format, tc.Message)
+			helpers.AssertErrors(t, tc.ExpectedErr, err)
+			if err != nil {
+				return
+			}
+
+			var filter pb.EmployeeFilter
+			require.NoError(t, ptypes.UnmarshalAny(e.GetPayload(), &filter))
+			assert.Equal(t, "employees", e.GetChannel())
+			assert.Equal(t, "john@page.com", filter.GetEmail())
+		})
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	format, err := ParseFormat("")
+	assert.NoError(t, err)
+	assert.Equal(t, JSON, format)
+
+	format, err = ParseFormat("cloudevents-binary")
+	assert.NoError(t, err)
+	assert.Equal(t, CloudEventsBinary, format)
+
+	_, err = ParseFormat("xml")
+	helpers.AssertErrors(t, `unknown format "xml"`, err)
+}
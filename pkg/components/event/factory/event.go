@@ -1,69 +1,62 @@
 package factory
 
 import (
+	"fmt"
+
 	"github.com/golang/protobuf/ptypes"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 
-	employeeFactory "github.com/migotom/cell-centre-services/pkg/components/employee/factory"
+	"github.com/migotom/cell-centre-services/pkg/components/event"
 	"github.com/migotom/cell-centre-services/pkg/entities"
 	"github.com/migotom/cell-centre-services/pkg/pb"
 )
 
 // EventEntityFactory is entities.Event factory.
 type EventEntityFactory struct {
-	employeeFactory *employeeFactory.EmployeeEntityFactory
+	registry *event.Registry
 }
 
-// NewEventEntityFactory creates new factory.
-func NewEventEntityFactory() *EventEntityFactory {
+// NewEventEntityFactory creates new factory decoding payloads registered in given registry.
+func NewEventEntityFactory(registry *event.Registry) *EventEntityFactory {
 	return &EventEntityFactory{
-		employeeFactory: employeeFactory.NewEmployeeEntityFactory(nil),
+		registry: registry,
 	}
 }
 
-func (factory *EventEntityFactory) NewFromEvent(e pb.Event) *entities.Event {
-	originatorID, _ := primitive.ObjectIDFromHex(e.Originator.EntityId)
+// NewFromEvent creates Event entity from Event message upcasted to current schema version.
+func (factory *EventEntityFactory) NewFromEvent(e pb.Event) (*entities.Event, error) {
+	if err := factory.registry.Upcast(&e); err != nil {
+		return nil, err
+	}
+
+	originatorID, _ := primitive.ObjectIDFromHex(e.GetOriginator().GetEntityId())
 
-	event := entities.Event{
+	entity := entities.Event{
 		EventID:       e.EventId,
 		Channel:       e.Channel,
 		Type:          entities.EventType(e.Type),
 		AggregateID:   e.AggregateId,
 		AggregateType: e.AggregateType,
+		Sequence:      e.Sequence,
+		SchemaVersion: e.SchemaVersion,
+		CorrelationID: e.CorrelationId,
+		CausationID:   e.CausationId,
 		Originator: entities.EventOriginator{
 			EntityID: originatorID,
-			Entity:   e.Originator.Entity,
-			Login:    e.Originator.Login,
+			Entity:   e.GetOriginator().GetEntity(),
+			Login:    e.GetOriginator().GetLogin(),
 		},
 	}
 
-	switch data := e.Data.(type) {
-	case *pb.Event_Employee:
-		var err error
-		event.Data, err = factory.employeeFactory.NewFromEmployee(data.Employee)
-		if err != nil {
-			return nil
-		}
-	case *pb.Event_UpdateRequest:
-		var err error
-		event.Data, err = factory.employeeFactory.NewFromUpdateEmployeeRequest(data.UpdateRequest)
-		if err != nil {
-			return nil
-		}
-	case *pb.Event_EmployeeFilter:
-		var err error
-		event.Data, err = factory.employeeFactory.NewFromEmployeeFilter(data.EmployeeFilter)
-		if err != nil {
-			return nil
-		}
-	}
-
-	createdAt, err := ptypes.Timestamp(e.CreatedAt)
+	var err error
+	entity.Data, err = factory.registry.Decode(&e)
 	if err != nil {
-		return nil
+		return nil, fmt.Errorf("invalid data of event %s: %v", e.EventId, err)
 	}
 
-	event.CreatedAt = createdAt
+	if entity.CreatedAt, err = ptypes.Timestamp(e.CreatedAt); err != nil {
+		return nil, fmt.Errorf("invalid creation time of event %s: %v", e.EventId, err)
+	}
 
-	return &event
+	return &entity, nil
 }
@@ -0,0 +1,193 @@
+package event
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/migotom/cell-centre-services/pkg/entities"
+	"github.com/migotom/cell-centre-services/pkg/pb"
+)
+
+// SchemaVersion is current version of events schema, events of older versions are upcasted when read.
+// Version 1 carried data in oneof of event, version 2 carries payload as google.protobuf.Any.
+const SchemaVersion = 2
+
+// Definition of event type registered by component.
+type Definition struct {
+	Type          entities.EventType
+	Channel       string
+	AggregateType string
+	// Payload is prototype of message carried by events of type.
+	Payload proto.Message
+	// Encode encodes data of stored event back into payload, events of types without encoder can't be archived.
+	Encode PayloadEncoder
+}
+
+// PayloadDecoder decodes payload message into entity.
+type PayloadDecoder func(payload proto.Message) (interface{}, error)
+
+// PayloadEncoder encodes data of event, either entity or document read from event log, into payload message.
+type PayloadEncoder func(data interface{}) (proto.Message, error)
+
+type payloadRegistration struct {
+	prototype proto.Message
+	decode    PayloadDecoder
+}
+
+// Registry of event types, decoders of their payloads and upcasters of older schema versions,
+// filled by components owning aggregates.
+type Registry struct {
+	sync.RWMutex
+
+	definitions map[entities.EventType]Definition
+	payloads    map[string]payloadRegistration
+	upcasters   map[uint32][]Upcaster
+}
+
+// NewRegistry returns registry with upcasters of envelope only.
+func NewRegistry() *Registry {
+	registry := Registry{
+		definitions: make(map[entities.EventType]Definition),
+		payloads:    make(map[string]payloadRegistration),
+		upcasters:   make(map[uint32][]Upcaster),
+	}
+	registry.upcasters[1] = []Upcaster{upcastDataToPayload}
+	return &registry
+}
+
+// RegisterEvent registers event type, each type may be registered once.
+func (registry *Registry) RegisterEvent(definition Definition) error {
+	if definition.Type == "" || definition.Channel == "" || definition.Payload == nil {
+		return fmt.Errorf("incomplete definition of event %q", definition.Type)
+	}
+
+	registry.Lock()
+	defer registry.Unlock()
+
+	if _, ok := registry.definitions[definition.Type]; ok {
+		return fmt.Errorf("event %s already registered", definition.Type)
+	}
+	registry.definitions[definition.Type] = definition
+	return nil
+}
+
+// RegisterPayload registers decoder of payload message, each message may be registered once.
+func (registry *Registry) RegisterPayload(prototype proto.Message, decode PayloadDecoder) error {
+	name := proto.MessageName(prototype)
+	if name == "" {
+		return fmt.Errorf("unknown name of payload message %T", prototype)
+	}
+
+	registry.Lock()
+	defer registry.Unlock()
+
+	if _, ok := registry.payloads[name]; ok {
+		return fmt.Errorf("payload %s already registered", name)
+	}
+	registry.payloads[name] = payloadRegistration{prototype: prototype, decode: decode}
+	return nil
+}
+
+// Event returns definition of registered event type.
+func (registry *Registry) Event(eventType entities.EventType) (Definition, bool) {
+	registry.RLock()
+	defer registry.RUnlock()
+
+	definition, ok := registry.definitions[eventType]
+	return definition, ok
+}
+
+// Channel reports whether any registered event type is published on given channel.
+func (registry *Registry) Channel(channel string) bool {
+	registry.RLock()
+	defer registry.RUnlock()
+
+	for _, definition := range registry.definitions {
+		if definition.Channel == channel {
+			return true
+		}
+	}
+	return false
+}
+
+// Payload upcasts event to current schema version and returns its payload message.
+func (registry *Registry) Payload(e *pb.Event) (proto.Message, error) {
+	if err := registry.Upcast(e); err != nil {
+		return nil, err
+	}
+	if e.GetPayload() == nil {
+		return nil, fmt.Errorf("missing payload of event %s", e.GetEventId())
+	}
+
+	name, err := ptypes.AnyMessageName(e.GetPayload())
+	if err != nil {
+		return nil, err
+	}
+
+	registry.RLock()
+	registration, ok := registry.payloads[name]
+	registry.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown payload %s", name)
+	}
+
+	message := proto.Clone(registration.prototype)
+	message.Reset()
+	if err := ptypes.UnmarshalAny(e.GetPayload(), message); err != nil {
+		return nil, err
+	}
+	return message, nil
+}
+
+// Decode decodes payload of event into entity.
+func (registry *Registry) Decode(e *pb.Event) (interface{}, error) {
+	message, err := registry.Payload(e)
+	if err != nil {
+		return nil, err
+	}
+
+	registry.RLock()
+	registration := registry.payloads[proto.MessageName(message)]
+	registry.RUnlock()
+
+	return registration.decode(message)
+}
+
+// Encode encodes data of stored event into payload message of its type.
+func (registry *Registry) Encode(e *entities.Event) (proto.Message, error) {
+	definition, ok := registry.Event(e.Type)
+	if !ok {
+		return nil, fmt.Errorf("unknown event %s", e.Type)
+	}
+	if definition.Encode == nil {
+		return nil, fmt.Errorf("missing encoder of event %s", e.Type)
+	}
+	return definition.Encode(e.Data)
+}
+
+// DecodeData decodes data of event, either entity decoded from message or document read from event log.
+func DecodeData(data interface{}, v interface{}) error {
+	if data == nil {
+		return fmt.Errorf("missing data")
+	}
+	raw, err := bson.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("can't decode data: %v", err)
+	}
+	if err := bson.Unmarshal(raw, v); err != nil {
+		return fmt.Errorf("can't decode data: %v", err)
+	}
+	return nil
+}
+
+// PayloadCipher encrypts and decrypts personal data carried by payloads of events, payloads of other events
+// aren't changed.
+type PayloadCipher interface {
+	Encrypt(ctx context.Context, e *pb.Event) error
+	Decrypt(ctx context.Context, e *pb.Event) error
+}
@@ -0,0 +1,114 @@
+package event
+
+import (
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/migotom/cell-centre-services/pkg/entities"
+	"github.com/migotom/cell-centre-services/pkg/helpers"
+	"github.com/migotom/cell-centre-services/pkg/pb"
+)
+
+func newTestRegistry(t *testing.T) *Registry {
+	registry := NewRegistry()
+	if err := registry.RegisterEvent(Definition{Type: entities.NewEmployeeEvent, Channel: "employees", AggregateType: "employees", Payload: &pb.Employee{}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := registry.RegisterPayload(&pb.Employee{}, func(m proto.Message) (interface{}, error) {
+		return m.(*pb.Employee).Email, nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	return registry
+}
+
+func TestRegister(t *testing.T) {
+	cases := []struct {
+		Name        string
+		Register    func(*Registry) error
+		ExpectedErr string
+	}{
+		{
+			Name: "New event type",
+			Register: func(r *Registry) error {
+				return r.RegisterEvent(Definition{Type: entities.DeleteEmployeeEvent, Channel: "employees", Payload: &pb.EmployeeFilter{}})
+			},
+		},
+		{
+			Name: "Event type registered twice",
+			Register: func(r *Registry) error {
+				return r.RegisterEvent(Definition{Type: entities.NewEmployeeEvent, Channel: "employees", Payload: &pb.Employee{}})
+			},
+			ExpectedErr: "event NewEmployee already registered",
+		},
+		{
+			Name: "Event type without payload",
+			Register: func(r *Registry) error {
+				return r.RegisterEvent(Definition{Type: entities.DeleteEmployeeEvent, Channel: "employees"})
+			},
+			ExpectedErr: `incomplete definition of event "DeleteEmployee"`,
+		},
+		{
+			Name: "Payload registered twice",
+			Register: func(r *Registry) error {
+				return r.RegisterPayload(&pb.Employee{}, nil)
+			},
+			ExpectedErr: "payload pb.Employee already registered",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			err := tc.Register(newTestRegistry(t))
+			helpers.AssertErrors(t, tc.ExpectedErr, err)
+		})
+	}
+}
+
+func TestChannel(t *testing.T) {
+	registry := newTestRegistry(t)
+	assert.True(t, registry.Channel("employees"))
+	assert.False(t, registry.Channel("deadletters"))
+}
+
+func TestDecode(t *testing.T) {
+	employeePayload, _ := ptypes.MarshalAny(&pb.Employee{Email: "admin@page.com"})
+	rolePayload, _ := ptypes.MarshalAny(&pb.Role{Name: "admin"})
+
+	cases := []struct {
+		Name         string
+		Event        *pb.Event
+		ExpectedData interface{}
+		ExpectedErr  string
+	}{
+		{
+			Name:         "Payload",
+			Event:        &pb.Event{EventId: "1", Payload: employeePayload, SchemaVersion: SchemaVersion},
+			ExpectedData: "admin@page.com",
+		},
+		{
+			Name:         "Data of schema version 1",
+			Event:        &pb.Event{EventId: "1", Data: &pb.Event_Employee{Employee: &pb.Employee{Email: "admin@page.com"}}},
+			ExpectedData: "admin@page.com",
+		},
+		{
+			Name:        "Unknown payload",
+			Event:       &pb.Event{EventId: "1", Payload: rolePayload, SchemaVersion: SchemaVersion},
+			ExpectedErr: "unknown payload pb.Role",
+		},
+		{
+			Name:        "Missing payload",
+			Event:       &pb.Event{EventId: "1"},
+			ExpectedErr: "missing payload of event 1",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			data, err := newTestRegistry(t).Decode(tc.Event)
+			helpers.AssertErrors(t, tc.ExpectedErr, err)
+			assert.Equal(t, tc.ExpectedData, data)
+		})
+	}
+}
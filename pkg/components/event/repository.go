@@ -2,11 +2,33 @@ package event
 
 import (
 	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
 
 	"github.com/migotom/cell-centre-services/pkg/entities"
 )
 
+// ErrDuplicateEvent is returned by repository when event with the same ID is already stored.
+var ErrDuplicateEvent = errors.New("event already stored")
+
+// ErrSequenceConflict is returned by repository when other event of aggregate is already stored at the same sequence.
+var ErrSequenceConflict = errors.New("other event of aggregate already stored at the same sequence")
+
 // Repository of events.
 type Repository interface {
 	New(ctx context.Context, event *entities.Event) error
+	NewMany(ctx context.Context, events []*entities.Event) []error
+	LastSequence(ctx context.Context, aggregateType, aggregateID string) (uint64, error)
+	Events(ctx context.Context, aggregateType, aggregateID string, afterSequence uint64) ([]*entities.Event, error)
+	AggregateIDs(ctx context.Context, aggregateType string) ([]string, error)
+	EventsBefore(ctx context.Context, before time.Time, limit int) ([]*entities.Event, error)
+	SubjectEvents(ctx context.Context, aggregateType, aggregateID string, originatorID primitive.ObjectID) ([]*entities.Event, error)
+	Remove(ctx context.Context, eventIDs []string) (int64, error)
+}
+
+// Sequencer generates sequence numbers of events within aggregate.
+type Sequencer interface {
+	Next(ctx context.Context, aggregateType, aggregateID string) (uint64, error)
 }
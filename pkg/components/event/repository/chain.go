@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/migotom/cell-centre-services/pkg/components/event"
+	"github.com/migotom/cell-centre-services/pkg/entities"
+)
+
+type mongoChainRepo struct {
+	DB *mongo.Database
+}
+
+// NewMongoChainRepository returns new repository of hash chains over events collection, links are stored in
+// documents of their events.
+func NewMongoChainRepository(db *mongo.Database) event.ChainRepository {
+	return &mongoChainRepo{
+		DB: db,
+	}
+}
+
+// Head returns link of the last chained event of channel, nil when no event is chained.
+func (repository *mongoChainRepo) Head(ctx context.Context, channel string) (*entities.ChainLink, error) {
+	collection := repository.DB.Collection(collectionName)
+
+	res := collection.FindOne(ctx,
+		bson.D{{Key: "channel", Value: channel}, {Key: "chain.position", Value: bson.M{"$gt": 0}}},
+		options.FindOne().SetSort(bson.M{"chain.position": -1}).SetProjection(bson.M{"chain": 1}),
+	)
+
+	var head struct {
+		Chain *entities.ChainLink
+	}
+	if err := res.Decode(&head); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return head.Chain, nil
+}
+
+// Unchained returns up to limit events of channel not chained yet, in order they were stored.
+func (repository *mongoChainRepo) Unchained(ctx context.Context, channel string, limit int) ([]*entities.ChainedEvent, error) {
+	return repository.find(ctx,
+		bson.D{{Key: "channel", Value: channel}, {Key: "chain.position", Value: nil}},
+		options.Find().SetSort(bson.M{"_id": 1}).SetLimit(int64(limit)),
+	)
+}
+
+// Chained returns up to limit chained events of channel following given position, in order of chain.
+func (repository *mongoChainRepo) Chained(ctx context.Context, channel string, afterPosition uint64, limit int) ([]*entities.ChainedEvent, error) {
+	return repository.find(ctx,
+		bson.D{{Key: "channel", Value: channel}, {Key: "chain.position", Value: bson.M{"$gt": afterPosition}}},
+		options.Find().SetSort(bson.M{"chain.position": 1}).SetLimit(int64(limit)),
+	)
+}
+
+// Link stores link of event not chained yet, event.ErrChainConflict is returned when event is already chained
+// or other event of channel holds the same position.
+func (repository *mongoChainRepo) Link(ctx context.Context, eventID string, link *entities.ChainLink) error {
+	collection := repository.DB.Collection(collectionName)
+
+	res, err := collection.UpdateOne(ctx,
+		bson.M{"event_id": eventID, "chain": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"chain": link}},
+	)
+	if writeException, ok := err.(mongo.WriteException); ok {
+		for _, writeError := range writeException.WriteErrors {
+			if writeError.Code == duplicateKeyErrorCode {
+				return event.ErrChainConflict
+			}
+		}
+	}
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return event.ErrChainConflict
+	}
+	return nil
+}
+
+func (repository *mongoChainRepo) find(ctx context.Context, filter interface{}, opts *options.FindOptions) ([]*entities.ChainedEvent, error) {
+	collection := repository.DB.Collection(collectionName)
+
+	cursor, err := collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var events []*entities.ChainedEvent
+	for cursor.Next(ctx) {
+		var stored struct {
+			EventID string `bson:"event_id"`
+			Chain   *entities.ChainLink
+		}
+		if err := cursor.Decode(&stored); err != nil {
+			return nil, err
+		}
+
+		// current document is reused by cursor
+		document := make(bson.Raw, len(cursor.Current))
+		copy(document, cursor.Current)
+
+		events = append(events, &entities.ChainedEvent{
+			EventID:  stored.EventID,
+			Document: document,
+			Link:     stored.Chain,
+		})
+	}
+	return events, cursor.Err()
+}
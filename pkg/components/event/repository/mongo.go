@@ -2,13 +2,30 @@ package repository
 
 import (
 	"context"
+	"fmt"
+	"time"
 
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 
 	"github.com/migotom/cell-centre-services/pkg/components/event"
+	"github.com/migotom/cell-centre-services/pkg/components/event/chain"
 	"github.com/migotom/cell-centre-services/pkg/entities"
 )
 
+const (
+	collectionName = "events"
+
+	// sequenceIndexName is name of unique index of sequenced events within aggregate.
+	sequenceIndexName = "aggregate_sequence"
+	// chainIndexName is name of unique index of positions of events in hash chain of channel.
+	chainIndexName = "channel_chain"
+
+	duplicateKeyErrorCode = 11000
+)
+
 type mongoEventRepo struct {
 	DB *mongo.Database
 }
@@ -20,13 +37,288 @@ func NewMongoEventRepository(db *mongo.Database) event.Repository {
 	}
 }
 
-func (repository *mongoEventRepo) New(ctx context.Context, event *entities.Event) error {
-	collection := repository.DB.Collection("events")
+// CreateIndexes creates indexes of events collection, unique event ID index makes storing of events idempotent
+// and unique sequence within aggregate makes appending of events conditional on expected version of aggregate.
+// Creation time of events is indexed for archival, originators for exports of their data and unique position in hash chain of channel makes chain linear.
+func CreateIndexes(ctx context.Context, db *mongo.Database) error {
+	_, err := db.Collection(collectionName).Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.M{"event_id": 1},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.M{"createdat": 1},
+		},
+		{
+			Keys: bson.M{"originator.entity_id": 1},
+		},
+		{
+			Keys: bson.D{
+				{Key: "aggregatetype", Value: 1},
+				{Key: "aggregateid", Value: 1},
+				{Key: "sequence", Value: -1},
+			},
+		},
+		{
+			Keys: bson.D{
+				{Key: "aggregatetype", Value: 1},
+				{Key: "aggregateid", Value: 1},
+				{Key: "sequence", Value: 1},
+			},
+			Options: options.Index().
+				SetName(sequenceIndexName).
+				SetUnique(true).
+				SetPartialFilterExpression(bson.M{"sequence": bson.M{"$gt": 0}}),
+		},
+		{
+			Keys: bson.D{
+				{Key: "channel", Value: 1},
+				{Key: "chain.position", Value: 1},
+				{Key: "_id", Value: 1},
+			},
+		},
+		{
+			Keys: bson.D{
+				{Key: "channel", Value: 1},
+				{Key: "chain.position", Value: 1},
+			},
+			Options: options.Index().
+				SetName(chainIndexName).
+				SetUnique(true).
+				SetPartialFilterExpression(bson.M{"chain.position": bson.M{"$gt": 0}}),
+		},
+	})
+	return err
+}
+
+// New stores event, event.ErrDuplicateEvent is returned if event with the same ID was already stored and
+// event.ErrSequenceConflict if other event of aggregate was stored at the same sequence.
+func (repository *mongoEventRepo) New(ctx context.Context, e *entities.Event) error {
+	collection := repository.DB.Collection(collectionName)
+
+	_, err := collection.InsertOne(ctx, e)
+	if writeException, ok := err.(mongo.WriteException); ok {
+		for _, writeError := range writeException.WriteErrors {
+			if writeError.Code == duplicateKeyErrorCode {
+				return repository.duplicate(ctx, e)
+			}
+		}
+	}
+	return err
+}
+
+// NewMany stores events with single unordered write and returns result of each event in order of given events,
+// event.ErrDuplicateEvent is result of events already stored. Failure of one event doesn't stop storing others.
+// Events carrying their documents are stored as their documents followed by their links.
+func (repository *mongoEventRepo) NewMany(ctx context.Context, events []*entities.Event) []error {
+	results := make([]error, len(events))
+	if len(events) == 0 {
+		return results
+	}
+
+	documents := make([]interface{}, 0, len(events))
+	for _, e := range events {
+		if e.Document == nil {
+			documents = append(documents, e)
+			continue
+		}
 
-	_, err := collection.InsertOne(ctx, event)
+		// restored event keeps its archived document, so its hash in chain still matches
+		document, err := chain.Restored(e.Document, e.Chain)
+		if err != nil {
+			for i := range results {
+				results[i] = fmt.Errorf("invalid document of event %s: %v", e.EventID, err)
+			}
+			return results
+		}
+		documents = append(documents, document)
+	}
+
+	collection := repository.DB.Collection(collectionName)
+
+	_, err := collection.InsertMany(ctx, documents, options.InsertMany().SetOrdered(false))
+	switch err := err.(type) {
+	case nil:
+	case mongo.BulkWriteException:
+		for _, writeError := range err.WriteErrors {
+			if writeError.Index < 0 || writeError.Index >= len(results) {
+				continue
+			}
+			if writeError.Code == duplicateKeyErrorCode {
+				results[writeError.Index] = repository.duplicate(ctx, events[writeError.Index])
+			} else {
+				results[writeError.Index] = writeError
+			}
+		}
+		if err.WriteConcernError != nil {
+			// acknowledgement of written events is unknown, all of them are written again
+			for i := range results {
+				if results[i] == nil {
+					results[i] = err.WriteConcernError
+				}
+			}
+		}
+	default:
+		for i := range results {
+			results[i] = err
+		}
+	}
+	return results
+}
+
+// LastSequence returns the highest sequence of stored events of given aggregate.
+func (repository *mongoEventRepo) LastSequence(ctx context.Context, aggregateType, aggregateID string) (uint64, error) {
+	collection := repository.DB.Collection(collectionName)
+
+	res := collection.FindOne(ctx,
+		bson.D{{Key: "aggregatetype", Value: aggregateType}, {Key: "aggregateid", Value: aggregateID}},
+		options.FindOne().SetSort(bson.M{"sequence": -1}).SetProjection(bson.M{"sequence": 1}),
+	)
+
+	var last struct {
+		Sequence uint64
+	}
+	if err := res.Decode(&last); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return 0, nil
+		}
+		return 0, err
+	}
+	return last.Sequence, nil
+}
+
+// Events returns stored events of given aggregate following given sequence, in order of their sequence.
+// Data of returned events is left as decoded by driver.
+func (repository *mongoEventRepo) Events(ctx context.Context, aggregateType, aggregateID string, afterSequence uint64) ([]*entities.Event, error) {
+	collection := repository.DB.Collection(collectionName)
+
+	cursor, err := collection.Find(ctx,
+		bson.D{
+			{Key: "aggregatetype", Value: aggregateType},
+			{Key: "aggregateid", Value: aggregateID},
+			{Key: "sequence", Value: bson.M{"$gt": afterSequence}},
+		},
+		options.Find().SetSort(bson.M{"sequence": 1}),
+	)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var events []*entities.Event
+	for cursor.Next(ctx) {
+		var e entities.Event
+		if err := cursor.Decode(&e); err != nil {
+			return nil, err
+		}
+		events = append(events, &e)
+	}
+	return events, cursor.Err()
+}
+
+// AggregateIDs returns IDs of aggregates of given type with stored events.
+func (repository *mongoEventRepo) AggregateIDs(ctx context.Context, aggregateType string) ([]string, error) {
+	collection := repository.DB.Collection(collectionName)
+
+	values, err := collection.Distinct(ctx, "aggregateid", bson.M{"aggregatetype": aggregateType})
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(values))
+	for _, value := range values {
+		if id, ok := value.(string); ok && id != "" {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+// EventsBefore returns up to limit stored events created before given time, the oldest first. Data of returned
+// events is left as decoded by driver, their stored documents are kept along.
+func (repository *mongoEventRepo) EventsBefore(ctx context.Context, before time.Time, limit int) ([]*entities.Event, error) {
+	collection := repository.DB.Collection(collectionName)
+
+	cursor, err := collection.Find(ctx,
+		bson.M{"createdat": bson.M{"$lt": before}},
+		options.Find().SetSort(bson.D{{Key: "createdat", Value: 1}, {Key: "_id", Value: 1}}).SetLimit(int64(limit)),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var events []*entities.Event
+	for cursor.Next(ctx) {
+		var e entities.Event
+		if err := cursor.Decode(&e); err != nil {
+			return nil, err
+		}
+
+		// current document is reused by cursor
+		e.Document = make(bson.Raw, len(cursor.Current))
+		copy(e.Document, cursor.Current)
+		events = append(events, &e)
 	}
+	return events, cursor.Err()
+}
+
+// SubjectEvents returns stored events of given aggregate and events originated by given entity, in order of their
+// creation. Data of returned events is left as decoded by driver.
+func (repository *mongoEventRepo) SubjectEvents(ctx context.Context, aggregateType, aggregateID string, originatorID primitive.ObjectID) ([]*entities.Event, error) {
+	collection := repository.DB.Collection(collectionName)
 
-	return nil
+	cursor, err := collection.Find(ctx,
+		bson.M{"$or": bson.A{
+			bson.D{
+				{Key: "aggregatetype", Value: aggregateType},
+				{Key: "aggregateid", Value: aggregateID},
+			},
+			bson.M{"originator.entity_id": originatorID},
+		}},
+		options.Find().SetSort(bson.D{{Key: "createdat", Value: 1}, {Key: "_id", Value: 1}}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var events []*entities.Event
+	for cursor.Next(ctx) {
+		var e entities.Event
+		if err := cursor.Decode(&e); err != nil {
+			return nil, err
+		}
+		events = append(events, &e)
+	}
+	return events, cursor.Err()
+}
+
+// Remove deletes stored events of given IDs and returns number of deleted events.
+func (repository *mongoEventRepo) Remove(ctx context.Context, eventIDs []string) (int64, error) {
+	if len(eventIDs) == 0 {
+		return 0, nil
+	}
+	collection := repository.DB.Collection(collectionName)
+
+	res, err := collection.DeleteMany(ctx, bson.M{"event_id": bson.M{"$in": eventIDs}})
+	if err != nil {
+		return 0, err
+	}
+	return res.DeletedCount, nil
+}
+
+// duplicate returns error of event violating unique index. Event violating both indexes may be reported
+// for any of them, so stored ID of event is checked to tell redelivered event from conflicting one.
+func (repository *mongoEventRepo) duplicate(ctx context.Context, e *entities.Event) error {
+	collection := repository.DB.Collection(collectionName)
+
+	count, err := collection.CountDocuments(ctx, bson.M{"event_id": e.EventID})
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return event.ErrDuplicateEvent
+	}
+	return event.ErrSequenceConflict
 }
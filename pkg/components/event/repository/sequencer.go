@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/migotom/cell-centre-services/pkg/components/event"
+)
+
+const sequencesCollectionName = "aggregate_sequences"
+
+type mongoSequencer struct {
+	DB *mongo.Database
+}
+
+// NewMongoSequencer returns sequencer keeping counters of aggregates in MongoDB.
+func NewMongoSequencer(db *mongo.Database) event.Sequencer {
+	return &mongoSequencer{
+		DB: db,
+	}
+}
+
+// Next increments and returns sequence counter of given aggregate.
+func (sequencer *mongoSequencer) Next(ctx context.Context, aggregateType, aggregateID string) (uint64, error) {
+	collection := sequencer.DB.Collection(sequencesCollectionName)
+
+	res := collection.FindOneAndUpdate(ctx,
+		bson.M{"_id": aggregateType + "/" + aggregateID},
+		bson.M{"$inc": bson.M{"sequence": int64(1)}},
+		options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After),
+	)
+
+	var counter struct {
+		Sequence int64
+	}
+	if err := res.Decode(&counter); err != nil {
+		return 0, err
+	}
+	return uint64(counter.Sequence), nil
+}
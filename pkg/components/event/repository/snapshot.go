@@ -0,0 +1,132 @@
+package repository
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/migotom/cell-centre-services/pkg/components/event"
+	"github.com/migotom/cell-centre-services/pkg/entities"
+)
+
+const snapshotsCollectionName = "snapshots"
+
+type mongoSnapshotRepo struct {
+	DB *mongo.Database
+}
+
+// NewMongoSnapshotRepository returns new snapshot MongoDB repository.
+func NewMongoSnapshotRepository(db *mongo.Database) event.SnapshotRepository {
+	return &mongoSnapshotRepo{
+		DB: db,
+	}
+}
+
+// CreateSnapshotIndexes creates indexes of snapshots collection, snapshot of aggregate at sequence is unique
+// within version of state.
+func CreateSnapshotIndexes(ctx context.Context, db *mongo.Database) error {
+	_, err := db.Collection(snapshotsCollectionName).Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{
+			{Key: "aggregate_type", Value: 1},
+			{Key: "aggregate_id", Value: 1},
+			{Key: "version", Value: 1},
+			{Key: "sequence", Value: -1},
+		},
+		Options: options.Index().SetUnique(true),
+	})
+	return err
+}
+
+// Save stores snapshot, snapshot of the same aggregate, version and sequence is replaced.
+func (repository *mongoSnapshotRepo) Save(ctx context.Context, snapshot *entities.Snapshot) error {
+	collection := repository.DB.Collection(snapshotsCollectionName)
+
+	_, err := collection.ReplaceOne(ctx,
+		bson.D{
+			{Key: "aggregate_type", Value: snapshot.AggregateType},
+			{Key: "aggregate_id", Value: snapshot.AggregateID},
+			{Key: "version", Value: snapshot.Version},
+			{Key: "sequence", Value: snapshot.Sequence},
+		},
+		snapshot,
+		options.Replace().SetUpsert(true),
+	)
+	return err
+}
+
+// Latest returns snapshot of aggregate with the highest sequence among snapshots of given version.
+func (repository *mongoSnapshotRepo) Latest(ctx context.Context, aggregateType, aggregateID string, version uint32) (*entities.Snapshot, error) {
+	collection := repository.DB.Collection(snapshotsCollectionName)
+
+	res := collection.FindOne(ctx,
+		bson.D{
+			{Key: "aggregate_type", Value: aggregateType},
+			{Key: "aggregate_id", Value: aggregateID},
+			{Key: "version", Value: version},
+		},
+		options.FindOne().SetSort(bson.M{"sequence": -1}),
+	)
+
+	var snapshot entities.Snapshot
+	if err := res.Decode(&snapshot); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, event.ErrSnapshotNotFound
+		}
+		return nil, err
+	}
+	return &snapshot, nil
+}
+
+// Prune removes snapshots of aggregates of given type except given number of the latest snapshots of each aggregate,
+// returns number of removed snapshots.
+func (repository *mongoSnapshotRepo) Prune(ctx context.Context, aggregateType string, keep int) (int64, error) {
+	collection := repository.DB.Collection(snapshotsCollectionName)
+
+	cursor, err := collection.Find(ctx,
+		bson.M{"aggregate_type": aggregateType},
+		options.Find().
+			SetSort(bson.D{{Key: "aggregate_id", Value: 1}, {Key: "sequence", Value: -1}}).
+			SetProjection(bson.M{"_id": 1, "aggregate_id": 1}),
+	)
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var (
+		pruned    []interface{}
+		aggregate string
+		kept      int
+	)
+	for cursor.Next(ctx) {
+		var snapshot struct {
+			ID          interface{} `bson:"_id"`
+			AggregateID string      `bson:"aggregate_id"`
+		}
+		if err := cursor.Decode(&snapshot); err != nil {
+			return 0, err
+		}
+		if snapshot.AggregateID != aggregate {
+			aggregate, kept = snapshot.AggregateID, 0
+		}
+		if kept < keep {
+			kept++
+			continue
+		}
+		pruned = append(pruned, snapshot.ID)
+	}
+	if err := cursor.Err(); err != nil {
+		return 0, err
+	}
+	if len(pruned) == 0 {
+		return 0, nil
+	}
+
+	res, err := collection.DeleteMany(ctx, bson.M{"_id": bson.M{"$in": pruned}})
+	if err != nil {
+		return 0, err
+	}
+	return res.DeletedCount, nil
+}
@@ -0,0 +1,117 @@
+package event
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/migotom/cell-centre-services/pkg/entities"
+)
+
+// ErrSnapshotNotFound is returned by repository when aggregate has no snapshot.
+var ErrSnapshotNotFound = errors.New("snapshot not found")
+
+// SnapshotRepository of aggregate snapshots.
+type SnapshotRepository interface {
+	Save(ctx context.Context, snapshot *entities.Snapshot) error
+	Latest(ctx context.Context, aggregateType, aggregateID string, version uint32) (*entities.Snapshot, error)
+	Prune(ctx context.Context, aggregateType string, keep int) (int64, error)
+}
+
+// Aggregate is state rebuilt by folding events of aggregate in order of their sequence.
+type Aggregate interface {
+	AggregateType() string
+	AggregateID() string
+	// Sequence of the last event folded into state.
+	Sequence() uint64
+	// Apply folds next event of aggregate into state.
+	Apply(e *entities.Event) error
+	// SnapshotVersion is version of state layout, snapshots of other versions are ignored.
+	SnapshotVersion() uint32
+	MarshalState() (bson.Raw, error)
+	UnmarshalState(state bson.Raw, sequence uint64) error
+}
+
+// SnapshotStore rebuilds aggregates from their latest snapshots and events following them, and appends their
+// new events.
+type SnapshotStore struct {
+	events    Repository
+	snapshots SnapshotRepository
+	every     uint64
+}
+
+// NewSnapshotStore returns new snapshot store saving snapshot of aggregate once every given number of events
+// was folded past its latest snapshot, zero disables automatic snapshots.
+func NewSnapshotStore(events Repository, snapshots SnapshotRepository, every uint64) *SnapshotStore {
+	return &SnapshotStore{
+		events:    events,
+		snapshots: snapshots,
+		every:     every,
+	}
+}
+
+// Load rebuilds aggregate from its latest snapshot and folds only events stored after it.
+func (store *SnapshotStore) Load(ctx context.Context, aggregate Aggregate) error {
+	var snapshotSequence uint64
+
+	snapshot, err := store.snapshots.Latest(ctx, aggregate.AggregateType(), aggregate.AggregateID(), aggregate.SnapshotVersion())
+	switch err {
+	case nil:
+		if err := aggregate.UnmarshalState(snapshot.State, snapshot.Sequence); err != nil {
+			return fmt.Errorf("can't restore snapshot at sequence %d: %v", snapshot.Sequence, err)
+		}
+		snapshotSequence = snapshot.Sequence
+	case ErrSnapshotNotFound:
+	default:
+		return fmt.Errorf("can't load snapshot: %v", err)
+	}
+
+	events, err := store.events.Events(ctx, aggregate.AggregateType(), aggregate.AggregateID(), snapshotSequence)
+	if err != nil {
+		return fmt.Errorf("can't load events: %v", err)
+	}
+	for _, e := range events {
+		if err := aggregate.Apply(e); err != nil {
+			return fmt.Errorf("can't apply event %s: %v", e.EventID, err)
+		}
+	}
+
+	if store.every > 0 && aggregate.Sequence()-snapshotSequence >= store.every {
+		return store.Save(ctx, aggregate)
+	}
+	return nil
+}
+
+// Save saves snapshot of current state of aggregate.
+func (store *SnapshotStore) Save(ctx context.Context, aggregate Aggregate) error {
+	state, err := aggregate.MarshalState()
+	if err != nil {
+		return fmt.Errorf("can't encode state: %v", err)
+	}
+
+	snapshot := entities.Snapshot{
+		AggregateType: aggregate.AggregateType(),
+		AggregateID:   aggregate.AggregateID(),
+		Sequence:      aggregate.Sequence(),
+		Version:       aggregate.SnapshotVersion(),
+		State:         state,
+		CreatedAt:     time.Now().UTC(),
+	}
+	if err := store.snapshots.Save(ctx, &snapshot); err != nil {
+		return fmt.Errorf("can't save snapshot: %v", err)
+	}
+	return nil
+}
+
+// Append stores next event of aggregate expecting aggregate wasn't changed since it was loaded and applies it
+// to aggregate. ErrSequenceConflict is returned if other event was appended in the meantime.
+func (store *SnapshotStore) Append(ctx context.Context, aggregate Aggregate, e *entities.Event) error {
+	e.Sequence = aggregate.Sequence() + 1
+	if err := store.events.New(ctx, e); err != nil {
+		return err
+	}
+	return aggregate.Apply(e)
+}
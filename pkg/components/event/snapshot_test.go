@@ -0,0 +1,167 @@
+package event
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/migotom/cell-centre-services/pkg/entities"
+	"github.com/migotom/cell-centre-services/pkg/helpers"
+)
+
+// counterAggregate counts applied events.
+type counterAggregate struct {
+	sequence uint64
+	Count    int `bson:"count"`
+}
+
+func (aggregate *counterAggregate) AggregateType() string   { return "counters" }
+func (aggregate *counterAggregate) AggregateID() string     { return "1" }
+func (aggregate *counterAggregate) Sequence() uint64        { return aggregate.sequence }
+func (aggregate *counterAggregate) SnapshotVersion() uint32 { return 1 }
+func (aggregate *counterAggregate) Apply(e *entities.Event) error {
+	if e.Type != "Increment" {
+		return errors.New("not an increment")
+	}
+	aggregate.Count++
+	aggregate.sequence = e.Sequence
+	return nil
+}
+func (aggregate *counterAggregate) MarshalState() (bson.Raw, error) {
+	return bson.Marshal(aggregate)
+}
+func (aggregate *counterAggregate) UnmarshalState(state bson.Raw, sequence uint64) error {
+	aggregate.sequence = sequence
+	return bson.Unmarshal(state, aggregate)
+}
+
+// historyRepository holds events of single aggregate.
+type historyRepository struct {
+	Repository
+
+	events []*entities.Event
+}
+
+func (repository *historyRepository) Events(ctx context.Context, aggregateType, aggregateID string, afterSequence uint64) ([]*entities.Event, error) {
+	var events []*entities.Event
+	for _, e := range repository.events {
+		if e.Sequence > afterSequence {
+			events = append(events, e)
+		}
+	}
+	return events, nil
+}
+
+// memorySnapshotRepository holds the latest snapshot.
+type memorySnapshotRepository struct {
+	SnapshotRepository
+
+	latest *entities.Snapshot
+	saved  int
+}
+
+func (repository *memorySnapshotRepository) Save(ctx context.Context, snapshot *entities.Snapshot) error {
+	repository.latest = snapshot
+	repository.saved++
+	return nil
+}
+
+func (repository *memorySnapshotRepository) Latest(ctx context.Context, aggregateType, aggregateID string, version uint32) (*entities.Snapshot, error) {
+	if repository.latest == nil || repository.latest.Version != version {
+		return nil, ErrSnapshotNotFound
+	}
+	return repository.latest, nil
+}
+
+func increments(from, to uint64) []*entities.Event {
+	var events []*entities.Event
+	for sequence := from; sequence <= to; sequence++ {
+		events = append(events, &entities.Event{Type: "Increment", Sequence: sequence})
+	}
+	return events
+}
+
+func snapshotOf(t *testing.T, count int, sequence uint64) *entities.Snapshot {
+	state, err := bson.Marshal(&counterAggregate{Count: count})
+	assert.NoError(t, err)
+	return &entities.Snapshot{AggregateType: "counters", AggregateID: "1", Sequence: sequence, Version: 1, State: state}
+}
+
+func TestSnapshotStoreLoad(t *testing.T) {
+	cases := []struct {
+		Name             string
+		Events           []*entities.Event
+		Snapshot         *entities.Snapshot
+		Every            uint64
+		ExpectedCount    int
+		ExpectedSequence uint64
+		ExpectedSaved    int
+		ExpectedErr      string
+	}{
+		{
+			Name:             "Aggregate without snapshot",
+			Events:           increments(1, 3),
+			ExpectedCount:    3,
+			ExpectedSequence: 3,
+		},
+		{
+			Name:             "Only events after snapshot are folded",
+			Events:           increments(1, 5),
+			Snapshot:         snapshotOf(t, 40, 4),
+			ExpectedCount:    41,
+			ExpectedSequence: 5,
+		},
+		{
+			Name:             "Snapshot of other version is ignored",
+			Events:           increments(1, 2),
+			Snapshot:         &entities.Snapshot{Sequence: 2, Version: 2},
+			ExpectedCount:    2,
+			ExpectedSequence: 2,
+		},
+		{
+			Name:             "Snapshot is saved every N events",
+			Events:           increments(1, 7),
+			Snapshot:         snapshotOf(t, 2, 2),
+			Every:            5,
+			ExpectedCount:    7,
+			ExpectedSequence: 7,
+			ExpectedSaved:    1,
+		},
+		{
+			Name:             "Snapshot isn't saved before N events",
+			Events:           increments(1, 6),
+			Snapshot:         snapshotOf(t, 2, 2),
+			Every:            5,
+			ExpectedCount:    6,
+			ExpectedSequence: 6,
+		},
+		{
+			Name:        "Event that can't be applied",
+			Events:      []*entities.Event{{EventID: "e1", Type: "Decrement", Sequence: 1}},
+			ExpectedErr: "can't apply event e1: not an increment",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			snapshots := &memorySnapshotRepository{latest: tc.Snapshot}
+			store := NewSnapshotStore(&historyRepository{events: tc.Events}, snapshots, tc.Every)
+
+			aggregate := &counterAggregate{}
+			err := store.Load(context.Background(), aggregate)
+			helpers.AssertErrors(t, tc.ExpectedErr, err)
+			if err != nil {
+				return
+			}
+
+			assert.Equal(t, tc.ExpectedCount, aggregate.Count)
+			assert.Equal(t, tc.ExpectedSequence, aggregate.Sequence())
+			assert.Equal(t, tc.ExpectedSaved, snapshots.saved)
+			if tc.ExpectedSaved > 0 {
+				assert.Equal(t, tc.ExpectedSequence, snapshots.latest.Sequence)
+			}
+		})
+	}
+}
@@ -1,55 +1,128 @@
 package event
 
 import (
-	"log"
-	"sync"
-
-	"github.com/golang/protobuf/proto"
-	stan "github.com/nats-io/go-nats-streaming"
-	"github.com/nats-io/nuid"
+	"time"
 
 	"github.com/migotom/cell-centre-services/pkg/pb"
 )
 
-func NewEventsStreaming() *EventsStreaming {
-	return &EventsStreaming{
-		id: nuid.Next(),
+// Streaming of events through NATS based message bus.
+type Streaming interface {
+	// Publish event on its channel.
+	Publish(event *pb.Event) error
+	// PublishData publishes raw message data on given channel, non empty ID is used for deduplication when supported.
+	PublishData(channel, id string, data []byte) error
+	// Subscribe to events published on given channel.
+	Subscribe(channel string, handler MessageHandler, options ...SubscribeOption) (Subscription, error)
+	// Ping reports error when connection with message bus isn't established.
+	Ping() error
+	// Close connection with message bus.
+	Close() error
+}
+
+// MessageHandler handles messages delivered by subscription.
+type MessageHandler func(msg Message)
+
+// Message delivered by subscription.
+type Message interface {
+	// Data returns marshaled pb.Event.
+	Data() []byte
+	// Sequence returns sequence number of message in channel.
+	Sequence() uint64
+	// Timestamp returns time when message was stored by message bus.
+	Timestamp() time.Time
+	// Deliveries returns number of delivery attempts of message including current one.
+	Deliveries() int
+	// Ack acknowledges message, required only by subscriptions in manual ack mode.
+	Ack() error
+	// Nak reports message which failed to be handled. It's redelivered after delay growing with its deliveries
+	// when message bus supports negative acknowledgements, otherwise after ack wait.
+	Nak() error
+}
+
+// Subscription of events channel.
+type Subscription interface {
+	// Close subscription, durable subscriptions keep their position.
+	Close() error
+}
+
+// SubscribeOptions of events channel subscription.
+type SubscribeOptions struct {
+	QueueGroup          string
+	DurableName         string
+	ManualAck           bool
+	AckWait             time.Duration
+	DeliverAllAvailable bool
+	StartAtSequence     uint64
+	StartAtTime         time.Time
+	MaxInFlight         int
+}
+
+// SubscribeOption sets option of subscription.
+type SubscribeOption func(*SubscribeOptions)
+
+// QueueGroup distributes messages between subscribers of the same group.
+func QueueGroup(group string) SubscribeOption {
+	return func(options *SubscribeOptions) {
+		options.QueueGroup = group
 	}
 }
 
-type EventsStreaming struct {
-	sync.Mutex
+// DurableName makes subscription durable, position in channel is kept by message bus between reconnects.
+func DurableName(name string) SubscribeOption {
+	return func(options *SubscribeOptions) {
+		options.DurableName = name
+	}
+}
 
-	id   string
-	conn stan.Conn
+// ManualAck disables acknowledging of messages once handler returns, handler has to call Message.Ack by itself.
+func ManualAck() SubscribeOption {
+	return func(options *SubscribeOptions) {
+		options.ManualAck = true
+	}
 }
 
-func (es *EventsStreaming) Connect(clusterID string, options ...stan.Option) (err error) {
-	es.Lock()
-	defer es.Unlock()
+// MaxInFlight limits number of delivered messages waiting for acknowledgement, with one message is delivered
+// after the previous one is acknowledged, so they're handled in order of channel.
+func MaxInFlight(messages int) SubscribeOption {
+	return func(options *SubscribeOptions) {
+		options.MaxInFlight = messages
+	}
+}
 
-	if es.conn, err = stan.Connect(clusterID, es.id, options...); err != nil {
-		return nil
+// AckWait sets time after which not acknowledged message is redelivered.
+func AckWait(wait time.Duration) SubscribeOption {
+	return func(options *SubscribeOptions) {
+		options.AckWait = wait
 	}
-	return
 }
 
-// NATS returns the current NATS connection.
-func (es *EventsStreaming) NATS() stan.Conn {
-	es.Lock()
-	defer es.Unlock()
+// DeliverAllAvailable starts new subscription from the first message available in channel.
+func DeliverAllAvailable() SubscribeOption {
+	return func(options *SubscribeOptions) {
+		options.DeliverAllAvailable = true
+	}
+}
 
-	return es.conn
+// StartAtSequence starts new subscription from message with given sequence number.
+func StartAtSequence(sequence uint64) SubscribeOption {
+	return func(options *SubscribeOptions) {
+		options.StartAtSequence = sequence
+	}
 }
 
-func (es *EventsStreaming) Publish(event *pb.Event) error {
-	data, err := proto.Marshal(event)
-	if err != nil {
-		return err
+// StartAtTime starts new subscription from the first message stored at or after given time.
+func StartAtTime(start time.Time) SubscribeOption {
+	return func(options *SubscribeOptions) {
+		options.StartAtTime = start
 	}
+}
 
-	if es.conn == nil {
-		log.Fatal("ups")
+// NewSubscribeOptions returns subscription options with applied given ones.
+func NewSubscribeOptions(options ...SubscribeOption) SubscribeOptions {
+	var subscribeOptions SubscribeOptions
+	for _, option := range options {
+		option(&subscribeOptions)
 	}
-	return es.conn.Publish(event.Channel, data)
+	return subscribeOptions
 }
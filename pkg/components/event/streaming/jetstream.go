@@ -0,0 +1,295 @@
+package streaming
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+
+	"github.com/migotom/cell-centre-services/pkg/components/event"
+	"github.com/migotom/cell-centre-services/pkg/helpers"
+	"github.com/migotom/cell-centre-services/pkg/pb"
+)
+
+const (
+	defaultPublishTimeout = 5 * time.Second
+	defaultNakDelay       = time.Second
+)
+
+// JetStreamConfig of NATS JetStream streams and consumers provisioning.
+type JetStreamConfig struct {
+	PublishTimeout helpers.Duration        `toml:"publish_timeout"`
+	Streams        []JetStreamStreamConfig `toml:"streams"`
+	Consumer       JetStreamConsumerConfig `toml:"consumer"`
+}
+
+// JetStreamStreamConfig of stream created or updated during connection.
+type JetStreamStreamConfig struct {
+	Name            string           `toml:"name"`
+	Subjects        []string         `toml:"subjects"`
+	Storage         string           `toml:"storage"`
+	Replicas        int              `toml:"replicas"`
+	MaxAge          helpers.Duration `toml:"max_age"`
+	DuplicateWindow helpers.Duration `toml:"duplicate_window"`
+}
+
+// JetStreamConsumerConfig of pull consumers created by subscriptions.
+type JetStreamConsumerConfig struct {
+	AckWait       helpers.Duration `toml:"ack_wait"`
+	MaxDeliver    int              `toml:"max_deliver"`
+	MaxAckPending int              `toml:"max_ack_pending"`
+	// NakDelay before redelivery of message which failed to be handled, multiplied by its deliveries and
+	// limited by ack wait.
+	NakDelay helpers.Duration `toml:"nak_delay"`
+}
+
+type jetStreamStreaming struct {
+	config JetStreamConfig
+	conn   *nats.Conn
+	js     jetstream.JetStream
+}
+
+// NewJetStreamStreaming connects to NATS server, provisions configured streams and returns events streaming.
+func NewJetStreamStreaming(ctx context.Context, url string, config JetStreamConfig, options ...nats.Option) (event.Streaming, error) {
+	conn, err := nats.Connect(url, options...)
+	if err != nil {
+		return nil, err
+	}
+
+	js, err := jetstream.New(conn)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	streaming := jetStreamStreaming{
+		config: config,
+		conn:   conn,
+		js:     js,
+	}
+	if err := streaming.provisionStreams(ctx); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return &streaming, nil
+}
+
+// provisionStreams creates or updates configured streams. Services share streams, so subjects of existing stream
+// are kept along with configured ones, otherwise service started last would drop subjects of other services.
+func (streaming *jetStreamStreaming) provisionStreams(ctx context.Context) error {
+	for _, streamConfig := range streaming.config.Streams {
+		storage := jetstream.FileStorage
+		switch streamConfig.Storage {
+		case "", "file":
+		case "memory":
+			storage = jetstream.MemoryStorage
+		default:
+			return fmt.Errorf("unknown storage %q of stream %s", streamConfig.Storage, streamConfig.Name)
+		}
+
+		subjects, err := streaming.streamSubjects(ctx, streamConfig)
+		if err != nil {
+			return fmt.Errorf("can't provision stream %s: %v", streamConfig.Name, err)
+		}
+
+		_, err = streaming.js.CreateOrUpdateStream(ctx, jetstream.StreamConfig{
+			Name:       streamConfig.Name,
+			Subjects:   subjects,
+			Storage:    storage,
+			Replicas:   streamConfig.Replicas,
+			MaxAge:     streamConfig.MaxAge.Duration,
+			Duplicates: streamConfig.DuplicateWindow.Duration,
+		})
+		if err != nil {
+			return fmt.Errorf("can't provision stream %s: %v", streamConfig.Name, err)
+		}
+	}
+	return nil
+}
+
+// streamSubjects returns configured subjects of stream followed by subjects of existing stream missing in config.
+func (streaming *jetStreamStreaming) streamSubjects(ctx context.Context, streamConfig JetStreamStreamConfig) ([]string, error) {
+	stream, err := streaming.js.Stream(ctx, streamConfig.Name)
+	if errors.Is(err, jetstream.ErrStreamNotFound) {
+		return streamConfig.Subjects, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	subjects := append([]string(nil), streamConfig.Subjects...)
+	configured := make(map[string]bool, len(subjects))
+	for _, subject := range subjects {
+		configured[subject] = true
+	}
+	for _, subject := range stream.CachedInfo().Config.Subjects {
+		if !configured[subject] {
+			subjects = append(subjects, subject)
+		}
+	}
+	return subjects, nil
+}
+
+func (streaming *jetStreamStreaming) Publish(event *pb.Event) error {
+	data, err := proto.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	// event ID is used as message ID, JetStream drops duplicates published within stream's duplicate window
+	return streaming.PublishData(event.Channel, event.EventId, data)
+}
+
+func (streaming *jetStreamStreaming) PublishData(channel, id string, data []byte) error {
+	timeout := streaming.config.PublishTimeout.Duration
+	if timeout == 0 {
+		timeout = defaultPublishTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var options []jetstream.PublishOpt
+	if id != "" {
+		options = append(options, jetstream.WithMsgID(id))
+	}
+	_, err := streaming.js.Publish(ctx, channel, data, options...)
+	return err
+}
+
+func (streaming *jetStreamStreaming) Subscribe(channel string, handler event.MessageHandler, options ...event.SubscribeOption) (event.Subscription, error) {
+	subscribeOptions := event.NewSubscribeOptions(options...)
+
+	ctx, cancel := context.WithTimeout(context.Background(), defaultPublishTimeout)
+	defer cancel()
+
+	stream, err := streaming.js.StreamNameBySubject(ctx, channel)
+	if err != nil {
+		return nil, fmt.Errorf("can't find stream of channel %s: %v", channel, err)
+	}
+
+	consumerConfig := jetstream.ConsumerConfig{
+		Durable:       consumerName(subscribeOptions),
+		FilterSubject: channel,
+		DeliverPolicy: jetstream.DeliverNewPolicy,
+		AckPolicy:     jetstream.AckExplicitPolicy,
+		AckWait:       streaming.config.Consumer.AckWait.Duration,
+		MaxDeliver:    streaming.config.Consumer.MaxDeliver,
+		MaxAckPending: streaming.config.Consumer.MaxAckPending,
+	}
+	if subscribeOptions.AckWait != 0 {
+		consumerConfig.AckWait = subscribeOptions.AckWait
+	}
+	if subscribeOptions.MaxInFlight != 0 {
+		consumerConfig.MaxAckPending = subscribeOptions.MaxInFlight
+	}
+	switch {
+	case subscribeOptions.StartAtSequence != 0:
+		consumerConfig.DeliverPolicy = jetstream.DeliverByStartSequencePolicy
+		consumerConfig.OptStartSeq = subscribeOptions.StartAtSequence
+	case !subscribeOptions.StartAtTime.IsZero():
+		startTime := subscribeOptions.StartAtTime
+		consumerConfig.DeliverPolicy = jetstream.DeliverByStartTimePolicy
+		consumerConfig.OptStartTime = &startTime
+	case subscribeOptions.DeliverAllAvailable:
+		consumerConfig.DeliverPolicy = jetstream.DeliverAllPolicy
+	}
+
+	consumer, err := streaming.js.CreateOrUpdateConsumer(ctx, stream, consumerConfig)
+	if err != nil {
+		return nil, fmt.Errorf("can't provision consumer of channel %s: %v", channel, err)
+	}
+
+	nakDelay := streaming.config.Consumer.NakDelay.Duration
+	if nakDelay == 0 {
+		nakDelay = defaultNakDelay
+	}
+
+	consumeContext, err := consumer.Consume(func(msg jetstream.Msg) {
+		message := jetStreamMessage{msg: msg, nakDelay: nakDelay, ackWait: consumerConfig.AckWait}
+		if metadata, err := msg.Metadata(); err == nil {
+			message.metadata = *metadata
+		}
+
+		handler(&message)
+
+		if !subscribeOptions.ManualAck {
+			msg.Ack()
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &jetStreamSubscription{consumeContext: consumeContext}, nil
+}
+
+func (streaming *jetStreamStreaming) Ping() error {
+	if status := streaming.conn.Status(); status != nats.CONNECTED {
+		return fmt.Errorf("not connected to NATS server (%v)", status)
+	}
+	return nil
+}
+
+func (streaming *jetStreamStreaming) Close() error {
+	return streaming.conn.Drain()
+}
+
+// consumerName returns name of durable consumer shared by all members of queue group,
+// empty name makes consumer ephemeral.
+func consumerName(options event.SubscribeOptions) string {
+	var parts []string
+	for _, part := range []string{options.DurableName, options.QueueGroup} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return strings.NewReplacer(".", "_", "*", "_", ">", "_", " ", "_").Replace(strings.Join(parts, "_"))
+}
+
+type jetStreamSubscription struct {
+	consumeContext jetstream.ConsumeContext
+}
+
+func (subscription *jetStreamSubscription) Close() error {
+	subscription.consumeContext.Stop()
+	return nil
+}
+
+type jetStreamMessage struct {
+	msg      jetstream.Msg
+	metadata jetstream.MsgMetadata
+	nakDelay time.Duration
+	ackWait  time.Duration
+}
+
+func (message *jetStreamMessage) Data() []byte {
+	return message.msg.Data()
+}
+
+func (message *jetStreamMessage) Sequence() uint64 {
+	return message.metadata.Sequence.Stream
+}
+
+func (message *jetStreamMessage) Timestamp() time.Time {
+	return message.metadata.Timestamp
+}
+
+func (message *jetStreamMessage) Deliveries() int {
+	return int(message.metadata.NumDelivered)
+}
+
+func (message *jetStreamMessage) Ack() error {
+	return message.msg.Ack()
+}
+
+func (message *jetStreamMessage) Nak() error {
+	delay := message.nakDelay * time.Duration(message.Deliveries())
+	if message.ackWait != 0 && delay > message.ackWait {
+		delay = message.ackWait
+	}
+	return message.msg.NakWithDelay(delay)
+}
@@ -0,0 +1,322 @@
+package streaming
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/nats-io/nats-server/v2/server"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/migotom/cell-centre-services/pkg/components/event"
+	"github.com/migotom/cell-centre-services/pkg/helpers"
+	"github.com/migotom/cell-centre-services/pkg/pb"
+)
+
+func runJetStreamServer(t *testing.T) *server.Server {
+	natsServer, err := server.NewServer(&server.Options{
+		Host:      "127.0.0.1",
+		Port:      server.RANDOM_PORT,
+		JetStream: true,
+		StoreDir:  t.TempDir(),
+		NoLog:     true,
+		NoSigs:    true,
+	})
+	require.NoError(t, err)
+
+	go natsServer.Start()
+	if !natsServer.ReadyForConnections(5 * time.Second) {
+		t.Fatal("embedded NATS server not ready")
+	}
+	t.Cleanup(natsServer.Shutdown)
+
+	return natsServer
+}
+
+func testJetStreamConfig() JetStreamConfig {
+	return JetStreamConfig{
+		Streams: []JetStreamStreamConfig{
+			{
+				Name:            "EVENTS",
+				Subjects:        []string{"employees"},
+				Storage:         "memory",
+				DuplicateWindow: helpers.Duration{Duration: time.Minute},
+			},
+		},
+		Consumer: JetStreamConsumerConfig{
+			AckWait:    helpers.Duration{Duration: 200 * time.Millisecond},
+			MaxDeliver: 3,
+		},
+	}
+}
+
+func streamMessages(t *testing.T, natsServer *server.Server, stream string) uint64 {
+	conn, err := nats.Connect(natsServer.ClientURL())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	js, err := jetstream.New(conn)
+	require.NoError(t, err)
+
+	s, err := js.Stream(context.Background(), stream)
+	require.NoError(t, err)
+
+	info, err := s.Info(context.Background())
+	require.NoError(t, err)
+	return info.State.Msgs
+}
+
+func receive(t *testing.T, messages <-chan event.Message) event.Message {
+	select {
+	case msg := <-messages:
+		return msg
+	case <-time.After(5 * time.Second):
+		t.Fatal("message not delivered")
+	}
+	return nil
+}
+
+func TestJetStreamProvisioning(t *testing.T) {
+	cases := []struct {
+		Name        string
+		Config      JetStreamConfig
+		ExpectedErr string
+	}{
+		{
+			Name:   "Valid streams",
+			Config: testJetStreamConfig(),
+		},
+		{
+			Name: "Unknown storage",
+			Config: JetStreamConfig{
+				Streams: []JetStreamStreamConfig{{Name: "EVENTS", Subjects: []string{"employees"}, Storage: "tape"}},
+			},
+			ExpectedErr: `unknown storage "tape" of stream EVENTS`,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			natsServer := runJetStreamServer(t)
+
+			streaming, err := NewJetStreamStreaming(context.Background(), natsServer.ClientURL(), tc.Config)
+			helpers.AssertErrors(t, tc.ExpectedErr, err)
+			if err != nil {
+				return
+			}
+			defer streaming.Close()
+
+			assert.Equal(t, uint64(0), streamMessages(t, natsServer, "EVENTS"))
+		})
+	}
+}
+
+func TestJetStreamSharedStreamProvisioning(t *testing.T) {
+	natsServer := runJetStreamServer(t)
+
+	projectorConfig := testJetStreamConfig()
+	projectorConfig.Streams[0].Subjects = []string{"employees", "roles"}
+	projector, err := NewJetStreamStreaming(context.Background(), natsServer.ClientURL(), projectorConfig)
+	require.NoError(t, err)
+	defer projector.Close()
+
+	// service started later with fewer subjects of shared stream
+	eventLogger, err := NewJetStreamStreaming(context.Background(), natsServer.ClientURL(), testJetStreamConfig())
+	require.NoError(t, err)
+	defer eventLogger.Close()
+
+	conn, err := nats.Connect(natsServer.ClientURL())
+	require.NoError(t, err)
+	defer conn.Close()
+	js, err := jetstream.New(conn)
+	require.NoError(t, err)
+	stream, err := js.Stream(context.Background(), "EVENTS")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"employees", "roles"}, stream.CachedInfo().Config.Subjects)
+
+	subscription, err := projector.Subscribe("roles", func(event.Message) {}, event.DurableName("projector"))
+	require.NoError(t, err)
+	defer subscription.Close()
+
+	require.NoError(t, eventLogger.Publish(&pb.Event{EventId: "1", Channel: "roles"}))
+	assert.Equal(t, uint64(1), streamMessages(t, natsServer, "EVENTS"))
+}
+
+func TestJetStreamPublishDeduplication(t *testing.T) {
+	natsServer := runJetStreamServer(t)
+
+	streaming, err := NewJetStreamStreaming(context.Background(), natsServer.ClientURL(), testJetStreamConfig())
+	require.NoError(t, err)
+	defer streaming.Close()
+
+	first := &pb.Event{EventId: "1", Channel: "employees", Type: "NewEmployee"}
+	second := &pb.Event{EventId: "2", Channel: "employees", Type: "NewEmployee"}
+
+	for _, e := range []*pb.Event{first, first, second, first} {
+		require.NoError(t, streaming.Publish(e))
+	}
+
+	assert.Equal(t, uint64(2), streamMessages(t, natsServer, "EVENTS"))
+}
+
+func TestJetStreamPing(t *testing.T) {
+	natsServer := runJetStreamServer(t)
+
+	streaming, err := NewJetStreamStreaming(context.Background(), natsServer.ClientURL(), testJetStreamConfig(), nats.NoReconnect())
+	require.NoError(t, err)
+	defer streaming.Close()
+
+	assert.NoError(t, streaming.Ping())
+
+	natsServer.Shutdown()
+	assert.Eventually(t, func() bool { return streaming.Ping() != nil }, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestJetStreamDurableSubscription(t *testing.T) {
+	natsServer := runJetStreamServer(t)
+
+	streaming, err := NewJetStreamStreaming(context.Background(), natsServer.ClientURL(), testJetStreamConfig())
+	require.NoError(t, err)
+	defer streaming.Close()
+
+	require.NoError(t, streaming.Publish(&pb.Event{EventId: "1", Channel: "employees", Type: "NewEmployee"}))
+
+	messages := make(chan event.Message, 10)
+	subscription, err := streaming.Subscribe("employees", func(msg event.Message) {
+		messages <- msg
+	}, event.DurableName("logger"), event.QueueGroup("employees-group"), event.DeliverAllAvailable())
+	require.NoError(t, err)
+
+	msg := receive(t, messages)
+	var received pb.Event
+	require.NoError(t, proto.Unmarshal(msg.Data(), &received))
+	assert.Equal(t, "1", received.EventId)
+	assert.Equal(t, uint64(1), msg.Sequence())
+	assert.Equal(t, 1, msg.Deliveries())
+
+	// durable consumer continues from its last acknowledged position
+	require.NoError(t, subscription.Close())
+	require.NoError(t, streaming.Publish(&pb.Event{EventId: "2", Channel: "employees", Type: "NewEmployee"}))
+
+	_, err = streaming.Subscribe("employees", func(msg event.Message) {
+		messages <- msg
+	}, event.DurableName("logger"), event.QueueGroup("employees-group"), event.DeliverAllAvailable())
+	require.NoError(t, err)
+
+	msg = receive(t, messages)
+	require.NoError(t, proto.Unmarshal(msg.Data(), &received))
+	assert.Equal(t, "2", received.EventId)
+	assert.Equal(t, uint64(2), msg.Sequence())
+}
+
+func TestJetStreamManualAck(t *testing.T) {
+	natsServer := runJetStreamServer(t)
+
+	streaming, err := NewJetStreamStreaming(context.Background(), natsServer.ClientURL(), testJetStreamConfig())
+	require.NoError(t, err)
+	defer streaming.Close()
+
+	require.NoError(t, streaming.Publish(&pb.Event{EventId: "1", Channel: "employees", Type: "NewEmployee"}))
+
+	messages := make(chan event.Message, 10)
+	_, err = streaming.Subscribe("employees", func(msg event.Message) {
+		messages <- msg
+		if msg.Deliveries() > 1 {
+			msg.Ack()
+		}
+	}, event.DurableName("logger"), event.ManualAck(), event.DeliverAllAvailable())
+	require.NoError(t, err)
+
+	// not acknowledged message is redelivered after ack wait
+	assert.Equal(t, 1, receive(t, messages).Deliveries())
+	assert.Equal(t, 2, receive(t, messages).Deliveries())
+}
+
+func TestJetStreamNak(t *testing.T) {
+	natsServer := runJetStreamServer(t)
+
+	config := testJetStreamConfig()
+	config.Consumer.AckWait = helpers.Duration{Duration: time.Minute}
+	config.Consumer.NakDelay = helpers.Duration{Duration: 10 * time.Millisecond}
+	streaming, err := NewJetStreamStreaming(context.Background(), natsServer.ClientURL(), config)
+	require.NoError(t, err)
+	defer streaming.Close()
+
+	require.NoError(t, streaming.Publish(&pb.Event{EventId: "1", Channel: "employees", Type: "NewEmployee"}))
+
+	messages := make(chan event.Message, 10)
+	_, err = streaming.Subscribe("employees", func(msg event.Message) {
+		messages <- msg
+		if msg.Deliveries() > 1 {
+			msg.Ack()
+			return
+		}
+		msg.Nak()
+	}, event.DurableName("logger"), event.ManualAck(), event.DeliverAllAvailable())
+	require.NoError(t, err)
+
+	// failed message is redelivered after nak delay instead of ack wait
+	assert.Equal(t, 1, receive(t, messages).Deliveries())
+	start := time.Now()
+	assert.Equal(t, 2, receive(t, messages).Deliveries())
+	assert.Less(t, time.Since(start), time.Minute/2)
+}
+
+func TestJetStreamMaxInFlight(t *testing.T) {
+	natsServer := runJetStreamServer(t)
+
+	config := testJetStreamConfig()
+	config.Consumer.NakDelay = helpers.Duration{Duration: 10 * time.Millisecond}
+	streaming, err := NewJetStreamStreaming(context.Background(), natsServer.ClientURL(), config)
+	require.NoError(t, err)
+	defer streaming.Close()
+
+	for _, id := range []string{"1", "2"} {
+		require.NoError(t, streaming.Publish(&pb.Event{EventId: id, Channel: "employees", Type: "NewEmployee"}))
+	}
+
+	messages := make(chan event.Message, 10)
+	_, err = streaming.Subscribe("employees", func(msg event.Message) {
+		messages <- msg
+		if msg.Sequence() == 1 && msg.Deliveries() == 1 {
+			msg.Nak()
+			return
+		}
+		msg.Ack()
+	}, event.ManualAck(), event.DeliverAllAvailable(), event.MaxInFlight(1))
+	require.NoError(t, err)
+
+	// following message waits until failed one is redelivered and acknowledged
+	for _, expected := range []struct {
+		sequence   uint64
+		deliveries int
+	}{{1, 1}, {1, 2}, {2, 1}} {
+		msg := receive(t, messages)
+		assert.Equal(t, expected.sequence, msg.Sequence())
+		assert.Equal(t, expected.deliveries, msg.Deliveries())
+	}
+}
+
+func TestJetStreamStartAtSequence(t *testing.T) {
+	natsServer := runJetStreamServer(t)
+
+	streaming, err := NewJetStreamStreaming(context.Background(), natsServer.ClientURL(), testJetStreamConfig())
+	require.NoError(t, err)
+	defer streaming.Close()
+
+	for _, id := range []string{"1", "2", "3"} {
+		require.NoError(t, streaming.Publish(&pb.Event{EventId: id, Channel: "employees", Type: "NewEmployee"}))
+	}
+
+	messages := make(chan event.Message, 10)
+	_, err = streaming.Subscribe("employees", func(msg event.Message) {
+		messages <- msg
+	}, event.StartAtSequence(2))
+	require.NoError(t, err)
+
+	assert.Equal(t, uint64(2), receive(t, messages).Sequence())
+	assert.Equal(t, uint64(3), receive(t, messages).Sequence())
+}
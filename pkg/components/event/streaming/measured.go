@@ -0,0 +1,80 @@
+package streaming
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/migotom/cell-centre-services/pkg/components/event"
+	"github.com/migotom/cell-centre-services/pkg/helpers/metrics"
+	"github.com/migotom/cell-centre-services/pkg/pb"
+)
+
+// Stages of events processing counted by failedEvents.
+const (
+	stagePublish = "publish"
+	stageConsume = "consume"
+)
+
+var (
+	publishedEvents = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "events",
+		Name:      "published_total",
+		Help:      "Events published per channel.",
+	}, []string{"channel"})
+	consumedEvents = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "events",
+		Name:      "consumed_total",
+		Help:      "Events delivered to subscribers per channel, including redeliveries.",
+	}, []string{"channel"})
+	failedEvents = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "events",
+		Name:      "failed_total",
+		Help:      "Events per channel that failed to be published, or to be consumed and were redelivered.",
+	}, []string{"channel", "stage"})
+)
+
+type measuredStreaming struct {
+	event.Streaming
+}
+
+// NewMeasuredStreaming returns events streaming that counts published, consumed and failed events per channel.
+// Consumption failure is counted once message is redelivered, as subscribers acknowledge messages on their own.
+func NewMeasuredStreaming(streaming event.Streaming) event.Streaming {
+	return &measuredStreaming{Streaming: streaming}
+}
+
+func (streaming *measuredStreaming) Publish(e *pb.Event) error {
+	err := streaming.Streaming.Publish(e)
+	observePublish(e.Channel, err)
+	return err
+}
+
+func (streaming *measuredStreaming) PublishData(channel, id string, data []byte) error {
+	err := streaming.Streaming.PublishData(channel, id, data)
+	observePublish(channel, err)
+	return err
+}
+
+func (streaming *measuredStreaming) Subscribe(channel string, handler event.MessageHandler, options ...event.SubscribeOption) (event.Subscription, error) {
+	consumed := consumedEvents.WithLabelValues(channel)
+	failed := failedEvents.WithLabelValues(channel, stageConsume)
+
+	return streaming.Streaming.Subscribe(channel, func(msg event.Message) {
+		consumed.Inc()
+		if msg.Deliveries() > 1 {
+			failed.Inc()
+		}
+		handler(msg)
+	}, options...)
+}
+
+func observePublish(channel string, err error) {
+	if err != nil {
+		failedEvents.WithLabelValues(channel, stagePublish).Inc()
+		return
+	}
+	publishedEvents.WithLabelValues(channel).Inc()
+}
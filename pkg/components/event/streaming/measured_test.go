@@ -0,0 +1,78 @@
+package streaming
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/migotom/cell-centre-services/pkg/components/event"
+	"github.com/migotom/cell-centre-services/pkg/helpers"
+	"github.com/migotom/cell-centre-services/pkg/helpers/mocks"
+	"github.com/migotom/cell-centre-services/pkg/pb"
+)
+
+func TestMeasuredStreamingPublish(t *testing.T) {
+	cases := []struct {
+		Name              string
+		Event             *pb.Event
+		ExpectedMockCalls func(*mocks.StreamingMock)
+		ExpectedErr       string
+		ExpectedPublished float64
+		ExpectedFailed    float64
+	}{
+		{
+			Name:  "Published event",
+			Event: &pb.Event{EventId: "1", Channel: "measured-published"},
+			ExpectedMockCalls: func(streaming *mocks.StreamingMock) {
+				streaming.On("Publish", &pb.Event{EventId: "1", Channel: "measured-published"}).Return(nil)
+			},
+			ExpectedPublished: 1,
+		},
+		{
+			Name:  "Failed event",
+			Event: &pb.Event{EventId: "1", Channel: "measured-failed"},
+			ExpectedMockCalls: func(streaming *mocks.StreamingMock) {
+				streaming.On("Publish", &pb.Event{EventId: "1", Channel: "measured-failed"}).Return(errors.New("nats down"))
+			},
+			ExpectedErr:    "nats down",
+			ExpectedFailed: 1,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			streamingMock := mocks.StreamingMock{}
+			tc.ExpectedMockCalls(&streamingMock)
+
+			err := NewMeasuredStreaming(&streamingMock).Publish(tc.Event)
+			helpers.AssertErrors(t, tc.ExpectedErr, err)
+
+			streamingMock.AssertExpectations(t)
+			assert.Equal(t, tc.ExpectedPublished, testutil.ToFloat64(publishedEvents.WithLabelValues(tc.Event.Channel)))
+			assert.Equal(t, tc.ExpectedFailed, testutil.ToFloat64(failedEvents.WithLabelValues(tc.Event.Channel, stagePublish)))
+		})
+	}
+}
+
+func TestMeasuredStreamingSubscribe(t *testing.T) {
+	const channel = "measured-consumed"
+
+	var handler event.MessageHandler
+	streamingMock := mocks.StreamingMock{}
+	streamingMock.On("Subscribe", channel, mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) { handler = args.Get(1).(event.MessageHandler) }).
+		Return(&mocks.SubscriptionMock{}, nil)
+
+	var handled int
+	_, err := NewMeasuredStreaming(&streamingMock).Subscribe(channel, func(event.Message) { handled++ })
+	assert.NoError(t, err)
+
+	handler(&mocks.MessageMock{MsgDeliveries: 1})
+	handler(&mocks.MessageMock{MsgDeliveries: 2})
+
+	assert.Equal(t, 2, handled)
+	assert.Equal(t, float64(2), testutil.ToFloat64(consumedEvents.WithLabelValues(channel)))
+	assert.Equal(t, float64(1), testutil.ToFloat64(failedEvents.WithLabelValues(channel, stageConsume)))
+}
@@ -0,0 +1,35 @@
+package streaming
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/migotom/cell-centre-services/pkg/components/event"
+	"github.com/migotom/cell-centre-services/pkg/pb"
+)
+
+type sequencedStreaming struct {
+	event.Streaming
+
+	sequencer event.Sequencer
+}
+
+// NewSequencedStreaming returns events streaming that numbers published events within their aggregates.
+// Sequence allocated for event that failed to be published leaves a gap in aggregate's sequence.
+func NewSequencedStreaming(streaming event.Streaming, sequencer event.Sequencer) event.Streaming {
+	return &sequencedStreaming{
+		Streaming: streaming,
+		sequencer: sequencer,
+	}
+}
+
+func (streaming *sequencedStreaming) Publish(e *pb.Event) error {
+	if e.Sequence == 0 && e.AggregateId != "" {
+		sequence, err := streaming.sequencer.Next(context.Background(), e.AggregateType, e.AggregateId)
+		if err != nil {
+			return fmt.Errorf("can't allocate sequence of event %s: %v", e.EventId, err)
+		}
+		e.Sequence = sequence
+	}
+	return streaming.Streaming.Publish(e)
+}
@@ -0,0 +1,66 @@
+package streaming
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+
+	"github.com/migotom/cell-centre-services/pkg/helpers"
+	"github.com/migotom/cell-centre-services/pkg/helpers/mocks"
+	"github.com/migotom/cell-centre-services/pkg/pb"
+)
+
+func TestSequencedStreamingPublish(t *testing.T) {
+	cases := []struct {
+		Name              string
+		Event             *pb.Event
+		ExpectedMockCalls func(*mocks.SequencerMock, *mocks.StreamingMock)
+		ExpectedErr       string
+	}{
+		{
+			Name:  "Event of aggregate gets next sequence",
+			Event: &pb.Event{EventId: "1", AggregateType: "employee", AggregateId: "42"},
+			ExpectedMockCalls: func(sequencer *mocks.SequencerMock, streaming *mocks.StreamingMock) {
+				sequencer.On("Next", mock.Anything, "employee", "42").Return(uint64(7), nil)
+				streaming.On("Publish", &pb.Event{EventId: "1", AggregateType: "employee", AggregateId: "42", Sequence: 7}).Return(nil)
+			},
+		},
+		{
+			Name:  "Already sequenced event is published as is",
+			Event: &pb.Event{EventId: "1", AggregateType: "employee", AggregateId: "42", Sequence: 3},
+			ExpectedMockCalls: func(sequencer *mocks.SequencerMock, streaming *mocks.StreamingMock) {
+				streaming.On("Publish", &pb.Event{EventId: "1", AggregateType: "employee", AggregateId: "42", Sequence: 3}).Return(nil)
+			},
+		},
+		{
+			Name:  "Event without aggregate is not sequenced",
+			Event: &pb.Event{EventId: "1"},
+			ExpectedMockCalls: func(sequencer *mocks.SequencerMock, streaming *mocks.StreamingMock) {
+				streaming.On("Publish", &pb.Event{EventId: "1"}).Return(nil)
+			},
+		},
+		{
+			Name:  "Sequencer failure",
+			Event: &pb.Event{EventId: "1", AggregateType: "employee", AggregateId: "42"},
+			ExpectedMockCalls: func(sequencer *mocks.SequencerMock, streaming *mocks.StreamingMock) {
+				sequencer.On("Next", mock.Anything, "employee", "42").Return(uint64(0), errors.New("database down"))
+			},
+			ExpectedErr: "can't allocate sequence of event 1: database down",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			sequencerMock := mocks.SequencerMock{}
+			streamingMock := mocks.StreamingMock{}
+
+			tc.ExpectedMockCalls(&sequencerMock, &streamingMock)
+
+			err := NewSequencedStreaming(&streamingMock, &sequencerMock).Publish(tc.Event)
+			helpers.AssertErrors(t, tc.ExpectedErr, err)
+
+			sequencerMock.AssertExpectations(t)
+			streamingMock.AssertExpectations(t)
+		})
+	}
+}
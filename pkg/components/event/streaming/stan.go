@@ -0,0 +1,216 @@
+package streaming
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	stan "github.com/nats-io/go-nats-streaming"
+	"github.com/nats-io/nuid"
+
+	"github.com/migotom/cell-centre-services/pkg/components/event"
+	"github.com/migotom/cell-centre-services/pkg/pb"
+)
+
+type stanStreaming struct {
+	sync.Mutex
+
+	id   string
+	conn stan.Conn
+	lost error
+}
+
+// NewSTANStreaming connects to NATS Streaming cluster and returns events streaming.
+func NewSTANStreaming(clusterID string, options ...stan.Option) (event.Streaming, error) {
+	streaming := stanStreaming{
+		id: nuid.Next(),
+	}
+
+	options = append(options, stan.SetConnectionLostHandler(streaming.connectionLost))
+
+	var err error
+	if streaming.conn, err = stan.Connect(clusterID, streaming.id, options...); err != nil {
+		return nil, err
+	}
+	return &streaming, nil
+}
+
+func (streaming *stanStreaming) Publish(event *pb.Event) error {
+	data, err := proto.Marshal(event)
+	if err != nil {
+		return err
+	}
+	return streaming.PublishData(event.Channel, event.EventId, data)
+}
+
+func (streaming *stanStreaming) PublishData(channel, id string, data []byte) error {
+	conn := streaming.nats()
+	if conn == nil {
+		return errors.New("not connected to NATS Streaming cluster")
+	}
+	return conn.Publish(channel, data)
+}
+
+func (streaming *stanStreaming) Subscribe(channel string, handler event.MessageHandler, options ...event.SubscribeOption) (event.Subscription, error) {
+	conn := streaming.nats()
+	if conn == nil {
+		return nil, errors.New("not connected to NATS Streaming cluster")
+	}
+
+	subscribeOptions := event.NewSubscribeOptions(options...)
+
+	var stanOptions []stan.SubscriptionOption
+	if subscribeOptions.DurableName != "" {
+		stanOptions = append(stanOptions, stan.DurableName(subscribeOptions.DurableName))
+	}
+	if subscribeOptions.ManualAck {
+		stanOptions = append(stanOptions, stan.SetManualAckMode())
+	}
+	if subscribeOptions.AckWait != 0 {
+		stanOptions = append(stanOptions, stan.AckWait(subscribeOptions.AckWait))
+	}
+	if subscribeOptions.MaxInFlight != 0 {
+		stanOptions = append(stanOptions, stan.MaxInflight(subscribeOptions.MaxInFlight))
+	}
+	switch {
+	case subscribeOptions.StartAtSequence != 0:
+		stanOptions = append(stanOptions, stan.StartAtSequence(subscribeOptions.StartAtSequence))
+	case !subscribeOptions.StartAtTime.IsZero():
+		stanOptions = append(stanOptions, stan.StartAtTime(subscribeOptions.StartAtTime))
+	case subscribeOptions.DeliverAllAvailable:
+		stanOptions = append(stanOptions, stan.DeliverAllAvailable())
+	}
+
+	deliveries := newDeliveriesCounter()
+	callback := func(msg *stan.Msg) {
+		handler(&stanMessage{
+			msg:        msg,
+			deliveries: deliveries,
+			attempt:    deliveries.delivered(msg),
+		})
+	}
+
+	if subscribeOptions.QueueGroup != "" {
+		return conn.QueueSubscribe(channel, subscribeOptions.QueueGroup, callback, stanOptions...)
+	}
+	return conn.Subscribe(channel, callback, stanOptions...)
+}
+
+func (streaming *stanStreaming) Close() error {
+	streaming.Lock()
+	defer streaming.Unlock()
+
+	if streaming.conn == nil {
+		return nil
+	}
+	err := streaming.conn.Close()
+	streaming.conn = nil
+	return err
+}
+
+func (streaming *stanStreaming) Ping() error {
+	streaming.Lock()
+	defer streaming.Unlock()
+
+	switch {
+	case streaming.conn == nil:
+		return errors.New("not connected to NATS Streaming cluster")
+	case streaming.lost != nil:
+		return fmt.Errorf("connection to NATS Streaming cluster lost: %v", streaming.lost)
+	case !streaming.conn.NatsConn().IsConnected():
+		return errors.New("not connected to NATS server")
+	}
+	return nil
+}
+
+// connectionLost records reason of lost connection, NATS Streaming connection isn't restored once it's lost.
+func (streaming *stanStreaming) connectionLost(_ stan.Conn, reason error) {
+	streaming.Lock()
+	defer streaming.Unlock()
+
+	streaming.lost = reason
+}
+
+// nats returns the current NATS Streaming connection.
+func (streaming *stanStreaming) nats() stan.Conn {
+	streaming.Lock()
+	defer streaming.Unlock()
+
+	return streaming.conn
+}
+
+// deliveriesCounter counts redeliveries of not acknowledged messages, NATS Streaming reports only redelivery flag.
+// Counter is local to subscriber, messages of queue group redelivered to other member are counted from the beginning.
+type deliveriesCounter struct {
+	sync.Mutex
+
+	redelivered map[uint64]int
+}
+
+func newDeliveriesCounter() *deliveriesCounter {
+	return &deliveriesCounter{
+		redelivered: make(map[uint64]int),
+	}
+}
+
+func (counter *deliveriesCounter) delivered(msg *stan.Msg) int {
+	if !msg.Redelivered {
+		return 1
+	}
+
+	counter.Lock()
+	defer counter.Unlock()
+
+	deliveries, ok := counter.redelivered[msg.Sequence]
+	if !ok {
+		// first attempt was made before subscriber started to count
+		deliveries = 1
+	}
+	deliveries++
+	counter.redelivered[msg.Sequence] = deliveries
+	return deliveries
+}
+
+func (counter *deliveriesCounter) acked(msg *stan.Msg) {
+	counter.Lock()
+	defer counter.Unlock()
+
+	delete(counter.redelivered, msg.Sequence)
+}
+
+type stanMessage struct {
+	msg        *stan.Msg
+	deliveries *deliveriesCounter
+	attempt    int
+}
+
+func (message *stanMessage) Data() []byte {
+	return message.msg.Data
+}
+
+func (message *stanMessage) Sequence() uint64 {
+	return message.msg.Sequence
+}
+
+func (message *stanMessage) Timestamp() time.Time {
+	return time.Unix(0, message.msg.Timestamp)
+}
+
+func (message *stanMessage) Deliveries() int {
+	return message.attempt
+}
+
+func (message *stanMessage) Ack() error {
+	if err := message.msg.Ack(); err != nil {
+		return err
+	}
+	message.deliveries.acked(message.msg)
+	return nil
+}
+
+// Nak is no-op, NATS Streaming redelivers message once its ack wait passes.
+func (message *stanMessage) Nak() error {
+	return nil
+}
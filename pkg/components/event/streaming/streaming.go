@@ -0,0 +1,62 @@
+package streaming
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	stan "github.com/nats-io/go-nats-streaming"
+
+	"github.com/migotom/cell-centre-services/pkg/components/event"
+	"github.com/migotom/cell-centre-services/pkg/helpers"
+)
+
+const (
+	// BackendSTAN is NATS Streaming backend.
+	BackendSTAN = "stan"
+	// BackendJetStream is NATS JetStream backend.
+	BackendJetStream = "jetstream"
+)
+
+// NATSConfig of events streaming connection.
+type NATSConfig struct {
+	Backend   string          `toml:"nats_backend"`
+	ClusterID string          `toml:"nats_cluster_id"`
+	URL       string          `toml:"nats_url"`
+	JetStream JetStreamConfig `toml:"jetstream"`
+}
+
+// Validate returns errors of missing or invalid options of connection.
+func (config *NATSConfig) Validate() error {
+	errs := []error{helpers.Required("nats_url", config.URL)}
+	switch config.Backend {
+	case "", BackendSTAN:
+		errs = append(errs, helpers.Required("nats_cluster_id", config.ClusterID))
+	case BackendJetStream:
+		for i, stream := range config.JetStream.Streams {
+			errs = append(errs, helpers.Required(fmt.Sprintf("jetstream.streams[%d].name", i), stream.Name))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("nats_backend %q is unknown, expected %q or %q", config.Backend, BackendSTAN, BackendJetStream))
+	}
+	return errors.Join(errs...)
+}
+
+// Connect returns measured and traced events streaming of backend selected by configuration, NATS Streaming
+// is used by default.
+func Connect(ctx context.Context, config NATSConfig) (event.Streaming, error) {
+	var streaming event.Streaming
+	var err error
+	switch config.Backend {
+	case "", BackendSTAN:
+		streaming, err = NewSTANStreaming(config.ClusterID, stan.NatsURL(config.URL))
+	case BackendJetStream:
+		streaming, err = NewJetStreamStreaming(ctx, config.URL, config.JetStream)
+	default:
+		return nil, fmt.Errorf("unknown NATS backend %q", config.Backend)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return NewMeasuredStreaming(NewTracedStreaming(streaming)), nil
+}
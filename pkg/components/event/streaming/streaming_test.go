@@ -0,0 +1,48 @@
+package streaming
+
+import (
+	"testing"
+
+	"github.com/migotom/cell-centre-services/pkg/helpers"
+)
+
+func TestNATSConfigValidate(t *testing.T) {
+	cases := []struct {
+		Name        string
+		Config      NATSConfig
+		ExpectedErr string
+	}{
+		{
+			Name:   "NATS Streaming by default",
+			Config: NATSConfig{ClusterID: "cell-centre-nats", URL: "nats://nats:4222"},
+		},
+		{
+			Name:        "NATS Streaming without cluster",
+			Config:      NATSConfig{Backend: BackendSTAN, URL: "nats://nats:4222"},
+			ExpectedErr: "nats_cluster_id is required",
+		},
+		{
+			Name: "JetStream",
+			Config: NATSConfig{Backend: BackendJetStream, URL: "nats://nats:4222", JetStream: JetStreamConfig{
+				Streams: []JetStreamStreamConfig{{Name: "EVENTS"}},
+			}},
+		},
+		{
+			Name: "JetStream stream without name",
+			Config: NATSConfig{Backend: BackendJetStream, URL: "nats://nats:4222", JetStream: JetStreamConfig{
+				Streams: []JetStreamStreamConfig{{Name: "EVENTS"}, {Subjects: []string{"roles"}}},
+			}},
+			ExpectedErr: "jetstream.streams[1].name is required",
+		},
+		{
+			Name:        "Unknown backend without URL",
+			Config:      NATSConfig{Backend: "kafka"},
+			ExpectedErr: "nats_url is required\nnats_backend \"kafka\" is unknown, expected \"stan\" or \"jetstream\"",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			helpers.AssertErrors(t, tc.ExpectedErr, tc.Config.Validate())
+		})
+	}
+}
@@ -0,0 +1,24 @@
+package streaming
+
+import (
+	"github.com/migotom/cell-centre-services/pkg/components/event"
+	"github.com/migotom/cell-centre-services/pkg/helpers/tracing"
+	"github.com/migotom/cell-centre-services/pkg/pb"
+)
+
+type tracedStreaming struct {
+	event.Streaming
+}
+
+// NewTracedStreaming returns events streaming that traces publishing of events, continuing traces carried
+// by events. Consumed events are traced by subscribers once they decode them.
+func NewTracedStreaming(streaming event.Streaming) event.Streaming {
+	return &tracedStreaming{Streaming: streaming}
+}
+
+func (streaming *tracedStreaming) Publish(e *pb.Event) error {
+	span := tracing.StartPublish(e.Channel, e)
+	err := streaming.Streaming.Publish(e)
+	tracing.End(span, err)
+	return err
+}
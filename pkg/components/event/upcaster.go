@@ -0,0 +1,77 @@
+package event
+
+import (
+	"fmt"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+
+	"github.com/migotom/cell-centre-services/pkg/pb"
+)
+
+// Upcaster migrates event of schema version it's registered for into shape of the next version.
+type Upcaster func(e *pb.Event) error
+
+// RegisterUpcaster registers upcaster of events of given schema version, upcasters of the same version
+// are applied in order of registration.
+func (registry *Registry) RegisterUpcaster(version uint32, upcaster Upcaster) error {
+	if version == 0 || version >= SchemaVersion {
+		return fmt.Errorf("invalid schema version %d of upcaster, current version is %d", version, SchemaVersion)
+	}
+
+	registry.Lock()
+	defer registry.Unlock()
+
+	registry.upcasters[version] = append(registry.upcasters[version], upcaster)
+	return nil
+}
+
+// Upcast migrates event of older schema version to current version.
+func (registry *Registry) Upcast(e *pb.Event) error {
+	version := e.GetSchemaVersion()
+	if version == 0 {
+		// events published before schema versioning was introduced
+		version = 1
+	}
+	if version > SchemaVersion {
+		return fmt.Errorf("unsupported schema version %d of event %s", version, e.GetEventId())
+	}
+
+	registry.RLock()
+	defer registry.RUnlock()
+
+	for ; version < SchemaVersion; version++ {
+		for _, upcaster := range registry.upcasters[version] {
+			if err := upcaster(e); err != nil {
+				return fmt.Errorf("can't upcast event %s from schema version %d: %v", e.GetEventId(), version, err)
+			}
+		}
+		e.SchemaVersion = version + 1
+	}
+	return nil
+}
+
+// upcastDataToPayload moves data of events of schema version 1 into payload.
+func upcastDataToPayload(e *pb.Event) error {
+	var message proto.Message
+	switch data := e.Data.(type) {
+	case *pb.Event_Employee:
+		message = data.Employee
+	case *pb.Event_UpdateRequest:
+		message = data.UpdateRequest
+	case *pb.Event_EmployeeFilter:
+		message = data.EmployeeFilter
+	case *pb.Event_EmployeeChanges:
+		message = data.EmployeeChanges
+	default:
+		return nil
+	}
+
+	payload, err := ptypes.MarshalAny(message)
+	if err != nil {
+		return err
+	}
+	e.Payload = payload
+	e.Data = nil
+	return nil
+}
@@ -0,0 +1,83 @@
+package event
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/migotom/cell-centre-services/pkg/helpers"
+	"github.com/migotom/cell-centre-services/pkg/pb"
+)
+
+func TestUpcast(t *testing.T) {
+	renameType := func(e *pb.Event) error {
+		if e.Type == "EmployeeCreated" {
+			e.Type = "NewEmployee"
+		}
+		return nil
+	}
+
+	cases := []struct {
+		Name            string
+		Event           *pb.Event
+		Upcasters       []Upcaster
+		ExpectedType    string
+		ExpectedVersion uint32
+		ExpectedErr     string
+	}{
+		{
+			Name:            "Event of schema version 1",
+			Event:           &pb.Event{EventId: "1", Type: "EmployeeCreated", Data: &pb.Event_Employee{Employee: &pb.Employee{}}},
+			Upcasters:       []Upcaster{renameType},
+			ExpectedType:    "NewEmployee",
+			ExpectedVersion: SchemaVersion,
+		},
+		{
+			Name:            "Event of current schema version",
+			Event:           &pb.Event{EventId: "1", Type: "EmployeeCreated", SchemaVersion: SchemaVersion},
+			Upcasters:       []Upcaster{renameType},
+			ExpectedType:    "EmployeeCreated",
+			ExpectedVersion: SchemaVersion,
+		},
+		{
+			Name:        "Event of unsupported schema version",
+			Event:       &pb.Event{EventId: "1", SchemaVersion: SchemaVersion + 1},
+			ExpectedErr: "unsupported schema version 3 of event 1",
+		},
+		{
+			Name:  "Failed upcaster",
+			Event: &pb.Event{EventId: "1"},
+			Upcasters: []Upcaster{func(e *pb.Event) error {
+				return errors.New("invalid payload")
+			}},
+			ExpectedErr: "can't upcast event 1 from schema version 1: invalid payload",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			registry := NewRegistry()
+			for _, upcaster := range tc.Upcasters {
+				if err := registry.RegisterUpcaster(1, upcaster); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			err := registry.Upcast(tc.Event)
+			helpers.AssertErrors(t, tc.ExpectedErr, err)
+			if err != nil {
+				return
+			}
+			assert.Equal(t, tc.ExpectedType, tc.Event.Type)
+			assert.Equal(t, tc.ExpectedVersion, tc.Event.SchemaVersion)
+			assert.Nil(t, tc.Event.Data)
+		})
+	}
+}
+
+func TestRegisterUpcaster(t *testing.T) {
+	registry := NewRegistry()
+	helpers.AssertErrors(t, "invalid schema version 0 of upcaster, current version is 2", registry.RegisterUpcaster(0, nil))
+	helpers.AssertErrors(t, "invalid schema version 2 of upcaster, current version is 2", registry.RegisterUpcaster(SchemaVersion, nil))
+	helpers.AssertErrors(t, "", registry.RegisterUpcaster(1, func(*pb.Event) error { return nil }))
+}
@@ -0,0 +1,96 @@
+package grpc
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	grpcHealth "google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+
+	"github.com/migotom/cell-centre-services/pkg/components/health"
+)
+
+// HealthDelivery is gRPC handler delivery of grpc.health.v1 service. Status of every dependency is reported under
+// its name, empty service name reports whether all dependencies are available.
+type HealthDelivery struct {
+	*grpcHealth.Server
+
+	log          *zap.Logger
+	interval     time.Duration
+	dependencies []health.Dependency
+
+	sync.Mutex
+	failing map[string]bool
+}
+
+// NewHealthDelivery returns new health gRPC delivery checking given dependencies every interval, services are
+// reported as not serving until dependencies are checked.
+func NewHealthDelivery(log *zap.Logger, interval time.Duration, dependencies ...health.Dependency) *HealthDelivery {
+	delivery := HealthDelivery{
+		Server:       grpcHealth.NewServer(),
+		log:          log,
+		interval:     interval,
+		dependencies: dependencies,
+		failing:      make(map[string]bool),
+	}
+
+	delivery.SetServingStatus("", healthpb.HealthCheckResponse_NOT_SERVING)
+	for _, dependency := range dependencies {
+		delivery.SetServingStatus(dependency.Name, healthpb.HealthCheckResponse_NOT_SERVING)
+	}
+	return &delivery
+}
+
+// AuthFuncOverride is authorization accessor for HealthDelivery gRPC, health is checked without authentication.
+func (delivery *HealthDelivery) AuthFuncOverride(ctx context.Context, fullMethodName string) (context.Context, error) {
+	return ctx, nil
+}
+
+// Run checks dependencies every interval until given context is done, afterwards all services are reported
+// as not serving.
+func (delivery *HealthDelivery) Run(ctx context.Context) {
+	ticker := time.NewTicker(delivery.interval)
+	defer ticker.Stop()
+
+	for {
+		delivery.check(ctx)
+
+		select {
+		case <-ctx.Done():
+			delivery.Shutdown()
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// check updates status of dependencies, changes of their availability are logged.
+func (delivery *HealthDelivery) check(ctx context.Context) {
+	delivery.Lock()
+	defer delivery.Unlock()
+
+	overall := healthpb.HealthCheckResponse_SERVING
+	for _, dependency := range delivery.dependencies {
+		checkCtx, cancel := context.WithTimeout(ctx, delivery.interval)
+		err := dependency.Check(checkCtx)
+		cancel()
+
+		status := healthpb.HealthCheckResponse_SERVING
+		if err != nil {
+			status = healthpb.HealthCheckResponse_NOT_SERVING
+			overall = healthpb.HealthCheckResponse_NOT_SERVING
+		}
+		delivery.SetServingStatus(dependency.Name, status)
+
+		switch {
+		case err != nil && !delivery.failing[dependency.Name]:
+			delivery.log.Error("Dependency unavailable", zap.String("dependency", dependency.Name), zap.Error(err))
+		case err == nil && delivery.failing[dependency.Name]:
+			delivery.log.Info("Dependency available", zap.String("dependency", dependency.Name))
+		}
+		delivery.failing[dependency.Name] = err != nil
+	}
+	delivery.SetServingStatus("", overall)
+}
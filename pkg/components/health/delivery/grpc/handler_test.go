@@ -0,0 +1,97 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+
+	"github.com/migotom/cell-centre-services/pkg/components/health"
+	"github.com/migotom/cell-centre-services/pkg/helpers"
+)
+
+func dependency(name string, err error) health.Dependency {
+	return health.Dependency{
+		Name:  name,
+		Check: func(context.Context) error { return err },
+	}
+}
+
+func TestHealthCheck(t *testing.T) {
+	cases := []struct {
+		Name           string
+		Dependencies   []health.Dependency
+		Service        string
+		ExpectedStatus healthpb.HealthCheckResponse_ServingStatus
+		ExpectedErr    string
+	}{
+		{
+			Name:           "All dependencies available",
+			Dependencies:   []health.Dependency{dependency("mongodb", nil), dependency("nats", nil)},
+			ExpectedStatus: healthpb.HealthCheckResponse_SERVING,
+		},
+		{
+			Name:           "Dependency unavailable",
+			Dependencies:   []health.Dependency{dependency("mongodb", nil), dependency("nats", errors.New("connection lost"))},
+			ExpectedStatus: healthpb.HealthCheckResponse_NOT_SERVING,
+		},
+		{
+			Name:           "Status of available dependency",
+			Dependencies:   []health.Dependency{dependency("mongodb", nil), dependency("nats", errors.New("connection lost"))},
+			Service:        "mongodb",
+			ExpectedStatus: healthpb.HealthCheckResponse_SERVING,
+		},
+		{
+			Name:           "Status of unavailable dependency",
+			Dependencies:   []health.Dependency{dependency("mongodb", nil), dependency("nats", errors.New("connection lost"))},
+			Service:        "nats",
+			ExpectedStatus: healthpb.HealthCheckResponse_NOT_SERVING,
+		},
+		{
+			Name:         "Unknown dependency",
+			Dependencies: []health.Dependency{dependency("mongodb", nil)},
+			Service:      "redis",
+			ExpectedErr:  "rpc error: code = NotFound desc = unknown service",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			delivery := NewHealthDelivery(zap.NewNop(), time.Second, tc.Dependencies...)
+			delivery.check(context.Background())
+
+			response, err := delivery.Check(context.Background(), &healthpb.HealthCheckRequest{Service: tc.Service})
+			helpers.AssertErrors(t, tc.ExpectedErr, err)
+			assert.Equal(t, tc.ExpectedStatus, response.GetStatus())
+		})
+	}
+}
+
+func TestHealthRun(t *testing.T) {
+	delivery := NewHealthDelivery(zap.NewNop(), time.Hour, dependency("mongodb", nil))
+
+	response, err := delivery.Check(context.Background(), &healthpb.HealthCheckRequest{})
+	assert.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, response.GetStatus(), "serving before dependencies are checked")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+	go func() {
+		delivery.Run(ctx)
+		close(stopped)
+	}()
+
+	assert.Eventually(t, func() bool {
+		response, err := delivery.Check(context.Background(), &healthpb.HealthCheckRequest{})
+		return err == nil && response.GetStatus() == healthpb.HealthCheckResponse_SERVING
+	}, 5*time.Second, 10*time.Millisecond)
+
+	cancel()
+	<-stopped
+	response, err = delivery.Check(context.Background(), &healthpb.HealthCheckRequest{})
+	assert.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, response.GetStatus(), "serving after shutdown")
+}
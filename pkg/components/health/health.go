@@ -0,0 +1,43 @@
+package health
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+
+	"github.com/migotom/cell-centre-services/pkg/components/event"
+)
+
+// DefaultCheckInterval is time between checks of dependencies.
+const DefaultCheckInterval = 10 * time.Second
+
+// Check reports error when dependency isn't available.
+type Check func(ctx context.Context) error
+
+// Dependency of service with check of its availability.
+type Dependency struct {
+	Name  string
+	Check Check
+}
+
+// MongoDB returns dependency on MongoDB checked by ping of primary.
+func MongoDB(client *mongo.Client) Dependency {
+	return Dependency{
+		Name: "mongodb",
+		Check: func(ctx context.Context) error {
+			return client.Ping(ctx, readpref.Primary())
+		},
+	}
+}
+
+// Streaming returns dependency on message bus checked by state of its connection.
+func Streaming(streaming event.Streaming) Dependency {
+	return Dependency{
+		Name: "nats",
+		Check: func(context.Context) error {
+			return streaming.Ping()
+		},
+	}
+}
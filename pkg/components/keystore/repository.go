@@ -0,0 +1,27 @@
+// Package keystore keeps data keys encrypting personal data of subjects, personal data of subject is erased by
+// destroying its key (crypto-shredding).
+package keystore
+
+import (
+	"context"
+	"errors"
+
+	"github.com/migotom/cell-centre-services/pkg/entities"
+)
+
+var (
+	// ErrKeyNotFound is returned by repository when subject has no data key.
+	ErrKeyNotFound = errors.New("data key not found")
+	// ErrKeyExists is returned by repository when data key of subject is created again.
+	ErrKeyExists = errors.New("data key already exists")
+	// ErrKeyDestroyed is returned when personal data of erased subject is encrypted.
+	ErrKeyDestroyed = errors.New("data key destroyed")
+)
+
+// Repository of data keys.
+type Repository interface {
+	Get(ctx context.Context, subjectID string) (*entities.DataKey, error)
+	New(ctx context.Context, key *entities.DataKey) error
+	// Destroy removes key material of subject and keeps its tombstone, subject without key gets tombstone too.
+	Destroy(ctx context.Context, subjectID string) error
+}
@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/migotom/cell-centre-services/pkg/components/keystore"
+	"github.com/migotom/cell-centre-services/pkg/entities"
+)
+
+const (
+	collectionName = "data_keys"
+
+	duplicateKeyErrorCode = 11000
+)
+
+type mongoKeyRepo struct {
+	DB *mongo.Database
+}
+
+// NewMongoKeyRepository returns new data key MongoDB repository, keys are stored by ID of their subjects.
+func NewMongoKeyRepository(db *mongo.Database) keystore.Repository {
+	return &mongoKeyRepo{
+		DB: db,
+	}
+}
+
+// Get returns data key of subject, keystore.ErrKeyNotFound is returned when subject has no key.
+func (repository *mongoKeyRepo) Get(ctx context.Context, subjectID string) (*entities.DataKey, error) {
+	collection := repository.DB.Collection(collectionName)
+
+	var key entities.DataKey
+	if err := collection.FindOne(ctx, bson.M{"_id": subjectID}).Decode(&key); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, keystore.ErrKeyNotFound
+		}
+		return nil, err
+	}
+	return &key, nil
+}
+
+// New stores data key, keystore.ErrKeyExists is returned when subject already has key or its tombstone.
+func (repository *mongoKeyRepo) New(ctx context.Context, key *entities.DataKey) error {
+	collection := repository.DB.Collection(collectionName)
+
+	_, err := collection.InsertOne(ctx, key)
+	if writeException, ok := err.(mongo.WriteException); ok {
+		for _, writeError := range writeException.WriteErrors {
+			if writeError.Code == duplicateKeyErrorCode {
+				return keystore.ErrKeyExists
+			}
+		}
+	}
+	return err
+}
+
+// Destroy removes key material of subject and keeps its tombstone.
+func (repository *mongoKeyRepo) Destroy(ctx context.Context, subjectID string) error {
+	collection := repository.DB.Collection(collectionName)
+
+	now := time.Now().UTC()
+	_, err := collection.UpdateOne(ctx,
+		bson.M{"_id": subjectID},
+		bson.M{
+			"$unset":       bson.M{"key": ""},
+			"$set":         bson.M{"destroyed_at": now},
+			"$setOnInsert": bson.M{"created_at": now},
+		},
+		options.Update().SetUpsert(true),
+	)
+	return err
+}
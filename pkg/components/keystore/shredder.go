@@ -0,0 +1,147 @@
+package keystore
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/migotom/cell-centre-services/pkg/entities"
+)
+
+const (
+	// Erased replaces personal data encrypted with destroyed key.
+	Erased = "[erased]"
+
+	encryptedPrefix = "pii:"
+	keySize         = 32
+)
+
+// Encrypted reports whether value is personal data encrypted by shredder.
+func Encrypted(value string) bool {
+	return strings.HasPrefix(value, encryptedPrefix)
+}
+
+// Shredder encrypts personal data of subjects with their data keys, data of subject can't be read once its key
+// is destroyed.
+type Shredder struct {
+	repository Repository
+}
+
+// NewShredder returns new shredder of personal data keeping data keys in given repository.
+func NewShredder(repository Repository) *Shredder {
+	return &Shredder{
+		repository: repository,
+	}
+}
+
+// Subject returns cipher of personal data of subject. Data key of subject is created when it's missing and
+// create is set, keys of erased subjects aren't created again.
+func (shredder *Shredder) Subject(ctx context.Context, subjectID string, create bool) (*SubjectCipher, error) {
+	key, err := shredder.repository.Get(ctx, subjectID)
+	if err == ErrKeyNotFound && create {
+		key, err = shredder.newKey(ctx, subjectID)
+	}
+	switch {
+	case err == ErrKeyNotFound:
+		return &SubjectCipher{subjectID: subjectID}, nil
+	case err != nil:
+		return nil, fmt.Errorf("can't read data key: %v", err)
+	case key.Destroyed():
+		if create {
+			return nil, ErrKeyDestroyed
+		}
+		return &SubjectCipher{subjectID: subjectID}, nil
+	}
+
+	block, err := aes.NewCipher(key.Key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid data key: %v", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("invalid data key: %v", err)
+	}
+	return &SubjectCipher{subjectID: subjectID, aead: aead}, nil
+}
+
+// newKey creates data key of subject, key created concurrently by other writer is used instead.
+func (shredder *Shredder) newKey(ctx context.Context, subjectID string) (*entities.DataKey, error) {
+	key := &entities.DataKey{
+		SubjectID: subjectID,
+		Key:       make([]byte, keySize),
+		CreatedAt: time.Now().UTC(),
+	}
+	if _, err := io.ReadFull(rand.Reader, key.Key); err != nil {
+		return nil, err
+	}
+
+	switch err := shredder.repository.New(ctx, key); err {
+	case nil:
+		return key, nil
+	case ErrKeyExists:
+		return shredder.repository.Get(ctx, subjectID)
+	default:
+		return nil, err
+	}
+}
+
+// Erase destroys data key of subject, personal data encrypted with it is erased.
+func (shredder *Shredder) Erase(ctx context.Context, subjectID string) error {
+	if err := shredder.repository.Destroy(ctx, subjectID); err != nil {
+		return fmt.Errorf("can't destroy data key: %v", err)
+	}
+	return nil
+}
+
+// SubjectCipher encrypts and decrypts personal data of single subject, cipher of subject without usable key
+// reads encrypted data as erased.
+type SubjectCipher struct {
+	subjectID string
+	aead      cipher.AEAD
+}
+
+// Encrypt returns encrypted value, empty and already encrypted values aren't changed.
+func (subject *SubjectCipher) Encrypt(value string) (string, error) {
+	if value == "" || Encrypted(value) {
+		return value, nil
+	}
+	if subject.aead == nil {
+		return "", ErrKeyDestroyed
+	}
+
+	nonce := make([]byte, subject.aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", err
+	}
+	sealed := subject.aead.Seal(nonce, nonce, []byte(value), []byte(subject.subjectID))
+	return encryptedPrefix + base64.RawStdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt returns decrypted value, values which aren't encrypted are returned as they are and values encrypted
+// with destroyed key as Erased.
+func (subject *SubjectCipher) Decrypt(value string) (string, error) {
+	if !Encrypted(value) {
+		return value, nil
+	}
+	if subject.aead == nil {
+		return Erased, nil
+	}
+
+	sealed, err := base64.RawStdEncoding.DecodeString(strings.TrimPrefix(value, encryptedPrefix))
+	if err != nil || len(sealed) < subject.aead.NonceSize() {
+		return "", errors.New("invalid encrypted value")
+	}
+	nonce, ciphertext := sealed[:subject.aead.NonceSize()], sealed[subject.aead.NonceSize():]
+	plaintext, err := subject.aead.Open(nil, nonce, ciphertext, []byte(subject.subjectID))
+	if err != nil {
+		return "", fmt.Errorf("can't decrypt value: %v", err)
+	}
+	return string(plaintext), nil
+}
@@ -0,0 +1,124 @@
+package keystore
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/migotom/cell-centre-services/pkg/entities"
+	"github.com/migotom/cell-centre-services/pkg/helpers"
+	"github.com/migotom/cell-centre-services/pkg/helpers/mocks"
+)
+
+func TestShredderSubject(t *testing.T) {
+	key := &entities.DataKey{SubjectID: "e1", Key: make([]byte, 32)}
+	destroyedAt := time.Date(2019, 7, 24, 20, 26, 40, 0, time.UTC)
+	destroyed := &entities.DataKey{SubjectID: "e1", DestroyedAt: &destroyedAt}
+
+	cases := []struct {
+		Name              string
+		Create            bool
+		ExpectedMockCalls func(*mocks.KeyRepositoryMock)
+		ExpectedDecrypted string
+		ExpectedErr       string
+	}{
+		{
+			Name: "Existing key",
+			ExpectedMockCalls: func(r *mocks.KeyRepositoryMock) {
+				r.On("Get", mock.Anything, "e1").Return(key, nil)
+			},
+			ExpectedDecrypted: "john@page.com",
+		},
+		{
+			Name:   "Created key",
+			Create: true,
+			ExpectedMockCalls: func(r *mocks.KeyRepositoryMock) {
+				r.On("Get", mock.Anything, "e1").Return((*entities.DataKey)(nil), ErrKeyNotFound)
+				r.On("New", mock.Anything, mock.AnythingOfType("*entities.DataKey")).Return(nil)
+			},
+			ExpectedDecrypted: "john@page.com",
+		},
+		{
+			Name:   "Key created concurrently",
+			Create: true,
+			ExpectedMockCalls: func(r *mocks.KeyRepositoryMock) {
+				r.On("Get", mock.Anything, "e1").Return((*entities.DataKey)(nil), ErrKeyNotFound).Once()
+				r.On("New", mock.Anything, mock.AnythingOfType("*entities.DataKey")).Return(ErrKeyExists)
+				r.On("Get", mock.Anything, "e1").Return(key, nil).Once()
+			},
+			ExpectedDecrypted: "john@page.com",
+		},
+		{
+			Name:   "Destroyed key isn't created again",
+			Create: true,
+			ExpectedMockCalls: func(r *mocks.KeyRepositoryMock) {
+				r.On("Get", mock.Anything, "e1").Return(destroyed, nil)
+			},
+			ExpectedErr: "data key destroyed",
+		},
+		{
+			Name:   "Failed read of key",
+			Create: true,
+			ExpectedMockCalls: func(r *mocks.KeyRepositoryMock) {
+				r.On("Get", mock.Anything, "e1").Return((*entities.DataKey)(nil), errors.New("database down"))
+			},
+			ExpectedErr: "can't read data key: database down",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			keyRepositoryMock := mocks.KeyRepositoryMock{}
+			tc.ExpectedMockCalls(&keyRepositoryMock)
+
+			subject, err := NewShredder(&keyRepositoryMock).Subject(context.Background(), "e1", tc.Create)
+			helpers.AssertErrors(t, tc.ExpectedErr, err)
+			keyRepositoryMock.AssertExpectations(t)
+			if err != nil {
+				return
+			}
+
+			encrypted, err := subject.Encrypt("john@page.com")
+			assert.NoError(t, err)
+			assert.True(t, Encrypted(encrypted))
+			assert.NotContains(t, encrypted, "john")
+
+			decrypted, err := subject.Decrypt(encrypted)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.ExpectedDecrypted, decrypted)
+		})
+	}
+}
+
+func TestShredderErase(t *testing.T) {
+	key := &entities.DataKey{SubjectID: "e1", Key: make([]byte, 32)}
+	keyRepositoryMock := mocks.KeyRepositoryMock{}
+	keyRepositoryMock.On("Get", mock.Anything, "e1").Return(key, nil).Once()
+	keyRepositoryMock.On("Destroy", mock.Anything, "e1").Return(nil)
+	keyRepositoryMock.On("Get", mock.Anything, "e1").Return((*entities.DataKey)(nil), ErrKeyNotFound)
+
+	shredder := NewShredder(&keyRepositoryMock)
+	subject, err := shredder.Subject(context.Background(), "e1", false)
+	assert.NoError(t, err)
+	encrypted, err := subject.Encrypt("John Page")
+	assert.NoError(t, err)
+
+	assert.NoError(t, shredder.Erase(context.Background(), "e1"))
+
+	erased, err := shredder.Subject(context.Background(), "e1", false)
+	assert.NoError(t, err)
+	decrypted, err := erased.Decrypt(encrypted)
+	assert.NoError(t, err)
+	assert.Equal(t, Erased, decrypted)
+
+	plain, err := erased.Decrypt("not encrypted")
+	assert.NoError(t, err)
+	assert.Equal(t, "not encrypted", plain)
+
+	_, err = erased.Encrypt("John Page")
+	assert.Equal(t, ErrKeyDestroyed, err)
+	keyRepositoryMock.AssertExpectations(t)
+}
@@ -0,0 +1,76 @@
+package role
+
+import (
+	"github.com/golang/protobuf/proto"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/migotom/cell-centre-services/pkg/components/event"
+	"github.com/migotom/cell-centre-services/pkg/entities"
+	"github.com/migotom/cell-centre-services/pkg/pb"
+)
+
+const (
+	// EventChannel is channel of role events.
+	EventChannel = "roles"
+	// AggregateType of role events.
+	AggregateType = "roles"
+)
+
+// RegisterEvents registers role event types and decoders of their payloads.
+func RegisterEvents(registry *event.Registry) error {
+	definitions := []event.Definition{
+		{Type: entities.NewRoleEvent, Channel: EventChannel, AggregateType: AggregateType, Payload: &pb.Role{}, Encode: encodeRole},
+		{Type: entities.UpdateRoleEvent, Channel: EventChannel, AggregateType: AggregateType, Payload: &pb.Role{}, Encode: encodeRole},
+		{Type: entities.DeleteRoleEvent, Channel: EventChannel, AggregateType: AggregateType, Payload: &pb.RoleFilter{}, Encode: encodeRoleFilter},
+	}
+	for _, definition := range definitions {
+		if err := registry.RegisterEvent(definition); err != nil {
+			return err
+		}
+	}
+
+	if err := registry.RegisterPayload(&pb.Role{}, func(m proto.Message) (interface{}, error) {
+		role := m.(*pb.Role)
+		return newRole(role.GetId(), role.GetName())
+	}); err != nil {
+		return err
+	}
+	return registry.RegisterPayload(&pb.RoleFilter{}, func(m proto.Message) (interface{}, error) {
+		filter := m.(*pb.RoleFilter)
+		return newRole(filter.GetId(), filter.GetName())
+	})
+}
+
+func encodeRole(data interface{}) (proto.Message, error) {
+	var role entities.Role
+	if err := event.DecodeData(data, &role); err != nil {
+		return nil, err
+	}
+	return &pb.Role{Id: roleID(role), Name: role.Name}, nil
+}
+
+func encodeRoleFilter(data interface{}) (proto.Message, error) {
+	var role entities.Role
+	if err := event.DecodeData(data, &role); err != nil {
+		return nil, err
+	}
+	return &pb.RoleFilter{Id: roleID(role), Name: role.Name}, nil
+}
+
+func roleID(role entities.Role) string {
+	if role.ID.IsZero() {
+		return ""
+	}
+	return role.ID.Hex()
+}
+
+func newRole(id, name string) (*entities.Role, error) {
+	role := entities.Role{Name: name}
+	if id != "" {
+		var err error
+		if role.ID, err = primitive.ObjectIDFromHex(id); err != nil {
+			return nil, err
+		}
+	}
+	return &role, nil
+}
@@ -0,0 +1,39 @@
+package grpc
+
+import "fmt"
+
+type WebhookDeliveryErrorReason int
+
+const (
+	ErrInvalidWebhookData = iota
+	ErrWebhookNotFound
+	ErrInternal
+)
+
+type WebhookDeliveryError struct {
+	Reason WebhookDeliveryErrorReason
+	Err    error
+}
+
+func (err WebhookDeliveryError) Error() string {
+	if err.Err != nil {
+		return fmt.Sprintf("%s (%v)", err.description(), err.Err)
+	}
+	return err.description()
+}
+
+func (err WebhookDeliveryError) description() string {
+	switch err.Reason {
+	case ErrInvalidWebhookData:
+		return "Invalid webhook data"
+
+	case ErrWebhookNotFound:
+		return "Webhook not found"
+
+	case ErrInternal:
+		return "Internal error"
+
+	default:
+		return "Unknown error"
+	}
+}
@@ -0,0 +1,140 @@
+package grpc
+
+import (
+	"context"
+
+	empty "github.com/golang/protobuf/ptypes/empty"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	authDelivery "github.com/migotom/cell-centre-services/pkg/components/auth/delivery/grpc"
+	"github.com/migotom/cell-centre-services/pkg/components/webhook"
+	webhookFactory "github.com/migotom/cell-centre-services/pkg/components/webhook/factory"
+	"github.com/migotom/cell-centre-services/pkg/helpers/correlation"
+	"github.com/migotom/cell-centre-services/pkg/pb"
+	pbFactory "github.com/migotom/cell-centre-services/pkg/pb/factory"
+)
+
+// WebhookDelivery is gRPC handler delivery of webhook subscriptions.
+type WebhookDelivery struct {
+	log               *zap.Logger
+	webhookFactory    *webhookFactory.WebhookEntityFactory
+	webhookPbFactory  *pbFactory.WebhookPbFactory
+	repository        webhook.Repository
+	attemptRepository webhook.AttemptRepository
+}
+
+// NewWebhookDelivery returns new Webhook gRPC delivery.
+func NewWebhookDelivery(log *zap.Logger, webhookRepository webhook.Repository, attemptRepository webhook.AttemptRepository) *WebhookDelivery {
+	return &WebhookDelivery{
+		log:               log,
+		webhookFactory:    webhookFactory.NewWebhookEntityFactory(),
+		webhookPbFactory:  pbFactory.NewWebhookPbFactory(),
+		repository:        webhookRepository,
+		attemptRepository: attemptRepository,
+	}
+}
+
+// AuthFuncOverride is authorization accessor for WebhookDelivery gRPC, webhooks are managed by admins only.
+func (delivery *WebhookDelivery) AuthFuncOverride(ctx context.Context, fullMethodName string) (context.Context, error) {
+	claims, err := authDelivery.ObtainClaimsFromMetadata(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Unauthenticated, "Request unauthenticated with error: %v", err)
+	}
+
+	if err := authDelivery.Authorize(claims, []string{"admin"}); err != nil {
+		return ctx, status.Errorf(codes.Unauthenticated, "Request unauthenticated with error: %v", err)
+	}
+
+	return context.WithValue(ctx, authDelivery.ContextKeyClaims, claims), nil
+}
+
+// GetWebhook gRPC handler gets webhook by given filter.
+func (delivery *WebhookDelivery) GetWebhook(ctx context.Context, filter *pb.WebhookFilter) (*pb.Webhook, error) {
+	webhook, err := delivery.repository.Get(ctx, filter)
+	if err != nil {
+		return nil, status.Errorf(codes.NotFound, "Can't get webhook: %v", WebhookDeliveryError{Reason: ErrWebhookNotFound, Err: err})
+	}
+
+	return delivery.webhookPbFactory.NewFromWebhook(webhook)
+}
+
+// ListWebhooks gRPC handler lists all webhooks.
+func (delivery *WebhookDelivery) ListWebhooks(ctx context.Context, _ *empty.Empty) (*pb.WebhookList, error) {
+	webhooks, err := delivery.repository.List(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "Can't list webhooks: %v", WebhookDeliveryError{Reason: ErrInternal, Err: err})
+	}
+
+	var list pb.WebhookList
+	for _, webhook := range webhooks {
+		webhookPb, err := delivery.webhookPbFactory.NewFromWebhook(webhook)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "%v", WebhookDeliveryError{Reason: ErrInternal, Err: err})
+		}
+		list.Webhooks = append(list.Webhooks, webhookPb)
+	}
+	return &list, nil
+}
+
+// NewWebhook gRPC handler registers new webhook, generated secret is returned only by this call.
+func (delivery *WebhookDelivery) NewWebhook(ctx context.Context, request *pb.NewWebhookRequest) (*pb.Webhook, error) {
+	if request == nil {
+		return nil, status.Errorf(codes.InvalidArgument, "Invalid request: %v", WebhookDeliveryError{Reason: ErrInvalidWebhookData})
+	}
+
+	webhookEntity, err := delivery.webhookFactory.NewFromNewWebhookRequest(request)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "Invalid request: %v", WebhookDeliveryError{Reason: ErrInvalidWebhookData, Err: err})
+	}
+
+	webhook, err := delivery.repository.New(ctx, webhookEntity)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "Can't create new webhook: %v", WebhookDeliveryError{Reason: ErrInternal, Err: err})
+	}
+
+	webhookPb, err := delivery.webhookPbFactory.NewFromWebhook(webhook)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "%v", WebhookDeliveryError{Reason: ErrInternal, Err: err})
+	}
+	webhookPb.Secret = webhook.Secret
+
+	correlation.Logger(ctx, delivery.log).Info("Webhook registered",
+		zap.String("id", webhookPb.Id),
+		zap.String("url", webhookPb.Url),
+		zap.String("by", authDelivery.ObtainClaimsFromContext(ctx).Login),
+	)
+	return webhookPb, nil
+}
+
+// DeleteWebhook gRPC handler deletes webhook by given filter.
+func (delivery *WebhookDelivery) DeleteWebhook(ctx context.Context, filter *pb.WebhookFilter) (*empty.Empty, error) {
+	if err := delivery.repository.Delete(ctx, filter); err != nil {
+		return nil, status.Errorf(codes.NotFound, "Can't delete webhook: %v", WebhookDeliveryError{Reason: ErrWebhookNotFound, Err: err})
+	}
+
+	correlation.Logger(ctx, delivery.log).Info("Webhook deleted",
+		zap.String("id", filter.GetId()),
+		zap.String("by", authDelivery.ObtainClaimsFromContext(ctx).Login),
+	)
+	return &empty.Empty{}, nil
+}
+
+// ListWebhookAttempts gRPC handler lists the latest delivery attempts of webhook.
+func (delivery *WebhookDelivery) ListWebhookAttempts(ctx context.Context, request *pb.WebhookAttemptsRequest) (*pb.WebhookAttemptList, error) {
+	attempts, err := delivery.attemptRepository.List(ctx, request)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "Can't list webhook attempts: %v", WebhookDeliveryError{Reason: ErrInvalidWebhookData, Err: err})
+	}
+
+	var list pb.WebhookAttemptList
+	for _, attempt := range attempts {
+		attemptPb, err := delivery.webhookPbFactory.NewFromWebhookAttempt(attempt)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "%v", WebhookDeliveryError{Reason: ErrInternal, Err: err})
+		}
+		list.Attempts = append(list.Attempts, attemptPb)
+	}
+	return &list, nil
+}
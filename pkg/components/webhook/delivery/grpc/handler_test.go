@@ -0,0 +1,126 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/ptypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/zap"
+
+	"github.com/migotom/cell-centre-services/pkg/entities"
+	"github.com/migotom/cell-centre-services/pkg/helpers"
+	"github.com/migotom/cell-centre-services/pkg/helpers/mocks"
+	"github.com/migotom/cell-centre-services/pkg/pb"
+)
+
+func TestNewWebhook(t *testing.T) {
+	id, _ := primitive.ObjectIDFromHex("5d3783ee28ae9468bc528906")
+	createdAt := time.Date(2019, 7, 23, 12, 0, 0, 0, time.UTC)
+	createdAtPb, _ := ptypes.TimestampProto(createdAt)
+
+	cases := []struct {
+		Name              string
+		Request           *pb.NewWebhookRequest
+		ExpectedMockCalls func(*mocks.WebhookRepositoryMock)
+		ExpectedWebhook   *pb.Webhook
+		ExpectedErr       string
+	}{
+		{
+			Name:    "Valid request",
+			Request: &pb.NewWebhookRequest{Url: "https://hr.page.com/hooks", Channels: []string{"employees"}, Secret: "secret"},
+			ExpectedMockCalls: func(r *mocks.WebhookRepositoryMock) {
+				r.On("New", mock.Anything, &entities.Webhook{URL: "https://hr.page.com/hooks", Channels: []string{"employees"}, Secret: "secret", Format: "json"}).
+					Return(&entities.Webhook{ID: id, URL: "https://hr.page.com/hooks", Channels: []string{"employees"}, Secret: "secret", Format: "json", CreatedAt: &createdAt}, nil)
+			},
+			ExpectedWebhook: &pb.Webhook{
+				Id:        "5d3783ee28ae9468bc528906",
+				Url:       "https://hr.page.com/hooks",
+				Channels:  []string{"employees"},
+				Secret:    "secret",
+				CreatedAt: createdAtPb,
+				Format:    "json",
+			},
+		},
+		{
+			Name:    "Valid request with CloudEvents format",
+			Request: &pb.NewWebhookRequest{Url: "https://hr.page.com/hooks", Channels: []string{"employees"}, Secret: "secret", Format: "cloudevents"},
+			ExpectedMockCalls: func(r *mocks.WebhookRepositoryMock) {
+				r.On("New", mock.Anything, &entities.Webhook{URL: "https://hr.page.com/hooks", Channels: []string{"employees"}, Secret: "secret", Format: "cloudevents"}).
+					Return(&entities.Webhook{ID: id, URL: "https://hr.page.com/hooks", Channels: []string{"employees"}, Secret: "secret", Format: "cloudevents", CreatedAt: &createdAt}, nil)
+			},
+			ExpectedWebhook: &pb.Webhook{
+				Id:        "5d3783ee28ae9468bc528906",
+				Url:       "https://hr.page.com/hooks",
+				Channels:  []string{"employees"},
+				Secret:    "secret",
+				CreatedAt: createdAtPb,
+				Format:    "cloudevents",
+			},
+		},
+		{
+			Name:              "Unknown format",
+			Request:           &pb.NewWebhookRequest{Url: "https://hr.page.com/hooks", Channels: []string{"employees"}, Format: "xml"},
+			ExpectedMockCalls: func(r *mocks.WebhookRepositoryMock) {},
+			ExpectedErr:       `rpc error: code = InvalidArgument desc = Invalid request: Invalid webhook data (unknown format "xml")`,
+		},
+		{
+			Name:              "Invalid URL",
+			Request:           &pb.NewWebhookRequest{Url: "hr.page.com/hooks", Channels: []string{"employees"}},
+			ExpectedMockCalls: func(r *mocks.WebhookRepositoryMock) {},
+			ExpectedErr:       `rpc error: code = InvalidArgument desc = Invalid request: Invalid webhook data (invalid URL "hr.page.com/hooks", absolute http(s) URL expected)`,
+		},
+		{
+			Name:              "Missing channels",
+			Request:           &pb.NewWebhookRequest{Url: "https://hr.page.com/hooks"},
+			ExpectedMockCalls: func(r *mocks.WebhookRepositoryMock) {},
+			ExpectedErr:       "rpc error: code = InvalidArgument desc = Invalid request: Invalid webhook data (missing channels)",
+		},
+		{
+			Name:    "Database failure",
+			Request: &pb.NewWebhookRequest{Url: "https://hr.page.com/hooks", Channels: []string{"employees"}, Secret: "secret"},
+			ExpectedMockCalls: func(r *mocks.WebhookRepositoryMock) {
+				r.On("New", mock.Anything, mock.Anything).Return((*entities.Webhook)(nil), errors.New("database down"))
+			},
+			ExpectedErr: "rpc error: code = Internal desc = Can't create new webhook: Internal error (database down)",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			log, _ := zap.NewProduction()
+			defer log.Sync()
+
+			webhookRepositoryMock := mocks.WebhookRepositoryMock{}
+			tc.ExpectedMockCalls(&webhookRepositoryMock)
+
+			delivery := NewWebhookDelivery(log, &webhookRepositoryMock, &mocks.WebhookAttemptRepositoryMock{})
+			webhook, err := delivery.NewWebhook(context.Background(), tc.Request)
+			helpers.AssertErrors(t, tc.ExpectedErr, err)
+
+			assert.Equal(t, tc.ExpectedWebhook, webhook)
+			webhookRepositoryMock.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGetWebhook(t *testing.T) {
+	id, _ := primitive.ObjectIDFromHex("5d3783ee28ae9468bc528906")
+
+	webhookRepositoryMock := mocks.WebhookRepositoryMock{}
+	webhookRepositoryMock.On("Get", mock.Anything, &pb.WebhookFilter{Id: "5d3783ee28ae9468bc528906"}).
+		Return(&entities.Webhook{ID: id, URL: "https://hr.page.com/hooks", Channels: []string{"employees"}, Secret: "secret"}, nil)
+
+	log, _ := zap.NewProduction()
+	defer log.Sync()
+
+	delivery := NewWebhookDelivery(log, &webhookRepositoryMock, &mocks.WebhookAttemptRepositoryMock{})
+	webhook, err := delivery.GetWebhook(context.Background(), &pb.WebhookFilter{Id: "5d3783ee28ae9468bc528906"})
+	assert.NoError(t, err)
+
+	// secret is never returned once webhook is created
+	assert.Equal(t, &pb.Webhook{Id: "5d3783ee28ae9468bc528906", Url: "https://hr.page.com/hooks", Channels: []string{"employees"}}, webhook)
+}
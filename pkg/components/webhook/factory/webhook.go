@@ -0,0 +1,55 @@
+package factory
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+
+	"github.com/migotom/cell-centre-services/pkg/components/event/encoding"
+	"github.com/migotom/cell-centre-services/pkg/components/webhook"
+	"github.com/migotom/cell-centre-services/pkg/entities"
+	"github.com/migotom/cell-centre-services/pkg/pb"
+)
+
+// WebhookEntityFactory is entities.Webhook factory.
+type WebhookEntityFactory struct {
+}
+
+// NewWebhookEntityFactory creates new factory.
+func NewWebhookEntityFactory() *WebhookEntityFactory {
+	return &WebhookEntityFactory{}
+}
+
+// NewFromNewWebhookRequest creates Webhook entity from NewWebhookRequest message, secret is generated when not given.
+func (factory *WebhookEntityFactory) NewFromNewWebhookRequest(request *pb.NewWebhookRequest) (*entities.Webhook, error) {
+	endpoint, err := url.Parse(request.GetUrl())
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL: %v", err)
+	}
+	if endpoint.Scheme != "http" && endpoint.Scheme != "https" || endpoint.Host == "" {
+		return nil, fmt.Errorf("invalid URL %q, absolute http(s) URL expected", request.GetUrl())
+	}
+	if len(request.GetChannels()) == 0 {
+		return nil, errors.New("missing channels")
+	}
+
+	format, err := encoding.ParseFormat(request.GetFormat())
+	if err != nil {
+		return nil, err
+	}
+
+	secret := request.GetSecret()
+	if secret == "" {
+		if secret, err = webhook.NewSecret(); err != nil {
+			return nil, fmt.Errorf("can't generate secret: %v", err)
+		}
+	}
+
+	return &entities.Webhook{
+		URL:        endpoint.String(),
+		Channels:   request.GetChannels(),
+		EventTypes: request.GetEventTypes(),
+		Secret:     secret,
+		Format:     string(format),
+	}, nil
+}
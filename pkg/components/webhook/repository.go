@@ -0,0 +1,35 @@
+package webhook
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/migotom/cell-centre-services/pkg/entities"
+	"github.com/migotom/cell-centre-services/pkg/pb"
+)
+
+// Repository of webhooks.
+type Repository interface {
+	Get(ctx context.Context, filter *pb.WebhookFilter) (*entities.Webhook, error)
+	List(ctx context.Context) ([]*entities.Webhook, error)
+	New(ctx context.Context, request *entities.Webhook) (*entities.Webhook, error)
+	Delete(ctx context.Context, filter *pb.WebhookFilter) error
+}
+
+// AttemptRepository is delivery log of webhooks.
+type AttemptRepository interface {
+	New(ctx context.Context, attempt *entities.WebhookAttempt) error
+	List(ctx context.Context, request *pb.WebhookAttemptsRequest) ([]*entities.WebhookAttempt, error)
+}
+
+// ParkingRepository keeps failed deliveries until their webhooks are available again.
+type ParkingRepository interface {
+	// Park stores delivery, delivery of event already parked for webhook is stored once.
+	Park(ctx context.Context, delivery *entities.ParkedDelivery) error
+	// List returns deliveries parked for webhook in order they were parked.
+	List(ctx context.Context, webhookID primitive.ObjectID) ([]*entities.ParkedDelivery, error)
+	// Parked reports whether webhook has parked deliveries.
+	Parked(ctx context.Context, webhookID primitive.ObjectID) (bool, error)
+	Remove(ctx context.Context, id primitive.ObjectID) error
+}
@@ -0,0 +1,249 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/migotom/cell-centre-services/pkg/components/webhook"
+	"github.com/migotom/cell-centre-services/pkg/entities"
+	"github.com/migotom/cell-centre-services/pkg/pb"
+)
+
+const (
+	collectionName        = "webhooks"
+	attemptCollectionName = "webhook_attempts"
+	parkingCollectionName = "webhook_parked_deliveries"
+	defaultAttemptsLimit  = 100
+	duplicateKeyErrorCode = 11000
+)
+
+// CreateIndexes creates index of delivery log used to list the latest attempts of webhook, and indexes of
+// parked deliveries listing them in order and keeping single delivery of event per webhook.
+func CreateIndexes(ctx context.Context, db *mongo.Database) error {
+	_, err := db.Collection(attemptCollectionName).Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{
+			{Key: "webhook_id", Value: 1},
+			{Key: "created_at", Value: -1},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Collection(parkingCollectionName).Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "webhook_id", Value: 1},
+				{Key: "parked_at", Value: 1},
+			},
+		},
+		{
+			Keys: bson.D{
+				{Key: "webhook_id", Value: 1},
+				{Key: "event_id", Value: 1},
+			},
+			Options: options.Index().SetUnique(true),
+		},
+	})
+	return err
+}
+
+type webhookRepository struct {
+	DB *mongo.Database
+}
+
+// NewWebhookRepository returns new webhook MongoDB repository.
+func NewWebhookRepository(db *mongo.Database) webhook.Repository {
+	return &webhookRepository{
+		DB: db,
+	}
+}
+
+// Get returns webhook entity by ID.
+func (repository *webhookRepository) Get(ctx context.Context, filter *pb.WebhookFilter) (*entities.Webhook, error) {
+	if filter.GetId() == "" {
+		return nil, errors.New("unknown webhook filter")
+	}
+	id, err := primitive.ObjectIDFromHex(filter.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	var webhook entities.Webhook
+	if err := repository.DB.Collection(collectionName).FindOne(ctx, bson.D{{"_id", id}}).Decode(&webhook); err != nil {
+		return nil, err
+	}
+	return &webhook, nil
+}
+
+// List returns all webhooks.
+func (repository *webhookRepository) List(ctx context.Context) ([]*entities.Webhook, error) {
+	cursor, err := repository.DB.Collection(collectionName).Find(ctx, bson.D{})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var webhooks []*entities.Webhook
+	for cursor.Next(ctx) {
+		var webhook entities.Webhook
+		if err := cursor.Decode(&webhook); err != nil {
+			return nil, err
+		}
+		webhooks = append(webhooks, &webhook)
+	}
+	return webhooks, cursor.Err()
+}
+
+// New stores new webhook.
+func (repository *webhookRepository) New(ctx context.Context, request *entities.Webhook) (*entities.Webhook, error) {
+	now := time.Now()
+	request.ID = primitive.NewObjectID()
+	request.CreatedAt = &now
+
+	if _, err := repository.DB.Collection(collectionName).InsertOne(ctx, request); err != nil {
+		return nil, err
+	}
+	return request, nil
+}
+
+// Delete removes webhook, its delivery log is kept.
+func (repository *webhookRepository) Delete(ctx context.Context, filter *pb.WebhookFilter) error {
+	if filter.GetId() == "" {
+		return errors.New("unknown webhook filter")
+	}
+	id, err := primitive.ObjectIDFromHex(filter.GetId())
+	if err != nil {
+		return err
+	}
+
+	res, err := repository.DB.Collection(collectionName).DeleteOne(ctx, bson.D{{"_id", id}})
+	if err != nil {
+		return err
+	}
+	if res.DeletedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+type attemptRepository struct {
+	DB *mongo.Database
+}
+
+// NewAttemptRepository returns new MongoDB delivery log of webhooks.
+func NewAttemptRepository(db *mongo.Database) webhook.AttemptRepository {
+	return &attemptRepository{
+		DB: db,
+	}
+}
+
+// New stores attempt of webhook delivery.
+func (repository *attemptRepository) New(ctx context.Context, attempt *entities.WebhookAttempt) error {
+	attempt.ID = primitive.NewObjectID()
+
+	_, err := repository.DB.Collection(attemptCollectionName).InsertOne(ctx, attempt)
+	return err
+}
+
+// List returns the latest delivery attempts of webhook.
+func (repository *attemptRepository) List(ctx context.Context, request *pb.WebhookAttemptsRequest) ([]*entities.WebhookAttempt, error) {
+	webhookID, err := primitive.ObjectIDFromHex(request.GetWebhookId())
+	if err != nil {
+		return nil, err
+	}
+
+	limit := request.GetLimit()
+	if limit <= 0 {
+		limit = defaultAttemptsLimit
+	}
+
+	cursor, err := repository.DB.Collection(attemptCollectionName).Find(ctx,
+		bson.D{{"webhook_id", webhookID}},
+		options.Find().SetSort(bson.D{{"created_at", -1}}).SetLimit(limit),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var attempts []*entities.WebhookAttempt
+	for cursor.Next(ctx) {
+		var attempt entities.WebhookAttempt
+		if err := cursor.Decode(&attempt); err != nil {
+			return nil, err
+		}
+		attempts = append(attempts, &attempt)
+	}
+	return attempts, cursor.Err()
+}
+
+type parkingRepository struct {
+	DB *mongo.Database
+}
+
+// NewParkingRepository returns new MongoDB repository of parked webhook deliveries.
+func NewParkingRepository(db *mongo.Database) webhook.ParkingRepository {
+	return &parkingRepository{
+		DB: db,
+	}
+}
+
+// Park stores failed delivery, delivery of event already parked for webhook is kept as it was.
+func (repository *parkingRepository) Park(ctx context.Context, delivery *entities.ParkedDelivery) error {
+	delivery.ID = primitive.NewObjectID()
+	delivery.ParkedAt = time.Now()
+
+	_, err := repository.DB.Collection(parkingCollectionName).InsertOne(ctx, delivery)
+	if writeException, ok := err.(mongo.WriteException); ok {
+		for _, writeError := range writeException.WriteErrors {
+			if writeError.Code == duplicateKeyErrorCode {
+				return nil
+			}
+		}
+	}
+	return err
+}
+
+// List returns deliveries parked for webhook, the oldest first.
+func (repository *parkingRepository) List(ctx context.Context, webhookID primitive.ObjectID) ([]*entities.ParkedDelivery, error) {
+	cursor, err := repository.DB.Collection(parkingCollectionName).Find(ctx,
+		bson.D{{"webhook_id", webhookID}},
+		options.Find().SetSort(bson.D{{"parked_at", 1}, {"_id", 1}}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var deliveries []*entities.ParkedDelivery
+	for cursor.Next(ctx) {
+		var delivery entities.ParkedDelivery
+		if err := cursor.Decode(&delivery); err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, &delivery)
+	}
+	return deliveries, cursor.Err()
+}
+
+// Parked reports whether webhook has parked deliveries.
+func (repository *parkingRepository) Parked(ctx context.Context, webhookID primitive.ObjectID) (bool, error) {
+	count, err := repository.DB.Collection(parkingCollectionName).CountDocuments(ctx,
+		bson.D{{"webhook_id", webhookID}},
+		options.Count().SetLimit(1),
+	)
+	return count > 0, err
+}
+
+// Remove deletes parked delivery.
+func (repository *parkingRepository) Remove(ctx context.Context, id primitive.ObjectID) error {
+	_, err := repository.DB.Collection(parkingCollectionName).DeleteOne(ctx, bson.D{{"_id", id}})
+	return err
+}
@@ -0,0 +1,79 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+const (
+	// SignatureHeader is HTTP header with signature of webhook request, see Sign for signed input.
+	SignatureHeader = "X-Cell-Centre-Signature"
+	// EventIDHeader is HTTP header with ID of delivered event, receivers may use it to drop repeated deliveries.
+	EventIDHeader = "X-Cell-Centre-Event-Id"
+	// EventTypeHeader is HTTP header with type of delivered event.
+	EventTypeHeader = "X-Cell-Centre-Event-Type"
+
+	// attributeHeaderPrefix of CloudEvents attributes carried in headers in binary mode.
+	attributeHeaderPrefix = "ce-"
+
+	signaturePrefix = "sha256="
+	secretLength    = 32
+)
+
+// Sign returns value of signature header, HMAC-SHA256 keyed with webhook secret. Signed input is one
+// "<header>:<value>\n" line per value of every Ce-* header, header names lower cased and sorted, followed by body.
+// Requests without Ce-* headers sign body alone.
+func Sign(secret string, header http.Header, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(signedAttributes(header))
+	mac.Write(body)
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifySignature reports whether signature header matches request signed with webhook secret.
+func VerifySignature(secret string, header http.Header, body []byte, signature string) bool {
+	if !strings.HasPrefix(signature, signaturePrefix) {
+		return false
+	}
+	return hmac.Equal([]byte(Sign(secret, header, body)), []byte(signature))
+}
+
+// signedAttributes returns Ce-* headers in signed form, so attributes of binary mode events can't be altered
+// without invalidating signature.
+func signedAttributes(header http.Header) []byte {
+	var names []string
+	values := make(map[string][]string)
+	for name, value := range header {
+		name = strings.ToLower(name)
+		if !strings.HasPrefix(name, attributeHeaderPrefix) {
+			continue
+		}
+		if _, ok := values[name]; !ok {
+			names = append(names, name)
+		}
+		values[name] = append(values[name], value...)
+	}
+	sort.Strings(names)
+
+	var signed strings.Builder
+	for _, name := range names {
+		for _, value := range values[name] {
+			signed.WriteString(name + ":" + value + "\n")
+		}
+	}
+	return []byte(signed.String())
+}
+
+// NewSecret returns random secret of webhook.
+func NewSecret() (string, error) {
+	secret := make([]byte, secretLength)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(secret), nil
+}
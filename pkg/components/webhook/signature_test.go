@@ -0,0 +1,84 @@
+package webhook
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSign(t *testing.T) {
+	// HMAC-SHA256 test vector of RFC 4231, test case 2
+	assert.Equal(t,
+		"sha256=5bdcc146bf60754e6a042426089575c75a003f089d2739839dec58b964ec3843",
+		Sign("Jefe", http.Header{"Content-Type": {"application/json"}}, []byte("what do ya want for nothing?")),
+	)
+	// signed input of binary mode is sorted Ce-* headers followed by body
+	assert.Equal(t,
+		Sign("Jefe", nil, []byte("ce-id:1\nce-subject:employee/2\nwhat do ya want for nothing?")),
+		Sign("Jefe", http.Header{"Ce-Subject": {"employee/2"}, "Ce-Id": {"1"}}, []byte("what do ya want for nothing?")),
+	)
+}
+
+func TestVerifySignature(t *testing.T) {
+	body := []byte(`{"event_id":"1"}`)
+	header := http.Header{"Ce-Id": {"1"}, "Ce-Subject": {"employee/2"}}
+
+	cases := []struct {
+		Name      string
+		Secret    string
+		Header    http.Header
+		Signature string
+		Expected  bool
+	}{
+		{
+			Name:      "Valid signature",
+			Secret:    "secret",
+			Header:    header,
+			Signature: Sign("secret", header, body),
+			Expected:  true,
+		},
+		{
+			Name:      "Valid signature with headers other than attributes",
+			Secret:    "secret",
+			Header:    http.Header{"Ce-Id": {"1"}, "Ce-Subject": {"employee/2"}, "Content-Type": {"application/json"}},
+			Signature: Sign("secret", header, body),
+			Expected:  true,
+		},
+		{
+			Name:      "Signature of other secret",
+			Secret:    "secret",
+			Header:    header,
+			Signature: Sign("other", header, body),
+		},
+		{
+			Name:      "Signature without algorithm",
+			Secret:    "secret",
+			Header:    header,
+			Signature: Sign("secret", header, body)[len("sha256="):],
+		},
+		{
+			Name:      "Altered attribute",
+			Secret:    "secret",
+			Header:    http.Header{"Ce-Id": {"1"}, "Ce-Subject": {"employee/3"}},
+			Signature: Sign("secret", header, body),
+		},
+		{
+			Name:      "Added attribute",
+			Secret:    "secret",
+			Header:    http.Header{"Ce-Id": {"1"}, "Ce-Subject": {"employee/2"}, "Ce-Originatorid": {"3"}},
+			Signature: Sign("secret", header, body),
+		},
+		{
+			Name:      "Removed attribute",
+			Secret:    "secret",
+			Header:    http.Header{"Ce-Id": {"1"}},
+			Signature: Sign("secret", header, body),
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			assert.Equal(t, tc.Expected, VerifySignature(tc.Secret, tc.Header, body, tc.Signature))
+		})
+	}
+}
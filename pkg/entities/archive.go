@@ -0,0 +1,52 @@
+package entities
+
+import "time"
+
+// ArchiveFile entity definition, file of archived events of single channel and day.
+type ArchiveFile struct {
+	// Path of file relative to archive directory.
+	Path           string   `json:"path"`
+	Channel        string   `json:"channel"`
+	Day            string   `json:"day"`
+	Part           int      `json:"part"`
+	Format         string   `json:"format"`
+	Events         int      `json:"events"`
+	AggregateTypes []string `json:"aggregate_types"`
+	// Aggregates index events of file by their aggregates, files archived before the index was introduced list
+	// only their AggregateTypes.
+	Aggregates []ArchiveAggregate `json:"aggregates,omitempty"`
+	From       time.Time          `json:"from"`
+	To         time.Time          `json:"to"`
+	// SHA256 is hex encoded checksum of compressed file.
+	SHA256     string    `json:"sha256"`
+	ArchivedAt time.Time `json:"archived_at"`
+	// ChainPath is path of file holding links of chained events, relative to archive directory. Files of events
+	// which weren't chained have no links.
+	ChainPath   string `json:"chain_path,omitempty"`
+	ChainSHA256 string `json:"chain_sha256,omitempty"`
+	// Chain lists runs of consecutive links of events of file in hash chain of channel.
+	Chain []ArchiveChainRun `json:"chain,omitempty"`
+}
+
+// ArchiveAggregate entity definition, sequences of events of aggregate kept by archive file.
+type ArchiveAggregate struct {
+	Type          string `json:"type"`
+	ID            string `json:"id"`
+	FirstSequence uint64 `json:"first_sequence"`
+	LastSequence  uint64 `json:"last_sequence"`
+}
+
+// ArchiveChainRun entity definition, consecutive links of archived events in hash chain of their channel.
+type ArchiveChainRun struct {
+	// From is position of the first link of run, following event of PreviousHash.
+	From         uint64 `json:"from"`
+	PreviousHash string `json:"previous_hash"`
+	// To is position of the last link of run, of Hash.
+	To   uint64 `json:"to"`
+	Hash string `json:"hash"`
+}
+
+// ArchiveManifest entity definition, list of files of archive.
+type ArchiveManifest struct {
+	Files []ArchiveFile `json:"files"`
+}
@@ -0,0 +1,32 @@
+package entities
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// ChainLink entity definition, link of stored event in hash chain of its channel. Hash covers hash of previous
+// event, position of event and its stored document.
+type ChainLink struct {
+	Position     uint64 `bson:"position" json:"position"`
+	PreviousHash string `bson:"previous_hash" json:"previous_hash"`
+	Hash         string `bson:"hash" json:"hash"`
+}
+
+// ChainedEvent entity definition, stored document of event with its link, link of event not chained yet is nil.
+type ChainedEvent struct {
+	EventID  string
+	Document bson.Raw
+	Link     *ChainLink
+}
+
+// ChainCheckpoint entity definition, signed head of hash chain of channel.
+type ChainCheckpoint struct {
+	Channel   string    `json:"channel"`
+	Position  uint64    `json:"position"`
+	Hash      string    `json:"hash"`
+	CreatedAt time.Time `json:"created_at"`
+	// Signature is base64 encoded Ed25519 signature of checkpoint.
+	Signature string `json:"signature"`
+}
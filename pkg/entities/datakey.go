@@ -0,0 +1,17 @@
+package entities
+
+import "time"
+
+// DataKey entity definition, key encrypting personal data of single subject. Key material of erased subject is
+// destroyed and only its tombstone is kept.
+type DataKey struct {
+	SubjectID   string     `bson:"_id"`
+	Key         []byte     `bson:"key,omitempty"`
+	CreatedAt   time.Time  `bson:"created_at"`
+	DestroyedAt *time.Time `bson:"destroyed_at,omitempty"`
+}
+
+// Destroyed reports whether key material was destroyed.
+func (key *DataKey) Destroyed() bool {
+	return key.DestroyedAt != nil
+}
@@ -0,0 +1,37 @@
+package entities
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Statuses of employees listed by employee directory.
+const (
+	ActiveEmployee  = "active"
+	DeletedEmployee = "deleted"
+)
+
+// DirectoryEntry entity definition, employee denormalised for listing and searching.
+type DirectoryEntry struct {
+	ID        primitive.ObjectID `bson:"_id"`
+	Email     string             `bson:"email"`
+	Name      string             `bson:"name,omitempty"`
+	Phone     string             `bson:"phone,omitempty"`
+	Team      string             `bson:"team,omitempty"`
+	Status    string             `bson:"status"`
+	Roles     []Role             `bson:"roles,omitempty"`
+	CreatedAt *time.Time         `bson:"created_at,omitempty"`
+	UpdatedAt *time.Time         `bson:"updated_at,omitempty"`
+	// Sequence of the last event of employee applied to entry.
+	Sequence uint64 `bson:"sequence"`
+}
+
+// RoleNames returns names of roles of employee.
+func (entry *DirectoryEntry) RoleNames() []string {
+	names := make([]string, 0, len(entry.Roles))
+	for _, role := range entry.Roles {
+		names = append(names, role.Name)
+	}
+	return names
+}
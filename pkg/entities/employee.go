@@ -15,6 +15,7 @@ type Employee struct {
 	Password  string             `bson:"password,omitempty" json:"-"`
 	Name      string             `bson:"name,omitempty"`
 	Phone     string             `bson:"phone,omitempty"`
+	Team      string             `bson:"team,omitempty"`
 	CreatedAt *time.Time         `bson:"created_at,omitempty"`
 	UpdatedAt *time.Time         `bson:"updated_at,omitempty"`
 	Roles     []Role             `bson:"roles,omitempty"`
@@ -39,3 +40,17 @@ func (employee *Employee) GetLogin() string {
 func (employee *Employee) GetRoles() []Role {
 	return employee.Roles
 }
+
+// EmployeeChanges entity definition, changes of employee fields made by single update.
+type EmployeeChanges struct {
+	ID      primitive.ObjectID `bson:"_id"`
+	Changes []FieldChange      `bson:"changes"`
+}
+
+// FieldChange entity definition.
+type FieldChange struct {
+	Path     string `bson:"path"`
+	OldValue string `bson:"old_value,omitempty"`
+	NewValue string `bson:"new_value,omitempty"`
+	Redacted bool   `bson:"redacted,omitempty"`
+}
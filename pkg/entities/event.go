@@ -3,6 +3,7 @@ package entities
 import (
 	"time"
 
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
@@ -12,6 +13,10 @@ const (
 	NewEmployeeEvent    EventType = "NewEmployee"
 	UpdateEmployeeEvent EventType = "UpdateEmployee"
 	DeleteEmployeeEvent EventType = "DeleteEmployee"
+	EraseEmployeeEvent  EventType = "EraseEmployee"
+	NewRoleEvent        EventType = "NewRole"
+	UpdateRoleEvent     EventType = "UpdateRole"
+	DeleteRoleEvent     EventType = "DeleteRole"
 )
 
 // Event entity definition.
@@ -21,9 +26,17 @@ type Event struct {
 	Type          EventType
 	AggregateID   string
 	AggregateType string
+	Sequence      uint64
+	SchemaVersion uint32
+	CorrelationID string `bson:"correlation_id,omitempty"`
+	CausationID   string `bson:"causation_id,omitempty"`
 	Data          interface{}
 	Originator    EventOriginator
 	CreatedAt     time.Time
+	Chain         *ChainLink `bson:"chain,omitempty"`
+	// Document is stored document of event read to be archived, or canonical document of chained event restored
+	// from archive which is stored as it was instead of fields of event.
+	Document bson.Raw `bson:"-"`
 }
 
 type EventOriginator struct {
@@ -0,0 +1,17 @@
+package entities
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// Snapshot entity definition, state of aggregate covering its events up to sequence.
+type Snapshot struct {
+	AggregateType string    `bson:"aggregate_type"`
+	AggregateID   string    `bson:"aggregate_id"`
+	Sequence      uint64    `bson:"sequence"`
+	Version       uint32    `bson:"version"`
+	State         bson.Raw  `bson:"state"`
+	CreatedAt     time.Time `bson:"created_at"`
+}
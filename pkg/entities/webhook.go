@@ -0,0 +1,60 @@
+package entities
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Webhook entity definition.
+type Webhook struct {
+	ID         primitive.ObjectID `bson:"_id"`
+	URL        string             `bson:"url"`
+	Channels   []string           `bson:"channels"`
+	EventTypes []string           `bson:"event_types,omitempty"`
+	Secret     string             `bson:"secret" json:"-"`
+	Format     string             `bson:"format,omitempty"`
+	CreatedAt  *time.Time         `bson:"created_at,omitempty"`
+}
+
+// Accepts reports whether event of given channel and type should be delivered to webhook.
+func (webhook *Webhook) Accepts(channel, eventType string) bool {
+	if !contains(webhook.Channels, channel) {
+		return false
+	}
+	return len(webhook.EventTypes) == 0 || contains(webhook.EventTypes, eventType)
+}
+
+// WebhookAttempt entity definition, single attempt of event delivery to webhook.
+type WebhookAttempt struct {
+	ID         primitive.ObjectID `bson:"_id"`
+	WebhookID  primitive.ObjectID `bson:"webhook_id"`
+	EventID    string             `bson:"event_id"`
+	EventType  string             `bson:"event_type"`
+	Attempt    int                `bson:"attempt"`
+	StatusCode int                `bson:"status_code,omitempty"`
+	Error      string             `bson:"error,omitempty"`
+	Delivered  bool               `bson:"delivered"`
+	Duration   time.Duration      `bson:"duration"`
+	CreatedAt  time.Time          `bson:"created_at"`
+}
+
+// ParkedDelivery entity definition, delivery of event which failed and waits for webhook to become available.
+type ParkedDelivery struct {
+	ID        primitive.ObjectID `bson:"_id"`
+	WebhookID primitive.ObjectID `bson:"webhook_id"`
+	Channel   string             `bson:"channel"`
+	EventID   string             `bson:"event_id"`
+	// Data of event as it was published, personal data stays encrypted until delivery.
+	Data     []byte    `bson:"data"`
+	ParkedAt time.Time `bson:"parked_at"`
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,173 @@
+package helpers
+
+import (
+	"context"
+	"encoding"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/heetch/confita"
+	"github.com/heetch/confita/backend/file"
+)
+
+// Config of service loaded by LoadConfig.
+type Config interface {
+	// SetDefaults sets default values of not configured options.
+	SetDefaults()
+	// Validate returns errors of options which are missing or invalid.
+	Validate() error
+}
+
+// LoadConfig reads TOML file of given path into config and overrides its options by environment variables,
+// afterwards default values are set. Variables are named by given prefix and upper-cased keys of options,
+// keys of tables are joined by underscore, e.g. PREFIX_TRACING_EXPORTER. Lists of strings are given as comma
+// separated values, arrays of tables can't be overridden.
+func LoadConfig(ctx context.Context, path, envPrefix string, config Config) error {
+	if err := confita.NewLoader(file.NewBackend(path)).Load(ctx, config); err != nil {
+		return err
+	}
+	if err := overrideFromEnv(reflect.ValueOf(config).Elem(), envPrefix); err != nil {
+		return err
+	}
+	config.SetDefaults()
+	return nil
+}
+
+// PrintConfig writes config as TOML, passwords of URLs are redacted.
+func PrintConfig(w io.Writer, config interface{}) error {
+	printed := reflect.New(reflect.TypeOf(config).Elem()).Elem()
+	printed.Set(reflect.ValueOf(config).Elem())
+	redactPasswords(printed)
+	return toml.NewEncoder(w).Encode(printed.Interface())
+}
+
+// Required returns error of option with given key when its value is empty.
+func Required(key, value string) error {
+	if value == "" {
+		return fmt.Errorf("%s is required", key)
+	}
+	return nil
+}
+
+var textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+
+func overrideFromEnv(value reflect.Value, prefix string) error {
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		if field.PkgPath != "" && !field.Anonymous {
+			continue
+		}
+		fieldValue := value.Field(i)
+
+		key := field.Tag.Get("toml")
+		if key == "-" {
+			continue
+		}
+		if key == "" {
+			// fields of embedded structs are options of embedding one
+			if field.Anonymous && fieldValue.Kind() == reflect.Struct {
+				if err := overrideFromEnv(fieldValue, prefix); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		name := prefix + strings.ToUpper(key)
+
+		if fieldValue.Kind() == reflect.Struct && !fieldValue.Addr().Type().Implements(textUnmarshalerType) {
+			if err := overrideFromEnv(fieldValue, name+"_"); err != nil {
+				return err
+			}
+			continue
+		}
+
+		env, ok := os.LookupEnv(name)
+		if !ok {
+			continue
+		}
+		if err := setValue(fieldValue, env); err != nil {
+			return fmt.Errorf("invalid value of %s: %v", name, err)
+		}
+	}
+	return nil
+}
+
+func setValue(value reflect.Value, text string) error {
+	if value.Kind() == reflect.Ptr {
+		// pointers tell options which are set from unset ones
+		if value.IsNil() {
+			value.Set(reflect.New(value.Type().Elem()))
+		}
+		return setValue(value.Elem(), text)
+	}
+	if unmarshaler, ok := value.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return unmarshaler.UnmarshalText([]byte(text))
+	}
+
+	switch value.Kind() {
+	case reflect.String:
+		value.SetString(text)
+	case reflect.Bool:
+		parsed, err := strconv.ParseBool(text)
+		if err != nil {
+			return err
+		}
+		value.SetBool(parsed)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		parsed, err := strconv.ParseInt(text, 10, value.Type().Bits())
+		if err != nil {
+			return err
+		}
+		value.SetInt(parsed)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		parsed, err := strconv.ParseUint(text, 10, value.Type().Bits())
+		if err != nil {
+			return err
+		}
+		value.SetUint(parsed)
+	case reflect.Float32, reflect.Float64:
+		parsed, err := strconv.ParseFloat(text, value.Type().Bits())
+		if err != nil {
+			return err
+		}
+		value.SetFloat(parsed)
+	case reflect.Slice:
+		if value.Type().Elem().Kind() != reflect.String {
+			return fmt.Errorf("option of type %s can't be set by environment", value.Type())
+		}
+		var items []string
+		for _, item := range strings.Split(text, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		value.Set(reflect.ValueOf(items).Convert(value.Type()))
+	default:
+		return fmt.Errorf("option of type %s can't be set by environment", value.Type())
+	}
+	return nil
+}
+
+func redactPasswords(value reflect.Value) {
+	for i := 0; i < value.NumField(); i++ {
+		if field := value.Type().Field(i); field.PkgPath != "" && !field.Anonymous {
+			continue
+		}
+		switch field := value.Field(i); field.Kind() {
+		case reflect.Struct:
+			redactPasswords(field)
+		case reflect.String:
+			if parsed, err := url.Parse(field.String()); err == nil && parsed.User != nil {
+				if _, ok := parsed.User.Password(); ok {
+					field.SetString(parsed.Redacted())
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,179 @@
+// Package correlation propagates IDs linking requests with events they produced and events with their reactions.
+package correlation
+
+import (
+	"context"
+
+	"github.com/gofrs/uuid"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/migotom/cell-centre-services/pkg/pb"
+)
+
+const (
+	// RequestIDHeader is HTTP header with ID of request, accepted from callers or generated by REST API.
+	RequestIDHeader = "X-Request-ID"
+	// RequestIDMetadataKey is gRPC metadata key with ID of request, used as correlation ID.
+	RequestIDMetadataKey = "x-request-id"
+	// CausationIDMetadataKey is gRPC metadata key with ID of event which caused request.
+	CausationIDMetadataKey = "x-causation-id"
+
+	maxRequestIDLength = 128
+)
+
+type contextKey int
+
+const contextKeyIDs contextKey = iota
+
+type ids struct {
+	correlationID string
+	causationID   string
+}
+
+// NewRequestID returns new random request ID.
+func NewRequestID() string {
+	return uuid.Must(uuid.NewV4()).String()
+}
+
+// ValidRequestID reports whether request ID given by caller may be accepted.
+func ValidRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, r := range id {
+		if r < '!' || r > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// NewContext returns context carrying correlation and causation IDs.
+func NewContext(ctx context.Context, correlationID, causationID string) context.Context {
+	return context.WithValue(ctx, contextKeyIDs, ids{correlationID: correlationID, causationID: causationID})
+}
+
+// NewContextFromEvent returns context of reaction to event, correlated with request which started chain of events
+// and caused by event.
+func NewContextFromEvent(ctx context.Context, e *pb.Event) context.Context {
+	correlationID := e.GetCorrelationId()
+	if correlationID == "" {
+		correlationID = e.GetEventId()
+	}
+	return NewContext(ctx, correlationID, e.GetEventId())
+}
+
+// FromContext returns correlation and causation IDs carried by context.
+func FromContext(ctx context.Context) (correlationID, causationID string) {
+	ids, _ := ctx.Value(contextKeyIDs).(ids)
+	return ids.correlationID, ids.causationID
+}
+
+// Fields returns log fields of IDs carried by context.
+func Fields(ctx context.Context) []zap.Field {
+	correlationID, causationID := FromContext(ctx)
+
+	var fields []zap.Field
+	if correlationID != "" {
+		fields = append(fields, zap.String("correlationID", correlationID))
+	}
+	if causationID != "" {
+		fields = append(fields, zap.String("causationID", causationID))
+	}
+	return fields
+}
+
+// Logger returns logger with fields of IDs carried by context.
+func Logger(ctx context.Context, log *zap.Logger) *zap.Logger {
+	return log.With(Fields(ctx)...)
+}
+
+// Stamp sets IDs carried by context on event.
+func Stamp(ctx context.Context, e *pb.Event) {
+	e.CorrelationId, e.CausationId = FromContext(ctx)
+}
+
+// UnaryServerInterceptor returns interceptor taking IDs from incoming metadata, calls without request ID
+// are given new one. Request ID is sent back to caller in response header.
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx = incomingContext(ctx)
+		// fails only outside of gRPC server, e.g. when handler is called directly
+		_ = grpc.SetHeader(ctx, responseHeader(ctx))
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor returns interceptor taking IDs from incoming metadata, calls without request ID
+// are given new one. Request ID is sent back to caller in response header.
+func StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx := incomingContext(stream.Context())
+		if err := stream.SetHeader(responseHeader(ctx)); err != nil {
+			return err
+		}
+		return handler(srv, &serverStream{ServerStream: stream, ctx: ctx})
+	}
+}
+
+// UnaryClientInterceptor returns interceptor forwarding IDs carried by context as outgoing metadata.
+func UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		return invoker(outgoingContext(ctx), method, req, reply, cc, opts...)
+	}
+}
+
+// StreamClientInterceptor returns interceptor forwarding IDs carried by context as outgoing metadata.
+func StreamClientInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		return streamer(outgoingContext(ctx), desc, cc, method, opts...)
+	}
+}
+
+func incomingContext(ctx context.Context) context.Context {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	correlationID := first(md.Get(RequestIDMetadataKey))
+	if !ValidRequestID(correlationID) {
+		correlationID = NewRequestID()
+	}
+	causationID := first(md.Get(CausationIDMetadataKey))
+	if !ValidRequestID(causationID) {
+		causationID = ""
+	}
+	return NewContext(ctx, correlationID, causationID)
+}
+
+func outgoingContext(ctx context.Context) context.Context {
+	correlationID, causationID := FromContext(ctx)
+	if correlationID != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, RequestIDMetadataKey, correlationID)
+	}
+	if causationID != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, CausationIDMetadataKey, causationID)
+	}
+	return ctx
+}
+
+func responseHeader(ctx context.Context) metadata.MD {
+	correlationID, _ := FromContext(ctx)
+	return metadata.Pairs(RequestIDMetadataKey, correlationID)
+}
+
+func first(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (stream *serverStream) Context() context.Context {
+	return stream.ctx
+}
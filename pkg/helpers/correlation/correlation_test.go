@@ -0,0 +1,142 @@
+package correlation
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/migotom/cell-centre-services/pkg/pb"
+)
+
+func TestValidRequestID(t *testing.T) {
+	cases := []struct {
+		Name     string
+		ID       string
+		Expected bool
+	}{
+		{Name: "UUID", ID: "0b9d3c0e-3f4e-4b8c-9d59-7c0f0f6f5a01", Expected: true},
+		{Name: "Empty", ID: ""},
+		{Name: "Too long", ID: strings.Repeat("a", maxRequestIDLength+1)},
+		{Name: "With whitespace", ID: "request 1"},
+		{Name: "With control characters", ID: "request\n1"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			assert.Equal(t, tc.Expected, ValidRequestID(tc.ID))
+		})
+	}
+}
+
+func TestNewContextFromEvent(t *testing.T) {
+	cases := []struct {
+		Name                  string
+		Event                 *pb.Event
+		ExpectedCorrelationID string
+		ExpectedCausationID   string
+	}{
+		{
+			Name:                  "Event of request",
+			Event:                 &pb.Event{EventId: "event-1", CorrelationId: "request-1"},
+			ExpectedCorrelationID: "request-1",
+			ExpectedCausationID:   "event-1",
+		},
+		{
+			Name:                  "Event without correlation",
+			Event:                 &pb.Event{EventId: "event-1"},
+			ExpectedCorrelationID: "event-1",
+			ExpectedCausationID:   "event-1",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			ctx := NewContextFromEvent(context.Background(), tc.Event)
+
+			var reaction pb.Event
+			Stamp(ctx, &reaction)
+			assert.Equal(t, tc.ExpectedCorrelationID, reaction.CorrelationId)
+			assert.Equal(t, tc.ExpectedCausationID, reaction.CausationId)
+		})
+	}
+}
+
+func TestInterceptors(t *testing.T) {
+	cases := []struct {
+		Name                string
+		Metadata            metadata.MD
+		ExpectedRequestID   string
+		ExpectedCausationID string
+	}{
+		{
+			Name:                "IDs taken from metadata",
+			Metadata:            metadata.Pairs(RequestIDMetadataKey, "request-1", CausationIDMetadataKey, "event-1"),
+			ExpectedRequestID:   "request-1",
+			ExpectedCausationID: "event-1",
+		},
+		{
+			Name:     "Missing request ID is generated",
+			Metadata: metadata.MD{},
+		},
+		{
+			Name:     "Invalid request ID is replaced",
+			Metadata: metadata.Pairs(RequestIDMetadataKey, "request 1"),
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			ctx := metadata.NewIncomingContext(context.Background(), tc.Metadata)
+
+			var correlationID, causationID string
+			_, err := UnaryServerInterceptor()(ctx, nil, &grpc.UnaryServerInfo{}, func(ctx context.Context, req interface{}) (interface{}, error) {
+				correlationID, causationID = FromContext(ctx)
+
+				// IDs are forwarded to calls made while handling request
+				return nil, UnaryClientInterceptor()(ctx, "/pb.AuthService/Authenticate", nil, nil, nil,
+					func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+						md, _ := metadata.FromOutgoingContext(ctx)
+						assert.Equal(t, []string{correlationID}, md.Get(RequestIDMetadataKey))
+						return nil
+					})
+			})
+			assert.NoError(t, err)
+
+			if tc.ExpectedRequestID != "" {
+				assert.Equal(t, tc.ExpectedRequestID, correlationID)
+			} else {
+				assert.True(t, ValidRequestID(correlationID))
+				assert.NotEqual(t, "request 1", correlationID)
+			}
+			assert.Equal(t, tc.ExpectedCausationID, causationID)
+		})
+	}
+}
+
+func TestStreamServerInterceptorResponseHeader(t *testing.T) {
+	stream := &headerStream{ctx: metadata.NewIncomingContext(context.Background(), metadata.Pairs(RequestIDMetadataKey, "request-1"))}
+
+	err := StreamServerInterceptor()(nil, stream, &grpc.StreamServerInfo{}, func(srv interface{}, stream grpc.ServerStream) error {
+		correlationID, _ := FromContext(stream.Context())
+		assert.Equal(t, "request-1", correlationID)
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"request-1"}, stream.header.Get(RequestIDMetadataKey))
+}
+
+type headerStream struct {
+	grpc.ServerStream
+	ctx    context.Context
+	header metadata.MD
+}
+
+func (stream *headerStream) Context() context.Context {
+	return stream.ctx
+}
+
+func (stream *headerStream) SetHeader(md metadata.MD) error {
+	stream.header = metadata.Join(stream.header, md)
+	return nil
+}
@@ -0,0 +1,19 @@
+package helpers
+
+import "time"
+
+// Duration is time.Duration that can be decoded from configuration text like "1m30s".
+type Duration struct {
+	time.Duration
+}
+
+// UnmarshalText parses duration from text.
+func (d *Duration) UnmarshalText(text []byte) (err error) {
+	d.Duration, err = time.ParseDuration(string(text))
+	return
+}
+
+// MarshalText returns duration as text.
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(d.Duration.String()), nil
+}
@@ -1,15 +1,19 @@
 package helpers
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 )
 
-// AssertErrors checks and compares error with expected one.
+// AssertErrors checks and compares error with expected one. Errors of protobuf randomly separate words by
+// non-breaking space, which is compared as regular one.
 func AssertErrors(t *testing.T, expected string, err error) {
 	if expected != "" {
-		assert.EqualError(t, err, expected)
+		if assert.Error(t, err) {
+			assert.Equal(t, expected, strings.ReplaceAll(err.Error(), "\u00a0", " "))
+		}
 	} else {
 		if err != nil {
 			t.Errorf("not expected err %v", err)
@@ -0,0 +1,136 @@
+// Package interceptors provides chain of interceptors shared by gRPC servers of services.
+package interceptors
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	grpc_middleware "github.com/grpc-ecosystem/go-grpc-middleware"
+	grpc_auth "github.com/grpc-ecosystem/go-grpc-middleware/auth"
+	grpc_zap "github.com/grpc-ecosystem/go-grpc-middleware/logging/zap"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	authDelivery "github.com/migotom/cell-centre-services/pkg/components/auth/delivery/grpc"
+	"github.com/migotom/cell-centre-services/pkg/helpers/correlation"
+	"github.com/migotom/cell-centre-services/pkg/helpers/metrics"
+	"github.com/migotom/cell-centre-services/pkg/helpers/tracing"
+)
+
+const healthServicePrefix = "/grpc.health.v1.Health/"
+
+// ServerOptions returns options of gRPC server tracing, measuring and logging calls with their request IDs,
+// recovering from panics of handlers and authenticating calls by given function. Calls aren't authenticated
+// with nil function.
+func ServerOptions(log *zap.Logger, authFunc grpc_auth.AuthFunc) []grpc.ServerOption {
+	return []grpc.ServerOption{
+		tracing.ServerOption(),
+		grpc.UnaryInterceptor(grpc_middleware.ChainUnaryServer(unaryServerInterceptors(log, authFunc)...)),
+		grpc.StreamInterceptor(grpc_middleware.ChainStreamServer(streamServerInterceptors(log, authFunc)...)),
+	}
+}
+
+func unaryServerInterceptors(log *zap.Logger, authFunc grpc_auth.AuthFunc) []grpc.UnaryServerInterceptor {
+	interceptors := []grpc.UnaryServerInterceptor{
+		metrics.UnaryServerInterceptor(),
+		correlation.UnaryServerInterceptor(),
+		UnaryLoggingInterceptor(log),
+		UnaryRecoveryInterceptor(),
+	}
+	if authFunc != nil {
+		interceptors = append(interceptors, grpc_auth.UnaryServerInterceptor(authFunc), unaryLoginInterceptor)
+	}
+	return interceptors
+}
+
+func streamServerInterceptors(log *zap.Logger, authFunc grpc_auth.AuthFunc) []grpc.StreamServerInterceptor {
+	interceptors := []grpc.StreamServerInterceptor{
+		metrics.StreamServerInterceptor(),
+		correlation.StreamServerInterceptor(),
+		StreamLoggingInterceptor(log),
+		StreamRecoveryInterceptor(),
+	}
+	if authFunc != nil {
+		interceptors = append(interceptors, grpc_auth.StreamServerInterceptor(authFunc), streamLoginInterceptor)
+	}
+	return interceptors
+}
+
+// UnaryLoggingInterceptor returns interceptor logging calls with their method, code, duration and IDs of request.
+// Successful health checks aren't logged.
+func UnaryLoggingInterceptor(log *zap.Logger) grpc.UnaryServerInterceptor {
+	opts := loggingOptions()
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		interceptor := grpc_zap.UnaryServerInterceptor(correlation.Logger(ctx, log), opts...)
+		return interceptor(ctx, req, info, handler)
+	}
+}
+
+// StreamLoggingInterceptor returns interceptor logging calls with their method, code, duration and IDs of request.
+// Successful health checks aren't logged.
+func StreamLoggingInterceptor(log *zap.Logger) grpc.StreamServerInterceptor {
+	opts := loggingOptions()
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		interceptor := grpc_zap.StreamServerInterceptor(correlation.Logger(stream.Context(), log), opts...)
+		return interceptor(srv, stream, info, handler)
+	}
+}
+
+// UnaryRecoveryInterceptor returns interceptor converting panics of handlers to Internal errors,
+// panics are logged with their stack trace.
+func UnaryRecoveryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (_ interface{}, err error) {
+		defer recoverFrom(ctx, &err)
+		return handler(ctx, req)
+	}
+}
+
+// StreamRecoveryInterceptor returns interceptor converting panics of handlers to Internal errors,
+// panics are logged with their stack trace.
+func StreamRecoveryInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		defer recoverFrom(stream.Context(), &err)
+		return handler(srv, stream)
+	}
+}
+
+func recoverFrom(ctx context.Context, err *error) {
+	if p := recover(); p != nil {
+		ctxzap.Extract(ctx).Error("Handler panicked", zap.Any("panic", p), zap.Stack("stack"))
+		*err = status.Error(codes.Internal, "internal error")
+	}
+}
+
+// unaryLoginInterceptor adds login of authenticated caller to call's log.
+func unaryLoginInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	addLogin(ctx)
+	return handler(ctx, req)
+}
+
+// streamLoginInterceptor adds login of authenticated caller to call's log.
+func streamLoginInterceptor(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	addLogin(stream.Context())
+	return handler(srv, stream)
+}
+
+func addLogin(ctx context.Context) {
+	if login := authDelivery.ObtainClaimsFromContext(ctx).Login; login != "" {
+		ctxzap.AddFields(ctx, zap.String("login", login))
+	}
+}
+
+func loggingOptions() []grpc_zap.Option {
+	return []grpc_zap.Option{
+		grpc_zap.WithDurationField(func(duration time.Duration) zapcore.Field {
+			return zap.Int64("grpc.time_ns", duration.Nanoseconds())
+		}),
+		grpc_zap.WithDecider(func(fullMethodName string, err error) bool {
+			return err != nil || !strings.HasPrefix(fullMethodName, healthServicePrefix)
+		}),
+	}
+}
@@ -0,0 +1,126 @@
+package interceptors
+
+import (
+	"context"
+	"testing"
+
+	grpc_middleware "github.com/grpc-ecosystem/go-grpc-middleware"
+	grpc_auth "github.com/grpc-ecosystem/go-grpc-middleware/auth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	authDelivery "github.com/migotom/cell-centre-services/pkg/components/auth/delivery/grpc"
+	"github.com/migotom/cell-centre-services/pkg/entities"
+	"github.com/migotom/cell-centre-services/pkg/helpers"
+	"github.com/migotom/cell-centre-services/pkg/helpers/correlation"
+)
+
+func TestUnaryServerInterceptors(t *testing.T) {
+	authenticated := func(ctx context.Context) (context.Context, error) {
+		return context.WithValue(ctx, authDelivery.ContextKeyClaims, entities.TokenClaims{Login: "admin@cell-centre"}), nil
+	}
+
+	cases := []struct {
+		Name           string
+		Method         string
+		AuthFunc       grpc_auth.AuthFunc
+		Handler        grpc.UnaryHandler
+		ExpectedErr    string
+		ExpectedLogs   []string
+		ExpectedFields map[string]interface{}
+		ExpectedStack  bool
+	}{
+		{
+			Name:     "Call logged with login of caller",
+			Method:   "/pb.EmployeeService/GetEmployee",
+			AuthFunc: authenticated,
+			Handler: func(ctx context.Context, req interface{}) (interface{}, error) {
+				return nil, nil
+			},
+			ExpectedLogs: []string{"finished unary call with code OK"},
+			ExpectedFields: map[string]interface{}{
+				"grpc.method":   "GetEmployee",
+				"grpc.code":     "OK",
+				"login":         "admin@cell-centre",
+				"correlationID": "request-1",
+			},
+		},
+		{
+			Name:   "Unauthenticated call logged without login",
+			Method: "/pb.EmployeeService/GetEmployee",
+			AuthFunc: func(ctx context.Context) (context.Context, error) {
+				return nil, status.Error(codes.Unauthenticated, "invalid token")
+			},
+			Handler: func(ctx context.Context, req interface{}) (interface{}, error) {
+				t.Fatal("handler of unauthenticated call was called")
+				return nil, nil
+			},
+			ExpectedErr:  "rpc error: code = Unauthenticated desc = invalid token",
+			ExpectedLogs: []string{"finished unary call with code Unauthenticated"},
+			ExpectedFields: map[string]interface{}{
+				"grpc.code":     "Unauthenticated",
+				"correlationID": "request-1",
+			},
+		},
+		{
+			Name:   "Panic converted to Internal error",
+			Method: "/pb.AuthService/Authenticate",
+			Handler: func(ctx context.Context, req interface{}) (interface{}, error) {
+				panic("nil claims")
+			},
+			ExpectedErr:  "rpc error: code = Internal desc = internal error",
+			ExpectedLogs: []string{"Handler panicked", "finished unary call with code Internal"},
+			ExpectedFields: map[string]interface{}{
+				"panic":         "nil claims",
+				"grpc.code":     "Internal",
+				"correlationID": "request-1",
+			},
+			ExpectedStack: true,
+		},
+		{
+			Name:   "Successful health check not logged",
+			Method: "/grpc.health.v1.Health/Check",
+			Handler: func(ctx context.Context, req interface{}) (interface{}, error) {
+				return nil, nil
+			},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			core, logs := observer.New(zapcore.DebugLevel)
+
+			interceptor := grpc_middleware.ChainUnaryServer(unaryServerInterceptors(zap.New(core), tc.AuthFunc)...)
+
+			ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(correlation.RequestIDMetadataKey, "request-1"))
+			_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: tc.Method}, tc.Handler)
+			helpers.AssertErrors(t, tc.ExpectedErr, err)
+
+			var messages []string
+			fields := make(map[string]interface{})
+			for _, entry := range logs.All() {
+				messages = append(messages, entry.Message)
+				for key, value := range entry.ContextMap() {
+					fields[key] = value
+				}
+			}
+			assert.Equal(t, tc.ExpectedLogs, messages)
+			for key, value := range tc.ExpectedFields {
+				assert.Equal(t, value, fields[key], key)
+			}
+			if tc.ExpectedStack {
+				require.Contains(t, fields, "stack")
+				assert.Contains(t, fields["stack"], "interceptors.TestUnaryServerInterceptors")
+			}
+			if _, ok := tc.ExpectedFields["login"]; !ok {
+				assert.NotContains(t, fields, "login")
+			}
+		})
+	}
+}
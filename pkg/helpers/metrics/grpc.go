@@ -0,0 +1,48 @@
+package metrics
+
+import (
+	"sync"
+
+	grpc_prometheus "github.com/grpc-ecosystem/go-grpc-prometheus"
+	"google.golang.org/grpc"
+)
+
+var enableHistograms sync.Once
+
+// histograms enables latency histograms of gRPC calls, counters of calls are collected always.
+func histograms() {
+	enableHistograms.Do(func() {
+		grpc_prometheus.EnableHandlingTimeHistogram()
+		grpc_prometheus.EnableClientHandlingTimeHistogram()
+	})
+}
+
+// UnaryServerInterceptor measures rate, errors and duration of unary calls handled by server.
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	histograms()
+	return grpc_prometheus.UnaryServerInterceptor
+}
+
+// StreamServerInterceptor measures rate, errors and duration of streaming calls handled by server.
+func StreamServerInterceptor() grpc.StreamServerInterceptor {
+	histograms()
+	return grpc_prometheus.StreamServerInterceptor
+}
+
+// RegisterServer initializes metrics of all methods registered in given server, so they are reported before
+// their first call.
+func RegisterServer(server *grpc.Server) {
+	grpc_prometheus.Register(server)
+}
+
+// UnaryClientInterceptor measures rate, errors and duration of unary calls made by client.
+func UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	histograms()
+	return grpc_prometheus.UnaryClientInterceptor
+}
+
+// StreamClientInterceptor measures rate, errors and duration of streaming calls made by client.
+func StreamClientInterceptor() grpc.StreamClientInterceptor {
+	histograms()
+	return grpc_prometheus.StreamClientInterceptor
+}
@@ -0,0 +1,30 @@
+// Package metrics exposes Prometheus metrics of services.
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/migotom/cell-centre-services/pkg/helpers"
+)
+
+// Namespace of metrics of all services.
+const Namespace = "cellcentre"
+
+// Path of metrics endpoint.
+const Path = "/metrics"
+
+// Serve serves metrics of default registry at given address until context is done.
+func Serve(ctx context.Context, address string, timeout time.Duration) error {
+	mux := http.NewServeMux()
+	mux.Handle(Path, promhttp.Handler())
+
+	server := &http.Server{Addr: address, Handler: mux}
+	if err := helpers.ServeHTTP(ctx, server, timeout); err != nil && err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
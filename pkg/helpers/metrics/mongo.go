@@ -0,0 +1,36 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.mongodb.org/mongo-driver/event"
+)
+
+var mongoCommandDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+	Namespace: Namespace,
+	Subsystem: "mongodb",
+	Name:      "command_duration_seconds",
+	Help:      "Duration of MongoDB commands by command name and result.",
+	Buckets:   prometheus.DefBuckets,
+}, []string{"command", "result"})
+
+// MongoCommandMonitor returns MongoDB driver's command monitor measuring latency of commands.
+func MongoCommandMonitor() *event.CommandMonitor {
+	return &event.CommandMonitor{
+		// driver reports finished commands only when started ones are monitored as well
+		Started: func(context.Context, *event.CommandStartedEvent) {},
+		Succeeded: func(_ context.Context, e *event.CommandSucceededEvent) {
+			observeMongoCommand(e.CommandFinishedEvent, "success")
+		},
+		Failed: func(_ context.Context, e *event.CommandFailedEvent) {
+			observeMongoCommand(e.CommandFinishedEvent, "failure")
+		},
+	}
+}
+
+func observeMongoCommand(e event.CommandFinishedEvent, result string) {
+	mongoCommandDuration.WithLabelValues(e.CommandName, result).Observe(time.Duration(e.DurationNanos).Seconds())
+}
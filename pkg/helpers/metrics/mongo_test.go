@@ -0,0 +1,57 @@
+package metrics
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/event"
+)
+
+func TestMongoCommandMonitor(t *testing.T) {
+	monitor := MongoCommandMonitor()
+	monitor.Started(context.Background(), &event.CommandStartedEvent{CommandName: "insert"})
+	monitor.Succeeded(context.Background(), &event.CommandSucceededEvent{
+		CommandFinishedEvent: event.CommandFinishedEvent{CommandName: "insert", DurationNanos: int64(20 * time.Millisecond)},
+	})
+	monitor.Failed(context.Background(), &event.CommandFailedEvent{
+		CommandFinishedEvent: event.CommandFinishedEvent{CommandName: "find", DurationNanos: int64(2 * time.Second)},
+	})
+
+	expected := `
+		# HELP cellcentre_mongodb_command_duration_seconds Duration of MongoDB commands by command name and result.
+		# TYPE cellcentre_mongodb_command_duration_seconds histogram
+		cellcentre_mongodb_command_duration_seconds_bucket{command="find",result="failure",le="0.005"} 0
+		cellcentre_mongodb_command_duration_seconds_bucket{command="find",result="failure",le="0.01"} 0
+		cellcentre_mongodb_command_duration_seconds_bucket{command="find",result="failure",le="0.025"} 0
+		cellcentre_mongodb_command_duration_seconds_bucket{command="find",result="failure",le="0.05"} 0
+		cellcentre_mongodb_command_duration_seconds_bucket{command="find",result="failure",le="0.1"} 0
+		cellcentre_mongodb_command_duration_seconds_bucket{command="find",result="failure",le="0.25"} 0
+		cellcentre_mongodb_command_duration_seconds_bucket{command="find",result="failure",le="0.5"} 0
+		cellcentre_mongodb_command_duration_seconds_bucket{command="find",result="failure",le="1"} 0
+		cellcentre_mongodb_command_duration_seconds_bucket{command="find",result="failure",le="2.5"} 1
+		cellcentre_mongodb_command_duration_seconds_bucket{command="find",result="failure",le="5"} 1
+		cellcentre_mongodb_command_duration_seconds_bucket{command="find",result="failure",le="10"} 1
+		cellcentre_mongodb_command_duration_seconds_bucket{command="find",result="failure",le="+Inf"} 1
+		cellcentre_mongodb_command_duration_seconds_sum{command="find",result="failure"} 2
+		cellcentre_mongodb_command_duration_seconds_count{command="find",result="failure"} 1
+		cellcentre_mongodb_command_duration_seconds_bucket{command="insert",result="success",le="0.005"} 0
+		cellcentre_mongodb_command_duration_seconds_bucket{command="insert",result="success",le="0.01"} 0
+		cellcentre_mongodb_command_duration_seconds_bucket{command="insert",result="success",le="0.025"} 1
+		cellcentre_mongodb_command_duration_seconds_bucket{command="insert",result="success",le="0.05"} 1
+		cellcentre_mongodb_command_duration_seconds_bucket{command="insert",result="success",le="0.1"} 1
+		cellcentre_mongodb_command_duration_seconds_bucket{command="insert",result="success",le="0.25"} 1
+		cellcentre_mongodb_command_duration_seconds_bucket{command="insert",result="success",le="0.5"} 1
+		cellcentre_mongodb_command_duration_seconds_bucket{command="insert",result="success",le="1"} 1
+		cellcentre_mongodb_command_duration_seconds_bucket{command="insert",result="success",le="2.5"} 1
+		cellcentre_mongodb_command_duration_seconds_bucket{command="insert",result="success",le="5"} 1
+		cellcentre_mongodb_command_duration_seconds_bucket{command="insert",result="success",le="10"} 1
+		cellcentre_mongodb_command_duration_seconds_bucket{command="insert",result="success",le="+Inf"} 1
+		cellcentre_mongodb_command_duration_seconds_sum{command="insert",result="success"} 0.02
+		cellcentre_mongodb_command_duration_seconds_count{command="insert",result="success"} 1
+	`
+	assert.NoError(t, testutil.CollectAndCompare(mongoCommandDuration, strings.NewReader(expected)))
+}
@@ -0,0 +1,30 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/stretchr/testify/mock"
+
+	"github.com/migotom/cell-centre-services/pkg/entities"
+)
+
+type ChainRepositoryMock struct {
+	mock.Mock
+}
+
+func (m *ChainRepositoryMock) Head(ctx context.Context, channel string) (*entities.ChainLink, error) {
+	args := m.Called(ctx, channel)
+	return args.Get(0).(*entities.ChainLink), args.Error(1)
+}
+func (m *ChainRepositoryMock) Unchained(ctx context.Context, channel string, limit int) ([]*entities.ChainedEvent, error) {
+	args := m.Called(ctx, channel, limit)
+	return args.Get(0).([]*entities.ChainedEvent), args.Error(1)
+}
+func (m *ChainRepositoryMock) Chained(ctx context.Context, channel string, afterPosition uint64, limit int) ([]*entities.ChainedEvent, error) {
+	args := m.Called(ctx, channel, afterPosition, limit)
+	return args.Get(0).([]*entities.ChainedEvent), args.Error(1)
+}
+func (m *ChainRepositoryMock) Link(ctx context.Context, eventID string, link *entities.ChainLink) error {
+	args := m.Called(ctx, eventID, link)
+	return args.Error(0)
+}
@@ -0,0 +1,52 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/migotom/cell-centre-services/pkg/components/directory"
+	"github.com/migotom/cell-centre-services/pkg/entities"
+)
+
+type DirectoryRepositoryMock struct {
+	mock.Mock
+}
+
+func (m *DirectoryRepositoryMock) Save(ctx context.Context, entry *entities.DirectoryEntry) error {
+	args := m.Called(ctx, entry)
+	return args.Error(0)
+}
+func (m *DirectoryRepositoryMock) Get(ctx context.Context, id primitive.ObjectID) (*entities.DirectoryEntry, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(*entities.DirectoryEntry), args.Error(1)
+}
+func (m *DirectoryRepositoryMock) GetByEmail(ctx context.Context, email string) (*entities.DirectoryEntry, error) {
+	args := m.Called(ctx, email)
+	return args.Get(0).(*entities.DirectoryEntry), args.Error(1)
+}
+func (m *DirectoryRepositoryMock) RenameRole(ctx context.Context, role entities.Role) error {
+	args := m.Called(ctx, role)
+	return args.Error(0)
+}
+func (m *DirectoryRepositoryMock) RemoveRole(ctx context.Context, role entities.Role) error {
+	args := m.Called(ctx, role)
+	return args.Error(0)
+}
+func (m *DirectoryRepositoryMock) List(ctx context.Context, query directory.Query) ([]*entities.DirectoryEntry, error) {
+	args := m.Called(ctx, query)
+	return args.Get(0).([]*entities.DirectoryEntry), args.Error(1)
+}
+func (m *DirectoryRepositoryMock) Checkpoint(ctx context.Context, channel string) (uint64, error) {
+	args := m.Called(ctx, channel)
+	return args.Get(0).(uint64), args.Error(1)
+}
+func (m *DirectoryRepositoryMock) SetCheckpoint(ctx context.Context, channel string, sequence uint64) error {
+	args := m.Called(ctx, channel, sequence)
+	return args.Error(0)
+}
+func (m *DirectoryRepositoryMock) Drop(ctx context.Context) error {
+	args := m.Called(ctx)
+	return args.Error(0)
+}
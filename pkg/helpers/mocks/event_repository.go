@@ -0,0 +1,74 @@
+package mocks
+
+import (
+	"context"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/migotom/cell-centre-services/pkg/entities"
+)
+
+type EventRepositoryMock struct {
+	mock.Mock
+}
+
+func (m *EventRepositoryMock) New(ctx context.Context, event *entities.Event) error {
+	args := m.Called(ctx, event)
+	return args.Error(0)
+}
+func (m *EventRepositoryMock) NewMany(ctx context.Context, events []*entities.Event) []error {
+	args := m.Called(ctx, events)
+	return args.Get(0).([]error)
+}
+func (m *EventRepositoryMock) LastSequence(ctx context.Context, aggregateType, aggregateID string) (uint64, error) {
+	args := m.Called(ctx, aggregateType, aggregateID)
+	return args.Get(0).(uint64), args.Error(1)
+}
+func (m *EventRepositoryMock) Events(ctx context.Context, aggregateType, aggregateID string, afterSequence uint64) ([]*entities.Event, error) {
+	args := m.Called(ctx, aggregateType, aggregateID, afterSequence)
+	return args.Get(0).([]*entities.Event), args.Error(1)
+}
+func (m *EventRepositoryMock) AggregateIDs(ctx context.Context, aggregateType string) ([]string, error) {
+	args := m.Called(ctx, aggregateType)
+	return args.Get(0).([]string), args.Error(1)
+}
+func (m *EventRepositoryMock) EventsBefore(ctx context.Context, before time.Time, limit int) ([]*entities.Event, error) {
+	args := m.Called(ctx, before, limit)
+	return args.Get(0).([]*entities.Event), args.Error(1)
+}
+func (m *EventRepositoryMock) Remove(ctx context.Context, eventIDs []string) (int64, error) {
+	args := m.Called(ctx, eventIDs)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+type SequencerMock struct {
+	mock.Mock
+}
+
+func (m *SequencerMock) Next(ctx context.Context, aggregateType, aggregateID string) (uint64, error) {
+	args := m.Called(ctx, aggregateType, aggregateID)
+	return args.Get(0).(uint64), args.Error(1)
+}
+
+type SnapshotRepositoryMock struct {
+	mock.Mock
+}
+
+func (m *SnapshotRepositoryMock) Save(ctx context.Context, snapshot *entities.Snapshot) error {
+	args := m.Called(ctx, snapshot)
+	return args.Error(0)
+}
+func (m *SnapshotRepositoryMock) Latest(ctx context.Context, aggregateType, aggregateID string, version uint32) (*entities.Snapshot, error) {
+	args := m.Called(ctx, aggregateType, aggregateID, version)
+	return args.Get(0).(*entities.Snapshot), args.Error(1)
+}
+func (m *SnapshotRepositoryMock) Prune(ctx context.Context, aggregateType string, keep int) (int64, error) {
+	args := m.Called(ctx, aggregateType, keep)
+	return args.Get(0).(int64), args.Error(1)
+}
+func (m *EventRepositoryMock) SubjectEvents(ctx context.Context, aggregateType, aggregateID string, originatorID primitive.ObjectID) ([]*entities.Event, error) {
+	args := m.Called(ctx, aggregateType, aggregateID, originatorID)
+	return args.Get(0).([]*entities.Event), args.Error(1)
+}
@@ -0,0 +1,26 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/stretchr/testify/mock"
+
+	"github.com/migotom/cell-centre-services/pkg/entities"
+)
+
+type KeyRepositoryMock struct {
+	mock.Mock
+}
+
+func (m *KeyRepositoryMock) Get(ctx context.Context, subjectID string) (*entities.DataKey, error) {
+	args := m.Called(ctx, subjectID)
+	return args.Get(0).(*entities.DataKey), args.Error(1)
+}
+func (m *KeyRepositoryMock) New(ctx context.Context, key *entities.DataKey) error {
+	args := m.Called(ctx, key)
+	return args.Error(0)
+}
+func (m *KeyRepositoryMock) Destroy(ctx context.Context, subjectID string) error {
+	args := m.Called(ctx, subjectID)
+	return args.Error(0)
+}
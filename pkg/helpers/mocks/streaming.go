@@ -0,0 +1,74 @@
+package mocks
+
+import (
+	"time"
+
+	"github.com/stretchr/testify/mock"
+
+	"github.com/migotom/cell-centre-services/pkg/components/event"
+	"github.com/migotom/cell-centre-services/pkg/pb"
+)
+
+type StreamingMock struct {
+	mock.Mock
+}
+
+func (m *StreamingMock) Publish(e *pb.Event) error {
+	args := m.Called(e)
+	return args.Error(0)
+}
+func (m *StreamingMock) PublishData(channel, id string, data []byte) error {
+	args := m.Called(channel, id, data)
+	return args.Error(0)
+}
+func (m *StreamingMock) Subscribe(channel string, handler event.MessageHandler, options ...event.SubscribeOption) (event.Subscription, error) {
+	args := m.Called(channel, handler, options)
+	return args.Get(0).(event.Subscription), args.Error(1)
+}
+func (m *StreamingMock) Ping() error {
+	args := m.Called()
+	return args.Error(0)
+}
+func (m *StreamingMock) Close() error {
+	args := m.Called()
+	return args.Error(0)
+}
+
+type MessageMock struct {
+	mock.Mock
+
+	MsgData       []byte
+	MsgSequence   uint64
+	MsgTimestamp  time.Time
+	MsgDeliveries int
+}
+
+func (m *MessageMock) Data() []byte {
+	return m.MsgData
+}
+func (m *MessageMock) Sequence() uint64 {
+	return m.MsgSequence
+}
+func (m *MessageMock) Timestamp() time.Time {
+	return m.MsgTimestamp
+}
+func (m *MessageMock) Deliveries() int {
+	return m.MsgDeliveries
+}
+func (m *MessageMock) Ack() error {
+	args := m.Called()
+	return args.Error(0)
+}
+func (m *MessageMock) Nak() error {
+	args := m.Called()
+	return args.Error(0)
+}
+
+type SubscriptionMock struct {
+	mock.Mock
+}
+
+func (m *SubscriptionMock) Close() error {
+	args := m.Called()
+	return args.Error(0)
+}
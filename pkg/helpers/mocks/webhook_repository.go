@@ -0,0 +1,66 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/migotom/cell-centre-services/pkg/entities"
+	"github.com/migotom/cell-centre-services/pkg/pb"
+)
+
+type WebhookRepositoryMock struct {
+	mock.Mock
+}
+
+func (m *WebhookRepositoryMock) Get(ctx context.Context, filter *pb.WebhookFilter) (*entities.Webhook, error) {
+	args := m.Called(ctx, filter)
+	return args.Get(0).(*entities.Webhook), args.Error(1)
+}
+func (m *WebhookRepositoryMock) List(ctx context.Context) ([]*entities.Webhook, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]*entities.Webhook), args.Error(1)
+}
+func (m *WebhookRepositoryMock) New(ctx context.Context, request *entities.Webhook) (*entities.Webhook, error) {
+	args := m.Called(ctx, request)
+	return args.Get(0).(*entities.Webhook), args.Error(1)
+}
+func (m *WebhookRepositoryMock) Delete(ctx context.Context, filter *pb.WebhookFilter) error {
+	args := m.Called(ctx, filter)
+	return args.Error(0)
+}
+
+type WebhookAttemptRepositoryMock struct {
+	mock.Mock
+}
+
+func (m *WebhookAttemptRepositoryMock) New(ctx context.Context, attempt *entities.WebhookAttempt) error {
+	args := m.Called(ctx, attempt)
+	return args.Error(0)
+}
+func (m *WebhookAttemptRepositoryMock) List(ctx context.Context, request *pb.WebhookAttemptsRequest) ([]*entities.WebhookAttempt, error) {
+	args := m.Called(ctx, request)
+	return args.Get(0).([]*entities.WebhookAttempt), args.Error(1)
+}
+
+type WebhookParkingRepositoryMock struct {
+	mock.Mock
+}
+
+func (m *WebhookParkingRepositoryMock) Park(ctx context.Context, delivery *entities.ParkedDelivery) error {
+	args := m.Called(ctx, delivery)
+	return args.Error(0)
+}
+func (m *WebhookParkingRepositoryMock) List(ctx context.Context, webhookID primitive.ObjectID) ([]*entities.ParkedDelivery, error) {
+	args := m.Called(ctx, webhookID)
+	return args.Get(0).([]*entities.ParkedDelivery), args.Error(1)
+}
+func (m *WebhookParkingRepositoryMock) Parked(ctx context.Context, webhookID primitive.ObjectID) (bool, error) {
+	args := m.Called(ctx, webhookID)
+	return args.Bool(0), args.Error(1)
+}
+func (m *WebhookParkingRepositoryMock) Remove(ctx context.Context, id primitive.ObjectID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
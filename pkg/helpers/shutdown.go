@@ -0,0 +1,90 @@
+package helpers
+
+import (
+	"context"
+	"net/http"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+// DefaultShutdownTimeout is time given to services to finish pending work once they are asked to stop.
+const DefaultShutdownTimeout = 30 * time.Second
+
+// SignalContext returns context cancelled once process receives SIGINT, SIGQUIT or SIGTERM.
+func SignalContext() (context.Context, context.CancelFunc) {
+	return signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGQUIT, syscall.SIGTERM)
+}
+
+// ServeGRPC serves gRPC calls until given context is done, then server stops accepting calls and waits for pending
+// ones up to given timeout before they are cancelled.
+func ServeGRPC(ctx context.Context, server *grpc.Server, serve func() error, timeout time.Duration) error {
+	served := make(chan error, 1)
+	go func() {
+		served <- serve()
+	}()
+
+	select {
+	case err := <-served:
+		return err
+	case <-ctx.Done():
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		server.GracefulStop()
+		close(stopped)
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-stopped:
+		return nil
+	case <-timer.C:
+		server.Stop()
+		return context.DeadlineExceeded
+	}
+}
+
+// ServeHTTP serves HTTP requests until given context is done, then server stops accepting requests and waits for
+// pending ones up to given timeout.
+func ServeHTTP(ctx context.Context, server *http.Server, timeout time.Duration) error {
+	served := make(chan error, 1)
+	go func() {
+		served <- server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-served:
+		return err
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		server.Close()
+		return err
+	}
+	return nil
+}
+
+// Wait waits for given wait group until given context is done.
+func Wait(ctx context.Context, wg *sync.WaitGroup) error {
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,66 @@
+package helpers
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+)
+
+func TestWait(t *testing.T) {
+	cases := []struct {
+		Name        string
+		Pending     time.Duration
+		ExpectedErr string
+	}{
+		{
+			Name:    "Pending work finished",
+			Pending: time.Millisecond,
+		},
+		{
+			Name:        "Pending work abandoned",
+			Pending:     time.Second,
+			ExpectedErr: "context deadline exceeded",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			var wg sync.WaitGroup
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				time.Sleep(tc.Pending)
+			}()
+
+			ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+			defer cancel()
+			AssertErrors(t, tc.ExpectedErr, Wait(ctx, &wg))
+		})
+	}
+}
+
+func TestServeGRPC(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	server := grpc.NewServer()
+	ctx, cancel := context.WithCancel(context.Background())
+
+	stopped := make(chan error, 1)
+	go func() {
+		stopped <- ServeGRPC(ctx, server, func() error { return server.Serve(listener) }, time.Second)
+	}()
+
+	cancel()
+	select {
+	case err := <-stopped:
+		assert.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("server isn't stopped")
+	}
+}
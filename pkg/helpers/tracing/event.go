@@ -0,0 +1,64 @@
+package tracing
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/migotom/cell-centre-services/pkg/pb"
+)
+
+// Stamp sets trace context of span carried by context on event.
+func Stamp(ctx context.Context, e *pb.Event) {
+	carrier := propagation.MapCarrier{}
+	otel.GetTextMapPropagator().Inject(ctx, carrier)
+	if len(carrier) > 0 {
+		e.TraceContext = carrier
+	}
+}
+
+// NewContextFromEvent returns context continuing trace carried by event.
+func NewContextFromEvent(ctx context.Context, e *pb.Event) context.Context {
+	return otel.GetTextMapPropagator().Extract(ctx, propagation.MapCarrier(e.GetTraceContext()))
+}
+
+// StartPublish starts span of publishing event on channel, continuing trace carried by event. Event carries
+// trace context of publishing span afterwards.
+func StartPublish(channel string, e *pb.Event) trace.Span {
+	ctx, span := tracer().Start(NewContextFromEvent(context.Background(), e), channel+" publish",
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(messageAttributes(channel, e)...),
+	)
+	Stamp(ctx, e)
+	return span
+}
+
+// StartConsume starts span of consuming event delivered on channel, continuing trace of its publisher.
+func StartConsume(ctx context.Context, channel string, e *pb.Event) (context.Context, trace.Span) {
+	return tracer().Start(NewContextFromEvent(ctx, e), channel+" process",
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(messageAttributes(channel, e)...),
+	)
+}
+
+// End ends span, given error marks span as failed.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+func messageAttributes(channel string, e *pb.Event) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		semconv.MessagingSystemKey.String("nats"),
+		semconv.MessagingDestinationName(channel),
+		semconv.MessagingMessageID(e.GetEventId()),
+	}
+}
@@ -0,0 +1,13 @@
+package tracing
+
+import (
+	"net/http"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+)
+
+// Handler traces requests served by given handler as operation, continuing traces of callers carried
+// by traceparent header.
+func Handler(handler http.Handler, operation string) http.Handler {
+	return otelhttp.NewHandler(handler, operation)
+}
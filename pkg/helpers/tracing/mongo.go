@@ -0,0 +1,62 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/event"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type mongoCommandKey struct {
+	connectionID string
+	requestID    int64
+}
+
+// MongoCommandMonitor returns MongoDB driver's command monitor tracing commands run within traced context,
+// events are passed to next monitor as well.
+func MongoCommandMonitor(next *event.CommandMonitor) *event.CommandMonitor {
+	var spans sync.Map
+
+	return &event.CommandMonitor{
+		Started: func(ctx context.Context, e *event.CommandStartedEvent) {
+			if trace.SpanContextFromContext(ctx).IsValid() {
+				_, span := tracer().Start(ctx, "mongodb."+e.CommandName,
+					trace.WithSpanKind(trace.SpanKindClient),
+					trace.WithAttributes(semconv.DBSystemMongoDB, semconv.DBName(e.DatabaseName), semconv.DBOperation(e.CommandName)),
+				)
+				spans.Store(mongoCommandKey{connectionID: e.ConnectionID, requestID: e.RequestID}, span)
+			}
+			if next != nil && next.Started != nil {
+				next.Started(ctx, e)
+			}
+		},
+		Succeeded: func(ctx context.Context, e *event.CommandSucceededEvent) {
+			endMongoCommand(&spans, e.CommandFinishedEvent, "")
+			if next != nil && next.Succeeded != nil {
+				next.Succeeded(ctx, e)
+			}
+		},
+		Failed: func(ctx context.Context, e *event.CommandFailedEvent) {
+			endMongoCommand(&spans, e.CommandFinishedEvent, e.Failure)
+			if next != nil && next.Failed != nil {
+				next.Failed(ctx, e)
+			}
+		},
+	}
+}
+
+func endMongoCommand(spans *sync.Map, e event.CommandFinishedEvent, failure string) {
+	span, ok := spans.LoadAndDelete(mongoCommandKey{connectionID: e.ConnectionID, requestID: e.RequestID})
+	if !ok {
+		return
+	}
+
+	var err error
+	if failure != "" {
+		err = errors.New(failure)
+	}
+	End(span.(trace.Span), err)
+}
@@ -0,0 +1,105 @@
+// Package tracing traces requests across services with OpenTelemetry, trace context travels in gRPC metadata
+// and inside published events.
+package tracing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// ExporterOTLP exports spans over OTLP/HTTP.
+	ExporterOTLP = "otlp"
+	// ExporterStdout writes spans to standard output, for local use.
+	ExporterStdout = "stdout"
+)
+
+const instrumentationName = "github.com/migotom/cell-centre-services"
+
+// Config of tracing.
+type Config struct {
+	// Exporter of spans, spans aren't recorded when empty while trace context is still propagated.
+	Exporter string `toml:"exporter"`
+	// OTLPEndpoint is URL of OTLP/HTTP collector, OTEL_EXPORTER_OTLP_* environment variables are used when empty.
+	OTLPEndpoint string `toml:"otlp_endpoint"`
+	// SampleRatio of traces started by service, traces of callers keep their sampling decision. All traces
+	// are sampled when it isn't set, zero samples none of them.
+	SampleRatio *float64 `toml:"sample_ratio"`
+}
+
+// SetDefaults sets default values of not configured options.
+func (config *Config) SetDefaults() {
+	if config.SampleRatio == nil {
+		ratio := 1.0
+		config.SampleRatio = &ratio
+	}
+}
+
+// Validate returns errors of invalid options.
+func (config *Config) Validate() error {
+	var errs []error
+	switch config.Exporter {
+	case "", ExporterOTLP, ExporterStdout:
+	default:
+		errs = append(errs, fmt.Errorf("tracing.exporter %q is unknown, expected %q or %q", config.Exporter, ExporterOTLP, ExporterStdout))
+	}
+	if ratio := config.sampleRatio(); ratio < 0 || ratio > 1 {
+		errs = append(errs, fmt.Errorf("tracing.sample_ratio %v isn't between 0 and 1", ratio))
+	}
+	return errors.Join(errs...)
+}
+
+func (config *Config) sampleRatio() float64 {
+	if config.SampleRatio == nil {
+		return 1
+	}
+	return *config.SampleRatio
+}
+
+// Setup installs global tracer provider of given service and W3C trace context propagator. Returned shutdown
+// exports spans which are still buffered.
+func Setup(ctx context.Context, service string, config Config) (shutdown func(context.Context) error, err error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	var exporter sdktrace.SpanExporter
+	switch config.Exporter {
+	case "":
+		return func(context.Context) error { return nil }, nil
+	case ExporterOTLP:
+		var options []otlptracehttp.Option
+		if config.OTLPEndpoint != "" {
+			options = append(options, otlptracehttp.WithEndpointURL(config.OTLPEndpoint))
+		}
+		exporter, err = otlptracehttp.New(ctx, options...)
+	case ExporterStdout:
+		exporter, err = stdouttrace.New(stdouttrace.WithPrettyPrint())
+	default:
+		return nil, fmt.Errorf("unknown tracing exporter %q", config.Exporter)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("can't create tracing exporter: %v", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(config.sampleRatio()))),
+		sdktrace.WithResource(resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceName(service))),
+	)
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
+
+// tracer returns tracer of global provider, so spans are recorded by provider installed after tracer was needed.
+func tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
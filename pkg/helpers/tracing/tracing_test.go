@@ -0,0 +1,170 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/event"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/migotom/cell-centre-services/pkg/helpers"
+	"github.com/migotom/cell-centre-services/pkg/pb"
+)
+
+func TestConfigDefaults(t *testing.T) {
+	cases := []struct {
+		Name          string
+		Config        Config
+		ExpectedRatio float64
+		ExpectedErr   string
+	}{
+		{
+			Name:          "Unset ratio samples all traces",
+			Config:        Config{Exporter: ExporterOTLP},
+			ExpectedRatio: 1,
+		},
+		{
+			Name:          "Zero ratio is kept",
+			Config:        Config{Exporter: ExporterOTLP, SampleRatio: ratio(0)},
+			ExpectedRatio: 0,
+		},
+		{
+			Name:          "Ratio out of range",
+			Config:        Config{Exporter: ExporterOTLP, SampleRatio: ratio(1.5)},
+			ExpectedRatio: 1.5,
+			ExpectedErr:   "tracing.sample_ratio 1.5 isn't between 0 and 1",
+		},
+		{
+			Name:          "Unknown exporter",
+			Config:        Config{Exporter: "jaeger"},
+			ExpectedRatio: 1,
+			ExpectedErr:   `tracing.exporter "jaeger" is unknown, expected "otlp" or "stdout"`,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			tc.Config.SetDefaults()
+			helpers.AssertErrors(t, tc.ExpectedErr, tc.Config.Validate())
+			require.NotNil(t, tc.Config.SampleRatio)
+			assert.Equal(t, tc.ExpectedRatio, *tc.Config.SampleRatio)
+		})
+	}
+}
+
+func ratio(value float64) *float64 {
+	return &value
+}
+
+func TestEventTraceContext(t *testing.T) {
+	recorder := recordSpans(t)
+
+	ctx, request := otel.Tracer("test").Start(context.Background(), "request")
+	var e pb.Event
+	Stamp(ctx, &e)
+	request.End()
+
+	publish := StartPublish("employees", &e)
+	publish.End()
+
+	_, consume := StartConsume(context.Background(), "employees", &e)
+	consume.End()
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 3)
+	assert.Equal(t, request.SpanContext().TraceID(), spans[1].SpanContext().TraceID())
+	assert.Equal(t, request.SpanContext().SpanID(), spans[1].Parent().SpanID())
+	assert.Equal(t, trace.SpanKindProducer, spans[1].SpanKind())
+	assert.Equal(t, spans[1].SpanContext().SpanID(), spans[2].Parent().SpanID())
+	assert.Equal(t, trace.SpanKindConsumer, spans[2].SpanKind())
+}
+
+func TestStampWithoutSpan(t *testing.T) {
+	recordSpans(t)
+
+	var e pb.Event
+	Stamp(context.Background(), &e)
+	assert.Nil(t, e.TraceContext)
+}
+
+func TestMongoCommandMonitor(t *testing.T) {
+	cases := []struct {
+		Name           string
+		Traced         bool
+		Failure        string
+		ExpectedSpans  int
+		ExpectedStatus codes.Code
+	}{
+		{
+			Name:           "Command of traced request",
+			Traced:         true,
+			ExpectedSpans:  2,
+			ExpectedStatus: codes.Unset,
+		},
+		{
+			Name:           "Failed command of traced request",
+			Traced:         true,
+			Failure:        "duplicate key",
+			ExpectedSpans:  2,
+			ExpectedStatus: codes.Error,
+		},
+		{
+			Name:          "Command without trace",
+			ExpectedSpans: 0,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			recorder := recordSpans(t)
+
+			var finished int
+			monitor := MongoCommandMonitor(&event.CommandMonitor{
+				Succeeded: func(context.Context, *event.CommandSucceededEvent) { finished++ },
+				Failed:    func(context.Context, *event.CommandFailedEvent) { finished++ },
+			})
+
+			ctx := context.Background()
+			var request trace.Span
+			if tc.Traced {
+				ctx, request = otel.Tracer("test").Start(ctx, "request")
+			}
+
+			monitor.Started(ctx, &event.CommandStartedEvent{CommandName: "insert", RequestID: 1, ConnectionID: "mongodb:27017[-1]"})
+			finishedEvent := event.CommandFinishedEvent{CommandName: "insert", RequestID: 1, ConnectionID: "mongodb:27017[-1]"}
+			if tc.Failure != "" {
+				monitor.Failed(ctx, &event.CommandFailedEvent{CommandFinishedEvent: finishedEvent, Failure: tc.Failure})
+			} else {
+				monitor.Succeeded(ctx, &event.CommandSucceededEvent{CommandFinishedEvent: finishedEvent})
+			}
+			if request != nil {
+				request.End()
+			}
+
+			assert.Equal(t, 1, finished)
+			spans := recorder.Ended()
+			require.Len(t, spans, tc.ExpectedSpans)
+			if tc.ExpectedSpans > 0 {
+				assert.Equal(t, "mongodb.insert", spans[0].Name())
+				assert.Equal(t, tc.ExpectedStatus, spans[0].Status().Code)
+			}
+		})
+	}
+}
+
+// recordSpans installs global tracer provider recording spans for duration of test.
+func recordSpans(t *testing.T) *tracetest.SpanRecorder {
+	_, err := Setup(context.Background(), "test", Config{})
+	require.NoError(t, err)
+
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+	return recorder
+}
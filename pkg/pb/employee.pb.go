@@ -36,6 +36,7 @@ type Employee struct {
 	Roles                []*Role              `protobuf:"bytes,6,rep,name=roles,proto3" json:"roles,omitempty"`
 	CreatedAt            *timestamp.Timestamp `protobuf:"bytes,7,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
 	UpdatedAt            *timestamp.Timestamp `protobuf:"bytes,8,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	Team                 string               `protobuf:"bytes,9,opt,name=team,proto3" json:"team,omitempty"`
 	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
 	XXX_unrecognized     []byte               `json:"-"`
 	XXX_sizecache        int32                `json:"-"`
@@ -122,6 +123,13 @@ func (m *Employee) GetUpdatedAt() *timestamp.Timestamp {
 	return nil
 }
 
+func (m *Employee) GetTeam() string {
+	if m != nil {
+		return m.Team
+	}
+	return ""
+}
+
 type EmployeeFilter struct {
 	Id                   string   `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
 	Email                string   `protobuf:"bytes,2,opt,name=email,proto3" json:"email,omitempty"`
@@ -175,6 +183,7 @@ type NewEmployeeRequest struct {
 	Password             string   `protobuf:"bytes,3,opt,name=password,proto3" json:"password,omitempty"`
 	Phone                string   `protobuf:"bytes,4,opt,name=phone,proto3" json:"phone,omitempty"`
 	Roles                []*Role  `protobuf:"bytes,5,rep,name=roles,proto3" json:"roles,omitempty"`
+	Team                 string   `protobuf:"bytes,6,opt,name=team,proto3" json:"team,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -240,6 +249,13 @@ func (m *NewEmployeeRequest) GetRoles() []*Role {
 	return nil
 }
 
+func (m *NewEmployeeRequest) GetTeam() string {
+	if m != nil {
+		return m.Team
+	}
+	return ""
+}
+
 type UpdateEmployeeRequest struct {
 	Id                   string   `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
 	Email                string   `protobuf:"bytes,2,opt,name=email,proto3" json:"email,omitempty"`
@@ -247,6 +263,7 @@ type UpdateEmployeeRequest struct {
 	Password             string   `protobuf:"bytes,4,opt,name=password,proto3" json:"password,omitempty"`
 	Phone                string   `protobuf:"bytes,5,opt,name=phone,proto3" json:"phone,omitempty"`
 	Roles                []*Role  `protobuf:"bytes,6,rep,name=roles,proto3" json:"roles,omitempty"`
+	Team                 string   `protobuf:"bytes,7,opt,name=team,proto3" json:"team,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -319,43 +336,416 @@ func (m *UpdateEmployeeRequest) GetRoles() []*Role {
 	return nil
 }
 
+func (m *UpdateEmployeeRequest) GetTeam() string {
+	if m != nil {
+		return m.Team
+	}
+	return ""
+}
+
+// FieldChange describes change of single employee field, values of sensitive fields are redacted.
+type FieldChange struct {
+	Path                 string   `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	OldValue             string   `protobuf:"bytes,2,opt,name=old_value,json=oldValue,proto3" json:"old_value,omitempty"`
+	NewValue             string   `protobuf:"bytes,3,opt,name=new_value,json=newValue,proto3" json:"new_value,omitempty"`
+	Redacted             bool     `protobuf:"varint,4,opt,name=redacted,proto3" json:"redacted,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *FieldChange) Reset()         { *m = FieldChange{} }
+func (m *FieldChange) String() string { return proto.CompactTextString(m) }
+func (*FieldChange) ProtoMessage()    {}
+func (*FieldChange) Descriptor() ([]byte, []int) {
+	return fileDescriptor_eb50a19aa79a6eac, []int{4}
+}
+
+func (m *FieldChange) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_FieldChange.Unmarshal(m, b)
+}
+func (m *FieldChange) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_FieldChange.Marshal(b, m, deterministic)
+}
+func (m *FieldChange) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_FieldChange.Merge(m, src)
+}
+func (m *FieldChange) XXX_Size() int {
+	return xxx_messageInfo_FieldChange.Size(m)
+}
+func (m *FieldChange) XXX_DiscardUnknown() {
+	xxx_messageInfo_FieldChange.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_FieldChange proto.InternalMessageInfo
+
+func (m *FieldChange) GetPath() string {
+	if m != nil {
+		return m.Path
+	}
+	return ""
+}
+
+func (m *FieldChange) GetOldValue() string {
+	if m != nil {
+		return m.OldValue
+	}
+	return ""
+}
+
+func (m *FieldChange) GetNewValue() string {
+	if m != nil {
+		return m.NewValue
+	}
+	return ""
+}
+
+func (m *FieldChange) GetRedacted() bool {
+	if m != nil {
+		return m.Redacted
+	}
+	return false
+}
+
+type EmployeeChanges struct {
+	Id                   string         `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Changes              []*FieldChange `protobuf:"bytes,2,rep,name=changes,proto3" json:"changes,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}       `json:"-"`
+	XXX_unrecognized     []byte         `json:"-"`
+	XXX_sizecache        int32          `json:"-"`
+}
+
+func (m *EmployeeChanges) Reset()         { *m = EmployeeChanges{} }
+func (m *EmployeeChanges) String() string { return proto.CompactTextString(m) }
+func (*EmployeeChanges) ProtoMessage()    {}
+func (*EmployeeChanges) Descriptor() ([]byte, []int) {
+	return fileDescriptor_eb50a19aa79a6eac, []int{5}
+}
+
+func (m *EmployeeChanges) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_EmployeeChanges.Unmarshal(m, b)
+}
+func (m *EmployeeChanges) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_EmployeeChanges.Marshal(b, m, deterministic)
+}
+func (m *EmployeeChanges) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_EmployeeChanges.Merge(m, src)
+}
+func (m *EmployeeChanges) XXX_Size() int {
+	return xxx_messageInfo_EmployeeChanges.Size(m)
+}
+func (m *EmployeeChanges) XXX_DiscardUnknown() {
+	xxx_messageInfo_EmployeeChanges.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_EmployeeChanges proto.InternalMessageInfo
+
+func (m *EmployeeChanges) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *EmployeeChanges) GetChanges() []*FieldChange {
+	if m != nil {
+		return m.Changes
+	}
+	return nil
+}
+
+// ListEmployeesRequest searches employee directory, empty status selects active employees.
+type ListEmployeesRequest struct {
+	Query                string   `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`
+	Role                 string   `protobuf:"bytes,2,opt,name=role,proto3" json:"role,omitempty"`
+	Team                 string   `protobuf:"bytes,3,opt,name=team,proto3" json:"team,omitempty"`
+	Status               string   `protobuf:"bytes,4,opt,name=status,proto3" json:"status,omitempty"`
+	PageSize             int32    `protobuf:"varint,5,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	PageToken            string   `protobuf:"bytes,6,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ListEmployeesRequest) Reset()         { *m = ListEmployeesRequest{} }
+func (m *ListEmployeesRequest) String() string { return proto.CompactTextString(m) }
+func (*ListEmployeesRequest) ProtoMessage()    {}
+func (*ListEmployeesRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_eb50a19aa79a6eac, []int{6}
+}
+
+func (m *ListEmployeesRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListEmployeesRequest.Unmarshal(m, b)
+}
+func (m *ListEmployeesRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListEmployeesRequest.Marshal(b, m, deterministic)
+}
+func (m *ListEmployeesRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListEmployeesRequest.Merge(m, src)
+}
+func (m *ListEmployeesRequest) XXX_Size() int {
+	return xxx_messageInfo_ListEmployeesRequest.Size(m)
+}
+func (m *ListEmployeesRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListEmployeesRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListEmployeesRequest proto.InternalMessageInfo
+
+func (m *ListEmployeesRequest) GetQuery() string {
+	if m != nil {
+		return m.Query
+	}
+	return ""
+}
+
+func (m *ListEmployeesRequest) GetRole() string {
+	if m != nil {
+		return m.Role
+	}
+	return ""
+}
+
+func (m *ListEmployeesRequest) GetTeam() string {
+	if m != nil {
+		return m.Team
+	}
+	return ""
+}
+
+func (m *ListEmployeesRequest) GetStatus() string {
+	if m != nil {
+		return m.Status
+	}
+	return ""
+}
+
+func (m *ListEmployeesRequest) GetPageSize() int32 {
+	if m != nil {
+		return m.PageSize
+	}
+	return 0
+}
+
+func (m *ListEmployeesRequest) GetPageToken() string {
+	if m != nil {
+		return m.PageToken
+	}
+	return ""
+}
+
+// DirectoryEntry is employee as listed by employee directory.
+type DirectoryEntry struct {
+	Id                   string               `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Email                string               `protobuf:"bytes,2,opt,name=email,proto3" json:"email,omitempty"`
+	Name                 string               `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
+	Phone                string               `protobuf:"bytes,4,opt,name=phone,proto3" json:"phone,omitempty"`
+	Team                 string               `protobuf:"bytes,5,opt,name=team,proto3" json:"team,omitempty"`
+	Status               string               `protobuf:"bytes,6,opt,name=status,proto3" json:"status,omitempty"`
+	Roles                []string             `protobuf:"bytes,7,rep,name=roles,proto3" json:"roles,omitempty"`
+	CreatedAt            *timestamp.Timestamp `protobuf:"bytes,8,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt            *timestamp.Timestamp `protobuf:"bytes,9,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
+}
+
+func (m *DirectoryEntry) Reset()         { *m = DirectoryEntry{} }
+func (m *DirectoryEntry) String() string { return proto.CompactTextString(m) }
+func (*DirectoryEntry) ProtoMessage()    {}
+func (*DirectoryEntry) Descriptor() ([]byte, []int) {
+	return fileDescriptor_eb50a19aa79a6eac, []int{7}
+}
+
+func (m *DirectoryEntry) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_DirectoryEntry.Unmarshal(m, b)
+}
+func (m *DirectoryEntry) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_DirectoryEntry.Marshal(b, m, deterministic)
+}
+func (m *DirectoryEntry) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DirectoryEntry.Merge(m, src)
+}
+func (m *DirectoryEntry) XXX_Size() int {
+	return xxx_messageInfo_DirectoryEntry.Size(m)
+}
+func (m *DirectoryEntry) XXX_DiscardUnknown() {
+	xxx_messageInfo_DirectoryEntry.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DirectoryEntry proto.InternalMessageInfo
+
+func (m *DirectoryEntry) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *DirectoryEntry) GetEmail() string {
+	if m != nil {
+		return m.Email
+	}
+	return ""
+}
+
+func (m *DirectoryEntry) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *DirectoryEntry) GetPhone() string {
+	if m != nil {
+		return m.Phone
+	}
+	return ""
+}
+
+func (m *DirectoryEntry) GetTeam() string {
+	if m != nil {
+		return m.Team
+	}
+	return ""
+}
+
+func (m *DirectoryEntry) GetStatus() string {
+	if m != nil {
+		return m.Status
+	}
+	return ""
+}
+
+func (m *DirectoryEntry) GetRoles() []string {
+	if m != nil {
+		return m.Roles
+	}
+	return nil
+}
+
+func (m *DirectoryEntry) GetCreatedAt() *timestamp.Timestamp {
+	if m != nil {
+		return m.CreatedAt
+	}
+	return nil
+}
+
+func (m *DirectoryEntry) GetUpdatedAt() *timestamp.Timestamp {
+	if m != nil {
+		return m.UpdatedAt
+	}
+	return nil
+}
+
+type ListEmployeesResponse struct {
+	Employees            []*DirectoryEntry `protobuf:"bytes,1,rep,name=employees,proto3" json:"employees,omitempty"`
+	NextPageToken        string            `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
+	XXX_unrecognized     []byte            `json:"-"`
+	XXX_sizecache        int32             `json:"-"`
+}
+
+func (m *ListEmployeesResponse) Reset()         { *m = ListEmployeesResponse{} }
+func (m *ListEmployeesResponse) String() string { return proto.CompactTextString(m) }
+func (*ListEmployeesResponse) ProtoMessage()    {}
+func (*ListEmployeesResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_eb50a19aa79a6eac, []int{8}
+}
+
+func (m *ListEmployeesResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListEmployeesResponse.Unmarshal(m, b)
+}
+func (m *ListEmployeesResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListEmployeesResponse.Marshal(b, m, deterministic)
+}
+func (m *ListEmployeesResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListEmployeesResponse.Merge(m, src)
+}
+func (m *ListEmployeesResponse) XXX_Size() int {
+	return xxx_messageInfo_ListEmployeesResponse.Size(m)
+}
+func (m *ListEmployeesResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListEmployeesResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListEmployeesResponse proto.InternalMessageInfo
+
+func (m *ListEmployeesResponse) GetEmployees() []*DirectoryEntry {
+	if m != nil {
+		return m.Employees
+	}
+	return nil
+}
+
+func (m *ListEmployeesResponse) GetNextPageToken() string {
+	if m != nil {
+		return m.NextPageToken
+	}
+	return ""
+}
+
 func init() {
 	proto.RegisterType((*Employee)(nil), "pb.Employee")
 	proto.RegisterType((*EmployeeFilter)(nil), "pb.EmployeeFilter")
 	proto.RegisterType((*NewEmployeeRequest)(nil), "pb.NewEmployeeRequest")
 	proto.RegisterType((*UpdateEmployeeRequest)(nil), "pb.UpdateEmployeeRequest")
+	proto.RegisterType((*FieldChange)(nil), "pb.FieldChange")
+	proto.RegisterType((*EmployeeChanges)(nil), "pb.EmployeeChanges")
+	proto.RegisterType((*ListEmployeesRequest)(nil), "pb.ListEmployeesRequest")
+	proto.RegisterType((*DirectoryEntry)(nil), "pb.DirectoryEntry")
+	proto.RegisterType((*ListEmployeesResponse)(nil), "pb.ListEmployeesResponse")
 }
 
 func init() { proto.RegisterFile("employee.proto", fileDescriptor_eb50a19aa79a6eac) }
 
 var fileDescriptor_eb50a19aa79a6eac = []byte{
-	// 408 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xcc, 0x93, 0x41, 0x6f, 0x9b, 0x30,
-	0x14, 0xc7, 0x03, 0x09, 0x19, 0x79, 0x4c, 0x4c, 0xb2, 0xb6, 0x88, 0xb1, 0x69, 0x8b, 0x38, 0xe5,
-	0x44, 0xb4, 0x4c, 0x9b, 0x34, 0x4d, 0x9a, 0x14, 0x69, 0xd9, 0x6e, 0x3d, 0xd0, 0xf6, 0x5c, 0x99,
-	0xf0, 0x9a, 0x5a, 0x02, 0xec, 0x82, 0xd3, 0x28, 0xdf, 0xa1, 0x87, 0x7e, 0x87, 0x7e, 0xc9, 0x1e,
-	0x2b, 0x0c, 0xa4, 0x49, 0x20, 0x6a, 0x8f, 0xbd, 0xe1, 0xbf, 0xff, 0x7f, 0xfb, 0xbd, 0xdf, 0x33,
-	0x60, 0x63, 0x22, 0x62, 0xbe, 0x41, 0xf4, 0x45, 0xc6, 0x25, 0x27, 0xba, 0x08, 0xdd, 0xaf, 0x4b,
-	0xce, 0x97, 0x31, 0x4e, 0x94, 0x12, 0xae, 0x2e, 0x27, 0x92, 0x25, 0x98, 0x4b, 0x9a, 0x88, 0xd2,
-	0xe4, 0x7e, 0xae, 0x0c, 0x54, 0xb0, 0x09, 0x4d, 0x53, 0x2e, 0xa9, 0x64, 0x3c, 0xcd, 0xab, 0xdd,
-	0x4f, 0x87, 0x71, 0x4c, 0x84, 0xdc, 0x54, 0x9b, 0x90, 0xf1, 0xb8, 0xba, 0xcb, 0xbb, 0xd5, 0xc1,
-	0x9c, 0x57, 0xd7, 0x13, 0x1b, 0x74, 0x16, 0x39, 0xda, 0x48, 0x1b, 0x0f, 0x02, 0x9d, 0x45, 0xe4,
-	0x3d, 0x18, 0x98, 0x50, 0x16, 0x3b, 0xba, 0x92, 0xca, 0x05, 0x21, 0xd0, 0x4b, 0x69, 0x82, 0x4e,
-	0x57, 0x89, 0xea, 0x9b, 0xb8, 0x60, 0x0a, 0x9a, 0xe7, 0x6b, 0x9e, 0x45, 0x4e, 0x4f, 0xe9, 0xdb,
-	0x75, 0x71, 0x8a, 0xb8, 0xe2, 0x29, 0x3a, 0x46, 0x79, 0x8a, 0x5a, 0x90, 0x2f, 0x60, 0x14, 0x65,
-	0xe4, 0x4e, 0x7f, 0xd4, 0x1d, 0x5b, 0x53, 0xd3, 0x17, 0xa1, 0x1f, 0xf0, 0x18, 0x83, 0x52, 0x26,
-	0xbf, 0x00, 0x16, 0x19, 0x52, 0x89, 0xd1, 0x05, 0x95, 0xce, 0x9b, 0x91, 0x36, 0xb6, 0xa6, 0xae,
-	0x5f, 0xb6, 0xe5, 0xd7, 0x6d, 0xf9, 0x67, 0x35, 0x95, 0x60, 0x50, 0xb9, 0x67, 0xb2, 0x88, 0xae,
-	0x44, 0x54, 0x47, 0xcd, 0xe7, 0xa3, 0x95, 0x7b, 0x26, 0xbd, 0x9f, 0x60, 0xd7, 0x34, 0xfe, 0xb1,
-	0x58, 0x62, 0xf6, 0x32, 0x26, 0xde, 0x9d, 0x06, 0xe4, 0x04, 0xd7, 0x75, 0x36, 0xc0, 0xeb, 0x15,
-	0xe6, 0xf2, 0xc9, 0xac, 0xb5, 0x01, 0xd4, 0x8f, 0x00, 0xec, 0x1e, 0x03, 0xd8, 0x6b, 0x05, 0x68,
-	0xb4, 0x02, 0xf4, 0xee, 0x35, 0xf8, 0x70, 0xae, 0x1a, 0x3b, 0xac, 0xea, 0x15, 0x8d, 0x79, 0xfa,
-	0xa0, 0xc1, 0xbb, 0xba, 0xbe, 0x53, 0xcc, 0x6e, 0xd8, 0x02, 0xc9, 0x37, 0xb0, 0xfe, 0xa3, 0xdc,
-	0xbe, 0x4a, 0x52, 0x64, 0xf6, 0xa7, 0xe2, 0xbe, 0xdd, 0xd5, 0xbc, 0x0e, 0xf9, 0x01, 0xd6, 0x0e,
-	0x7e, 0x32, 0x2c, 0xb6, 0x9b, 0xf3, 0x68, 0xc4, 0x7e, 0x83, 0xbd, 0x8f, 0x88, 0x7c, 0x2c, 0x1c,
-	0xad, 0xd8, 0x1a, 0xe1, 0x3f, 0x60, 0xff, 0xc5, 0x18, 0x77, 0xc2, 0x6d, 0x95, 0x0e, 0x1b, 0x0f,
-	0x6f, 0x5e, 0xfc, 0x8a, 0x5e, 0x27, 0xec, 0x2b, 0xe5, 0xfb, 0x63, 0x00, 0x00, 0x00, 0xff, 0xff,
-	0x15, 0x63, 0x60, 0x8f, 0xff, 0x03, 0x00, 0x00,
+	// 707 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xcc, 0x55, 0xcd, 0x6e, 0xd3, 0x40,
+	0x10, 0xae, 0x9d, 0x26, 0x71, 0x26, 0x34, 0x95, 0x56, 0x6d, 0xe5, 0xa6, 0xfc, 0x44, 0x3e, 0xa0,
+	0x72, 0x49, 0xa1, 0x08, 0x24, 0x84, 0x40, 0xaa, 0x68, 0xca, 0xa5, 0x42, 0xc8, 0x2d, 0x5c, 0xa3,
+	0x4d, 0x3c, 0xa4, 0x16, 0xb6, 0xd7, 0xf5, 0x6e, 0x1a, 0xd2, 0x27, 0xe1, 0xc8, 0x81, 0x03, 0x6f,
+	0xc0, 0xe3, 0x81, 0x76, 0xbd, 0x9b, 0x38, 0x8d, 0xcb, 0x4f, 0xb9, 0x70, 0xdb, 0xf9, 0xdb, 0x9d,
+	0xef, 0x9b, 0xcf, 0x63, 0x68, 0x61, 0x9c, 0x46, 0x6c, 0x8a, 0xd8, 0x4d, 0x33, 0x26, 0x18, 0xb1,
+	0xd3, 0x41, 0xfb, 0xde, 0x88, 0xb1, 0x51, 0x84, 0x7b, 0xca, 0x33, 0x18, 0x7f, 0xd8, 0x13, 0x61,
+	0x8c, 0x5c, 0xd0, 0x38, 0xcd, 0x93, 0xda, 0xb7, 0x75, 0x02, 0x4d, 0xc3, 0x3d, 0x9a, 0x24, 0x4c,
+	0x50, 0x11, 0xb2, 0x84, 0xeb, 0xe8, 0xce, 0xd5, 0x72, 0x8c, 0x53, 0x31, 0xd5, 0x41, 0xc8, 0x58,
+	0xa4, 0xdf, 0xf2, 0xbe, 0xd8, 0xe0, 0xf4, 0xf4, 0xf3, 0xa4, 0x05, 0x76, 0x18, 0xb8, 0x56, 0xc7,
+	0xda, 0x6d, 0xf8, 0x76, 0x18, 0x90, 0x0d, 0xa8, 0x62, 0x4c, 0xc3, 0xc8, 0xb5, 0x95, 0x2b, 0x37,
+	0x08, 0x81, 0xd5, 0x84, 0xc6, 0xe8, 0x56, 0x94, 0x53, 0x9d, 0x49, 0x1b, 0x9c, 0x94, 0x72, 0x3e,
+	0x61, 0x59, 0xe0, 0xae, 0x2a, 0xff, 0xcc, 0x96, 0xb7, 0xa4, 0x67, 0x2c, 0x41, 0xb7, 0x9a, 0xdf,
+	0xa2, 0x0c, 0x72, 0x17, 0xaa, 0xb2, 0x0d, 0xee, 0xd6, 0x3a, 0x95, 0xdd, 0xe6, 0xbe, 0xd3, 0x4d,
+	0x07, 0x5d, 0x9f, 0x45, 0xe8, 0xe7, 0x6e, 0xf2, 0x0c, 0x60, 0x98, 0x21, 0x15, 0x18, 0xf4, 0xa9,
+	0x70, 0xeb, 0x1d, 0x6b, 0xb7, 0xb9, 0xdf, 0xee, 0xe6, 0xb0, 0xba, 0x06, 0x56, 0xf7, 0xd4, 0xb0,
+	0xe2, 0x37, 0x74, 0xf6, 0x81, 0x90, 0xa5, 0xe3, 0x34, 0x30, 0xa5, 0xce, 0xef, 0x4b, 0x75, 0xf6,
+	0x81, 0x90, 0xd8, 0x04, 0xd2, 0xd8, 0x6d, 0xe4, 0xd8, 0xe4, 0xd9, 0x7b, 0x0a, 0x2d, 0xc3, 0xd0,
+	0x51, 0x18, 0x09, 0xcc, 0xfe, 0x8c, 0x27, 0xef, 0xab, 0x05, 0xe4, 0x0d, 0x4e, 0x4c, 0xad, 0x8f,
+	0xe7, 0x63, 0xe4, 0x62, 0x9e, 0x6c, 0x95, 0x91, 0x6a, 0x5f, 0x43, 0x6a, 0xe5, 0x3a, 0x52, 0x57,
+	0x4b, 0x49, 0xad, 0x96, 0x93, 0x6a, 0xe0, 0xd5, 0x0a, 0xf0, 0xbe, 0x5b, 0xb0, 0xf9, 0x4e, 0x11,
+	0x70, 0xb5, 0xd3, 0xff, 0x49, 0x0e, 0xa6, 0xf3, 0x7a, 0xa1, 0xf3, 0x09, 0x34, 0x8f, 0x42, 0x8c,
+	0x82, 0x57, 0x67, 0x34, 0x19, 0xa1, 0x4c, 0x49, 0xa9, 0x38, 0xd3, 0x0d, 0xab, 0x33, 0xd9, 0x81,
+	0x06, 0x8b, 0x82, 0xfe, 0x05, 0x8d, 0xc6, 0x86, 0x5b, 0x87, 0x45, 0xc1, 0x7b, 0x69, 0xcb, 0x60,
+	0x82, 0x13, 0x1d, 0xd4, 0x04, 0x27, 0x38, 0xc9, 0x83, 0x6d, 0x70, 0x32, 0x0c, 0xe8, 0x50, 0x60,
+	0x0e, 0xc1, 0xf1, 0x67, 0xb6, 0x77, 0x0c, 0xeb, 0x86, 0xab, 0xfc, 0x6d, 0xbe, 0xc4, 0xd5, 0x03,
+	0xa8, 0x0f, 0xf3, 0x90, 0x6b, 0x2b, 0x44, 0xeb, 0x12, 0x51, 0xa1, 0x5d, 0xdf, 0xc4, 0xbd, 0x6f,
+	0x16, 0x6c, 0x1c, 0x87, 0x5c, 0x98, 0x2b, 0x79, 0x41, 0x29, 0xe7, 0x63, 0xcc, 0xa6, 0x46, 0x29,
+	0xca, 0x90, 0x30, 0x25, 0x25, 0x46, 0x29, 0xf2, 0x3c, 0x63, 0xa7, 0x32, 0x67, 0x87, 0x6c, 0x41,
+	0x8d, 0x0b, 0x2a, 0xc6, 0x5c, 0x4f, 0x40, 0x5b, 0x12, 0x75, 0x4a, 0x47, 0xd8, 0xe7, 0xe1, 0x65,
+	0x3e, 0x83, 0xaa, 0x1c, 0xce, 0x08, 0x4f, 0xc2, 0x4b, 0x24, 0x77, 0x00, 0x54, 0x50, 0xb0, 0x8f,
+	0x98, 0x68, 0x99, 0xa8, 0xf4, 0x53, 0xe9, 0xf0, 0x3e, 0xdb, 0xd0, 0x3a, 0x0c, 0x33, 0x1c, 0x0a,
+	0x96, 0x4d, 0x7b, 0x89, 0xc8, 0xa6, 0xff, 0x20, 0x92, 0x72, 0x09, 0x1b, 0x28, 0xd5, 0x52, 0x28,
+	0xb5, 0x05, 0x28, 0x1b, 0x46, 0x34, 0xf5, 0x4e, 0x45, 0xde, 0x50, 0xb6, 0x39, 0x9c, 0x9b, 0x6f,
+	0x8e, 0xc6, 0x5f, 0x6c, 0x0e, 0xef, 0x1c, 0x36, 0xaf, 0x0c, 0x91, 0xa7, 0x2c, 0xe1, 0x48, 0x1e,
+	0x42, 0xc3, 0xec, 0x77, 0xee, 0x5a, 0x4a, 0x0b, 0x44, 0x6a, 0x61, 0x91, 0x47, 0x7f, 0x9e, 0x44,
+	0xee, 0xc3, 0x7a, 0x82, 0x9f, 0x44, 0xbf, 0x30, 0x89, 0x9c, 0xcc, 0x35, 0xe9, 0x7e, 0x6b, 0xa6,
+	0xb1, 0xff, 0xc3, 0x9e, 0xeb, 0xf0, 0x04, 0xb3, 0x8b, 0x70, 0x88, 0xe4, 0x11, 0x34, 0x5f, 0xe3,
+	0xac, 0x0b, 0xa2, 0x5e, 0x5a, 0xdc, 0x5e, 0xed, 0x5b, 0x45, 0x9f, 0xb7, 0x42, 0x9e, 0x40, 0xb3,
+	0xb0, 0xa6, 0xc8, 0x96, 0x0c, 0x2f, 0xef, 0xad, 0xa5, 0xb2, 0xe7, 0xd0, 0x5a, 0x5c, 0x1b, 0x64,
+	0x5b, 0x66, 0x94, 0xae, 0x92, 0xa5, 0xe2, 0x97, 0xd0, 0x3a, 0xc4, 0x08, 0x05, 0xfe, 0xb2, 0xd3,
+	0xad, 0x25, 0xea, 0x7b, 0xf2, 0x37, 0xe6, 0xad, 0x90, 0x23, 0x58, 0x5b, 0x60, 0x9b, 0xb8, 0xb2,
+	0xbc, 0xec, 0x2b, 0x6a, 0x6f, 0x97, 0x44, 0xf2, 0xd1, 0x78, 0x2b, 0xe4, 0x05, 0xac, 0xf5, 0x32,
+	0xca, 0x6f, 0xd8, 0xc6, 0xa0, 0xa6, 0x3c, 0x8f, 0x7f, 0x0e, 0x00, 0x42, 0xe2, 0xd0, 0xe7, 0xc2,
+	0x07, 0x00, 0x00,
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -374,6 +764,9 @@ type EmployeeServiceClient interface {
 	NewEmployee(ctx context.Context, in *NewEmployeeRequest, opts ...grpc.CallOption) (*Employee, error)
 	UpdateEmployee(ctx context.Context, in *UpdateEmployeeRequest, opts ...grpc.CallOption) (*Employee, error)
 	DeleteEmployee(ctx context.Context, in *EmployeeFilter, opts ...grpc.CallOption) (*empty.Empty, error)
+	ListEmployees(ctx context.Context, in *ListEmployeesRequest, opts ...grpc.CallOption) (*ListEmployeesResponse, error)
+	// EraseEmployee destroys data key of deleted employee, personal data carried by its events can't be read afterwards
+	EraseEmployee(ctx context.Context, in *EmployeeFilter, opts ...grpc.CallOption) (*empty.Empty, error)
 }
 
 type employeeServiceClient struct {
@@ -420,12 +813,33 @@ func (c *employeeServiceClient) DeleteEmployee(ctx context.Context, in *Employee
 	return out, nil
 }
 
+func (c *employeeServiceClient) ListEmployees(ctx context.Context, in *ListEmployeesRequest, opts ...grpc.CallOption) (*ListEmployeesResponse, error) {
+	out := new(ListEmployeesResponse)
+	err := c.cc.Invoke(ctx, "/pb.EmployeeService/ListEmployees", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *employeeServiceClient) EraseEmployee(ctx context.Context, in *EmployeeFilter, opts ...grpc.CallOption) (*empty.Empty, error) {
+	out := new(empty.Empty)
+	err := c.cc.Invoke(ctx, "/pb.EmployeeService/EraseEmployee", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // EmployeeServiceServer is the server API for EmployeeService service.
 type EmployeeServiceServer interface {
 	GetEmployee(context.Context, *EmployeeFilter) (*Employee, error)
 	NewEmployee(context.Context, *NewEmployeeRequest) (*Employee, error)
 	UpdateEmployee(context.Context, *UpdateEmployeeRequest) (*Employee, error)
 	DeleteEmployee(context.Context, *EmployeeFilter) (*empty.Empty, error)
+	ListEmployees(context.Context, *ListEmployeesRequest) (*ListEmployeesResponse, error)
+	// EraseEmployee destroys data key of deleted employee, personal data carried by its events can't be read afterwards
+	EraseEmployee(context.Context, *EmployeeFilter) (*empty.Empty, error)
 }
 
 // UnimplementedEmployeeServiceServer can be embedded to have forward compatible implementations.
@@ -444,6 +858,12 @@ func (*UnimplementedEmployeeServiceServer) UpdateEmployee(ctx context.Context, r
 func (*UnimplementedEmployeeServiceServer) DeleteEmployee(ctx context.Context, req *EmployeeFilter) (*empty.Empty, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method DeleteEmployee not implemented")
 }
+func (*UnimplementedEmployeeServiceServer) ListEmployees(ctx context.Context, req *ListEmployeesRequest) (*ListEmployeesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListEmployees not implemented")
+}
+func (*UnimplementedEmployeeServiceServer) EraseEmployee(ctx context.Context, req *EmployeeFilter) (*empty.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method EraseEmployee not implemented")
+}
 
 func RegisterEmployeeServiceServer(s *grpc.Server, srv EmployeeServiceServer) {
 	s.RegisterService(&_EmployeeService_serviceDesc, srv)
@@ -521,6 +941,42 @@ func _EmployeeService_DeleteEmployee_Handler(srv interface{}, ctx context.Contex
 	return interceptor(ctx, in, info, handler)
 }
 
+func _EmployeeService_ListEmployees_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListEmployeesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EmployeeServiceServer).ListEmployees(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.EmployeeService/ListEmployees",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EmployeeServiceServer).ListEmployees(ctx, req.(*ListEmployeesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _EmployeeService_EraseEmployee_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EmployeeFilter)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EmployeeServiceServer).EraseEmployee(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.EmployeeService/EraseEmployee",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EmployeeServiceServer).EraseEmployee(ctx, req.(*EmployeeFilter))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _EmployeeService_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "pb.EmployeeService",
 	HandlerType: (*EmployeeServiceServer)(nil),
@@ -541,6 +997,14 @@ var _EmployeeService_serviceDesc = grpc.ServiceDesc{
 			MethodName: "DeleteEmployee",
 			Handler:    _EmployeeService_DeleteEmployee_Handler,
 		},
+		{
+			MethodName: "ListEmployees",
+			Handler:    _EmployeeService_ListEmployees_Handler,
+		},
+		{
+			MethodName: "EraseEmployee",
+			Handler:    _EmployeeService_EraseEmployee_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "employee.proto",
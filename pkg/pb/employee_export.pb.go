@@ -0,0 +1,221 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// source: employee_export.proto
+
+package pb
+
+import (
+	context "context"
+	fmt "fmt"
+	proto "github.com/golang/protobuf/proto"
+	timestamp "github.com/golang/protobuf/ptypes/timestamp"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	math "math"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
+
+// EmployeeDataExport bundles personal data held about employee in answer to subject access request, password hashes
+// and secrets are excluded. Authentication keeps no records of logins and issued tokens aren't stored, so none are
+// part of export.
+type EmployeeDataExport struct {
+	EmployeeId string `protobuf:"bytes,1,opt,name=employee_id,json=employeeId,proto3" json:"employee_id,omitempty"`
+	// profile of employee, empty for deleted employees
+	Profile *Employee `protobuf:"bytes,2,opt,name=profile,proto3" json:"profile,omitempty"`
+	// roles assigned to employee
+	Roles []*Role `protobuf:"bytes,3,rep,name=roles,proto3" json:"roles,omitempty"`
+	// entry of employee directory, deleted employees stay listed
+	DirectoryEntry *DirectoryEntry `protobuf:"bytes,4,opt,name=directory_entry,json=directoryEntry,proto3" json:"directory_entry,omitempty"`
+	// events of employee and events originated by employee, in order of their creation
+	Events               []*Event             `protobuf:"bytes,5,rep,name=events,proto3" json:"events,omitempty"`
+	ExportedAt           *timestamp.Timestamp `protobuf:"bytes,6,opt,name=exported_at,json=exportedAt,proto3" json:"exported_at,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
+}
+
+func (m *EmployeeDataExport) Reset()         { *m = EmployeeDataExport{} }
+func (m *EmployeeDataExport) String() string { return proto.CompactTextString(m) }
+func (*EmployeeDataExport) ProtoMessage()    {}
+func (*EmployeeDataExport) Descriptor() ([]byte, []int) {
+	return fileDescriptor_c63b716a1824c4fb, []int{0}
+}
+
+func (m *EmployeeDataExport) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_EmployeeDataExport.Unmarshal(m, b)
+}
+func (m *EmployeeDataExport) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_EmployeeDataExport.Marshal(b, m, deterministic)
+}
+func (m *EmployeeDataExport) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_EmployeeDataExport.Merge(m, src)
+}
+func (m *EmployeeDataExport) XXX_Size() int {
+	return xxx_messageInfo_EmployeeDataExport.Size(m)
+}
+func (m *EmployeeDataExport) XXX_DiscardUnknown() {
+	xxx_messageInfo_EmployeeDataExport.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_EmployeeDataExport proto.InternalMessageInfo
+
+func (m *EmployeeDataExport) GetEmployeeId() string {
+	if m != nil {
+		return m.EmployeeId
+	}
+	return ""
+}
+
+func (m *EmployeeDataExport) GetProfile() *Employee {
+	if m != nil {
+		return m.Profile
+	}
+	return nil
+}
+
+func (m *EmployeeDataExport) GetRoles() []*Role {
+	if m != nil {
+		return m.Roles
+	}
+	return nil
+}
+
+func (m *EmployeeDataExport) GetDirectoryEntry() *DirectoryEntry {
+	if m != nil {
+		return m.DirectoryEntry
+	}
+	return nil
+}
+
+func (m *EmployeeDataExport) GetEvents() []*Event {
+	if m != nil {
+		return m.Events
+	}
+	return nil
+}
+
+func (m *EmployeeDataExport) GetExportedAt() *timestamp.Timestamp {
+	if m != nil {
+		return m.ExportedAt
+	}
+	return nil
+}
+
+func init() {
+	proto.RegisterType((*EmployeeDataExport)(nil), "pb.EmployeeDataExport")
+}
+
+func init() { proto.RegisterFile("employee_export.proto", fileDescriptor_c63b716a1824c4fb) }
+
+var fileDescriptor_c63b716a1824c4fb = []byte{
+	// 299 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x54, 0x90, 0x41, 0x4f, 0x3a, 0x31,
+	0x14, 0xc4, 0xff, 0xc0, 0x1f, 0x94, 0xb7, 0x06, 0x93, 0x97, 0x60, 0x9a, 0x3d, 0x08, 0x72, 0x30,
+	0x9c, 0x4a, 0x82, 0x47, 0x4e, 0x1a, 0x30, 0xf1, 0x5a, 0xbd, 0x79, 0x20, 0xbb, 0xec, 0x83, 0x34,
+	0x29, 0xb4, 0xe9, 0x56, 0xe2, 0x7e, 0x15, 0x3f, 0xad, 0xd9, 0x76, 0xab, 0xeb, 0xb1, 0x33, 0xb3,
+	0x33, 0xfb, 0x7e, 0x30, 0xa6, 0xa3, 0x51, 0xba, 0x22, 0xda, 0xd2, 0xa7, 0xd1, 0xd6, 0x71, 0x63,
+	0xb5, 0xd3, 0xd8, 0x35, 0x79, 0x3a, 0x39, 0x68, 0x7d, 0x50, 0xb4, 0xf0, 0x4a, 0xfe, 0xb1, 0x5f,
+	0x38, 0x79, 0xa4, 0xd2, 0x65, 0x47, 0x13, 0x42, 0xe9, 0x28, 0x7e, 0xdb, 0xbc, 0x13, 0x3a, 0xd3,
+	0xa9, 0x69, 0x48, 0xc1, 0x6a, 0xd5, 0x18, 0xb3, 0xaf, 0x2e, 0xe0, 0xa6, 0xc9, 0xae, 0x33, 0x97,
+	0x6d, 0xfc, 0x14, 0x4e, 0x20, 0xf9, 0x59, 0x97, 0x05, 0xeb, 0x4c, 0x3b, 0xf3, 0xa1, 0x80, 0x28,
+	0xbd, 0x14, 0x78, 0x0f, 0x17, 0xc6, 0xea, 0xbd, 0x54, 0xc4, 0xba, 0xd3, 0xce, 0x3c, 0x59, 0x5e,
+	0x71, 0x93, 0xf3, 0xd8, 0x24, 0xa2, 0x89, 0xb7, 0xd0, 0xaf, 0xd7, 0x4a, 0xd6, 0x9b, 0xf6, 0xe6,
+	0xc9, 0xf2, 0xb2, 0x4e, 0x09, 0xad, 0x48, 0x04, 0x19, 0x57, 0x70, 0x5d, 0x48, 0x4b, 0x3b, 0xa7,
+	0x6d, 0xb5, 0xa5, 0x93, 0xb3, 0x15, 0xfb, 0xef, 0xfb, 0xb0, 0x4e, 0xae, 0xa3, 0xb5, 0xa9, 0x1d,
+	0x31, 0x2a, 0xfe, 0xbc, 0xf1, 0x0e, 0x06, 0xfe, 0xae, 0x92, 0xf5, 0x7d, 0xfb, 0xd0, 0xff, 0x43,
+	0xad, 0x88, 0xc6, 0xc0, 0x15, 0x24, 0x81, 0x1e, 0x15, 0xdb, 0xcc, 0xb1, 0x81, 0xef, 0x4e, 0x79,
+	0xe0, 0xc7, 0x23, 0x3f, 0xfe, 0x16, 0xf9, 0x09, 0x88, 0xf1, 0x47, 0xb7, 0x7c, 0x87, 0x71, 0xbc,
+	0x28, 0x70, 0x79, 0x25, 0x7b, 0x96, 0x3b, 0xc2, 0x27, 0xc0, 0x20, 0xb4, 0xd1, 0x21, 0xb6, 0x11,
+	0x3c, 0x4b, 0xe5, 0xc8, 0xa6, 0x37, 0x6d, 0xed, 0x17, 0xf0, 0xec, 0x5f, 0x3e, 0xf0, 0xe3, 0x0f,
+	0xdf, 0x03, 0x00, 0x8e, 0x7b, 0xf2, 0x7e, 0xe7, 0x01, 0x00, 0x00,
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// EmployeeExportServiceClient is the client API for EmployeeExportService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type EmployeeExportServiceClient interface {
+	ExportEmployeeData(ctx context.Context, in *EmployeeFilter, opts ...grpc.CallOption) (*EmployeeDataExport, error)
+}
+
+type employeeExportServiceClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewEmployeeExportServiceClient(cc *grpc.ClientConn) EmployeeExportServiceClient {
+	return &employeeExportServiceClient{cc}
+}
+
+func (c *employeeExportServiceClient) ExportEmployeeData(ctx context.Context, in *EmployeeFilter, opts ...grpc.CallOption) (*EmployeeDataExport, error) {
+	out := new(EmployeeDataExport)
+	err := c.cc.Invoke(ctx, "/pb.EmployeeExportService/ExportEmployeeData", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// EmployeeExportServiceServer is the server API for EmployeeExportService service.
+type EmployeeExportServiceServer interface {
+	ExportEmployeeData(context.Context, *EmployeeFilter) (*EmployeeDataExport, error)
+}
+
+// UnimplementedEmployeeExportServiceServer can be embedded to have forward compatible implementations.
+type UnimplementedEmployeeExportServiceServer struct {
+}
+
+func (*UnimplementedEmployeeExportServiceServer) ExportEmployeeData(ctx context.Context, req *EmployeeFilter) (*EmployeeDataExport, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ExportEmployeeData not implemented")
+}
+
+func RegisterEmployeeExportServiceServer(s *grpc.Server, srv EmployeeExportServiceServer) {
+	s.RegisterService(&_EmployeeExportService_serviceDesc, srv)
+}
+
+func _EmployeeExportService_ExportEmployeeData_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EmployeeFilter)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EmployeeExportServiceServer).ExportEmployeeData(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.EmployeeExportService/ExportEmployeeData",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EmployeeExportServiceServer).ExportEmployeeData(ctx, req.(*EmployeeFilter))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _EmployeeExportService_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "pb.EmployeeExportService",
+	HandlerType: (*EmployeeExportServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ExportEmployeeData",
+			Handler:    _EmployeeExportService_ExportEmployeeData_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "employee_export.proto",
+}
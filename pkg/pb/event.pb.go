@@ -4,9 +4,14 @@
 package pb
 
 import (
+	context "context"
 	fmt "fmt"
 	proto "github.com/golang/protobuf/proto"
+	any "github.com/golang/protobuf/ptypes/any"
 	timestamp "github.com/golang/protobuf/ptypes/timestamp"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
 	math "math"
 )
 
@@ -27,16 +32,31 @@ type Event struct {
 	Type          string `protobuf:"bytes,3,opt,name=type,proto3" json:"type,omitempty"`
 	AggregateId   string `protobuf:"bytes,4,opt,name=aggregate_id,json=aggregateId,proto3" json:"aggregate_id,omitempty"`
 	AggregateType string `protobuf:"bytes,5,opt,name=aggregate_type,json=aggregateType,proto3" json:"aggregate_type,omitempty"`
+	// data of events stored before schema version 2, replaced by payload
+	//
 	// Types that are valid to be assigned to Data:
 	//	*Event_Employee
 	//	*Event_UpdateRequest
 	//	*Event_EmployeeFilter
-	Data                 isEvent_Data         `protobuf_oneof:"data"`
-	Originator           *Event_Claims        `protobuf:"bytes,9,opt,name=originator,proto3" json:"originator,omitempty"`
-	CreatedAt            *timestamp.Timestamp `protobuf:"bytes,10,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
-	XXX_unrecognized     []byte               `json:"-"`
-	XXX_sizecache        int32                `json:"-"`
+	//	*Event_EmployeeChanges
+	Data       isEvent_Data         `protobuf_oneof:"data"`
+	Originator *Event_Claims        `protobuf:"bytes,9,opt,name=originator,proto3" json:"originator,omitempty"`
+	CreatedAt  *timestamp.Timestamp `protobuf:"bytes,10,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	// sequence number of event within its aggregate, starting from 1
+	Sequence uint64 `protobuf:"varint,11,opt,name=sequence,proto3" json:"sequence,omitempty"`
+	// message of event type registered in event registry
+	Payload *any.Any `protobuf:"bytes,13,opt,name=payload,proto3" json:"payload,omitempty"`
+	// version of event schema, events without version are of version 1
+	SchemaVersion uint32 `protobuf:"varint,14,opt,name=schema_version,json=schemaVersion,proto3" json:"schema_version,omitempty"`
+	// ID of request which started chain of events
+	CorrelationId string `protobuf:"bytes,15,opt,name=correlation_id,json=correlationId,proto3" json:"correlation_id,omitempty"`
+	// ID of event this event was emitted in reaction to, empty for events emitted by requests
+	CausationId string `protobuf:"bytes,16,opt,name=causation_id,json=causationId,proto3" json:"causation_id,omitempty"`
+	// W3C trace context of span which published event, consumers continue its trace
+	TraceContext         map[string]string `protobuf:"bytes,17,rep,name=trace_context,json=traceContext,proto3" json:"trace_context,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
+	XXX_unrecognized     []byte            `json:"-"`
+	XXX_sizecache        int32             `json:"-"`
 }
 
 func (m *Event) Reset()         { *m = Event{} }
@@ -115,12 +135,18 @@ type Event_EmployeeFilter struct {
 	EmployeeFilter *EmployeeFilter `protobuf:"bytes,8,opt,name=employee_filter,json=employeeFilter,proto3,oneof"`
 }
 
+type Event_EmployeeChanges struct {
+	EmployeeChanges *EmployeeChanges `protobuf:"bytes,12,opt,name=employee_changes,json=employeeChanges,proto3,oneof"`
+}
+
 func (*Event_Employee) isEvent_Data() {}
 
 func (*Event_UpdateRequest) isEvent_Data() {}
 
 func (*Event_EmployeeFilter) isEvent_Data() {}
 
+func (*Event_EmployeeChanges) isEvent_Data() {}
+
 func (m *Event) GetData() isEvent_Data {
 	if m != nil {
 		return m.Data
@@ -149,6 +175,13 @@ func (m *Event) GetEmployeeFilter() *EmployeeFilter {
 	return nil
 }
 
+func (m *Event) GetEmployeeChanges() *EmployeeChanges {
+	if x, ok := m.GetData().(*Event_EmployeeChanges); ok {
+		return x.EmployeeChanges
+	}
+	return nil
+}
+
 func (m *Event) GetOriginator() *Event_Claims {
 	if m != nil {
 		return m.Originator
@@ -163,12 +196,55 @@ func (m *Event) GetCreatedAt() *timestamp.Timestamp {
 	return nil
 }
 
+func (m *Event) GetSequence() uint64 {
+	if m != nil {
+		return m.Sequence
+	}
+	return 0
+}
+
+func (m *Event) GetPayload() *any.Any {
+	if m != nil {
+		return m.Payload
+	}
+	return nil
+}
+
+func (m *Event) GetSchemaVersion() uint32 {
+	if m != nil {
+		return m.SchemaVersion
+	}
+	return 0
+}
+
+func (m *Event) GetCorrelationId() string {
+	if m != nil {
+		return m.CorrelationId
+	}
+	return ""
+}
+
+func (m *Event) GetCausationId() string {
+	if m != nil {
+		return m.CausationId
+	}
+	return ""
+}
+
+func (m *Event) GetTraceContext() map[string]string {
+	if m != nil {
+		return m.TraceContext
+	}
+	return nil
+}
+
 // XXX_OneofWrappers is for the internal use of the proto package.
 func (*Event) XXX_OneofWrappers() []interface{} {
 	return []interface{}{
 		(*Event_Employee)(nil),
 		(*Event_UpdateRequest)(nil),
 		(*Event_EmployeeFilter)(nil),
+		(*Event_EmployeeChanges)(nil),
 	}
 }
 
@@ -227,36 +303,381 @@ func (m *Event_Claims) GetLogin() string {
 	return ""
 }
 
+type DeadLetter struct {
+	Channel              string               `protobuf:"bytes,1,opt,name=channel,proto3" json:"channel,omitempty"`
+	Sequence             uint64               `protobuf:"varint,2,opt,name=sequence,proto3" json:"sequence,omitempty"`
+	Data                 []byte               `protobuf:"bytes,3,opt,name=data,proto3" json:"data,omitempty"`
+	Error                string               `protobuf:"bytes,4,opt,name=error,proto3" json:"error,omitempty"`
+	Deliveries           uint32               `protobuf:"varint,5,opt,name=deliveries,proto3" json:"deliveries,omitempty"`
+	Subscriber           string               `protobuf:"bytes,6,opt,name=subscriber,proto3" json:"subscriber,omitempty"`
+	FailedAt             *timestamp.Timestamp `protobuf:"bytes,7,opt,name=failed_at,json=failedAt,proto3" json:"failed_at,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
+}
+
+func (m *DeadLetter) Reset()         { *m = DeadLetter{} }
+func (m *DeadLetter) String() string { return proto.CompactTextString(m) }
+func (*DeadLetter) ProtoMessage()    {}
+func (*DeadLetter) Descriptor() ([]byte, []int) {
+	return fileDescriptor_2d17a9d3f0ddf27e, []int{1}
+}
+
+func (m *DeadLetter) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_DeadLetter.Unmarshal(m, b)
+}
+func (m *DeadLetter) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_DeadLetter.Marshal(b, m, deterministic)
+}
+func (m *DeadLetter) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DeadLetter.Merge(m, src)
+}
+func (m *DeadLetter) XXX_Size() int {
+	return xxx_messageInfo_DeadLetter.Size(m)
+}
+func (m *DeadLetter) XXX_DiscardUnknown() {
+	xxx_messageInfo_DeadLetter.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DeadLetter proto.InternalMessageInfo
+
+func (m *DeadLetter) GetChannel() string {
+	if m != nil {
+		return m.Channel
+	}
+	return ""
+}
+
+func (m *DeadLetter) GetSequence() uint64 {
+	if m != nil {
+		return m.Sequence
+	}
+	return 0
+}
+
+func (m *DeadLetter) GetData() []byte {
+	if m != nil {
+		return m.Data
+	}
+	return nil
+}
+
+func (m *DeadLetter) GetError() string {
+	if m != nil {
+		return m.Error
+	}
+	return ""
+}
+
+func (m *DeadLetter) GetDeliveries() uint32 {
+	if m != nil {
+		return m.Deliveries
+	}
+	return 0
+}
+
+func (m *DeadLetter) GetSubscriber() string {
+	if m != nil {
+		return m.Subscriber
+	}
+	return ""
+}
+
+func (m *DeadLetter) GetFailedAt() *timestamp.Timestamp {
+	if m != nil {
+		return m.FailedAt
+	}
+	return nil
+}
+
+type SubscribeEventsRequest struct {
+	Channel string `protobuf:"bytes,1,opt,name=channel,proto3" json:"channel,omitempty"`
+	// only events of given aggregate type and ID, all aggregates when empty
+	AggregateType string `protobuf:"bytes,2,opt,name=aggregate_type,json=aggregateType,proto3" json:"aggregate_type,omitempty"`
+	AggregateId   string `protobuf:"bytes,3,opt,name=aggregate_id,json=aggregateId,proto3" json:"aggregate_id,omitempty"`
+	// only events of given types, all types when empty
+	Types []string `protobuf:"bytes,4,rep,name=types,proto3" json:"types,omitempty"`
+	// resume subscription from given sequence of channel, only new events are streamed when zero
+	FromSequence         uint64   `protobuf:"varint,5,opt,name=from_sequence,json=fromSequence,proto3" json:"from_sequence,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SubscribeEventsRequest) Reset()         { *m = SubscribeEventsRequest{} }
+func (m *SubscribeEventsRequest) String() string { return proto.CompactTextString(m) }
+func (*SubscribeEventsRequest) ProtoMessage()    {}
+func (*SubscribeEventsRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_2d17a9d3f0ddf27e, []int{2}
+}
+
+func (m *SubscribeEventsRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SubscribeEventsRequest.Unmarshal(m, b)
+}
+func (m *SubscribeEventsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SubscribeEventsRequest.Marshal(b, m, deterministic)
+}
+func (m *SubscribeEventsRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SubscribeEventsRequest.Merge(m, src)
+}
+func (m *SubscribeEventsRequest) XXX_Size() int {
+	return xxx_messageInfo_SubscribeEventsRequest.Size(m)
+}
+func (m *SubscribeEventsRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_SubscribeEventsRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SubscribeEventsRequest proto.InternalMessageInfo
+
+func (m *SubscribeEventsRequest) GetChannel() string {
+	if m != nil {
+		return m.Channel
+	}
+	return ""
+}
+
+func (m *SubscribeEventsRequest) GetAggregateType() string {
+	if m != nil {
+		return m.AggregateType
+	}
+	return ""
+}
+
+func (m *SubscribeEventsRequest) GetAggregateId() string {
+	if m != nil {
+		return m.AggregateId
+	}
+	return ""
+}
+
+func (m *SubscribeEventsRequest) GetTypes() []string {
+	if m != nil {
+		return m.Types
+	}
+	return nil
+}
+
+func (m *SubscribeEventsRequest) GetFromSequence() uint64 {
+	if m != nil {
+		return m.FromSequence
+	}
+	return 0
+}
+
+type StreamedEvent struct {
+	// sequence of event in channel, next one is used to resume subscription
+	Sequence             uint64   `protobuf:"varint,1,opt,name=sequence,proto3" json:"sequence,omitempty"`
+	Event                *Event   `protobuf:"bytes,2,opt,name=event,proto3" json:"event,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *StreamedEvent) Reset()         { *m = StreamedEvent{} }
+func (m *StreamedEvent) String() string { return proto.CompactTextString(m) }
+func (*StreamedEvent) ProtoMessage()    {}
+func (*StreamedEvent) Descriptor() ([]byte, []int) {
+	return fileDescriptor_2d17a9d3f0ddf27e, []int{3}
+}
+
+func (m *StreamedEvent) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_StreamedEvent.Unmarshal(m, b)
+}
+func (m *StreamedEvent) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_StreamedEvent.Marshal(b, m, deterministic)
+}
+func (m *StreamedEvent) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_StreamedEvent.Merge(m, src)
+}
+func (m *StreamedEvent) XXX_Size() int {
+	return xxx_messageInfo_StreamedEvent.Size(m)
+}
+func (m *StreamedEvent) XXX_DiscardUnknown() {
+	xxx_messageInfo_StreamedEvent.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_StreamedEvent proto.InternalMessageInfo
+
+func (m *StreamedEvent) GetSequence() uint64 {
+	if m != nil {
+		return m.Sequence
+	}
+	return 0
+}
+
+func (m *StreamedEvent) GetEvent() *Event {
+	if m != nil {
+		return m.Event
+	}
+	return nil
+}
+
 func init() {
 	proto.RegisterType((*Event)(nil), "pb.Event")
+	proto.RegisterMapType((map[string]string)(nil), "pb.Event.TraceContextEntry")
 	proto.RegisterType((*Event_Claims)(nil), "pb.Event.Claims")
+	proto.RegisterType((*DeadLetter)(nil), "pb.DeadLetter")
+	proto.RegisterType((*SubscribeEventsRequest)(nil), "pb.SubscribeEventsRequest")
+	proto.RegisterType((*StreamedEvent)(nil), "pb.StreamedEvent")
 }
 
 func init() { proto.RegisterFile("event.proto", fileDescriptor_2d17a9d3f0ddf27e) }
 
 var fileDescriptor_2d17a9d3f0ddf27e = []byte{
-	// 363 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x4c, 0x51, 0xcd, 0x4e, 0xea, 0x40,
-	0x14, 0x06, 0x6e, 0x29, 0xed, 0xe1, 0xe7, 0xde, 0x4c, 0x6e, 0xcc, 0x50, 0x17, 0xa2, 0x89, 0x09,
-	0x71, 0x51, 0x8c, 0xae, 0x5c, 0xb8, 0x10, 0x83, 0x81, 0x6d, 0xc5, 0x35, 0x99, 0xd2, 0x43, 0x9d,
-	0xa4, 0xed, 0xd4, 0x32, 0x98, 0xf0, 0x1c, 0xbe, 0xb0, 0xe9, 0x99, 0xb6, 0xb0, 0x9b, 0xef, 0xf7,
-	0x34, 0xfd, 0xa0, 0x8f, 0xdf, 0x98, 0x69, 0x3f, 0x2f, 0x94, 0x56, 0xac, 0x93, 0x87, 0xde, 0x55,
-	0xac, 0x54, 0x9c, 0xe0, 0x8c, 0x98, 0xf0, 0xb0, 0x9b, 0x69, 0x99, 0xe2, 0x5e, 0x8b, 0x34, 0x37,
-	0x26, 0x6f, 0x84, 0x69, 0x9e, 0xa8, 0x23, 0xa2, 0xc1, 0x37, 0x3f, 0x16, 0x74, 0x17, 0x65, 0x09,
-	0x1b, 0x83, 0x43, 0x6d, 0x1b, 0x19, 0xf1, 0xf6, 0xa4, 0x3d, 0x75, 0x83, 0x1e, 0xe1, 0x55, 0xc4,
-	0x38, 0xf4, 0xb6, 0x9f, 0x22, 0xcb, 0x30, 0xe1, 0x1d, 0xa3, 0x54, 0x90, 0x31, 0xb0, 0xf4, 0x31,
-	0x47, 0xfe, 0x87, 0x68, 0x7a, 0xb3, 0x6b, 0x18, 0x88, 0x38, 0x2e, 0x30, 0x16, 0x1a, 0xcb, 0x32,
-	0x8b, 0xb4, 0x7e, 0xc3, 0xad, 0x22, 0x76, 0x0b, 0xa3, 0x93, 0x85, 0x0a, 0xba, 0x64, 0x1a, 0x36,
-	0xec, 0xba, 0x6c, 0xba, 0x03, 0xa7, 0xfe, 0x5c, 0x6e, 0x4f, 0xda, 0xd3, 0xfe, 0xc3, 0xc0, 0xcf,
-	0x43, 0x7f, 0x51, 0x71, 0xcb, 0x56, 0xd0, 0xe8, 0x6c, 0x0e, 0xa3, 0x43, 0x1e, 0x95, 0x7d, 0x05,
-	0x7e, 0x1d, 0x70, 0xaf, 0x79, 0x8f, 0x12, 0xe3, 0x32, 0xf1, 0x41, 0x4a, 0x9d, 0x0b, 0x8c, 0x61,
-	0xd9, 0x0a, 0x86, 0x26, 0x52, 0x11, 0xec, 0x19, 0xfe, 0xd6, 0x7d, 0x9b, 0x9d, 0x4c, 0x34, 0x16,
-	0xdc, 0xa1, 0x12, 0x76, 0x7e, 0xf6, 0x8d, 0x94, 0x65, 0x2b, 0x68, 0xfe, 0xa5, 0x61, 0xd8, 0x3d,
-	0x80, 0x2a, 0x64, 0x2c, 0x33, 0xa1, 0x55, 0xc1, 0x5d, 0x4a, 0xfe, 0xa3, 0x24, 0xad, 0xf4, 0x9a,
-	0x08, 0x99, 0xee, 0x83, 0x33, 0x0f, 0x7b, 0x02, 0xd8, 0x16, 0x28, 0x34, 0x46, 0x1b, 0xa1, 0x39,
-	0x50, 0xc2, 0xf3, 0xcd, 0x86, 0x7e, 0xbd, 0xa1, 0xbf, 0xae, 0x37, 0x0c, 0xdc, 0xca, 0xfd, 0xa2,
-	0xbd, 0x77, 0xb0, 0x4d, 0x21, 0xbb, 0x04, 0x17, 0x33, 0x2d, 0xf5, 0xf1, 0xb4, 0x9c, 0x63, 0x88,
-	0x55, 0xc4, 0x2e, 0xc0, 0x36, 0xef, 0x6a, 0xb9, 0x0a, 0xb1, 0xff, 0xd0, 0x4d, 0x54, 0x2c, 0xb3,
-	0x6a, 0x39, 0x03, 0xe6, 0x36, 0x58, 0x91, 0xd0, 0x22, 0xb4, 0xe9, 0xf6, 0xe3, 0x6f, 0x00, 0x00,
-	0x00, 0xff, 0xff, 0xfe, 0xb8, 0x5c, 0xd7, 0x60, 0x02, 0x00, 0x00,
+	// 758 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x84, 0x54, 0xdd, 0x6e, 0xf2, 0x46,
+	0x10, 0xc5, 0xfc, 0x33, 0x60, 0x20, 0xdb, 0x28, 0x72, 0x1c, 0xa9, 0xa1, 0x54, 0x95, 0x50, 0x2f,
+	0x48, 0x44, 0x2f, 0xfa, 0x23, 0x55, 0xcd, 0x6f, 0x05, 0x52, 0xae, 0x16, 0xda, 0x5b, 0xb4, 0xd8,
+	0x83, 0x63, 0xd5, 0xd8, 0xee, 0x7a, 0x41, 0xf5, 0x83, 0xf4, 0x59, 0xfa, 0x44, 0x7d, 0x8f, 0x4f,
+	0xbb, 0x6b, 0x3b, 0x0e, 0xe4, 0xd3, 0x77, 0xe7, 0x39, 0x33, 0xe7, 0xec, 0x7a, 0xe6, 0xec, 0x40,
+	0x17, 0x0f, 0x18, 0x8a, 0x69, 0xcc, 0x23, 0x11, 0x91, 0x6a, 0xbc, 0xb1, 0x2f, 0xbd, 0x28, 0xf2,
+	0x02, 0xbc, 0x51, 0xc8, 0x66, 0xbf, 0xbd, 0x61, 0x61, 0xaa, 0xd3, 0xf6, 0xf5, 0x71, 0x4a, 0xf8,
+	0x3b, 0x4c, 0x04, 0xdb, 0xc5, 0x59, 0x41, 0x1f, 0x77, 0x71, 0x10, 0xa5, 0x88, 0x3a, 0x1e, 0xff,
+	0xdb, 0x82, 0xc6, 0xb3, 0xd4, 0x27, 0x97, 0xd0, 0x56, 0x07, 0xad, 0x7d, 0xd7, 0x32, 0x46, 0xc6,
+	0xa4, 0x43, 0x5b, 0x2a, 0x5e, 0xb8, 0xc4, 0x82, 0x96, 0xf3, 0xca, 0xc2, 0x10, 0x03, 0xab, 0xaa,
+	0x33, 0x59, 0x48, 0x08, 0xd4, 0x45, 0x1a, 0xa3, 0x55, 0x53, 0xb0, 0xfa, 0x26, 0xdf, 0x40, 0x8f,
+	0x79, 0x1e, 0x47, 0x8f, 0x09, 0x94, 0x62, 0x75, 0x95, 0xeb, 0x16, 0xd8, 0xc2, 0x25, 0xdf, 0x41,
+	0xff, 0xad, 0x44, 0x09, 0x34, 0x54, 0x91, 0x59, 0xa0, 0x2b, 0xa9, 0xf4, 0x3d, 0xb4, 0xf3, 0xeb,
+	0x5a, 0xcd, 0x91, 0x31, 0xe9, 0xce, 0x7a, 0xd3, 0x78, 0x33, 0x7d, 0xce, 0xb0, 0x79, 0x85, 0x16,
+	0x79, 0xf2, 0x00, 0xfd, 0x7d, 0xec, 0x4a, 0x3d, 0x8e, 0x7f, 0xef, 0x31, 0x11, 0x56, 0x4b, 0x31,
+	0x2e, 0x25, 0xe3, 0x0f, 0x95, 0xc9, 0x79, 0x54, 0x17, 0xcc, 0x2b, 0xd4, 0xd4, 0x94, 0x0c, 0x20,
+	0xbf, 0xc2, 0x20, 0xd7, 0x5b, 0x6f, 0xfd, 0x40, 0x20, 0xb7, 0xda, 0x4a, 0x84, 0x94, 0x8f, 0xfd,
+	0x5d, 0x65, 0xe6, 0x15, 0xda, 0xc7, 0x77, 0x08, 0xb9, 0x83, 0x61, 0x41, 0x97, 0x0d, 0xf2, 0x30,
+	0xb1, 0x7a, 0x8a, 0xff, 0x55, 0x99, 0xff, 0xa8, 0x53, 0xf3, 0x0a, 0x1d, 0xe0, 0x7b, 0x88, 0xdc,
+	0x02, 0x44, 0xdc, 0xf7, 0xfc, 0x90, 0x89, 0x88, 0x5b, 0x1d, 0xc5, 0x1d, 0x2a, 0xae, 0xb2, 0xc0,
+	0x63, 0xc0, 0xfc, 0x5d, 0x42, 0x4b, 0x35, 0xe4, 0x67, 0x00, 0x87, 0x23, 0x13, 0xe8, 0xae, 0x99,
+	0xb0, 0x40, 0x31, 0xec, 0xa9, 0x76, 0xc1, 0x34, 0x77, 0xc1, 0x74, 0x95, 0xbb, 0x80, 0x76, 0xb2,
+	0xea, 0x7b, 0x41, 0x6c, 0x68, 0x27, 0xf2, 0xc7, 0x43, 0x07, 0xad, 0xee, 0xc8, 0x98, 0xd4, 0x69,
+	0x11, 0x93, 0x29, 0xb4, 0x62, 0x96, 0x06, 0x11, 0x73, 0x2d, 0x53, 0x69, 0x9e, 0x9f, 0x68, 0xde,
+	0x87, 0x29, 0xcd, 0x8b, 0xe4, 0x40, 0x13, 0xe7, 0x15, 0x77, 0x6c, 0x7d, 0x40, 0x9e, 0xf8, 0x51,
+	0x68, 0xf5, 0x47, 0xc6, 0xc4, 0xa4, 0xa6, 0x46, 0xff, 0xd4, 0xa0, 0x2c, 0x73, 0x22, 0xce, 0x31,
+	0x60, 0xc2, 0x8f, 0x42, 0x69, 0x8e, 0x81, 0x9e, 0x7b, 0x09, 0x5d, 0xb8, 0xd2, 0x41, 0x0e, 0xdb,
+	0x27, 0x45, 0xd1, 0x50, 0x3b, 0xa8, 0xc0, 0x16, 0x2e, 0xb9, 0x03, 0x53, 0x70, 0xe6, 0xe0, 0xda,
+	0x89, 0x42, 0x81, 0xff, 0x08, 0xeb, 0x6c, 0x54, 0x9b, 0x74, 0x67, 0x57, 0x6f, 0xcd, 0x5a, 0xc9,
+	0xf4, 0xa3, 0xce, 0x3e, 0x87, 0x82, 0xa7, 0xb4, 0x27, 0x4a, 0x90, 0xbd, 0x84, 0xa6, 0xee, 0x27,
+	0xb9, 0x82, 0x0e, 0x86, 0xc2, 0x17, 0xe9, 0x9b, 0xf5, 0xdb, 0x1a, 0x58, 0xb8, 0xe4, 0x02, 0x9a,
+	0xfa, 0x3b, 0xb3, 0x7e, 0x16, 0x91, 0x73, 0x68, 0x04, 0x91, 0xe7, 0x87, 0x99, 0xf5, 0x75, 0x60,
+	0xff, 0x06, 0x67, 0x27, 0xe7, 0x92, 0x21, 0xd4, 0xfe, 0xc2, 0x34, 0x53, 0x96, 0x9f, 0x92, 0x7c,
+	0x60, 0xc1, 0x1e, 0x33, 0x4d, 0x1d, 0xfc, 0x52, 0xfd, 0xc9, 0x78, 0x68, 0x42, 0xdd, 0x65, 0x82,
+	0x8d, 0xff, 0x37, 0x00, 0x9e, 0x90, 0xb9, 0x2f, 0x28, 0xa4, 0xb5, 0x4a, 0x2f, 0xd0, 0x78, 0xff,
+	0x02, 0xcb, 0x53, 0xac, 0x1e, 0x4d, 0x91, 0x68, 0x31, 0x75, 0xc5, 0x1e, 0x55, 0xdf, 0xf2, 0x68,
+	0xe4, 0x3c, 0xe2, 0xd9, 0xb3, 0xd4, 0x01, 0xf9, 0x1a, 0xc0, 0xc5, 0xc0, 0x3f, 0x20, 0xf7, 0x31,
+	0x51, 0x8f, 0xd1, 0xa4, 0x25, 0x44, 0xe6, 0x93, 0xfd, 0x26, 0x71, 0xb8, 0xbf, 0x41, 0xae, 0xde,
+	0x62, 0x87, 0x96, 0x10, 0xf2, 0x23, 0x74, 0xb6, 0xcc, 0x0f, 0xb4, 0x0b, 0x5b, 0x5f, 0x74, 0x61,
+	0x5b, 0x17, 0xdf, 0x8b, 0xf1, 0x7f, 0x06, 0x5c, 0x2c, 0x73, 0x1d, 0x35, 0xb8, 0x24, 0x7f, 0x8d,
+	0x9f, 0xff, 0xe7, 0xd3, 0xf5, 0x51, 0xfd, 0x68, 0x7d, 0x1c, 0x2f, 0xa2, 0xda, 0xe9, 0x22, 0x3a,
+	0x87, 0x86, 0xe4, 0x27, 0x56, 0x7d, 0x54, 0x93, 0xdd, 0x50, 0x01, 0xf9, 0x16, 0xcc, 0x2d, 0x8f,
+	0x76, 0xeb, 0xa2, 0xb1, 0x0d, 0xd5, 0xd8, 0x9e, 0x04, 0x97, 0x19, 0x36, 0x7e, 0x01, 0x73, 0x29,
+	0x38, 0xb2, 0x1d, 0xba, 0x7a, 0x81, 0x96, 0x27, 0x61, 0x1c, 0x4d, 0xe2, 0x1a, 0x1a, 0x6a, 0x99,
+	0xaa, 0x8b, 0x76, 0x67, 0x9d, 0xc2, 0xa6, 0x54, 0xe3, 0xb3, 0x15, 0xf4, 0x54, 0xbc, 0x44, 0x7e,
+	0xf0, 0x1d, 0x24, 0x4f, 0x30, 0x38, 0x6a, 0x0b, 0xb1, 0x25, 0xe9, 0xe3, 0x5e, 0xd9, 0x67, 0x2a,
+	0x57, 0xbe, 0xce, 0xb8, 0x72, 0x6b, 0x6c, 0x9a, 0xaa, 0xf7, 0x3f, 0x7c, 0x1a, 0x00, 0x67, 0x93,
+	0x76, 0x8d, 0x43, 0x06, 0x00, 0x00,
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// EventServiceClient is the client API for EventService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type EventServiceClient interface {
+	SubscribeEvents(ctx context.Context, in *SubscribeEventsRequest, opts ...grpc.CallOption) (EventService_SubscribeEventsClient, error)
+}
+
+type eventServiceClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewEventServiceClient(cc *grpc.ClientConn) EventServiceClient {
+	return &eventServiceClient{cc}
+}
+
+func (c *eventServiceClient) SubscribeEvents(ctx context.Context, in *SubscribeEventsRequest, opts ...grpc.CallOption) (EventService_SubscribeEventsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_EventService_serviceDesc.Streams[0], "/pb.EventService/SubscribeEvents", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &eventServiceSubscribeEventsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type EventService_SubscribeEventsClient interface {
+	Recv() (*StreamedEvent, error)
+	grpc.ClientStream
+}
+
+type eventServiceSubscribeEventsClient struct {
+	grpc.ClientStream
+}
+
+func (x *eventServiceSubscribeEventsClient) Recv() (*StreamedEvent, error) {
+	m := new(StreamedEvent)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// EventServiceServer is the server API for EventService service.
+type EventServiceServer interface {
+	SubscribeEvents(*SubscribeEventsRequest, EventService_SubscribeEventsServer) error
+}
+
+// UnimplementedEventServiceServer can be embedded to have forward compatible implementations.
+type UnimplementedEventServiceServer struct {
+}
+
+func (*UnimplementedEventServiceServer) SubscribeEvents(req *SubscribeEventsRequest, srv EventService_SubscribeEventsServer) error {
+	return status.Errorf(codes.Unimplemented, "method SubscribeEvents not implemented")
+}
+
+func RegisterEventServiceServer(s *grpc.Server, srv EventServiceServer) {
+	s.RegisterService(&_EventService_serviceDesc, srv)
+}
+
+func _EventService_SubscribeEvents_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SubscribeEventsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(EventServiceServer).SubscribeEvents(m, &eventServiceSubscribeEventsServer{stream})
+}
+
+type EventService_SubscribeEventsServer interface {
+	Send(*StreamedEvent) error
+	grpc.ServerStream
+}
+
+type eventServiceSubscribeEventsServer struct {
+	grpc.ServerStream
+}
+
+func (x *eventServiceSubscribeEventsServer) Send(m *StreamedEvent) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+var _EventService_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "pb.EventService",
+	HandlerType: (*EventServiceServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "SubscribeEvents",
+			Handler:       _EventService_SubscribeEvents_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "event.proto",
 }
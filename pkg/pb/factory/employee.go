@@ -1,6 +1,9 @@
 package factory
 
 import (
+	"sort"
+	"strings"
+
 	"github.com/golang/protobuf/ptypes"
 
 	"github.com/migotom/cell-centre-services/pkg/entities"
@@ -24,6 +27,7 @@ func (factory *EmployeePbFactory) NewFromEmployee(e *entities.Employee) (*pb.Emp
 		Password: e.Password,
 		Name:     e.Name,
 		Phone:    e.Phone,
+		Team:     e.Team,
 	}
 
 	if e.CreatedAt != nil {
@@ -54,3 +58,95 @@ func (factory *EmployeePbFactory) NewFromEmployee(e *entities.Employee) (*pb.Emp
 	}
 	return &employee, nil
 }
+
+// NewFromDirectoryEntry creates new pb.DirectoryEntry instance from DirectoryEntry entity.
+func (factory *EmployeePbFactory) NewFromDirectoryEntry(e *entities.DirectoryEntry) (*pb.DirectoryEntry, error) {
+	entry := pb.DirectoryEntry{
+		Id:     e.ID.Hex(),
+		Email:  e.Email,
+		Name:   e.Name,
+		Phone:  e.Phone,
+		Team:   e.Team,
+		Status: e.Status,
+		Roles:  e.RoleNames(),
+	}
+
+	var err error
+	if e.CreatedAt != nil {
+		if entry.CreatedAt, err = ptypes.TimestampProto(*e.CreatedAt); err != nil {
+			return nil, err
+		}
+	}
+	if e.UpdatedAt != nil {
+		if entry.UpdatedAt, err = ptypes.TimestampProto(*e.UpdatedAt); err != nil {
+			return nil, err
+		}
+	}
+	return &entry, nil
+}
+
+// sensitiveFields are reported as changed without their values.
+var sensitiveFields = map[string]bool{
+	"password": true,
+}
+
+// NewChanges creates new pb.EmployeeChanges instance listing fields of employee that differ between
+// before and after states, timestamps are not compared.
+func (factory *EmployeePbFactory) NewChanges(before, after *entities.Employee) *pb.EmployeeChanges {
+	changes := pb.EmployeeChanges{
+		Id: after.ID.Hex(),
+	}
+
+	fields := []struct {
+		path          string
+		before, after string
+	}{
+		{"email", before.Email, after.Email},
+		{"name", before.Name, after.Name},
+		{"phone", before.Phone, after.Phone},
+		{"team", before.Team, after.Team},
+		{"password", before.Password, after.Password},
+		{"roles", roleNames(before.Roles), roleNames(after.Roles)},
+	}
+	for _, field := range fields {
+		if field.before == field.after {
+			continue
+		}
+
+		change := pb.FieldChange{Path: field.path}
+		if sensitiveFields[field.path] {
+			change.Redacted = true
+		} else {
+			change.OldValue = field.before
+			change.NewValue = field.after
+		}
+		changes.Changes = append(changes.Changes, &change)
+	}
+	return &changes
+}
+
+// NewFromEmployeeChanges creates new pb.EmployeeChanges instance from EmployeeChanges entity.
+func (factory *EmployeePbFactory) NewFromEmployeeChanges(e *entities.EmployeeChanges) *pb.EmployeeChanges {
+	changes := pb.EmployeeChanges{
+		Id: e.ID.Hex(),
+	}
+	for _, change := range e.Changes {
+		changes.Changes = append(changes.Changes, &pb.FieldChange{
+			Path:     change.Path,
+			OldValue: change.OldValue,
+			NewValue: change.NewValue,
+			Redacted: change.Redacted,
+		})
+	}
+	return &changes
+}
+
+// roleNames returns sorted, comma separated names of roles.
+func roleNames(roles []entities.Role) string {
+	names := make([]string, 0, len(roles))
+	for _, role := range roles {
+		names = append(names, role.Name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ",")
+}
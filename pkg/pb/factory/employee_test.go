@@ -0,0 +1,73 @@
+package factory
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/migotom/cell-centre-services/pkg/entities"
+	"github.com/migotom/cell-centre-services/pkg/pb"
+)
+
+func TestNewChanges(t *testing.T) {
+	id, _ := primitive.ObjectIDFromHex("5d3783ee28ae9468bc528906")
+	before := entities.Employee{
+		ID:       id,
+		Email:    "admin@page.com",
+		Name:     "Admin",
+		Password: "$2a$04$old",
+		Roles:    []entities.Role{{Name: "serviceman"}, {Name: "admin"}},
+	}
+
+	cases := []struct {
+		Name            string
+		After           func(entities.Employee) entities.Employee
+		ExpectedChanges []*pb.FieldChange
+	}{
+		{
+			Name:  "Nothing changed",
+			After: func(e entities.Employee) entities.Employee { return e },
+		},
+		{
+			Name: "Reordered roles are not a change",
+			After: func(e entities.Employee) entities.Employee {
+				e.Roles = []entities.Role{{Name: "admin"}, {Name: "serviceman"}}
+				return e
+			},
+		},
+		{
+			Name: "Changed fields",
+			After: func(e entities.Employee) entities.Employee {
+				e.Email = "root@page.com"
+				e.Phone = "+48 600 000 000"
+				e.Roles = []entities.Role{{Name: "admin"}}
+				return e
+			},
+			ExpectedChanges: []*pb.FieldChange{
+				{Path: "email", OldValue: "admin@page.com", NewValue: "root@page.com"},
+				{Path: "phone", NewValue: "+48 600 000 000"},
+				{Path: "roles", OldValue: "admin,serviceman", NewValue: "admin"},
+			},
+		},
+		{
+			Name: "Changed password is redacted",
+			After: func(e entities.Employee) entities.Employee {
+				e.Password = "$2a$04$new"
+				return e
+			},
+			ExpectedChanges: []*pb.FieldChange{
+				{Path: "password", Redacted: true},
+			},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			after := tc.After(before)
+			changes := NewEmployeePbFactory().NewChanges(&before, &after)
+
+			assert.Equal(t, "5d3783ee28ae9468bc528906", changes.Id)
+			assert.Equal(t, tc.ExpectedChanges, changes.Changes)
+		})
+	}
+}
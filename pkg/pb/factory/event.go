@@ -1,90 +1,107 @@
 package factory
 
 import (
+	"context"
+	"fmt"
 	"time"
 
 	"github.com/gofrs/uuid"
+	"github.com/golang/protobuf/proto"
 	"github.com/golang/protobuf/ptypes"
 
+	"github.com/migotom/cell-centre-services/pkg/components/event"
 	"github.com/migotom/cell-centre-services/pkg/entities"
+	"github.com/migotom/cell-centre-services/pkg/helpers/correlation"
+	"github.com/migotom/cell-centre-services/pkg/helpers/tracing"
 	"github.com/migotom/cell-centre-services/pkg/pb"
 )
 
-const employeeEventChannel = "employees"
-
 // EventPbFactory is pb.Event factory.
 type EventPbFactory struct {
+	registry *event.Registry
 }
 
-// NewEventPbFactory creates new factory.
-func NewEventPbFactory() *EventPbFactory {
-	return &EventPbFactory{}
+// NewEventPbFactory creates new factory of events registered in given registry.
+func NewEventPbFactory(registry *event.Registry) *EventPbFactory {
+	return &EventPbFactory{
+		registry: registry,
+	}
 }
 
-// NewFromEmployeeMessage creates event based on given Employee message.
-func (factory *EventPbFactory) NewFromEmployeeMessage(originator entities.TokenClaims, eventType entities.EventType, employee *pb.Employee) (*pb.Event, error) {
-	event, err := newPbFromBase(originator)
-	if err != nil {
-		return nil, err
+// New creates event of registered type with given payload, channel and aggregate type are taken from event definition
+// and correlation IDs and trace context from context.
+func (factory *EventPbFactory) New(ctx context.Context, originator entities.TokenClaims, eventType entities.EventType, aggregateID string, payload proto.Message) (*pb.Event, error) {
+	definition, ok := factory.registry.Event(eventType)
+	if !ok {
+		return nil, fmt.Errorf("unknown event %s", eventType)
+	}
+	if proto.MessageName(payload) != proto.MessageName(definition.Payload) {
+		return nil, fmt.Errorf("invalid payload %s of event %s, expected %s", proto.MessageName(payload), eventType, proto.MessageName(definition.Payload))
 	}
 
-	event.AggregateId = employee.Id
-	event.Type = string(eventType)
-	event.Data = &pb.Event_Employee{employee}
-
-	return event, nil
-}
-
-// NewFromUpdateEmployeeRequest creates event based on given Employee message.
-func (factory *EventPbFactory) NewFromUpdateEmployeeRequest(originator entities.TokenClaims, eventType entities.EventType, request *pb.UpdateEmployeeRequest) (*pb.Event, error) {
-	event, err := newPbFromBase(originator)
+	packed, err := ptypes.MarshalAny(payload)
 	if err != nil {
 		return nil, err
 	}
 
-	event.AggregateId = request.Id
-	event.Type = string(eventType)
-	event.Data = &pb.Event_UpdateRequest{request}
-
-	return event, nil
-}
-
-// NewFromEmployeeFilter creates event based on given Employee message.
-func (factory *EventPbFactory) NewFromEmployeeFilter(originator entities.TokenClaims, eventType entities.EventType, filter *pb.EmployeeFilter) (*pb.Event, error) {
-	event, err := newPbFromBase(originator)
+	createdAt, err := ptypes.TimestampProto(time.Now())
 	if err != nil {
 		return nil, err
 	}
 
-	var aggregateId string
-	switch {
-	case filter.GetId() != "":
-		aggregateId = filter.GetId()
-	case filter.GetEmail() != "":
-		aggregateId = filter.GetEmail()
+	e := pb.Event{
+		EventId:       uuid.Must(uuid.NewV4()).String(),
+		Channel:       definition.Channel,
+		Type:          string(eventType),
+		AggregateId:   aggregateID,
+		AggregateType: definition.AggregateType,
+		// login of originator isn't carried, it would outlive erasure of originator's personal data
+		Originator: &pb.Event_Claims{
+			EntityId: originator.EntityID.Hex(),
+			Entity:   originator.Entity,
+		},
+		CreatedAt:     createdAt,
+		Payload:       packed,
+		SchemaVersion: event.SchemaVersion,
 	}
-
-	event.AggregateId = aggregateId
-	event.Type = string(eventType)
-	event.Data = &pb.Event_EmployeeFilter{filter}
-
-	return event, nil
+	correlation.Stamp(ctx, &e)
+	tracing.Stamp(ctx, &e)
+	return &e, nil
 }
 
-func newPbFromBase(originator entities.TokenClaims) (*pb.Event, error) {
-	createdAt, err := ptypes.TimestampProto(time.Now())
+// NewFromEvent creates pb.Event instance from stored Event entity, data of event is encoded into payload
+// by encoder of its registered type.
+func (factory *EventPbFactory) NewFromEvent(e *entities.Event) (*pb.Event, error) {
+	payload, err := factory.registry.Encode(e)
+	if err != nil {
+		return nil, fmt.Errorf("can't encode data of event %s: %v", e.EventID, err)
+	}
+	packed, err := ptypes.MarshalAny(payload)
 	if err != nil {
 		return nil, err
 	}
+
+	createdAt, err := ptypes.TimestampProto(e.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("invalid creation time of event %s: %v", e.EventID, err)
+	}
+
 	return &pb.Event{
-		EventId:       uuid.Must(uuid.NewV4()).String(),
-		Channel:       employeeEventChannel,
-		AggregateType: employeeEventChannel,
+		EventId:       e.EventID,
+		Channel:       e.Channel,
+		Type:          string(e.Type),
+		AggregateId:   e.AggregateID,
+		AggregateType: e.AggregateType,
+		Sequence:      e.Sequence,
 		Originator: &pb.Event_Claims{
-			EntityId: originator.EntityID.Hex(),
-			Entity:   originator.Entity,
-			Login:    originator.Login,
+			EntityId: e.Originator.EntityID.Hex(),
+			Entity:   e.Originator.Entity,
+			Login:    e.Originator.Login,
 		},
-		CreatedAt: createdAt,
+		CreatedAt:     createdAt,
+		Payload:       packed,
+		SchemaVersion: event.SchemaVersion,
+		CorrelationId: e.CorrelationID,
+		CausationId:   e.CausationID,
 	}, nil
 }
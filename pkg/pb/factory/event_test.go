@@ -0,0 +1,70 @@
+package factory
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/migotom/cell-centre-services/pkg/components/event"
+	"github.com/migotom/cell-centre-services/pkg/entities"
+	"github.com/migotom/cell-centre-services/pkg/helpers"
+	"github.com/migotom/cell-centre-services/pkg/helpers/correlation"
+	"github.com/migotom/cell-centre-services/pkg/pb"
+)
+
+func TestNewEvent(t *testing.T) {
+	registry := event.NewRegistry()
+	if err := registry.RegisterEvent(event.Definition{Type: entities.NewEmployeeEvent, Channel: "employees", AggregateType: "employees", Payload: &pb.Employee{}}); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		Name        string
+		Type        entities.EventType
+		Payload     proto.Message
+		ExpectedErr string
+	}{
+		{
+			Name:    "Registered event",
+			Type:    entities.NewEmployeeEvent,
+			Payload: &pb.Employee{Id: "42", Email: "admin@page.com"},
+		},
+		{
+			Name:        "Unknown event",
+			Type:        entities.DeleteEmployeeEvent,
+			Payload:     &pb.EmployeeFilter{Id: "42"},
+			ExpectedErr: "unknown event DeleteEmployee",
+		},
+		{
+			Name:        "Invalid payload",
+			Type:        entities.NewEmployeeEvent,
+			Payload:     &pb.EmployeeFilter{Id: "42"},
+			ExpectedErr: "invalid payload pb.EmployeeFilter of event NewEmployee, expected pb.Employee",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			ctx := correlation.NewContext(context.Background(), "request-1", "")
+			e, err := NewEventPbFactory(registry).New(ctx, entities.TokenClaims{Login: "admin@page.com"}, tc.Type, "42", tc.Payload)
+			helpers.AssertErrors(t, tc.ExpectedErr, err)
+			if err != nil {
+				return
+			}
+
+			assert.Equal(t, "employees", e.Channel)
+			assert.Equal(t, "employees", e.AggregateType)
+			assert.Equal(t, "42", e.AggregateId)
+			assert.Equal(t, uint32(event.SchemaVersion), e.SchemaVersion)
+			assert.Equal(t, "request-1", e.CorrelationId)
+			assert.Empty(t, e.CausationId)
+			assert.Empty(t, e.Originator.Login, "login of originator isn't erasable")
+
+			var payload pb.Employee
+			assert.NoError(t, ptypes.UnmarshalAny(e.Payload, &payload))
+			assert.True(t, proto.Equal(tc.Payload, &payload))
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package factory
+
+import (
+	"github.com/golang/protobuf/ptypes"
+
+	"github.com/migotom/cell-centre-services/pkg/entities"
+	"github.com/migotom/cell-centre-services/pkg/pb"
+)
+
+// WebhookPbFactory is pb.Webhook and pb.WebhookAttempt factory.
+type WebhookPbFactory struct {
+}
+
+// NewWebhookPbFactory creates new factory.
+func NewWebhookPbFactory() *WebhookPbFactory {
+	return &WebhookPbFactory{}
+}
+
+// NewFromWebhook creates new pb.Webhook instance from Webhook entity, secret is not included.
+func (factory *WebhookPbFactory) NewFromWebhook(e *entities.Webhook) (*pb.Webhook, error) {
+	webhook := pb.Webhook{
+		Id:         e.ID.Hex(),
+		Url:        e.URL,
+		Channels:   e.Channels,
+		EventTypes: e.EventTypes,
+		Format:     e.Format,
+	}
+
+	if e.CreatedAt != nil {
+		createdAt, err := ptypes.TimestampProto(*e.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		webhook.CreatedAt = createdAt
+	}
+	return &webhook, nil
+}
+
+// NewFromWebhookAttempt creates new pb.WebhookAttempt instance from WebhookAttempt entity.
+func (factory *WebhookPbFactory) NewFromWebhookAttempt(e *entities.WebhookAttempt) (*pb.WebhookAttempt, error) {
+	createdAt, err := ptypes.TimestampProto(e.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.WebhookAttempt{
+		Id:         e.ID.Hex(),
+		WebhookId:  e.WebhookID.Hex(),
+		EventId:    e.EventID,
+		EventType:  e.EventType,
+		Attempt:    uint32(e.Attempt),
+		StatusCode: int32(e.StatusCode),
+		Error:      e.Error,
+		Delivered:  e.Delivered,
+		DurationMs: e.Duration.Milliseconds(),
+		CreatedAt:  createdAt,
+	}, nil
+}
@@ -0,0 +1,747 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// source: webhook.proto
+
+package pb
+
+import (
+	context "context"
+	fmt "fmt"
+	proto "github.com/golang/protobuf/proto"
+	empty "github.com/golang/protobuf/ptypes/empty"
+	timestamp "github.com/golang/protobuf/ptypes/timestamp"
+	_ "google.golang.org/genproto/googleapis/api/annotations"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	math "math"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
+
+type Webhook struct {
+	Id  string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Url string `protobuf:"bytes,2,opt,name=url,proto3" json:"url,omitempty"`
+	// channels of events delivered to webhook
+	Channels []string `protobuf:"bytes,3,rep,name=channels,proto3" json:"channels,omitempty"`
+	// types of delivered events, all types when empty
+	EventTypes []string `protobuf:"bytes,4,rep,name=event_types,json=eventTypes,proto3" json:"event_types,omitempty"`
+	// secret of HMAC-SHA256 signature, returned only once webhook is created
+	Secret    string               `protobuf:"bytes,5,opt,name=secret,proto3" json:"secret,omitempty"`
+	CreatedAt *timestamp.Timestamp `protobuf:"bytes,6,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	// wire format of delivered events: json, cloudevents (structured mode) or cloudevents-binary
+	Format               string   `protobuf:"bytes,7,opt,name=format,proto3" json:"format,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Webhook) Reset()         { *m = Webhook{} }
+func (m *Webhook) String() string { return proto.CompactTextString(m) }
+func (*Webhook) ProtoMessage()    {}
+func (*Webhook) Descriptor() ([]byte, []int) {
+	return fileDescriptor_4a0479a603100288, []int{0}
+}
+
+func (m *Webhook) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Webhook.Unmarshal(m, b)
+}
+func (m *Webhook) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Webhook.Marshal(b, m, deterministic)
+}
+func (m *Webhook) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Webhook.Merge(m, src)
+}
+func (m *Webhook) XXX_Size() int {
+	return xxx_messageInfo_Webhook.Size(m)
+}
+func (m *Webhook) XXX_DiscardUnknown() {
+	xxx_messageInfo_Webhook.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Webhook proto.InternalMessageInfo
+
+func (m *Webhook) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *Webhook) GetUrl() string {
+	if m != nil {
+		return m.Url
+	}
+	return ""
+}
+
+func (m *Webhook) GetChannels() []string {
+	if m != nil {
+		return m.Channels
+	}
+	return nil
+}
+
+func (m *Webhook) GetEventTypes() []string {
+	if m != nil {
+		return m.EventTypes
+	}
+	return nil
+}
+
+func (m *Webhook) GetSecret() string {
+	if m != nil {
+		return m.Secret
+	}
+	return ""
+}
+
+func (m *Webhook) GetCreatedAt() *timestamp.Timestamp {
+	if m != nil {
+		return m.CreatedAt
+	}
+	return nil
+}
+
+func (m *Webhook) GetFormat() string {
+	if m != nil {
+		return m.Format
+	}
+	return ""
+}
+
+type WebhookFilter struct {
+	Id                   string   `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *WebhookFilter) Reset()         { *m = WebhookFilter{} }
+func (m *WebhookFilter) String() string { return proto.CompactTextString(m) }
+func (*WebhookFilter) ProtoMessage()    {}
+func (*WebhookFilter) Descriptor() ([]byte, []int) {
+	return fileDescriptor_4a0479a603100288, []int{1}
+}
+
+func (m *WebhookFilter) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_WebhookFilter.Unmarshal(m, b)
+}
+func (m *WebhookFilter) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_WebhookFilter.Marshal(b, m, deterministic)
+}
+func (m *WebhookFilter) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_WebhookFilter.Merge(m, src)
+}
+func (m *WebhookFilter) XXX_Size() int {
+	return xxx_messageInfo_WebhookFilter.Size(m)
+}
+func (m *WebhookFilter) XXX_DiscardUnknown() {
+	xxx_messageInfo_WebhookFilter.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_WebhookFilter proto.InternalMessageInfo
+
+func (m *WebhookFilter) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+type NewWebhookRequest struct {
+	Url        string   `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"`
+	Channels   []string `protobuf:"bytes,2,rep,name=channels,proto3" json:"channels,omitempty"`
+	EventTypes []string `protobuf:"bytes,3,rep,name=event_types,json=eventTypes,proto3" json:"event_types,omitempty"`
+	// secret of HMAC-SHA256 signature, generated when empty
+	Secret string `protobuf:"bytes,4,opt,name=secret,proto3" json:"secret,omitempty"`
+	// wire format of delivered events, json when empty
+	Format               string   `protobuf:"bytes,5,opt,name=format,proto3" json:"format,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *NewWebhookRequest) Reset()         { *m = NewWebhookRequest{} }
+func (m *NewWebhookRequest) String() string { return proto.CompactTextString(m) }
+func (*NewWebhookRequest) ProtoMessage()    {}
+func (*NewWebhookRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_4a0479a603100288, []int{2}
+}
+
+func (m *NewWebhookRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_NewWebhookRequest.Unmarshal(m, b)
+}
+func (m *NewWebhookRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_NewWebhookRequest.Marshal(b, m, deterministic)
+}
+func (m *NewWebhookRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_NewWebhookRequest.Merge(m, src)
+}
+func (m *NewWebhookRequest) XXX_Size() int {
+	return xxx_messageInfo_NewWebhookRequest.Size(m)
+}
+func (m *NewWebhookRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_NewWebhookRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_NewWebhookRequest proto.InternalMessageInfo
+
+func (m *NewWebhookRequest) GetUrl() string {
+	if m != nil {
+		return m.Url
+	}
+	return ""
+}
+
+func (m *NewWebhookRequest) GetChannels() []string {
+	if m != nil {
+		return m.Channels
+	}
+	return nil
+}
+
+func (m *NewWebhookRequest) GetEventTypes() []string {
+	if m != nil {
+		return m.EventTypes
+	}
+	return nil
+}
+
+func (m *NewWebhookRequest) GetSecret() string {
+	if m != nil {
+		return m.Secret
+	}
+	return ""
+}
+
+func (m *NewWebhookRequest) GetFormat() string {
+	if m != nil {
+		return m.Format
+	}
+	return ""
+}
+
+type WebhookList struct {
+	Webhooks             []*Webhook `protobuf:"bytes,1,rep,name=webhooks,proto3" json:"webhooks,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}   `json:"-"`
+	XXX_unrecognized     []byte     `json:"-"`
+	XXX_sizecache        int32      `json:"-"`
+}
+
+func (m *WebhookList) Reset()         { *m = WebhookList{} }
+func (m *WebhookList) String() string { return proto.CompactTextString(m) }
+func (*WebhookList) ProtoMessage()    {}
+func (*WebhookList) Descriptor() ([]byte, []int) {
+	return fileDescriptor_4a0479a603100288, []int{3}
+}
+
+func (m *WebhookList) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_WebhookList.Unmarshal(m, b)
+}
+func (m *WebhookList) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_WebhookList.Marshal(b, m, deterministic)
+}
+func (m *WebhookList) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_WebhookList.Merge(m, src)
+}
+func (m *WebhookList) XXX_Size() int {
+	return xxx_messageInfo_WebhookList.Size(m)
+}
+func (m *WebhookList) XXX_DiscardUnknown() {
+	xxx_messageInfo_WebhookList.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_WebhookList proto.InternalMessageInfo
+
+func (m *WebhookList) GetWebhooks() []*Webhook {
+	if m != nil {
+		return m.Webhooks
+	}
+	return nil
+}
+
+type WebhookAttempt struct {
+	Id        string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	WebhookId string `protobuf:"bytes,2,opt,name=webhook_id,json=webhookId,proto3" json:"webhook_id,omitempty"`
+	EventId   string `protobuf:"bytes,3,opt,name=event_id,json=eventId,proto3" json:"event_id,omitempty"`
+	EventType string `protobuf:"bytes,4,opt,name=event_type,json=eventType,proto3" json:"event_type,omitempty"`
+	Attempt   uint32 `protobuf:"varint,5,opt,name=attempt,proto3" json:"attempt,omitempty"`
+	// HTTP status code of response, 0 when request failed
+	StatusCode           int32                `protobuf:"varint,6,opt,name=status_code,json=statusCode,proto3" json:"status_code,omitempty"`
+	Error                string               `protobuf:"bytes,7,opt,name=error,proto3" json:"error,omitempty"`
+	Delivered            bool                 `protobuf:"varint,8,opt,name=delivered,proto3" json:"delivered,omitempty"`
+	DurationMs           int64                `protobuf:"varint,9,opt,name=duration_ms,json=durationMs,proto3" json:"duration_ms,omitempty"`
+	CreatedAt            *timestamp.Timestamp `protobuf:"bytes,10,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
+}
+
+func (m *WebhookAttempt) Reset()         { *m = WebhookAttempt{} }
+func (m *WebhookAttempt) String() string { return proto.CompactTextString(m) }
+func (*WebhookAttempt) ProtoMessage()    {}
+func (*WebhookAttempt) Descriptor() ([]byte, []int) {
+	return fileDescriptor_4a0479a603100288, []int{4}
+}
+
+func (m *WebhookAttempt) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_WebhookAttempt.Unmarshal(m, b)
+}
+func (m *WebhookAttempt) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_WebhookAttempt.Marshal(b, m, deterministic)
+}
+func (m *WebhookAttempt) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_WebhookAttempt.Merge(m, src)
+}
+func (m *WebhookAttempt) XXX_Size() int {
+	return xxx_messageInfo_WebhookAttempt.Size(m)
+}
+func (m *WebhookAttempt) XXX_DiscardUnknown() {
+	xxx_messageInfo_WebhookAttempt.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_WebhookAttempt proto.InternalMessageInfo
+
+func (m *WebhookAttempt) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *WebhookAttempt) GetWebhookId() string {
+	if m != nil {
+		return m.WebhookId
+	}
+	return ""
+}
+
+func (m *WebhookAttempt) GetEventId() string {
+	if m != nil {
+		return m.EventId
+	}
+	return ""
+}
+
+func (m *WebhookAttempt) GetEventType() string {
+	if m != nil {
+		return m.EventType
+	}
+	return ""
+}
+
+func (m *WebhookAttempt) GetAttempt() uint32 {
+	if m != nil {
+		return m.Attempt
+	}
+	return 0
+}
+
+func (m *WebhookAttempt) GetStatusCode() int32 {
+	if m != nil {
+		return m.StatusCode
+	}
+	return 0
+}
+
+func (m *WebhookAttempt) GetError() string {
+	if m != nil {
+		return m.Error
+	}
+	return ""
+}
+
+func (m *WebhookAttempt) GetDelivered() bool {
+	if m != nil {
+		return m.Delivered
+	}
+	return false
+}
+
+func (m *WebhookAttempt) GetDurationMs() int64 {
+	if m != nil {
+		return m.DurationMs
+	}
+	return 0
+}
+
+func (m *WebhookAttempt) GetCreatedAt() *timestamp.Timestamp {
+	if m != nil {
+		return m.CreatedAt
+	}
+	return nil
+}
+
+type WebhookAttemptsRequest struct {
+	WebhookId string `protobuf:"bytes,1,opt,name=webhook_id,json=webhookId,proto3" json:"webhook_id,omitempty"`
+	// maximum number of returned attempts, starting with the latest one
+	Limit                int64    `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *WebhookAttemptsRequest) Reset()         { *m = WebhookAttemptsRequest{} }
+func (m *WebhookAttemptsRequest) String() string { return proto.CompactTextString(m) }
+func (*WebhookAttemptsRequest) ProtoMessage()    {}
+func (*WebhookAttemptsRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_4a0479a603100288, []int{5}
+}
+
+func (m *WebhookAttemptsRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_WebhookAttemptsRequest.Unmarshal(m, b)
+}
+func (m *WebhookAttemptsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_WebhookAttemptsRequest.Marshal(b, m, deterministic)
+}
+func (m *WebhookAttemptsRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_WebhookAttemptsRequest.Merge(m, src)
+}
+func (m *WebhookAttemptsRequest) XXX_Size() int {
+	return xxx_messageInfo_WebhookAttemptsRequest.Size(m)
+}
+func (m *WebhookAttemptsRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_WebhookAttemptsRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_WebhookAttemptsRequest proto.InternalMessageInfo
+
+func (m *WebhookAttemptsRequest) GetWebhookId() string {
+	if m != nil {
+		return m.WebhookId
+	}
+	return ""
+}
+
+func (m *WebhookAttemptsRequest) GetLimit() int64 {
+	if m != nil {
+		return m.Limit
+	}
+	return 0
+}
+
+type WebhookAttemptList struct {
+	Attempts             []*WebhookAttempt `protobuf:"bytes,1,rep,name=attempts,proto3" json:"attempts,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
+	XXX_unrecognized     []byte            `json:"-"`
+	XXX_sizecache        int32             `json:"-"`
+}
+
+func (m *WebhookAttemptList) Reset()         { *m = WebhookAttemptList{} }
+func (m *WebhookAttemptList) String() string { return proto.CompactTextString(m) }
+func (*WebhookAttemptList) ProtoMessage()    {}
+func (*WebhookAttemptList) Descriptor() ([]byte, []int) {
+	return fileDescriptor_4a0479a603100288, []int{6}
+}
+
+func (m *WebhookAttemptList) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_WebhookAttemptList.Unmarshal(m, b)
+}
+func (m *WebhookAttemptList) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_WebhookAttemptList.Marshal(b, m, deterministic)
+}
+func (m *WebhookAttemptList) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_WebhookAttemptList.Merge(m, src)
+}
+func (m *WebhookAttemptList) XXX_Size() int {
+	return xxx_messageInfo_WebhookAttemptList.Size(m)
+}
+func (m *WebhookAttemptList) XXX_DiscardUnknown() {
+	xxx_messageInfo_WebhookAttemptList.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_WebhookAttemptList proto.InternalMessageInfo
+
+func (m *WebhookAttemptList) GetAttempts() []*WebhookAttempt {
+	if m != nil {
+		return m.Attempts
+	}
+	return nil
+}
+
+func init() {
+	proto.RegisterType((*Webhook)(nil), "pb.Webhook")
+	proto.RegisterType((*WebhookFilter)(nil), "pb.WebhookFilter")
+	proto.RegisterType((*NewWebhookRequest)(nil), "pb.NewWebhookRequest")
+	proto.RegisterType((*WebhookList)(nil), "pb.WebhookList")
+	proto.RegisterType((*WebhookAttempt)(nil), "pb.WebhookAttempt")
+	proto.RegisterType((*WebhookAttemptsRequest)(nil), "pb.WebhookAttemptsRequest")
+	proto.RegisterType((*WebhookAttemptList)(nil), "pb.WebhookAttemptList")
+}
+
+func init() { proto.RegisterFile("webhook.proto", fileDescriptor_4a0479a603100288) }
+
+var fileDescriptor_4a0479a603100288 = []byte{
+	// 597 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x94, 0x54, 0xcd, 0x6e, 0xd3, 0x4c,
+	0x14, 0xad, 0xe3, 0xa6, 0xb1, 0x6f, 0xbe, 0xf4, 0xa3, 0x43, 0x89, 0x06, 0x53, 0x54, 0xcb, 0x1b,
+	0xbc, 0x72, 0xa5, 0x20, 0x21, 0x55, 0x62, 0x53, 0x51, 0x40, 0x15, 0x94, 0x85, 0xa9, 0xc4, 0x32,
+	0x72, 0xe2, 0xdb, 0x76, 0x84, 0xed, 0x31, 0x33, 0x93, 0x56, 0x7d, 0x0d, 0x1e, 0x87, 0x35, 0x6f,
+	0xc0, 0x0b, 0x21, 0xcf, 0x8c, 0xf3, 0x0b, 0x91, 0xd8, 0xe5, 0x9e, 0xfb, 0x33, 0xe7, 0xdc, 0x73,
+	0x1d, 0x18, 0xdc, 0xe3, 0xe4, 0x96, 0xf3, 0xaf, 0x49, 0x2d, 0xb8, 0xe2, 0xa4, 0x53, 0x4f, 0x82,
+	0xe3, 0x1b, 0xce, 0x6f, 0x0a, 0x3c, 0xd1, 0xc8, 0x64, 0x76, 0x7d, 0xa2, 0x58, 0x89, 0x52, 0x65,
+	0x65, 0x6d, 0x8a, 0x82, 0x23, 0x5b, 0x90, 0xd5, 0xec, 0x24, 0xab, 0x2a, 0xae, 0x32, 0xc5, 0x78,
+	0x25, 0x6d, 0xf6, 0xd9, 0x7a, 0x3b, 0x96, 0xb5, 0x7a, 0x30, 0xc9, 0xe8, 0x97, 0x03, 0xbd, 0x2f,
+	0xe6, 0x45, 0xb2, 0x0f, 0x1d, 0x96, 0x53, 0x27, 0x74, 0x62, 0x3f, 0xed, 0xb0, 0x9c, 0x3c, 0x02,
+	0x77, 0x26, 0x0a, 0xda, 0xd1, 0x40, 0xf3, 0x93, 0x04, 0xe0, 0x4d, 0x6f, 0xb3, 0xaa, 0xc2, 0x42,
+	0x52, 0x37, 0x74, 0x63, 0x3f, 0x9d, 0xc7, 0xe4, 0x18, 0xfa, 0x78, 0x87, 0x95, 0x1a, 0xab, 0x87,
+	0x1a, 0x25, 0xdd, 0xd5, 0x69, 0xd0, 0xd0, 0x55, 0x83, 0x90, 0x21, 0xec, 0x49, 0x9c, 0x0a, 0x54,
+	0xb4, 0xab, 0x27, 0xda, 0x88, 0x9c, 0x02, 0x4c, 0x05, 0x66, 0x0a, 0xf3, 0x71, 0xa6, 0xe8, 0x5e,
+	0xe8, 0xc4, 0xfd, 0x51, 0x90, 0x18, 0xd2, 0x49, 0x4b, 0x3a, 0xb9, 0x6a, 0x35, 0xa7, 0xbe, 0xad,
+	0x3e, 0x53, 0xcd, 0xc8, 0x6b, 0x2e, 0xca, 0x4c, 0xd1, 0x9e, 0x19, 0x69, 0xa2, 0xe8, 0x18, 0x06,
+	0x56, 0xd4, 0x3b, 0x56, 0x28, 0x14, 0xeb, 0xd2, 0xa2, 0xef, 0x0e, 0x1c, 0x7c, 0xc2, 0x7b, 0x5b,
+	0x94, 0xe2, 0xb7, 0x19, 0x4a, 0xd5, 0x0a, 0x76, 0xfe, 0x2c, 0xb8, 0xb3, 0x5d, 0xb0, 0xbb, 0x45,
+	0xf0, 0xee, 0x8a, 0xe0, 0x05, 0xeb, 0xee, 0x0a, 0xeb, 0x57, 0xd0, 0xb7, 0x84, 0x3e, 0x32, 0xa9,
+	0xc8, 0x0b, 0xf0, 0xec, 0x2d, 0x48, 0xea, 0x84, 0x6e, 0xdc, 0x1f, 0xf5, 0x93, 0x7a, 0x92, 0xb4,
+	0x9c, 0xe7, 0xc9, 0xe8, 0x67, 0x07, 0xf6, 0x2d, 0x7a, 0xa6, 0x54, 0xe3, 0xee, 0x86, 0x95, 0xcf,
+	0x01, 0x6c, 0xf9, 0x98, 0xe5, 0xd6, 0x51, 0xdf, 0x22, 0x17, 0x39, 0x79, 0x0a, 0x9e, 0x91, 0xc2,
+	0x72, 0xea, 0xea, 0x64, 0x4f, 0xc7, 0x17, 0xba, 0x73, 0xa1, 0xd2, 0x0a, 0xf1, 0xe7, 0x22, 0x09,
+	0x85, 0x5e, 0x66, 0xde, 0xd4, 0x62, 0x06, 0x69, 0x1b, 0x36, 0xeb, 0x91, 0x2a, 0x53, 0x33, 0x39,
+	0x9e, 0xf2, 0x1c, 0xb5, 0xaf, 0xdd, 0x14, 0x0c, 0xf4, 0x86, 0xe7, 0x48, 0x0e, 0xa1, 0x8b, 0x42,
+	0x70, 0x61, 0xbd, 0x33, 0x01, 0x39, 0x02, 0x3f, 0xc7, 0x82, 0xdd, 0xa1, 0xc0, 0x9c, 0x7a, 0xa1,
+	0x13, 0x7b, 0xe9, 0x02, 0x68, 0x86, 0xe6, 0x33, 0xa1, 0xcf, 0x7b, 0x5c, 0x4a, 0xea, 0x87, 0x4e,
+	0xec, 0xa6, 0xd0, 0x42, 0x97, 0x72, 0xed, 0x98, 0xe0, 0x1f, 0x8e, 0x29, 0xba, 0x84, 0xe1, 0xea,
+	0x16, 0x65, 0x7b, 0x17, 0xab, 0xdb, 0x73, 0xd6, 0xb7, 0x77, 0x08, 0xdd, 0x82, 0x95, 0x4c, 0xe9,
+	0xbd, 0xba, 0xa9, 0x09, 0xa2, 0x73, 0x20, 0xab, 0xe3, 0xb4, 0xa9, 0x09, 0x78, 0x76, 0x41, 0xad,
+	0xa9, 0x64, 0xc9, 0x54, 0x5b, 0x99, 0xce, 0x6b, 0x46, 0x3f, 0x16, 0xde, 0x7e, 0x46, 0x71, 0xc7,
+	0xa6, 0x48, 0x12, 0x80, 0xf7, 0xa8, 0x2c, 0x48, 0x0e, 0x96, 0xda, 0xcd, 0xb1, 0x07, 0xcb, 0x67,
+	0x12, 0xed, 0x90, 0x53, 0xf8, 0xaf, 0x79, 0xda, 0x02, 0x92, 0x0c, 0x37, 0xd6, 0xf1, 0xb6, 0xf9,
+	0x43, 0x08, 0xfe, 0x5f, 0x6a, 0x6b, 0x1a, 0xa2, 0x1d, 0x32, 0x02, 0x58, 0x7c, 0x25, 0xe4, 0x49,
+	0x53, 0xb0, 0xf1, 0xd5, 0xac, 0x3f, 0xf7, 0x1a, 0x06, 0xe7, 0x58, 0xa0, 0xc2, 0x2d, 0x0c, 0xff,
+	0x42, 0x21, 0xda, 0x21, 0x1f, 0xe0, 0xf1, 0x12, 0xd9, 0xd6, 0x08, 0x12, 0x6c, 0x2e, 0xa9, 0x75,
+	0x27, 0x18, 0x6e, 0xe6, 0x0c, 0xfd, 0xc9, 0x9e, 0x1e, 0xff, 0xf2, 0xf7, 0x00, 0xb0, 0xeb, 0x88,
+	0xee, 0x54, 0x05, 0x00, 0x00,
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// WebhookServiceClient is the client API for WebhookService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type WebhookServiceClient interface {
+	GetWebhook(ctx context.Context, in *WebhookFilter, opts ...grpc.CallOption) (*Webhook, error)
+	ListWebhooks(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*WebhookList, error)
+	NewWebhook(ctx context.Context, in *NewWebhookRequest, opts ...grpc.CallOption) (*Webhook, error)
+	DeleteWebhook(ctx context.Context, in *WebhookFilter, opts ...grpc.CallOption) (*empty.Empty, error)
+	ListWebhookAttempts(ctx context.Context, in *WebhookAttemptsRequest, opts ...grpc.CallOption) (*WebhookAttemptList, error)
+}
+
+type webhookServiceClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewWebhookServiceClient(cc *grpc.ClientConn) WebhookServiceClient {
+	return &webhookServiceClient{cc}
+}
+
+func (c *webhookServiceClient) GetWebhook(ctx context.Context, in *WebhookFilter, opts ...grpc.CallOption) (*Webhook, error) {
+	out := new(Webhook)
+	err := c.cc.Invoke(ctx, "/pb.WebhookService/GetWebhook", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *webhookServiceClient) ListWebhooks(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*WebhookList, error) {
+	out := new(WebhookList)
+	err := c.cc.Invoke(ctx, "/pb.WebhookService/ListWebhooks", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *webhookServiceClient) NewWebhook(ctx context.Context, in *NewWebhookRequest, opts ...grpc.CallOption) (*Webhook, error) {
+	out := new(Webhook)
+	err := c.cc.Invoke(ctx, "/pb.WebhookService/NewWebhook", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *webhookServiceClient) DeleteWebhook(ctx context.Context, in *WebhookFilter, opts ...grpc.CallOption) (*empty.Empty, error) {
+	out := new(empty.Empty)
+	err := c.cc.Invoke(ctx, "/pb.WebhookService/DeleteWebhook", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *webhookServiceClient) ListWebhookAttempts(ctx context.Context, in *WebhookAttemptsRequest, opts ...grpc.CallOption) (*WebhookAttemptList, error) {
+	out := new(WebhookAttemptList)
+	err := c.cc.Invoke(ctx, "/pb.WebhookService/ListWebhookAttempts", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// WebhookServiceServer is the server API for WebhookService service.
+type WebhookServiceServer interface {
+	GetWebhook(context.Context, *WebhookFilter) (*Webhook, error)
+	ListWebhooks(context.Context, *empty.Empty) (*WebhookList, error)
+	NewWebhook(context.Context, *NewWebhookRequest) (*Webhook, error)
+	DeleteWebhook(context.Context, *WebhookFilter) (*empty.Empty, error)
+	ListWebhookAttempts(context.Context, *WebhookAttemptsRequest) (*WebhookAttemptList, error)
+}
+
+// UnimplementedWebhookServiceServer can be embedded to have forward compatible implementations.
+type UnimplementedWebhookServiceServer struct {
+}
+
+func (*UnimplementedWebhookServiceServer) GetWebhook(ctx context.Context, req *WebhookFilter) (*Webhook, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetWebhook not implemented")
+}
+func (*UnimplementedWebhookServiceServer) ListWebhooks(ctx context.Context, req *empty.Empty) (*WebhookList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListWebhooks not implemented")
+}
+func (*UnimplementedWebhookServiceServer) NewWebhook(ctx context.Context, req *NewWebhookRequest) (*Webhook, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method NewWebhook not implemented")
+}
+func (*UnimplementedWebhookServiceServer) DeleteWebhook(ctx context.Context, req *WebhookFilter) (*empty.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteWebhook not implemented")
+}
+func (*UnimplementedWebhookServiceServer) ListWebhookAttempts(ctx context.Context, req *WebhookAttemptsRequest) (*WebhookAttemptList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListWebhookAttempts not implemented")
+}
+
+func RegisterWebhookServiceServer(s *grpc.Server, srv WebhookServiceServer) {
+	s.RegisterService(&_WebhookService_serviceDesc, srv)
+}
+
+func _WebhookService_GetWebhook_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(WebhookFilter)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WebhookServiceServer).GetWebhook(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.WebhookService/GetWebhook",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WebhookServiceServer).GetWebhook(ctx, req.(*WebhookFilter))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WebhookService_ListWebhooks_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(empty.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WebhookServiceServer).ListWebhooks(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.WebhookService/ListWebhooks",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WebhookServiceServer).ListWebhooks(ctx, req.(*empty.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WebhookService_NewWebhook_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(NewWebhookRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WebhookServiceServer).NewWebhook(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.WebhookService/NewWebhook",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WebhookServiceServer).NewWebhook(ctx, req.(*NewWebhookRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WebhookService_DeleteWebhook_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(WebhookFilter)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WebhookServiceServer).DeleteWebhook(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.WebhookService/DeleteWebhook",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WebhookServiceServer).DeleteWebhook(ctx, req.(*WebhookFilter))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WebhookService_ListWebhookAttempts_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(WebhookAttemptsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WebhookServiceServer).ListWebhookAttempts(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.WebhookService/ListWebhookAttempts",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WebhookServiceServer).ListWebhookAttempts(ctx, req.(*WebhookAttemptsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _WebhookService_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "pb.WebhookService",
+	HandlerType: (*WebhookServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetWebhook",
+			Handler:    _WebhookService_GetWebhook_Handler,
+		},
+		{
+			MethodName: "ListWebhooks",
+			Handler:    _WebhookService_ListWebhooks_Handler,
+		},
+		{
+			MethodName: "NewWebhook",
+			Handler:    _WebhookService_NewWebhook_Handler,
+		},
+		{
+			MethodName: "DeleteWebhook",
+			Handler:    _WebhookService_DeleteWebhook_Handler,
+		},
+		{
+			MethodName: "ListWebhookAttempts",
+			Handler:    _WebhookService_ListWebhookAttempts_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "webhook.proto",
+}
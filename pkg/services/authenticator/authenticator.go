@@ -1,36 +1,48 @@
 package authenticator
 
 import (
+	"context"
+	"errors"
 	"log"
 	"net"
 
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/testdata"
 
 	authDelivery "github.com/migotom/cell-centre-services/pkg/components/auth/delivery/grpc"
+	"github.com/migotom/cell-centre-services/pkg/components/health"
+	healthDelivery "github.com/migotom/cell-centre-services/pkg/components/health/delivery/grpc"
+	"github.com/migotom/cell-centre-services/pkg/helpers"
+	"github.com/migotom/cell-centre-services/pkg/helpers/interceptors"
+	"github.com/migotom/cell-centre-services/pkg/helpers/metrics"
+	"github.com/migotom/cell-centre-services/pkg/helpers/tracing"
 	"github.com/migotom/cell-centre-services/pkg/pb"
 )
 
 // Authenticator defines authenticator gRPC service.
 type Authenticator struct {
-	log          *zap.Logger
-	config       *Config
-	authDelivery *authDelivery.AuthenticateDelivery
+	log            *zap.Logger
+	config         *Config
+	authDelivery   *authDelivery.AuthenticateDelivery
+	healthDelivery *healthDelivery.HealthDelivery
 }
 
-// NewAuthenticator returns new authenticator gRPC service.
-func NewAuthenticator(log *zap.Logger, config *Config, authDelivery *authDelivery.AuthenticateDelivery) *Authenticator {
+// NewAuthenticator returns new authenticator gRPC service, given health delivery is served as grpc.health.v1
+// service. Health isn't served without it.
+func NewAuthenticator(log *zap.Logger, config *Config, authDelivery *authDelivery.AuthenticateDelivery, healthDelivery *healthDelivery.HealthDelivery) *Authenticator {
 	return &Authenticator{
-		log:          log,
-		config:       config,
-		authDelivery: authDelivery,
+		log:            log,
+		config:         config,
+		authDelivery:   authDelivery,
+		healthDelivery: healthDelivery,
 	}
 }
 
-// Listen gRPC service.
-func (authenticator *Authenticator) Listen() {
+// Listen gRPC service until given context is done, pending calls are finished before return.
+func (authenticator *Authenticator) Listen(ctx context.Context) {
 	listener, err := net.Listen("tcp", authenticator.config.ListenAddress)
 	if err != nil {
 		log.Fatal("Failed to listen", zap.Error(err))
@@ -47,9 +59,19 @@ func (authenticator *Authenticator) Listen() {
 		opts = []grpc.ServerOption{grpc.Creds(creds)}
 	}
 
+	opts = append(opts, interceptors.ServerOptions(authenticator.log, nil)...)
+
 	grpcServer := grpc.NewServer(opts...)
 	pb.RegisterAuthServiceServer(grpcServer, authenticator.authDelivery)
-	grpcServer.Serve(listener)
+	metrics.RegisterServer(grpcServer)
+	if authenticator.healthDelivery != nil {
+		healthpb.RegisterHealthServer(grpcServer, authenticator.healthDelivery)
+		go authenticator.healthDelivery.Run(ctx)
+	}
+	serve := func() error { return grpcServer.Serve(listener) }
+	if err := helpers.ServeGRPC(ctx, grpcServer, serve, authenticator.config.ShutdownTimeout.Duration); err != nil {
+		authenticator.log.Error("gRPC server stopped", zap.Error(err))
+	}
 }
 
 // Config of Authenticator service.
@@ -59,4 +81,34 @@ type Config struct {
 	DatabaseName           string `toml:"database_name"`
 	GRPCTLSCertificateFile string `toml:"grpc_tls_certificate_file"`
 	GRPCTLSKeyFile         string `toml:"grpc_tls_key_file"`
+	MetricsAddress         string `toml:"metrics_address"`
+
+	ShutdownTimeout     helpers.Duration `toml:"shutdown_timeout"`
+	HealthCheckInterval helpers.Duration `toml:"health_check_interval"`
+	Tracing             tracing.Config   `toml:"tracing"`
+}
+
+// SetDefaults sets default values of not configured options.
+func (config *Config) SetDefaults() {
+	if config.ShutdownTimeout.Duration == 0 {
+		config.ShutdownTimeout.Duration = helpers.DefaultShutdownTimeout
+	}
+	if config.HealthCheckInterval.Duration == 0 {
+		config.HealthCheckInterval.Duration = health.DefaultCheckInterval
+	}
+	config.Tracing.SetDefaults()
+}
+
+// Validate returns errors of missing or invalid options.
+func (config *Config) Validate() error {
+	errs := []error{
+		helpers.Required("listen_address", config.ListenAddress),
+		helpers.Required("database_address", config.DatabaseAddress),
+		helpers.Required("database_name", config.DatabaseName),
+		config.Tracing.Validate(),
+	}
+	if (config.GRPCTLSCertificateFile == "") != (config.GRPCTLSKeyFile == "") {
+		errs = append(errs, errors.New("grpc_tls_certificate_file and grpc_tls_key_file have to be set together"))
+	}
+	return errors.Join(errs...)
 }
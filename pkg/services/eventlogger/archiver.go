@@ -0,0 +1,198 @@
+package eventlogger
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/migotom/cell-centre-services/pkg/components/event"
+	"github.com/migotom/cell-centre-services/pkg/components/event/archive"
+	eventFactory "github.com/migotom/cell-centre-services/pkg/components/event/factory"
+	"github.com/migotom/cell-centre-services/pkg/entities"
+	"github.com/migotom/cell-centre-services/pkg/pb"
+	pbFactory "github.com/migotom/cell-centre-services/pkg/pb/factory"
+)
+
+const (
+	defaultArchiveFormat    = "json"
+	defaultArchiveBatchSize = 10000
+)
+
+// ArchiveStats summarizes archival of events.
+type ArchiveStats struct {
+	Archived int
+	Removed  int64
+	Files    int
+}
+
+// RestoreStats summarizes restoring of archived events.
+type RestoreStats struct {
+	Restored   int
+	Duplicates int
+	Files      int
+}
+
+// Archiver moves old events from event log into archive and restores them back.
+type Archiver struct {
+	log             *zap.Logger
+	config          *Config
+	eventRepository event.Repository
+	archive         *archive.Archive
+	eventFactory    *eventFactory.EventEntityFactory
+	eventPbFactory  *pbFactory.EventPbFactory
+}
+
+// NewArchiver returns new archiver of event log.
+func NewArchiver(log *zap.Logger, config *Config, eventRepository event.Repository, eventArchive *archive.Archive, eventRegistry *event.Registry) *Archiver {
+	return &Archiver{
+		log:             log,
+		config:          config,
+		eventRepository: eventRepository,
+		archive:         eventArchive,
+		eventFactory:    eventFactory.NewEventEntityFactory(eventRegistry),
+		eventPbFactory:  pbFactory.NewEventPbFactory(eventRegistry),
+	}
+}
+
+// Archive moves events created before given time into archive in batches, events of batch are removed from event
+// log once their files are listed by manifest. Events archived by interrupted run may be archived again,
+// readers of archive skip such duplicates.
+func (archiver *Archiver) Archive(ctx context.Context, before time.Time) (ArchiveStats, error) {
+	var stats ArchiveStats
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return stats, err
+		}
+
+		events, err := archiver.eventRepository.EventsBefore(ctx, before, archiver.config.ArchiveBatchSize)
+		if err != nil {
+			return stats, fmt.Errorf("can't read events: %v", err)
+		}
+		if len(events) == 0 {
+			return stats, nil
+		}
+
+		files, err := archiver.write(events)
+		stats.Files += files
+		if err != nil {
+			return stats, err
+		}
+
+		ids := make([]string, 0, len(events))
+		for _, e := range events {
+			ids = append(ids, e.EventID)
+		}
+		removed, err := archiver.eventRepository.Remove(ctx, ids)
+		stats.Removed += removed
+		if err != nil {
+			return stats, fmt.Errorf("can't remove archived events: %v", err)
+		}
+		stats.Archived += len(events)
+
+		archiver.log.Info("Events archived", zap.Int("archived", stats.Archived), zap.Int("files", stats.Files))
+
+		if len(events) < archiver.config.ArchiveBatchSize {
+			return stats, nil
+		}
+	}
+}
+
+// write stores events in archive files of their channels and days with their links in chains and documents links
+// were hashed from, returns number of written files.
+func (archiver *Archiver) write(events []*entities.Event) (int, error) {
+	type partition struct {
+		channel, day string
+	}
+
+	var partitions []partition
+	partitioned := make(map[partition][]*pb.Event)
+	chained := make(map[string]*entities.ChainedEvent)
+	for _, entity := range events {
+		e, err := archiver.eventPbFactory.NewFromEvent(entity)
+		if err != nil {
+			return 0, err
+		}
+
+		key := partition{channel: entity.Channel, day: entity.CreatedAt.UTC().Format(archive.DayLayout)}
+		if _, ok := partitioned[key]; !ok {
+			partitions = append(partitions, key)
+		}
+		partitioned[key] = append(partitioned[key], e)
+		if entity.Chain != nil {
+			chained[entity.EventID] = &entities.ChainedEvent{EventID: entity.EventID, Document: entity.Document, Link: entity.Chain}
+		}
+	}
+
+	for i, key := range partitions {
+		file, err := archiver.archive.Write(key.channel, key.day, partitioned[key], chained)
+		if err != nil {
+			return i, fmt.Errorf("can't archive events of channel %s and day %s: %v", key.channel, key.day, err)
+		}
+		archiver.log.Debug("Archive file written", zap.String("path", file.Path), zap.Int("events", file.Events))
+	}
+	return len(partitions), nil
+}
+
+// Restore stores events of archive files matching filter back into event log, events already stored are skipped.
+// Chained events are stored as documents their links were hashed from, so they keep their links in chain. Events
+// of links archived without documents are rebuilt from archive and their links match only when rebuilt documents
+// equal hashed ones. Events archived before they were chained are linked after head of chain. Restored files are
+// removed from archive unless they are kept.
+func (archiver *Archiver) Restore(ctx context.Context, filter archive.Filter, keep bool) (RestoreStats, error) {
+	var stats RestoreStats
+
+	files, err := archiver.archive.Files(filter)
+	if err != nil {
+		return stats, err
+	}
+
+	for _, file := range files {
+		if err := ctx.Err(); err != nil {
+			return stats, err
+		}
+
+		archived, err := archiver.archive.Read(file)
+		if err != nil {
+			return stats, err
+		}
+		chained, err := archiver.archive.Links(file)
+		if err != nil {
+			return stats, err
+		}
+
+		events := make([]*entities.Event, 0, len(archived))
+		for _, e := range archived {
+			entity, err := archiver.eventFactory.NewFromEvent(*e)
+			if err != nil {
+				return stats, fmt.Errorf("can't restore archive file %s: %v", file.Path, err)
+			}
+			if linked := chained[entity.EventID]; linked != nil {
+				entity.Chain, entity.Document = linked.Link, linked.Document
+			}
+			events = append(events, entity)
+		}
+
+		for i, err := range archiver.eventRepository.NewMany(ctx, events) {
+			switch err {
+			case nil:
+				stats.Restored++
+			case event.ErrDuplicateEvent:
+				stats.Duplicates++
+			default:
+				return stats, fmt.Errorf("can't restore event %s of archive file %s: %v", events[i].EventID, file.Path, err)
+			}
+		}
+		stats.Files++
+
+		if keep {
+			continue
+		}
+		if err := archiver.archive.Remove(file); err != nil {
+			return stats, fmt.Errorf("can't remove restored archive file %s: %v", file.Path, err)
+		}
+	}
+	return stats, nil
+}
@@ -0,0 +1,263 @@
+package eventlogger
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/zap"
+
+	"github.com/migotom/cell-centre-services/pkg/components/event"
+	"github.com/migotom/cell-centre-services/pkg/components/event/archive"
+	"github.com/migotom/cell-centre-services/pkg/components/event/chain"
+	"github.com/migotom/cell-centre-services/pkg/components/event/encoding"
+	"github.com/migotom/cell-centre-services/pkg/entities"
+	"github.com/migotom/cell-centre-services/pkg/helpers"
+	"github.com/migotom/cell-centre-services/pkg/helpers/mocks"
+)
+
+// storedEvent returns event as read back from event log.
+func storedEvent(id string, createdAt time.Time) *entities.Event {
+	employeeID, _ := primitive.ObjectIDFromHex("5d2f0c8e9a1b2c3d4e5f6a7b")
+	raw, _ := bson.Marshal(bson.M{"data": &entities.Employee{ID: employeeID, Email: "admin@page.com"}})
+
+	var document struct {
+		Data interface{}
+	}
+	bson.Unmarshal(raw, &document)
+
+	return &entities.Event{
+		EventID:       id,
+		Channel:       "employees",
+		Type:          entities.DeleteEmployeeEvent,
+		AggregateID:   employeeID.Hex(),
+		AggregateType: "employees",
+		Data:          document.Data,
+		CreatedAt:     createdAt,
+	}
+}
+
+func TestArchiverArchive(t *testing.T) {
+	before := time.Date(2019, 8, 1, 0, 0, 0, 0, time.UTC)
+	july := time.Date(2019, 7, 24, 20, 26, 40, 0, time.UTC)
+
+	cases := []struct {
+		Name              string
+		ExpectedMockCalls func(*mocks.EventRepositoryMock)
+		ExpectedStats     ArchiveStats
+		ExpectedDays      []string
+		ExpectedErr       string
+	}{
+		{
+			Name: "Events are archived per day in batches",
+			ExpectedMockCalls: func(r *mocks.EventRepositoryMock) {
+				r.On("EventsBefore", mock.Anything, before, 2).Return([]*entities.Event{
+					storedEvent("e1", july),
+					storedEvent("e2", july.Add(24*time.Hour)),
+				}, nil).Once()
+				r.On("Remove", mock.Anything, []string{"e1", "e2"}).Return(int64(2), nil).Once()
+				r.On("EventsBefore", mock.Anything, before, 2).Return([]*entities.Event{storedEvent("e3", july)}, nil).Once()
+				r.On("Remove", mock.Anything, []string{"e3"}).Return(int64(1), nil).Once()
+			},
+			ExpectedStats: ArchiveStats{Archived: 3, Removed: 3, Files: 3},
+			ExpectedDays:  []string{"2019-07-24", "2019-07-24", "2019-07-25"},
+		},
+		{
+			Name: "Events aren't removed when they can't be archived",
+			ExpectedMockCalls: func(r *mocks.EventRepositoryMock) {
+				e := storedEvent("e1", july)
+				e.Type = "RenameEmployee"
+				r.On("EventsBefore", mock.Anything, before, 2).Return([]*entities.Event{e}, nil)
+			},
+			ExpectedErr: "can't encode data of event e1: unknown event RenameEmployee",
+		},
+		{
+			Name: "Archived events failed to be removed",
+			ExpectedMockCalls: func(r *mocks.EventRepositoryMock) {
+				r.On("EventsBefore", mock.Anything, before, 2).Return([]*entities.Event{storedEvent("e1", july)}, nil)
+				r.On("Remove", mock.Anything, []string{"e1"}).Return(int64(0), errors.New("database down"))
+			},
+			ExpectedStats: ArchiveStats{Files: 1},
+			ExpectedDays:  []string{"2019-07-24"},
+			ExpectedErr:   "can't remove archived events: database down",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			eventRepositoryMock := mocks.EventRepositoryMock{}
+			tc.ExpectedMockCalls(&eventRepositoryMock)
+
+			eventArchive, err := archive.NewArchive(t.TempDir(), encoding.JSON)
+			assert.NoError(t, err)
+
+			archiver := NewArchiver(zap.NewNop(), &Config{ArchiveBatchSize: 2}, &eventRepositoryMock, eventArchive, newEventRegistry(t))
+			stats, err := archiver.Archive(context.Background(), before)
+			helpers.AssertErrors(t, tc.ExpectedErr, err)
+			assert.Equal(t, tc.ExpectedStats, stats)
+
+			files, err := eventArchive.Files(archive.Filter{})
+			assert.NoError(t, err)
+			var days []string
+			for _, file := range files {
+				days = append(days, file.Day)
+			}
+			assert.Equal(t, tc.ExpectedDays, days)
+			eventRepositoryMock.AssertExpectations(t)
+		})
+	}
+}
+
+func TestArchiverRestore(t *testing.T) {
+	july := time.Date(2019, 7, 24, 20, 26, 40, 0, time.UTC)
+
+	cases := []struct {
+		Name              string
+		Keep              bool
+		ExpectedMockCalls func(*mocks.EventRepositoryMock)
+		ExpectedStats     RestoreStats
+		ExpectedFiles     int
+		ExpectedErr       string
+	}{
+		{
+			Name: "Restored file is removed from archive",
+			ExpectedMockCalls: func(r *mocks.EventRepositoryMock) {
+				r.On("NewMany", mock.Anything, mock.MatchedBy(func(events []*entities.Event) bool {
+					employee, ok := events[0].Data.(*entities.Employee)
+					return len(events) == 2 && events[1].EventID == "e2" && ok && employee.Email == "admin@page.com" &&
+						reflect.DeepEqual(events[0].Chain, &entities.ChainLink{Position: 1, Hash: "h1"}) && events[1].Chain == nil
+				})).Return([]error{nil, event.ErrDuplicateEvent})
+			},
+			ExpectedStats: RestoreStats{Restored: 1, Duplicates: 1, Files: 1},
+		},
+		{
+			Name: "Restored file is kept",
+			Keep: true,
+			ExpectedMockCalls: func(r *mocks.EventRepositoryMock) {
+				r.On("NewMany", mock.Anything, mock.Anything).Return([]error{nil, nil})
+			},
+			ExpectedStats: RestoreStats{Restored: 2, Files: 1},
+			ExpectedFiles: 1,
+		},
+		{
+			Name: "File failed to be restored stays in archive",
+			ExpectedMockCalls: func(r *mocks.EventRepositoryMock) {
+				r.On("NewMany", mock.Anything, mock.Anything).Return([]error{nil, errors.New("database down")})
+			},
+			ExpectedStats: RestoreStats{Restored: 1},
+			ExpectedFiles: 1,
+			ExpectedErr:   "can't restore event e2 of archive file employees/2019-07-24/employees-2019-07-24-0001.jsonl.gz: database down",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			registry := newEventRegistry(t)
+
+			eventArchive, err := archive.NewArchive(t.TempDir(), encoding.CloudEventsStructured)
+			assert.NoError(t, err)
+
+			// the second event isn't chained yet
+			chained := storedEvent("e1", july)
+			chained.Chain = &entities.ChainLink{Position: 1, Hash: "h1"}
+
+			stored := mocks.EventRepositoryMock{}
+			stored.On("EventsBefore", mock.Anything, mock.Anything, mock.Anything).Return([]*entities.Event{chained, storedEvent("e2", july)}, nil)
+			stored.On("Remove", mock.Anything, mock.Anything).Return(int64(2), nil)
+			_, err = NewArchiver(zap.NewNop(), &Config{ArchiveBatchSize: 10}, &stored, eventArchive, registry).Archive(context.Background(), time.Now())
+			assert.NoError(t, err)
+
+			eventRepositoryMock := mocks.EventRepositoryMock{}
+			tc.ExpectedMockCalls(&eventRepositoryMock)
+
+			archiver := NewArchiver(zap.NewNop(), &Config{}, &eventRepositoryMock, eventArchive, registry)
+			stats, err := archiver.Restore(context.Background(), archive.Filter{Channel: "employees"}, tc.Keep)
+			helpers.AssertErrors(t, tc.ExpectedErr, err)
+			assert.Equal(t, tc.ExpectedStats, stats)
+
+			files, err := eventArchive.Files(archive.Filter{})
+			assert.NoError(t, err)
+			assert.Len(t, files, tc.ExpectedFiles)
+			eventRepositoryMock.AssertExpectations(t)
+		})
+	}
+}
+
+func TestArchiverRestoreVerify(t *testing.T) {
+	july := time.Date(2019, 7, 24, 20, 26, 40, 0, time.UTC)
+	employeeID := primitive.NewObjectID()
+
+	// documents stored before schema versions were introduced, login of originator is kept as it was stored
+	var events []*entities.Event
+	var previous string
+	for i, id := range []string{"e1", "e2"} {
+		document, err := bson.Marshal(bson.D{
+			{Key: "_id", Value: primitive.NewObjectID()},
+			{Key: "event_id", Value: id},
+			{Key: "channel", Value: "employees"},
+			{Key: "type", Value: "DeleteEmployee"},
+			{Key: "aggregateid", Value: employeeID.Hex()},
+			{Key: "aggregatetype", Value: "employees"},
+			{Key: "data", Value: bson.D{{Key: "_id", Value: employeeID}, {Key: "email", Value: "admin@page.com"}}},
+			{Key: "originator", Value: bson.D{{Key: "entity_id", Value: primitive.NewObjectID()}, {Key: "login", Value: "admin"}}},
+			{Key: "createdat", Value: july},
+		})
+		require.NoError(t, err)
+
+		var e entities.Event
+		require.NoError(t, bson.Unmarshal(document, &e))
+		e.Document = document
+		e.Chain = &entities.ChainLink{Position: uint64(i + 1), PreviousHash: previous}
+		e.Chain.Hash, err = chain.Hash(previous, e.Chain.Position, document)
+		require.NoError(t, err)
+		previous = e.Chain.Hash
+		events = append(events, &e)
+	}
+
+	registry := newEventRegistry(t)
+	eventArchive, err := archive.NewArchive(t.TempDir(), encoding.JSON)
+	require.NoError(t, err)
+
+	stored := mocks.EventRepositoryMock{}
+	stored.On("EventsBefore", mock.Anything, mock.Anything, mock.Anything).Return(events, nil)
+	stored.On("Remove", mock.Anything, []string{"e1", "e2"}).Return(int64(2), nil)
+	_, err = NewArchiver(zap.NewNop(), &Config{ArchiveBatchSize: 10}, &stored, eventArchive, registry).Archive(context.Background(), time.Now())
+	require.NoError(t, err)
+
+	var restored []*entities.Event
+	eventRepositoryMock := mocks.EventRepositoryMock{}
+	eventRepositoryMock.On("NewMany", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		restored = args.Get(1).([]*entities.Event)
+	}).Return([]error{nil, nil})
+	stats, err := NewArchiver(zap.NewNop(), &Config{}, &eventRepositoryMock, eventArchive, registry).Restore(context.Background(), archive.Filter{Channel: "employees"}, false)
+	require.NoError(t, err)
+	assert.Equal(t, RestoreStats{Restored: 2, Files: 1}, stats)
+
+	// events rebuilt from archive differ from hashed documents, restored events are stored as hashed documents
+	require.Len(t, restored, 2)
+	rebuilt := *restored[0]
+	rebuilt.Document, rebuilt.Chain = nil, nil
+	rebuiltDocument, err := bson.Marshal(&rebuilt)
+	require.NoError(t, err)
+	rebuiltHash, err := chain.Hash("", 1, rebuiltDocument)
+	require.NoError(t, err)
+	assert.NotEqual(t, events[0].Chain.Hash, rebuiltHash)
+
+	var chained []*entities.ChainedEvent
+	for _, e := range restored {
+		document, err := chain.Restored(e.Document, e.Chain)
+		require.NoError(t, err)
+		chained = append(chained, &entities.ChainedEvent{EventID: e.EventID, Document: document, Link: e.Chain})
+	}
+	chainRepositoryMock := mocks.ChainRepositoryMock{}
+	chainRepositoryMock.On("Chained", mock.Anything, "employees", uint64(0), chainBatchSize).Return(chained, nil)
+
+	report, err := NewChainer(zap.NewNop(), &Config{}, &chainRepositoryMock, eventArchive, nil, nil).Verify(context.Background(), "employees", nil)
+	require.NoError(t, err)
+	assert.Equal(t, ChainReport{Channel: "employees", From: 1, To: 2, Verified: 2}, report)
+}
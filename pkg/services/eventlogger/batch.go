@@ -0,0 +1,160 @@
+package eventlogger
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/migotom/cell-centre-services/pkg/components/event"
+	"github.com/migotom/cell-centre-services/pkg/entities"
+	"github.com/migotom/cell-centre-services/pkg/helpers/tracing"
+)
+
+// batchedMessage is message of decoded event waiting for its batch to be stored, context carries span of its logging.
+type batchedMessage struct {
+	ctx        context.Context
+	channel    string
+	subscriber string
+	msg        event.Message
+	entity     *entities.Event
+}
+
+type aggregateKey struct {
+	aggregateType string
+	aggregateID   string
+}
+
+// batchWriter stores events in batches with single unordered write, batch is flushed once it's full or at least
+// every flush interval. Messages are acknowledged only after their batch is persisted. Queue of writer holds
+// a single batch, so subscriptions block while batch is being flushed and further messages wait in NATS.
+type batchWriter struct {
+	eventLogger *EventLogger
+	size        int
+	interval    time.Duration
+	queue       chan batchedMessage
+	closing     chan struct{}
+	done        chan struct{}
+}
+
+func newBatchWriter(eventLogger *EventLogger, size int, interval time.Duration) *batchWriter {
+	return &batchWriter{
+		eventLogger: eventLogger,
+		size:        size,
+		interval:    interval,
+		queue:       make(chan batchedMessage, size),
+		closing:     make(chan struct{}),
+		done:        make(chan struct{}),
+	}
+}
+
+// write queues message, blocks while queue is full. Messages written to closed writer aren't acknowledged and
+// are redelivered.
+func (writer *batchWriter) write(message batchedMessage) {
+	select {
+	case writer.queue <- message:
+	case <-writer.closing:
+		tracing.End(trace.SpanFromContext(message.ctx), nil)
+	}
+}
+
+// run collects and flushes batches until writer is closed.
+func (writer *batchWriter) run() {
+	defer close(writer.done)
+
+	ticker := time.NewTicker(writer.interval)
+	defer ticker.Stop()
+
+	batch := make([]batchedMessage, 0, writer.size)
+	for {
+		select {
+		case <-writer.closing:
+			writer.flush(writer.drain(batch))
+			return
+		case message := <-writer.queue:
+			batch = append(batch, message)
+			if len(batch) < writer.size {
+				continue
+			}
+		case <-ticker.C:
+			if len(batch) == 0 {
+				continue
+			}
+		}
+
+		writer.flush(batch)
+		batch = batch[:0]
+	}
+}
+
+// drain appends messages left in queue to batch.
+func (writer *batchWriter) drain(batch []batchedMessage) []batchedMessage {
+	for {
+		select {
+		case message := <-writer.queue:
+			batch = append(batch, message)
+		default:
+			return batch
+		}
+	}
+}
+
+// close flushes queued messages and stops writer, messages can't be written afterwards.
+func (writer *batchWriter) close() {
+	close(writer.closing)
+	<-writer.done
+}
+
+// flush stores batch of events and settles their messages. Sequence of every aggregate is read once per batch
+// and followed through events of batch in order of delivery.
+func (writer *batchWriter) flush(batch []batchedMessage) {
+	if len(batch) == 0 {
+		return
+	}
+
+	ctx := context.Background()
+	repository := writer.eventLogger.eventRepository
+
+	results := make([]error, len(batch))
+	lastSequences := make(map[aggregateKey]uint64)
+	sequenceErrors := make(map[aggregateKey]error)
+
+	events := make([]*entities.Event, 0, len(batch))
+	indexes := make([]int, 0, len(batch))
+	for i, message := range batch {
+		if message.entity.Sequence != 0 {
+			key := aggregateKey{aggregateType: message.entity.AggregateType, aggregateID: message.entity.AggregateID}
+			if _, ok := lastSequences[key]; !ok && sequenceErrors[key] == nil {
+				lastSequence, err := repository.LastSequence(ctx, key.aggregateType, key.aggregateID)
+				if err != nil {
+					sequenceErrors[key] = fmt.Errorf("can't read sequence of aggregate: %v", err)
+				} else {
+					lastSequences[key] = lastSequence
+				}
+			}
+			if err := sequenceErrors[key]; err != nil {
+				results[i] = err
+				continue
+			}
+		}
+		events = append(events, message.entity)
+		indexes = append(indexes, i)
+	}
+
+	stored := repository.NewMany(ctx, events)
+	for j, i := range indexes {
+		message := batch[i]
+		key := aggregateKey{aggregateType: message.entity.AggregateType, aggregateID: message.entity.AggregateID}
+
+		results[i] = writer.eventLogger.logged(message.channel, message.entity, lastSequences[key], stored[j])
+		if stored[j] == nil && message.entity.Sequence > lastSequences[key] {
+			lastSequences[key] = message.entity.Sequence
+		}
+	}
+
+	for i, message := range batch {
+		tracing.End(trace.SpanFromContext(message.ctx), results[i])
+		writer.eventLogger.settle(message.channel, message.subscriber, message.msg, results[i])
+	}
+}
@@ -0,0 +1,324 @@
+package eventlogger
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+
+	"github.com/migotom/cell-centre-services/pkg/components/employee"
+	"github.com/migotom/cell-centre-services/pkg/components/event"
+	"github.com/migotom/cell-centre-services/pkg/entities"
+	"github.com/migotom/cell-centre-services/pkg/helpers"
+	"github.com/migotom/cell-centre-services/pkg/helpers/mocks"
+)
+
+func TestFlush(t *testing.T) {
+	cases := []struct {
+		Name              string
+		Messages          []*mocks.MessageMock
+		ExpectedMockCalls func(*mocks.EventRepositoryMock, *mocks.StreamingMock, []*mocks.MessageMock)
+	}{
+		{
+			Name: "Stored events are acknowledged",
+			Messages: []*mocks.MessageMock{
+				{MsgData: validEventData(), MsgSequence: 1, MsgDeliveries: 1},
+				{MsgData: validEventData(), MsgSequence: 2, MsgDeliveries: 1},
+			},
+			ExpectedMockCalls: func(r *mocks.EventRepositoryMock, s *mocks.StreamingMock, m []*mocks.MessageMock) {
+				r.On("NewMany", mock.Anything, mock.MatchedBy(func(events []*entities.Event) bool {
+					return len(events) == 2
+				})).Return([]error{nil, event.ErrDuplicateEvent})
+				m[0].On("Ack").Return(nil)
+				m[1].On("Ack").Return(nil)
+			},
+		},
+		{
+			Name: "Sequence of aggregate is read once per batch",
+			Messages: []*mocks.MessageMock{
+				{MsgData: sequencedEventData(3), MsgSequence: 1, MsgDeliveries: 1},
+				{MsgData: sequencedEventData(4), MsgSequence: 2, MsgDeliveries: 1},
+			},
+			ExpectedMockCalls: func(r *mocks.EventRepositoryMock, s *mocks.StreamingMock, m []*mocks.MessageMock) {
+				r.On("LastSequence", mock.Anything, "employee", "5d2f0c8e9a1b2c3d4e5f6a7b").Return(uint64(2), nil).Once()
+				r.On("NewMany", mock.Anything, mock.Anything).Return([]error{nil, nil})
+				m[0].On("Ack").Return(nil)
+				m[1].On("Ack").Return(nil)
+			},
+		},
+		{
+			Name: "Events wait for redelivery when sequence can't be read",
+			Messages: []*mocks.MessageMock{
+				{MsgData: sequencedEventData(3), MsgSequence: 1, MsgDeliveries: 1},
+				{MsgData: validEventData(), MsgSequence: 2, MsgDeliveries: 1},
+			},
+			ExpectedMockCalls: func(r *mocks.EventRepositoryMock, s *mocks.StreamingMock, m []*mocks.MessageMock) {
+				r.On("LastSequence", mock.Anything, "employee", "5d2f0c8e9a1b2c3d4e5f6a7b").Return(uint64(0), errors.New("database down"))
+				r.On("NewMany", mock.Anything, mock.MatchedBy(func(events []*entities.Event) bool {
+					return len(events) == 1 && events[0].Sequence == 0
+				})).Return([]error{nil})
+				m[0].On("Nak").Return(nil)
+				m[1].On("Ack").Return(nil)
+			},
+		},
+		{
+			Name: "Failed events wait for redelivery or are dead lettered",
+			Messages: []*mocks.MessageMock{
+				{MsgData: validEventData(), MsgSequence: 1, MsgDeliveries: 1},
+				{MsgData: validEventData(), MsgSequence: 2, MsgDeliveries: 3},
+				{MsgData: validEventData(), MsgSequence: 3, MsgDeliveries: 1},
+			},
+			ExpectedMockCalls: func(r *mocks.EventRepositoryMock, s *mocks.StreamingMock, m []*mocks.MessageMock) {
+				r.On("NewMany", mock.Anything, mock.Anything).Return([]error{errors.New("database down"), errors.New("database down"), nil})
+				s.On("PublishData", "deadletters", "employees-2", mock.Anything).Return(nil)
+				m[0].On("Nak").Return(nil)
+				m[1].On("Ack").Return(nil)
+				m[2].On("Ack").Return(nil)
+			},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			log, _ := zap.NewProduction()
+			defer log.Sync()
+
+			eventRepositoryMock := mocks.EventRepositoryMock{}
+			streamingMock := mocks.StreamingMock{}
+
+			tc.ExpectedMockCalls(&eventRepositoryMock, &streamingMock, tc.Messages)
+
+			config := Config{MaxDeliveries: 3}
+			config.SetDefaults()
+
+			eventLogger := NewEventLogger(log, &config, &streamingMock, &eventRepositoryMock, newEventRegistry(t))
+			writer := newBatchWriter(eventLogger, len(tc.Messages), time.Hour)
+
+			var batch []batchedMessage
+			for _, msg := range tc.Messages {
+				ctx, entity, err := eventLogger.decode("employees", msg.Data())
+				assert.NoError(t, err)
+				batch = append(batch, batchedMessage{ctx: ctx, channel: "employees", subscriber: "eventlogger-1-durable", msg: msg, entity: entity})
+			}
+			writer.flush(batch)
+
+			eventRepositoryMock.AssertExpectations(t)
+			streamingMock.AssertExpectations(t)
+			for _, msg := range tc.Messages {
+				msg.AssertExpectations(t)
+			}
+		})
+	}
+}
+
+func TestBatchWriter(t *testing.T) {
+	cases := []struct {
+		Name          string
+		Size          int
+		Interval      time.Duration
+		Messages      int
+		ExpectedSizes []int
+	}{
+		{
+			Name:          "Full batches are flushed",
+			Size:          2,
+			Interval:      time.Hour,
+			Messages:      4,
+			ExpectedSizes: []int{2, 2},
+		},
+		{
+			Name:          "Partial batch is flushed after interval",
+			Size:          10,
+			Interval:      10 * time.Millisecond,
+			Messages:      3,
+			ExpectedSizes: []int{3},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			log, _ := zap.NewProduction()
+			defer log.Sync()
+
+			flushed := make(chan struct{}, tc.Messages)
+			repository := &recordingRepository{}
+
+			config := Config{BatchSize: tc.Size, FlushInterval: helpers.Duration{Duration: tc.Interval}}
+			config.SetDefaults()
+
+			eventLogger := NewEventLogger(log, &config, nil, repository, newEventRegistry(t))
+			eventLogger.writer = newBatchWriter(eventLogger, config.BatchSize, config.FlushInterval.Duration)
+			go eventLogger.writer.run()
+
+			for i := 0; i < tc.Messages; i++ {
+				msg := &mocks.MessageMock{MsgData: validEventData(), MsgSequence: uint64(i + 1), MsgDeliveries: 1}
+				msg.On("Ack").Return(nil).Run(func(mock.Arguments) {
+					repository.ack()
+					flushed <- struct{}{}
+				})
+				eventLogger.queueMessage("employees", "eventlogger-1-durable", msg)
+			}
+			for i := 0; i < tc.Messages; i++ {
+				select {
+				case <-flushed:
+				case <-time.After(time.Second):
+					t.Fatal("messages not acknowledged")
+				}
+			}
+			eventLogger.writer.close()
+
+			assert.Equal(t, tc.ExpectedSizes, repository.sizes)
+			assert.Zero(t, repository.ackedEarly, "messages acknowledged before their events were stored")
+		})
+	}
+}
+
+func TestBatchWriterClose(t *testing.T) {
+	log, _ := zap.NewProduction()
+	defer log.Sync()
+
+	eventRepositoryMock := mocks.EventRepositoryMock{}
+	eventRepositoryMock.On("NewMany", mock.Anything, mock.MatchedBy(func(events []*entities.Event) bool {
+		return len(events) == 1
+	})).Return([]error{nil})
+
+	config := Config{}
+	config.SetDefaults()
+
+	msg := &mocks.MessageMock{MsgData: validEventData(), MsgSequence: 1, MsgDeliveries: 1}
+	msg.On("Ack").Return(nil)
+
+	eventLogger := NewEventLogger(log, &config, nil, &eventRepositoryMock, newEventRegistry(t))
+	eventLogger.writer = newBatchWriter(eventLogger, config.BatchSize, time.Hour)
+	go eventLogger.writer.run()
+
+	eventLogger.queueMessage("employees", "eventlogger-1-durable", msg)
+	eventLogger.writer.close()
+
+	// message delivered during shutdown is left unacknowledged for redelivery
+	late := &mocks.MessageMock{MsgData: validEventData(), MsgSequence: 2, MsgDeliveries: 1}
+	eventLogger.queueMessage("employees", "eventlogger-1-durable", late)
+
+	eventRepositoryMock.AssertExpectations(t)
+	msg.AssertExpectations(t)
+	late.AssertNotCalled(t, "Ack")
+}
+
+// recordingRepository records sizes of stored batches and whether messages were acknowledged before their events
+// were stored.
+type recordingRepository struct {
+	event.Repository
+
+	mutex      sync.Mutex
+	sizes      []int
+	stored     int
+	acked      int
+	ackedEarly int
+}
+
+func (repository *recordingRepository) New(ctx context.Context, event *entities.Event) error {
+	return nil
+}
+
+func (repository *recordingRepository) NewMany(ctx context.Context, events []*entities.Event) []error {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	repository.sizes = append(repository.sizes, len(events))
+	repository.stored += len(events)
+	return make([]error, len(events))
+}
+
+func (repository *recordingRepository) LastSequence(ctx context.Context, aggregateType, aggregateID string) (uint64, error) {
+	return 0, nil
+}
+
+func (repository *recordingRepository) ack() {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	repository.acked++
+	if repository.acked > repository.stored {
+		repository.ackedEarly++
+	}
+}
+
+// latencyRepository simulates round trip of database.
+type latencyRepository struct {
+	event.Repository
+
+	latency time.Duration
+}
+
+func (repository *latencyRepository) New(ctx context.Context, event *entities.Event) error {
+	time.Sleep(repository.latency)
+	return nil
+}
+
+func (repository *latencyRepository) NewMany(ctx context.Context, events []*entities.Event) []error {
+	time.Sleep(repository.latency)
+	return make([]error, len(events))
+}
+
+func (repository *latencyRepository) LastSequence(ctx context.Context, aggregateType, aggregateID string) (uint64, error) {
+	time.Sleep(repository.latency)
+	return 0, nil
+}
+
+// benchmarkMessage is acknowledged without mock bookkeeping.
+type benchmarkMessage struct {
+	data  []byte
+	acked *sync.WaitGroup
+}
+
+func (msg *benchmarkMessage) Data() []byte         { return msg.data }
+func (msg *benchmarkMessage) Sequence() uint64     { return 1 }
+func (msg *benchmarkMessage) Timestamp() time.Time { return time.Time{} }
+func (msg *benchmarkMessage) Deliveries() int      { return 1 }
+func (msg *benchmarkMessage) Nak() error           { return nil }
+func (msg *benchmarkMessage) Ack() error {
+	msg.acked.Done()
+	return nil
+}
+
+func benchmarkEventLogger(b *testing.B, batchSize int) {
+	log := zap.NewNop()
+
+	registry := event.NewRegistry()
+	if err := employee.RegisterEvents(registry); err != nil {
+		b.Fatal(err)
+	}
+
+	config := Config{BatchSize: batchSize}
+	config.SetDefaults()
+
+	eventLogger := NewEventLogger(log, &config, nil, &latencyRepository{latency: 200 * time.Microsecond}, registry)
+	handle := eventLogger.handleMessage
+	if batchSize > 1 {
+		eventLogger.writer = newBatchWriter(eventLogger, config.BatchSize, config.FlushInterval.Duration)
+		go eventLogger.writer.run()
+		defer eventLogger.writer.close()
+		handle = eventLogger.queueMessage
+	}
+
+	var acked sync.WaitGroup
+	msg := &benchmarkMessage{data: validEventData(), acked: &acked}
+
+	b.ResetTimer()
+	acked.Add(b.N)
+	for i := 0; i < b.N; i++ {
+		handle("employees", "eventlogger-1-durable", msg)
+	}
+	acked.Wait()
+}
+
+func BenchmarkHandleMessage(b *testing.B) {
+	benchmarkEventLogger(b, 1)
+}
+
+func BenchmarkBatchWriter(b *testing.B) {
+	benchmarkEventLogger(b, defaultBatchSize)
+}
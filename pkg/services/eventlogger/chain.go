@@ -0,0 +1,331 @@
+package eventlogger
+
+import (
+	"context"
+	"crypto/ed25519"
+	"fmt"
+	"math"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/migotom/cell-centre-services/pkg/components/event"
+	"github.com/migotom/cell-centre-services/pkg/components/event/archive"
+	"github.com/migotom/cell-centre-services/pkg/components/event/chain"
+	"github.com/migotom/cell-centre-services/pkg/entities"
+)
+
+const (
+	defaultChainInterval = time.Second
+	chainBatchSize       = 1000
+	maxChainConflicts    = 10
+)
+
+// ChainBreak describes the first event breaking hash chain.
+type ChainBreak struct {
+	Position uint64
+	EventID  string
+	Reason   string
+}
+
+// ChainReport summarizes verification of hash chain of channel.
+type ChainReport struct {
+	Channel string
+	// From and To are positions of the first and the last verified events of event log, chain starts past
+	// position 1 once its oldest events are archived.
+	From     uint64
+	To       uint64
+	Verified int
+	// Archived is number of positions covered by runs of archived links.
+	Archived    uint64
+	Checkpoints int
+	Break       *ChainBreak
+}
+
+// Chainer links stored events of channels into hash chains and exports signed checkpoints of their heads.
+type Chainer struct {
+	log             *zap.Logger
+	config          *Config
+	chainRepository event.ChainRepository
+	archive         *archive.Archive
+	checkpoints     *chain.CheckpointFile
+	signingKey      ed25519.PrivateKey
+}
+
+// NewChainer returns new chainer of event log, checkpoints are signed with given key. Nil checkpoint file disables
+// checkpoints. Chains are verified to start in given archive, or in event log when archive is nil.
+func NewChainer(log *zap.Logger, config *Config, chainRepository event.ChainRepository, eventArchive *archive.Archive, checkpoints *chain.CheckpointFile, signingKey ed25519.PrivateKey) *Chainer {
+	return &Chainer{
+		log:             log,
+		config:          config,
+		chainRepository: chainRepository,
+		archive:         eventArchive,
+		checkpoints:     checkpoints,
+		signingKey:      signingKey,
+	}
+}
+
+// Run links events of subscribed channels every chain interval and exports their checkpoints every checkpoint
+// interval until context is done. Unchanged heads aren't checkpointed again.
+func (chainer *Chainer) Run(ctx context.Context) {
+	chainTicker := time.NewTicker(chainer.config.ChainInterval.Duration)
+	defer chainTicker.Stop()
+
+	var checkpoints <-chan time.Time
+	if chainer.checkpoints != nil && chainer.config.CheckpointInterval.Duration > 0 {
+		checkpointTicker := time.NewTicker(chainer.config.CheckpointInterval.Duration)
+		defer checkpointTicker.Stop()
+		checkpoints = checkpointTicker.C
+	}
+
+	checkpointed := make(map[string]string)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-chainTicker.C:
+			for _, channel := range chainer.config.Subscribes {
+				if _, err := chainer.Chain(ctx, channel); err != nil {
+					chainer.log.Error("Failed to chain events", zap.String("channel", channel), zap.Error(err))
+				}
+			}
+		case <-checkpoints:
+			for _, channel := range chainer.config.Subscribes {
+				checkpoint, err := chainer.Checkpoint(ctx, channel, checkpointed[channel])
+				if err != nil {
+					chainer.log.Error("Failed to export checkpoint of chain", zap.String("channel", channel), zap.Error(err))
+					continue
+				}
+				if checkpoint != nil {
+					checkpointed[channel] = checkpoint.Hash
+				}
+			}
+		}
+	}
+}
+
+// Chain links events of channel not chained yet after head of its chain, returns number of linked events.
+// Events linked concurrently by other loggers make chainer follow their new head.
+func (chainer *Chainer) Chain(ctx context.Context, channel string) (int, error) {
+	var chained, conflicts int
+
+	for {
+		head, err := chainer.chainRepository.Head(ctx, channel)
+		if err != nil {
+			return chained, fmt.Errorf("can't read head of chain: %v", err)
+		}
+		if head == nil {
+			head = &entities.ChainLink{}
+		}
+
+		events, err := chainer.chainRepository.Unchained(ctx, channel, chainBatchSize)
+		if err != nil {
+			return chained, fmt.Errorf("can't read events to chain: %v", err)
+		}
+
+		var conflict bool
+		for _, e := range events {
+			link := &entities.ChainLink{Position: head.Position + 1, PreviousHash: head.Hash}
+			if link.Hash, err = chain.Hash(link.PreviousHash, link.Position, e.Document); err != nil {
+				return chained, fmt.Errorf("can't hash event %s: %v", e.EventID, err)
+			}
+
+			err := chainer.chainRepository.Link(ctx, e.EventID, link)
+			if err == event.ErrChainConflict {
+				conflict = true
+				break
+			}
+			if err != nil {
+				return chained, fmt.Errorf("can't link event %s: %v", e.EventID, err)
+			}
+			chainedEvents.WithLabelValues(channel).Inc()
+			chained++
+			head = link
+		}
+
+		if conflict {
+			if conflicts++; conflicts > maxChainConflicts {
+				return chained, fmt.Errorf("can't link events, chain is being linked concurrently")
+			}
+			continue
+		}
+		if len(events) < chainBatchSize {
+			return chained, nil
+		}
+	}
+}
+
+// Checkpoint signs head of chain of channel and appends it to checkpoint file, head of given hash isn't exported
+// again. Returns nil checkpoint when nothing was exported.
+func (chainer *Chainer) Checkpoint(ctx context.Context, channel, lastHash string) (*entities.ChainCheckpoint, error) {
+	if chainer.checkpoints == nil {
+		return nil, fmt.Errorf("checkpoint file isn't configured")
+	}
+
+	head, err := chainer.chainRepository.Head(ctx, channel)
+	if err != nil {
+		return nil, fmt.Errorf("can't read head of chain: %v", err)
+	}
+	if head == nil || head.Hash == lastHash {
+		return nil, nil
+	}
+
+	checkpoint := &entities.ChainCheckpoint{
+		Channel:   channel,
+		Position:  head.Position,
+		Hash:      head.Hash,
+		CreatedAt: time.Now().UTC(),
+	}
+	chain.Sign(checkpoint, chainer.signingKey)
+
+	if err := chainer.checkpoints.Append(checkpoint); err != nil {
+		return nil, fmt.Errorf("can't append checkpoint: %v", err)
+	}
+	chainer.log.Info("Checkpoint of chain exported", zap.String("channel", channel), zap.Uint64("position", head.Position))
+	return checkpoint, nil
+}
+
+// Verify walks chain of channel from its start and reports its first break, stored events are compared with
+// checkpoints signed by given key. Nil key skips checkpoints. Positions missing in event log have to be covered by
+// runs of archived links following each other. Events past the last verified one which aren't chained yet aren't
+// covered by verification.
+func (chainer *Chainer) Verify(ctx context.Context, channel string, verifyKey ed25519.PublicKey) (ChainReport, error) {
+	report := ChainReport{Channel: channel}
+
+	// checkpoints are removed once they are verified or covered by archive
+	pending := make(map[uint64][]*entities.ChainCheckpoint)
+	if chainer.checkpoints != nil && verifyKey != nil {
+		checkpoints, err := chainer.checkpoints.Checkpoints(channel)
+		if err != nil {
+			return report, fmt.Errorf("can't read checkpoints: %v", err)
+		}
+		for _, checkpoint := range checkpoints {
+			if err := chain.Verify(checkpoint, verifyKey); err != nil {
+				report.Break = &ChainBreak{Position: checkpoint.Position, Reason: fmt.Sprintf("%v created at %s", err, checkpoint.CreatedAt.Format(time.RFC3339))}
+				return report, nil
+			}
+			pending[checkpoint.Position] = append(pending[checkpoint.Position], checkpoint)
+		}
+	}
+
+	var archived []entities.ArchiveChainRun
+	if chainer.archive != nil {
+		var err error
+		if archived, err = chainer.archive.Chain(channel); err != nil {
+			return report, fmt.Errorf("can't read archived chain: %v", err)
+		}
+	}
+
+	// chain starts at position 1 following empty hash
+	previous := &entities.ChainLink{}
+	var after uint64
+	for {
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+
+		events, err := chainer.chainRepository.Chained(ctx, channel, after, chainBatchSize)
+		if err != nil {
+			return report, fmt.Errorf("can't read chained events: %v", err)
+		}
+
+		for _, e := range events {
+			if previous, report.Break = followArchive(previous, e.Link.Position, archived, pending, &report); report.Break != nil {
+				return report, nil
+			}
+			if reason := verifyLink(previous, e); reason != "" {
+				report.Break = &ChainBreak{Position: e.Link.Position, EventID: e.EventID, Reason: reason}
+				return report, nil
+			}
+			for _, checkpoint := range pending[e.Link.Position] {
+				if checkpoint.Hash != e.Link.Hash {
+					report.Break = &ChainBreak{Position: e.Link.Position, EventID: e.EventID, Reason: fmt.Sprintf("hash differs from checkpoint created at %s", checkpoint.CreatedAt.Format(time.RFC3339))}
+					return report, nil
+				}
+				report.Checkpoints++
+			}
+			delete(pending, e.Link.Position)
+
+			if report.Verified == 0 {
+				report.From = e.Link.Position
+			}
+			report.To = e.Link.Position
+			report.Verified++
+			previous = e.Link
+			after = e.Link.Position
+		}
+		if len(events) < chainBatchSize {
+			break
+		}
+	}
+
+	// the latest events may be archived as well
+	if previous, report.Break = followArchive(previous, math.MaxUint64, archived, pending, &report); report.Break != nil {
+		return report, nil
+	}
+
+	// walk covers every position up to the last verified one, checkpoints left are past the end of chain
+	var last uint64
+	for position := range pending {
+		if position > last {
+			last = position
+		}
+	}
+	if last > 0 {
+		report.Break = &ChainBreak{Position: previous.Position + 1, Reason: fmt.Sprintf("chain ends before checkpoint at position %d", last)}
+	}
+	return report, nil
+}
+
+// followArchive returns link of the last archived event following previous link before given position, runs of
+// archived links have to follow each other. Checkpoints of archived positions are covered by archive, the last
+// links of runs are compared with them.
+func followArchive(previous *entities.ChainLink, position uint64, archived []entities.ArchiveChainRun, pending map[uint64][]*entities.ChainCheckpoint, report *ChainReport) (*entities.ChainLink, *ChainBreak) {
+	for _, run := range archived {
+		if run.From != previous.Position+1 || run.To >= position {
+			continue
+		}
+		if run.PreviousHash != previous.Hash {
+			return previous, &ChainBreak{Position: run.From, Reason: "previous hash of archived events doesn't match previous event"}
+		}
+
+		for checkpointPosition, checkpoints := range pending {
+			if checkpointPosition < run.From || checkpointPosition > run.To {
+				continue
+			}
+			if checkpointPosition == run.To {
+				for _, checkpoint := range checkpoints {
+					if checkpoint.Hash != run.Hash {
+						return previous, &ChainBreak{Position: run.To, Reason: fmt.Sprintf("hash of archived event differs from checkpoint created at %s", checkpoint.CreatedAt.Format(time.RFC3339))}
+					}
+					report.Checkpoints++
+				}
+			}
+			delete(pending, checkpointPosition)
+		}
+
+		report.Archived += run.To - run.From + 1
+		previous = &entities.ChainLink{Position: run.To, Hash: run.Hash}
+	}
+	return previous, nil
+}
+
+// verifyLink returns reason why event doesn't follow previous event of chain.
+func verifyLink(previous *entities.ChainLink, e *entities.ChainedEvent) string {
+	link := e.Link
+	if link.Position != previous.Position+1 {
+		return fmt.Sprintf("events at positions %d-%d are missing", previous.Position+1, link.Position-1)
+	}
+	if link.PreviousHash != previous.Hash {
+		return "previous hash doesn't match previous event"
+	}
+
+	hash, err := chain.Hash(link.PreviousHash, link.Position, e.Document)
+	if err != nil {
+		return err.Error()
+	}
+	if hash != link.Hash {
+		return "hash doesn't match content of event"
+	}
+	return ""
+}
@@ -0,0 +1,333 @@
+package eventlogger
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/ptypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.uber.org/zap"
+
+	"github.com/migotom/cell-centre-services/pkg/components/event"
+	"github.com/migotom/cell-centre-services/pkg/components/event/archive"
+	"github.com/migotom/cell-centre-services/pkg/components/event/chain"
+	"github.com/migotom/cell-centre-services/pkg/components/event/encoding"
+	"github.com/migotom/cell-centre-services/pkg/entities"
+	"github.com/migotom/cell-centre-services/pkg/helpers"
+	"github.com/migotom/cell-centre-services/pkg/helpers/mocks"
+	"github.com/migotom/cell-centre-services/pkg/pb"
+)
+
+// storedDocument returns stored document of event not chained yet.
+func storedDocument(id, email string) *entities.ChainedEvent {
+	document, _ := bson.Marshal(&entities.Event{
+		EventID: id,
+		Channel: "employees",
+		Type:    entities.UpdateEmployeeEvent,
+		Data:    &entities.Employee{Email: email},
+	})
+	return &entities.ChainedEvent{EventID: id, Document: document}
+}
+
+// chained links events in order starting at given position.
+func chained(position uint64, events ...*entities.ChainedEvent) []*entities.ChainedEvent {
+	var previous string
+	for i, e := range events {
+		link := &entities.ChainLink{Position: position + uint64(i), PreviousHash: previous}
+		link.Hash, _ = chain.Hash(link.PreviousHash, link.Position, e.Document)
+		e.Link = link
+		previous = link.Hash
+	}
+	return events
+}
+
+// archiveChain returns new archive holding events of given links.
+func archiveChain(t *testing.T, links ...*entities.ChainLink) *archive.Archive {
+	eventArchive, err := archive.NewArchive(t.TempDir(), encoding.JSON)
+	require.NoError(t, err)
+
+	var events []*pb.Event
+	linked := make(map[string]*entities.ChainedEvent)
+	for _, link := range links {
+		id := fmt.Sprintf("a%d", link.Position)
+		events = append(events, &pb.Event{EventId: id, Channel: "employees", CreatedAt: ptypes.TimestampNow()})
+		linked[id] = &entities.ChainedEvent{EventID: id, Link: link}
+	}
+	_, err = eventArchive.Write("employees", "2019-07-24", events, linked)
+	require.NoError(t, err)
+	return eventArchive
+}
+
+func TestChainerChain(t *testing.T) {
+	e1, e2, e3 := storedDocument("e1", "a@page.com"), storedDocument("e2", "b@page.com"), storedDocument("e3", "c@page.com")
+	head := chained(1, storedDocument("e0", "root@page.com"))[0].Link
+
+	linkOf := func(position uint64, previous string, e *entities.ChainedEvent) *entities.ChainLink {
+		hash, _ := chain.Hash(previous, position, e.Document)
+		return &entities.ChainLink{Position: position, PreviousHash: previous, Hash: hash}
+	}
+
+	cases := []struct {
+		Name              string
+		ExpectedMockCalls func(*mocks.ChainRepositoryMock)
+		ExpectedChained   int
+		ExpectedErr       string
+	}{
+		{
+			Name: "Events are linked after head of chain",
+			ExpectedMockCalls: func(r *mocks.ChainRepositoryMock) {
+				r.On("Head", mock.Anything, "employees").Return(head, nil)
+				r.On("Unchained", mock.Anything, "employees", chainBatchSize).Return([]*entities.ChainedEvent{e1, e2}, nil)
+				first := linkOf(2, head.Hash, e1)
+				r.On("Link", mock.Anything, "e1", first).Return(nil)
+				r.On("Link", mock.Anything, "e2", linkOf(3, first.Hash, e2)).Return(nil)
+			},
+			ExpectedChained: 2,
+		},
+		{
+			Name: "The first event of channel starts chain",
+			ExpectedMockCalls: func(r *mocks.ChainRepositoryMock) {
+				r.On("Head", mock.Anything, "employees").Return((*entities.ChainLink)(nil), nil)
+				r.On("Unchained", mock.Anything, "employees", chainBatchSize).Return([]*entities.ChainedEvent{e1}, nil)
+				r.On("Link", mock.Anything, "e1", linkOf(1, "", e1)).Return(nil)
+			},
+			ExpectedChained: 1,
+		},
+		{
+			Name: "Chain linked concurrently is followed from its new head",
+			ExpectedMockCalls: func(r *mocks.ChainRepositoryMock) {
+				r.On("Head", mock.Anything, "employees").Return((*entities.ChainLink)(nil), nil).Once()
+				r.On("Unchained", mock.Anything, "employees", chainBatchSize).Return([]*entities.ChainedEvent{e1, e3}, nil).Once()
+				r.On("Link", mock.Anything, "e1", linkOf(1, "", e1)).Return(event.ErrChainConflict).Once()
+
+				other := linkOf(1, "", e2)
+				r.On("Head", mock.Anything, "employees").Return(other, nil).Once()
+				r.On("Unchained", mock.Anything, "employees", chainBatchSize).Return([]*entities.ChainedEvent{e1, e3}, nil).Once()
+				first := linkOf(2, other.Hash, e1)
+				r.On("Link", mock.Anything, "e1", first).Return(nil).Once()
+				r.On("Link", mock.Anything, "e3", linkOf(3, first.Hash, e3)).Return(nil).Once()
+			},
+			ExpectedChained: 2,
+		},
+		{
+			Name: "Failed link",
+			ExpectedMockCalls: func(r *mocks.ChainRepositoryMock) {
+				r.On("Head", mock.Anything, "employees").Return(head, nil)
+				r.On("Unchained", mock.Anything, "employees", chainBatchSize).Return([]*entities.ChainedEvent{e1}, nil)
+				r.On("Link", mock.Anything, "e1", mock.Anything).Return(errors.New("database down"))
+			},
+			ExpectedErr: "can't link event e1: database down",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			chainRepositoryMock := mocks.ChainRepositoryMock{}
+			tc.ExpectedMockCalls(&chainRepositoryMock)
+
+			chainer := NewChainer(zap.NewNop(), &Config{Subscribes: []string{"employees"}}, &chainRepositoryMock, nil, nil, nil)
+			count, err := chainer.Chain(context.Background(), "employees")
+			helpers.AssertErrors(t, tc.ExpectedErr, err)
+			assert.Equal(t, tc.ExpectedChained, count)
+			chainRepositoryMock.AssertExpectations(t)
+		})
+	}
+}
+
+func TestChainerVerify(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	_, otherKey, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+
+	newChain := func() []*entities.ChainedEvent {
+		return chained(1, storedDocument("e1", "a@page.com"), storedDocument("e2", "b@page.com"), storedDocument("e3", "c@page.com"))
+	}
+	checkpoint := func(position uint64, hash string, key ed25519.PrivateKey) *entities.ChainCheckpoint {
+		checkpoint := &entities.ChainCheckpoint{Channel: "employees", Position: position, Hash: hash, CreatedAt: time.Date(2019, 7, 24, 20, 26, 40, 0, time.UTC)}
+		chain.Sign(checkpoint, key)
+		return checkpoint
+	}
+
+	cases := []struct {
+		Name           string
+		Events         func() []*entities.ChainedEvent
+		Archived       []*entities.ChainLink
+		Checkpoints    func(events []*entities.ChainedEvent) []*entities.ChainCheckpoint
+		ExpectedReport ChainReport
+	}{
+		{
+			Name:   "Intact chain",
+			Events: newChain,
+			Checkpoints: func(events []*entities.ChainedEvent) []*entities.ChainCheckpoint {
+				return []*entities.ChainCheckpoint{checkpoint(2, events[1].Link.Hash, privateKey)}
+			},
+			ExpectedReport: ChainReport{Channel: "employees", From: 1, To: 3, Verified: 3, Checkpoints: 1},
+		},
+		{
+			Name: "Chain starting with archived events",
+			Events: func() []*entities.ChainedEvent {
+				return newChain()[1:]
+			},
+			Archived: []*entities.ChainLink{newChain()[0].Link},
+			Checkpoints: func(events []*entities.ChainedEvent) []*entities.ChainCheckpoint {
+				return []*entities.ChainCheckpoint{checkpoint(1, newChain()[0].Link.Hash, privateKey)}
+			},
+			ExpectedReport: ChainReport{Channel: "employees", From: 2, To: 3, Verified: 2, Archived: 1, Checkpoints: 1},
+		},
+		{
+			Name: "Chain starting past archive",
+			Events: func() []*entities.ChainedEvent {
+				return newChain()[2:]
+			},
+			Archived: []*entities.ChainLink{newChain()[0].Link},
+			Checkpoints: func(events []*entities.ChainedEvent) []*entities.ChainCheckpoint {
+				return []*entities.ChainCheckpoint{checkpoint(2, newChain()[1].Link.Hash, privateKey)}
+			},
+			ExpectedReport: ChainReport{Channel: "employees", Archived: 1, Break: &ChainBreak{Position: 3, EventID: "e3", Reason: "events at positions 2-2 are missing"}},
+		},
+		{
+			Name: "Chain not starting at its first position",
+			Events: func() []*entities.ChainedEvent {
+				return newChain()[1:]
+			},
+			ExpectedReport: ChainReport{Channel: "employees", Break: &ChainBreak{Position: 2, EventID: "e2", Reason: "events at positions 1-1 are missing"}},
+		},
+		{
+			Name: "Chain not following archived events",
+			Events: func() []*entities.ChainedEvent {
+				return newChain()[1:]
+			},
+			Archived:       []*entities.ChainLink{{Position: 1, Hash: "forged"}},
+			ExpectedReport: ChainReport{Channel: "employees", Archived: 1, Break: &ChainBreak{Position: 2, EventID: "e2", Reason: "previous hash doesn't match previous event"}},
+		},
+		{
+			Name: "Archived event differs from checkpoint",
+			Events: func() []*entities.ChainedEvent {
+				return newChain()[1:]
+			},
+			Archived: []*entities.ChainLink{newChain()[0].Link},
+			Checkpoints: func(events []*entities.ChainedEvent) []*entities.ChainCheckpoint {
+				return []*entities.ChainCheckpoint{checkpoint(1, "rehashed", privateKey)}
+			},
+			ExpectedReport: ChainReport{Channel: "employees", Break: &ChainBreak{Position: 1, Reason: "hash of archived event differs from checkpoint created at 2019-07-24T20:26:40Z"}},
+		},
+		{
+			Name: "Chain with all events archived",
+			Events: func() []*entities.ChainedEvent {
+				return nil
+			},
+			Archived: []*entities.ChainLink{newChain()[0].Link, newChain()[1].Link},
+			Checkpoints: func(events []*entities.ChainedEvent) []*entities.ChainCheckpoint {
+				return []*entities.ChainCheckpoint{checkpoint(3, newChain()[2].Link.Hash, privateKey)}
+			},
+			ExpectedReport: ChainReport{Channel: "employees", Archived: 2, Break: &ChainBreak{Position: 3, Reason: "chain ends before checkpoint at position 3"}},
+		},
+		{
+			Name: "Edited event",
+			Events: func() []*entities.ChainedEvent {
+				events := newChain()
+				events[1].Document = storedDocument("e2", "root@page.com").Document
+				return events
+			},
+			ExpectedReport: ChainReport{Channel: "employees", From: 1, To: 1, Verified: 1, Break: &ChainBreak{Position: 2, EventID: "e2", Reason: "hash doesn't match content of event"}},
+		},
+		{
+			Name: "Removed event",
+			Events: func() []*entities.ChainedEvent {
+				events := newChain()
+				return []*entities.ChainedEvent{events[0], events[2]}
+			},
+			ExpectedReport: ChainReport{Channel: "employees", From: 1, To: 1, Verified: 1, Break: &ChainBreak{Position: 3, EventID: "e3", Reason: "events at positions 2-2 are missing"}},
+		},
+		{
+			Name: "Rehashed chain",
+			Events: func() []*entities.ChainedEvent {
+				events := newChain()
+				return chained(1, events[0], storedDocument("e2", "root@page.com"), events[2])
+			},
+			Checkpoints: func(events []*entities.ChainedEvent) []*entities.ChainCheckpoint {
+				return []*entities.ChainCheckpoint{checkpoint(2, newChain()[1].Link.Hash, privateKey)}
+			},
+			ExpectedReport: ChainReport{Channel: "employees", From: 1, To: 1, Verified: 1, Break: &ChainBreak{Position: 2, EventID: "e2", Reason: "hash differs from checkpoint created at 2019-07-24T20:26:40Z"}},
+		},
+		{
+			Name: "Truncated chain",
+			Events: func() []*entities.ChainedEvent {
+				return newChain()[:2]
+			},
+			Checkpoints: func(events []*entities.ChainedEvent) []*entities.ChainCheckpoint {
+				return []*entities.ChainCheckpoint{checkpoint(3, "removed", privateKey)}
+			},
+			ExpectedReport: ChainReport{Channel: "employees", From: 1, To: 2, Verified: 2, Break: &ChainBreak{Position: 3, Reason: "chain ends before checkpoint at position 3"}},
+		},
+		{
+			Name:   "Forged checkpoint",
+			Events: newChain,
+			Checkpoints: func(events []*entities.ChainedEvent) []*entities.ChainCheckpoint {
+				return []*entities.ChainCheckpoint{checkpoint(2, events[1].Link.Hash, otherKey)}
+			},
+			ExpectedReport: ChainReport{Channel: "employees", Break: &ChainBreak{Position: 2, Reason: "invalid signature of checkpoint created at 2019-07-24T20:26:40Z"}},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			events := tc.Events()
+
+			checkpoints := chain.NewCheckpointFile(filepath.Join(t.TempDir(), "checkpoints.jsonl"))
+			if tc.Checkpoints != nil {
+				for _, checkpoint := range tc.Checkpoints(events) {
+					assert.NoError(t, checkpoints.Append(checkpoint))
+				}
+			}
+
+			chainRepositoryMock := mocks.ChainRepositoryMock{}
+			chainRepositoryMock.On("Chained", mock.Anything, "employees", uint64(0), chainBatchSize).Return(events, nil)
+
+			var eventArchive *archive.Archive
+			if tc.Archived != nil {
+				eventArchive = archiveChain(t, tc.Archived...)
+			}
+
+			chainer := NewChainer(zap.NewNop(), &Config{}, &chainRepositoryMock, eventArchive, checkpoints, nil)
+			report, err := chainer.Verify(context.Background(), "employees", publicKey)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.ExpectedReport, report)
+		})
+	}
+}
+
+func TestChainerCheckpoint(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+
+	head := &entities.ChainLink{Position: 7, PreviousHash: "abc", Hash: "def"}
+	chainRepositoryMock := mocks.ChainRepositoryMock{}
+	chainRepositoryMock.On("Head", mock.Anything, "employees").Return(head, nil)
+
+	checkpoints := chain.NewCheckpointFile(filepath.Join(t.TempDir(), "checkpoints.jsonl"))
+	chainer := NewChainer(zap.NewNop(), &Config{}, &chainRepositoryMock, nil, checkpoints, privateKey)
+
+	checkpoint, err := chainer.Checkpoint(context.Background(), "employees", "")
+	assert.NoError(t, err)
+	assert.NoError(t, chain.Verify(checkpoint, publicKey))
+
+	// unchanged head isn't exported again
+	checkpoint, err = chainer.Checkpoint(context.Background(), "employees", head.Hash)
+	assert.NoError(t, err)
+	assert.Nil(t, checkpoint)
+
+	exported, err := checkpoints.Checkpoints("employees")
+	assert.NoError(t, err)
+	assert.Len(t, exported, 1)
+	assert.Equal(t, uint64(7), exported[0].Position)
+	assert.Equal(t, "def", exported[0].Hash)
+}
@@ -0,0 +1,130 @@
+package eventlogger
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"go.uber.org/zap"
+
+	"github.com/migotom/cell-centre-services/pkg/components/event"
+	"github.com/migotom/cell-centre-services/pkg/pb"
+)
+
+// DeadLetters inspects and replays messages moved by EventLogger into dead letter channel.
+type DeadLetters struct {
+	log             *zap.Logger
+	config          *Config
+	eventsStreaming event.Streaming
+}
+
+// NewDeadLetters returns new dead letters inspector.
+func NewDeadLetters(log *zap.Logger, config *Config, eventsStreaming event.Streaming) *DeadLetters {
+	return &DeadLetters{
+		log:             log,
+		config:          config,
+		eventsStreaming: eventsStreaming,
+	}
+}
+
+// DeadLetterFilter selects dead letters by channel and sequences of original messages, empty filter matches all.
+type DeadLetterFilter struct {
+	Channel   string
+	Sequences []uint64
+}
+
+// Match reports whether dead letter matches filter.
+func (filter DeadLetterFilter) Match(deadLetter *pb.DeadLetter) bool {
+	if filter.Channel != "" && filter.Channel != deadLetter.Channel {
+		return false
+	}
+	if len(filter.Sequences) == 0 {
+		return true
+	}
+	for _, sequence := range filter.Sequences {
+		if sequence == deadLetter.Sequence {
+			return true
+		}
+	}
+	return false
+}
+
+// Fetch reads dead letter channel from the beginning and returns dead letters matching filter,
+// reading stops once no message arrives for idle time.
+func (deadLetters *DeadLetters) Fetch(filter DeadLetterFilter, idle time.Duration) ([]*pb.DeadLetter, error) {
+	var (
+		mu       sync.Mutex
+		fetched  []*pb.DeadLetter
+		received = make(chan struct{}, 1)
+	)
+
+	subscription, err := deadLetters.eventsStreaming.Subscribe(deadLetters.config.DeadLetterChannel, func(msg event.Message) {
+		var deadLetter pb.DeadLetter
+		if err := proto.Unmarshal(msg.Data(), &deadLetter); err != nil {
+			deadLetters.log.Error("Invalid dead letter", zap.Uint64("sequence", msg.Sequence()), zap.Error(err))
+			return
+		}
+
+		if filter.Match(&deadLetter) {
+			mu.Lock()
+			fetched = append(fetched, &deadLetter)
+			mu.Unlock()
+		}
+
+		select {
+		case received <- struct{}{}:
+		default:
+		}
+	}, event.DeliverAllAvailable())
+	if err != nil {
+		return nil, fmt.Errorf("can't subscribe dead letter channel: %v", err)
+	}
+
+waitIdle:
+	for {
+		select {
+		case <-received:
+		case <-time.After(idle):
+			break waitIdle
+		}
+	}
+	subscription.Close()
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	return uniqueDeadLetters(fetched), nil
+}
+
+// Replay publishes original messages of given dead letters back into their channels.
+func (deadLetters *DeadLetters) Replay(letters []*pb.DeadLetter) error {
+	for _, deadLetter := range letters {
+		if err := deadLetters.eventsStreaming.PublishData(deadLetter.Channel, "", deadLetter.Data); err != nil {
+			return fmt.Errorf("can't replay message %d of channel %s: %v", deadLetter.Sequence, deadLetter.Channel, err)
+		}
+		deadLetters.log.Info("Dead letter replayed", zap.String("channel", deadLetter.Channel), zap.Uint64("sequence", deadLetter.Sequence))
+	}
+	return nil
+}
+
+// uniqueDeadLetters drops repeated dead letters of the same message, the latest one is kept.
+func uniqueDeadLetters(letters []*pb.DeadLetter) []*pb.DeadLetter {
+	type key struct {
+		channel  string
+		sequence uint64
+	}
+
+	positions := make(map[key]int)
+	var unique []*pb.DeadLetter
+	for _, deadLetter := range letters {
+		k := key{channel: deadLetter.Channel, sequence: deadLetter.Sequence}
+		if position, ok := positions[k]; ok {
+			unique[position] = deadLetter
+			continue
+		}
+		positions[k] = len(unique)
+		unique = append(unique, deadLetter)
+	}
+	return unique
+}
@@ -2,71 +2,350 @@ package eventlogger
 
 import (
 	"context"
-	"log"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
 
 	"github.com/golang/protobuf/proto"
-	stan "github.com/nats-io/go-nats-streaming"
+	"github.com/golang/protobuf/ptypes"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 
 	"github.com/migotom/cell-centre-services/pkg/components/event"
+	"github.com/migotom/cell-centre-services/pkg/components/event/encoding"
 	eventFactory "github.com/migotom/cell-centre-services/pkg/components/event/factory"
+	"github.com/migotom/cell-centre-services/pkg/components/event/streaming"
+	"github.com/migotom/cell-centre-services/pkg/entities"
+	"github.com/migotom/cell-centre-services/pkg/helpers"
+	"github.com/migotom/cell-centre-services/pkg/helpers/tracing"
 	"github.com/migotom/cell-centre-services/pkg/pb"
 )
 
+const (
+	defaultMaxDeliveries     = 5
+	defaultDeadLetterChannel = "deadletters"
+	defaultBatchSize         = 100
+	defaultFlushInterval     = 200 * time.Millisecond
+)
+
 // EventLogger defines event logging NATS subscriber service.
 type EventLogger struct {
 	log             *zap.Logger
 	config          *Config
-	eventsStreaming *event.EventsStreaming
+	eventsStreaming event.Streaming
 	eventRepository event.Repository
 	eventFactory    *eventFactory.EventEntityFactory
+	writer          *batchWriter
+
+	// messages being logged
+	handling sync.WaitGroup
 }
 
 // NewEventLogger returns new event logging service.
-func NewEventLogger(log *zap.Logger, config *Config, eventsStreaming *event.EventsStreaming, eventRepository event.Repository) *EventLogger {
+func NewEventLogger(log *zap.Logger, config *Config, eventsStreaming event.Streaming, eventRepository event.Repository, eventRegistry *event.Registry) *EventLogger {
 	return &EventLogger{
 		log:             log,
 		config:          config,
 		eventsStreaming: eventsStreaming,
 		eventRepository: eventRepository,
-		eventFactory:    eventFactory.NewEventEntityFactory(),
+		eventFactory:    eventFactory.NewEventEntityFactory(eventRegistry),
 	}
 }
 
-// Listen for events to log.
-func (eventLogger *EventLogger) Listen() {
-	streaming := eventLogger.eventsStreaming.NATS()
-	if streaming == nil {
-		log.Fatal("Failed to connect to NATS Streaming cluster")
+// Listen for events to log until given context is done, events are stored in batches unless batch size is 1.
+// Subscriptions are closed and events being logged are stored before return, unless shutdown timeout elapses.
+func (eventLogger *EventLogger) Listen(ctx context.Context) {
+	handle := eventLogger.handleMessage
+	if eventLogger.config.BatchSize > 1 {
+		eventLogger.writer = newBatchWriter(eventLogger, eventLogger.config.BatchSize, eventLogger.config.FlushInterval.Duration)
+		go eventLogger.writer.run()
+		handle = eventLogger.queueMessage
 	}
 
+	var subscriptions []event.Subscription
 	for _, queue := range eventLogger.config.Subscribes {
+		queue := queue
 		queueGroup := queue + "-eventlogger-group"
 		queueLoggerID := "eventlogger-" + eventLogger.config.NATSClientID + "-durable"
 
-		streaming.QueueSubscribe(queue, queueGroup, func(msg *stan.Msg) {
-			var event pb.Event
-			if err := proto.Unmarshal(msg.Data, &event); err != nil {
-				eventLogger.log.Error("Subscribed message error", zap.String("loggerID", queueLoggerID), zap.Error(err))
-				return
-			}
-
-			entity := eventLogger.eventFactory.NewFromEvent(event)
-			err := eventLogger.eventRepository.New(context.Background(), entity)
-			if err != nil {
-				eventLogger.log.Error("Error while storing in database", zap.Error(err))
-			}
-		}, stan.DurableName(queueLoggerID),
+		subscription, err := eventLogger.eventsStreaming.Subscribe(queue, func(msg event.Message) {
+			eventLogger.handling.Add(1)
+			defer eventLogger.handling.Done()
+			handle(queue, queueLoggerID, msg)
+		}, event.QueueGroup(queueGroup), event.DurableName(queueLoggerID),
+			event.ManualAck(), event.AckWait(eventLogger.config.AckWait.Duration),
 		)
+		if err != nil {
+			eventLogger.log.Fatal("Failed to subscribe events", zap.String("queue", queue), zap.Error(err))
+		}
+		subscriptions = append(subscriptions, subscription)
+	}
+
+	<-ctx.Done()
+	eventLogger.close(subscriptions)
+}
+
+// close stops receiving of events, events being logged and queued batch are stored up to shutdown timeout.
+func (eventLogger *EventLogger) close(subscriptions []event.Subscription) {
+	for _, subscription := range subscriptions {
+		if err := subscription.Close(); err != nil {
+			eventLogger.log.Error("Failed to close subscription", zap.Error(err))
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), eventLogger.config.ShutdownTimeout.Duration)
+	defer cancel()
+
+	var closing sync.WaitGroup
+	closing.Add(1)
+	go func() {
+		defer closing.Done()
+		if eventLogger.writer != nil {
+			// handlers blocked on full queue are released once writer is closed
+			eventLogger.writer.close()
+		}
+		eventLogger.handling.Wait()
+	}()
+	if err := helpers.Wait(ctx, &closing); err != nil {
+		eventLogger.log.Warn("Logging in progress abandoned, events are redelivered after restart", zap.Error(err))
+	}
+}
+
+// handleMessage stores event and acknowledges its message.
+func (eventLogger *EventLogger) handleMessage(channel, subscriber string, msg event.Message) {
+	eventLogger.settle(channel, subscriber, msg, eventLogger.LogEvent(channel, msg.Data()))
+}
+
+// queueMessage queues event into batch, decoding errors are settled at once.
+func (eventLogger *EventLogger) queueMessage(channel, subscriber string, msg event.Message) {
+	ctx, entity, err := eventLogger.decode(channel, msg.Data())
+	if err != nil {
+		eventLogger.settle(channel, subscriber, msg, err)
+		return
+	}
+	eventLogger.writer.write(batchedMessage{ctx: ctx, channel: channel, subscriber: subscriber, msg: msg, entity: entity})
+}
+
+// settle acknowledges message of logged event. Message that can't be logged is redelivered until limit of
+// deliveries is reached, afterwards it's moved into dead letter channel.
+func (eventLogger *EventLogger) settle(channel, subscriber string, msg event.Message, err error) {
+	log := eventLogger.log.With(
+		zap.String("loggerID", subscriber),
+		zap.String("channel", channel),
+		zap.Uint64("sequence", msg.Sequence()),
+		zap.Int("deliveries", msg.Deliveries()),
+	)
+
+	if err == nil {
+		loggingLag.WithLabelValues(channel).Set(time.Since(msg.Timestamp()).Seconds())
+		if err := msg.Ack(); err != nil {
+			log.Error("Failed to acknowledge message", zap.Error(err))
+		}
+		return
+	}
+
+	if msg.Deliveries() < eventLogger.config.MaxDeliveries {
+		log.Warn("Error while logging event, message will be redelivered", zap.Error(err))
+		if err := msg.Nak(); err != nil {
+			log.Error("Failed to negatively acknowledge message", zap.Error(err))
+		}
+		return
+	}
+
+	if deadLetterErr := eventLogger.deadLetter(channel, subscriber, msg, err); deadLetterErr != nil {
+		log.Error("Failed to move message into dead letter channel", zap.NamedError("cause", err), zap.Error(deadLetterErr))
+		return
+	}
+	log.Error("Error while logging event, message moved into dead letter channel", zap.Error(err))
+
+	if err := msg.Ack(); err != nil {
+		log.Error("Failed to acknowledge message", zap.Error(err))
+	}
+}
+
+// LogEvent stores event of channel once, events already stored are skipped.
+func (eventLogger *EventLogger) LogEvent(channel string, data []byte) (err error) {
+	ctx, entity, err := eventLogger.decode(channel, data)
+	if err != nil {
+		return err
+	}
+	defer func() { tracing.End(trace.SpanFromContext(ctx), err) }()
+
+	var lastSequence uint64
+	if entity.Sequence != 0 {
+		if lastSequence, err = eventLogger.eventRepository.LastSequence(ctx, entity.AggregateType, entity.AggregateID); err != nil {
+			return fmt.Errorf("can't read sequence of aggregate: %v", err)
+		}
+	}
+
+	return eventLogger.logged(channel, entity, lastSequence, eventLogger.eventRepository.New(ctx, entity))
+}
+
+// decode returns entity of event carried by message data and context of its logging, continuing trace
+// of event's publisher. Span of context is ended by caller.
+func (eventLogger *EventLogger) decode(channel string, data []byte) (context.Context, *entities.Event, error) {
+	var e pb.Event
+	if err := proto.Unmarshal(data, &e); err != nil {
+		return nil, nil, fmt.Errorf("can't decode event: %v", err)
+	}
+
+	ctx, span := tracing.StartConsume(context.Background(), channel, &e)
+	entity, err := eventLogger.eventFactory.NewFromEvent(e)
+	if err != nil {
+		tracing.End(span, err)
+		return nil, nil, err
 	}
+	return ctx, entity, nil
+}
+
+// logged reports result of storing event, event already stored is not an error.
+func (eventLogger *EventLogger) logged(channel string, entity *entities.Event, lastSequence uint64, err error) error {
+	switch err {
+	case nil:
+	case event.ErrDuplicateEvent:
+		duplicateEvents.WithLabelValues(channel).Inc()
+		eventLogger.log.Info("Event already logged", append(eventFields(entity), zap.String("channel", channel))...)
+		return nil
+	default:
+		return fmt.Errorf("can't store event in database: %v", err)
+	}
+
+	eventLogger.verifySequence(channel, entity, lastSequence)
+	return nil
+}
+
+// verifySequence reports events of aggregate which are missing or arrived out of order. Last sequence is read
+// before event is stored, so reports are approximate when members of queue group log events of the same
+// aggregate concurrently: event stored by other member in between is reported as gap or reordering. They are
+// exact with single logger of channel.
+func (eventLogger *EventLogger) verifySequence(channel string, entity *entities.Event, lastSequence uint64) {
+	if entity.Sequence == 0 {
+		return
+	}
+
+	log := eventLogger.log.With(eventFields(entity)...).With(
+		zap.String("channel", channel),
+		zap.String("aggregateType", entity.AggregateType),
+		zap.String("aggregateID", entity.AggregateID),
+		zap.Uint64("sequence", entity.Sequence),
+		zap.Uint64("lastSequence", lastSequence),
+	)
+
+	switch {
+	case entity.Sequence <= lastSequence:
+		reorderedEvents.WithLabelValues(channel).Inc()
+		log.Warn("Event of aggregate arrived out of order")
+	case entity.Sequence > lastSequence+1:
+		sequenceGaps.WithLabelValues(channel).Inc()
+		log.Warn("Gap in sequence of aggregate events", zap.Uint64("missing", entity.Sequence-lastSequence-1))
+	}
+}
+
+// eventFields returns log fields identifying event and request which caused it.
+func eventFields(entity *entities.Event) []zap.Field {
+	fields := []zap.Field{zap.String("eventID", entity.EventID)}
+	if entity.CorrelationID != "" {
+		fields = append(fields, zap.String("correlationID", entity.CorrelationID))
+	}
+	if entity.CausationID != "" {
+		fields = append(fields, zap.String("causationID", entity.CausationID))
+	}
+	return fields
+}
+
+func (eventLogger *EventLogger) deadLetter(channel, subscriber string, msg event.Message, cause error) error {
+	data, err := NewDeadLetter(channel, subscriber, msg, cause)
+	if err != nil {
+		return err
+	}
+
+	id := fmt.Sprintf("%s-%d", channel, msg.Sequence())
+	return eventLogger.eventsStreaming.PublishData(eventLogger.config.DeadLetterChannel, id, data)
+}
+
+// NewDeadLetter returns marshaled dead letter of message of channel which given subscriber failed to handle.
+func NewDeadLetter(channel, subscriber string, msg event.Message, cause error) ([]byte, error) {
+	return proto.Marshal(&pb.DeadLetter{
+		Channel:    channel,
+		Sequence:   msg.Sequence(),
+		Data:       msg.Data(),
+		Error:      cause.Error(),
+		Deliveries: uint32(msg.Deliveries()),
+		Subscriber: subscriber,
+		FailedAt:   ptypes.TimestampNow(),
+	})
 }
 
 // Config of EventLogger service.
 type Config struct {
-	DatabaseAddress string   `toml:"database_address"`
-	DatabaseName    string   `toml:"database_name"`
-	NATSClusterID   string   `toml:"nats_cluster_id"`
-	NATSURL         string   `toml:"nats_url"`
-	NATSClientID    string   `toml:"nats_client_id"`
-	Subscribes      []string `toml:"subscribes"`
+	DatabaseAddress     string           `toml:"database_address"`
+	DatabaseName        string           `toml:"database_name"`
+	NATSClientID        string           `toml:"nats_client_id"`
+	Subscribes          []string         `toml:"subscribes"`
+	MaxDeliveries       int              `toml:"max_deliveries"`
+	AckWait             helpers.Duration `toml:"ack_wait"`
+	DeadLetterChannel   string           `toml:"dead_letter_channel"`
+	MetricsAddress      string           `toml:"metrics_address"`
+	BatchSize           int              `toml:"batch_size"`
+	FlushInterval       helpers.Duration `toml:"flush_interval"`
+	ArchiveDirectory    string           `toml:"archive_directory"`
+	ArchiveAfter        helpers.Duration `toml:"archive_after"`
+	ArchiveFormat       string           `toml:"archive_format"`
+	ArchiveBatchSize    int              `toml:"archive_batch_size"`
+	ChainInterval       helpers.Duration `toml:"chain_interval"`
+	CheckpointInterval  helpers.Duration `toml:"checkpoint_interval"`
+	CheckpointFile      string           `toml:"checkpoint_file"`
+	CheckpointKey       string           `toml:"checkpoint_key"`
+	CheckpointVerifyKey string           `toml:"checkpoint_verify_key"`
+	ShutdownTimeout     helpers.Duration `toml:"shutdown_timeout"`
+	Tracing             tracing.Config   `toml:"tracing"`
+	streaming.NATSConfig
+}
+
+// SetDefaults sets default values of not configured options.
+func (config *Config) SetDefaults() {
+	if config.MaxDeliveries <= 0 {
+		config.MaxDeliveries = defaultMaxDeliveries
+	}
+	if config.DeadLetterChannel == "" {
+		config.DeadLetterChannel = defaultDeadLetterChannel
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = defaultBatchSize
+	}
+	if config.FlushInterval.Duration == 0 {
+		config.FlushInterval.Duration = defaultFlushInterval
+	}
+	if config.ArchiveFormat == "" {
+		config.ArchiveFormat = defaultArchiveFormat
+	}
+	if config.ArchiveBatchSize <= 0 {
+		config.ArchiveBatchSize = defaultArchiveBatchSize
+	}
+	if config.ChainInterval.Duration == 0 {
+		config.ChainInterval.Duration = defaultChainInterval
+	}
+	if config.ShutdownTimeout.Duration == 0 {
+		config.ShutdownTimeout.Duration = helpers.DefaultShutdownTimeout
+	}
+	config.Tracing.SetDefaults()
+}
+
+// Validate returns errors of missing or invalid options.
+func (config *Config) Validate() error {
+	errs := []error{
+		helpers.Required("database_address", config.DatabaseAddress),
+		helpers.Required("database_name", config.DatabaseName),
+		helpers.Required("nats_client_id", config.NATSClientID),
+		config.Tracing.Validate(),
+		config.NATSConfig.Validate(),
+	}
+	if len(config.Subscribes) == 0 {
+		errs = append(errs, errors.New("subscribes is required"))
+	}
+	if _, err := encoding.ParseFormat(config.ArchiveFormat); err != nil {
+		errs = append(errs, fmt.Errorf("archive_format %q is unknown", config.ArchiveFormat))
+	}
+	return errors.Join(errs...)
 }
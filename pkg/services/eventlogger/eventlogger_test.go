@@ -0,0 +1,236 @@
+package eventlogger
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+
+	"github.com/migotom/cell-centre-services/pkg/components/employee"
+	"github.com/migotom/cell-centre-services/pkg/components/event"
+	"github.com/migotom/cell-centre-services/pkg/entities"
+	"github.com/migotom/cell-centre-services/pkg/helpers/mocks"
+	"github.com/migotom/cell-centre-services/pkg/pb"
+)
+
+func validEventData() []byte {
+	return sequencedEventData(0)
+}
+
+func sequencedEventData(sequence uint64) []byte {
+	payload, _ := ptypes.MarshalAny(&pb.EmployeeFilter{Email: "admin@page.com"})
+	data, _ := proto.Marshal(&pb.Event{
+		EventId:       "7d5a3a8e-7c1a-4b54-a2d3-6a1e9f2e0a11",
+		Channel:       "employees",
+		Type:          "DeleteEmployee",
+		AggregateId:   "5d2f0c8e9a1b2c3d4e5f6a7b",
+		AggregateType: "employee",
+		Sequence:      sequence,
+		Payload:       payload,
+		SchemaVersion: event.SchemaVersion,
+		CreatedAt:     ptypes.TimestampNow(),
+	})
+	return data
+}
+
+func newEventRegistry(t *testing.T) *event.Registry {
+	registry := event.NewRegistry()
+	if err := employee.RegisterEvents(registry); err != nil {
+		t.Fatal(err)
+	}
+	return registry
+}
+
+func TestHandleMessage(t *testing.T) {
+	cases := []struct {
+		Name              string
+		Message           *mocks.MessageMock
+		ExpectedMockCalls func(*mocks.EventRepositoryMock, *mocks.StreamingMock, *mocks.MessageMock)
+	}{
+		{
+			Name:    "Stored event is acknowledged",
+			Message: &mocks.MessageMock{MsgData: validEventData(), MsgSequence: 1, MsgDeliveries: 1},
+			ExpectedMockCalls: func(r *mocks.EventRepositoryMock, s *mocks.StreamingMock, m *mocks.MessageMock) {
+				r.On("New", mock.Anything, mock.Anything).Return(nil)
+				m.On("Ack").Return(nil)
+			},
+		},
+		{
+			Name:    "Already logged event is acknowledged",
+			Message: &mocks.MessageMock{MsgData: validEventData(), MsgSequence: 1, MsgDeliveries: 2},
+			ExpectedMockCalls: func(r *mocks.EventRepositoryMock, s *mocks.StreamingMock, m *mocks.MessageMock) {
+				r.On("New", mock.Anything, mock.Anything).Return(event.ErrDuplicateEvent)
+				m.On("Ack").Return(nil)
+			},
+		},
+		{
+			Name:    "Sequenced event is acknowledged",
+			Message: &mocks.MessageMock{MsgData: sequencedEventData(3), MsgSequence: 1, MsgDeliveries: 1},
+			ExpectedMockCalls: func(r *mocks.EventRepositoryMock, s *mocks.StreamingMock, m *mocks.MessageMock) {
+				r.On("LastSequence", mock.Anything, "employee", "5d2f0c8e9a1b2c3d4e5f6a7b").Return(uint64(2), nil)
+				r.On("New", mock.Anything, mock.MatchedBy(func(e *entities.Event) bool {
+					return e.Sequence == 3
+				})).Return(nil)
+				m.On("Ack").Return(nil)
+			},
+		},
+		{
+			Name:    "Event waits for redelivery when sequence can't be read",
+			Message: &mocks.MessageMock{MsgData: sequencedEventData(3), MsgSequence: 1, MsgDeliveries: 1},
+			ExpectedMockCalls: func(r *mocks.EventRepositoryMock, s *mocks.StreamingMock, m *mocks.MessageMock) {
+				r.On("LastSequence", mock.Anything, "employee", "5d2f0c8e9a1b2c3d4e5f6a7b").Return(uint64(0), errors.New("database down"))
+				m.On("Nak").Return(nil)
+			},
+		},
+		{
+			Name:    "Failed event waits for redelivery",
+			Message: &mocks.MessageMock{MsgData: validEventData(), MsgSequence: 2, MsgDeliveries: 2},
+			ExpectedMockCalls: func(r *mocks.EventRepositoryMock, s *mocks.StreamingMock, m *mocks.MessageMock) {
+				r.On("New", mock.Anything, mock.Anything).Return(errors.New("database down"))
+				m.On("Nak").Return(nil)
+			},
+		},
+		{
+			Name:    "Failed event at last delivery is dead lettered",
+			Message: &mocks.MessageMock{MsgData: validEventData(), MsgSequence: 3, MsgDeliveries: 3},
+			ExpectedMockCalls: func(r *mocks.EventRepositoryMock, s *mocks.StreamingMock, m *mocks.MessageMock) {
+				r.On("New", mock.Anything, mock.Anything).Return(errors.New("database down"))
+				s.On("PublishData", "deadletters", "employees-3", mock.MatchedBy(func(data []byte) bool {
+					var deadLetter pb.DeadLetter
+					if err := proto.Unmarshal(data, &deadLetter); err != nil {
+						return false
+					}
+					return deadLetter.Channel == "employees" &&
+						deadLetter.Sequence == 3 &&
+						deadLetter.Deliveries == 3 &&
+						deadLetter.Error == "can't store event in database: database down"
+				})).Return(nil)
+				m.On("Ack").Return(nil)
+			},
+		},
+		{
+			Name:    "Poison message is dead lettered",
+			Message: &mocks.MessageMock{MsgData: []byte("not an event"), MsgSequence: 4, MsgDeliveries: 3},
+			ExpectedMockCalls: func(r *mocks.EventRepositoryMock, s *mocks.StreamingMock, m *mocks.MessageMock) {
+				s.On("PublishData", "deadletters", "employees-4", mock.Anything).Return(nil)
+				m.On("Ack").Return(nil)
+			},
+		},
+		{
+			Name:    "Message is not acknowledged when dead lettering fails",
+			Message: &mocks.MessageMock{MsgData: []byte("not an event"), MsgSequence: 5, MsgDeliveries: 3},
+			ExpectedMockCalls: func(r *mocks.EventRepositoryMock, s *mocks.StreamingMock, m *mocks.MessageMock) {
+				s.On("PublishData", "deadletters", "employees-5", mock.Anything).Return(errors.New("nats down"))
+			},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			log, _ := zap.NewProduction()
+			defer log.Sync()
+
+			eventRepositoryMock := mocks.EventRepositoryMock{}
+			streamingMock := mocks.StreamingMock{}
+
+			tc.ExpectedMockCalls(&eventRepositoryMock, &streamingMock, tc.Message)
+
+			config := Config{MaxDeliveries: 3}
+			config.SetDefaults()
+
+			eventLogger := NewEventLogger(log, &config, &streamingMock, &eventRepositoryMock, newEventRegistry(t))
+			eventLogger.handleMessage("employees", "eventlogger-1-durable", tc.Message)
+
+			eventRepositoryMock.AssertExpectations(t)
+			streamingMock.AssertExpectations(t)
+			tc.Message.AssertExpectations(t)
+		})
+	}
+}
+
+func TestSettleLag(t *testing.T) {
+	log, _ := zap.NewProduction()
+	defer log.Sync()
+
+	message := &mocks.MessageMock{MsgTimestamp: time.Now().Add(-time.Minute), MsgDeliveries: 1}
+	message.On("Ack").Return(nil)
+
+	eventLogger := NewEventLogger(log, &Config{}, nil, nil, nil)
+	eventLogger.settle("lag", "eventlogger-1-durable", message, nil)
+
+	message.AssertExpectations(t)
+	assert.InDelta(t, time.Minute.Seconds(), testutil.ToFloat64(loggingLag.WithLabelValues("lag")), 5)
+}
+
+func TestVerifySequence(t *testing.T) {
+	cases := []struct {
+		Name             string
+		Sequence         uint64
+		LastSequence     uint64
+		ExpectedGaps     int64
+		ExpectedReorders int64
+	}{
+		{
+			Name: "Not sequenced event",
+		},
+		{
+			Name:     "First event of aggregate",
+			Sequence: 1,
+		},
+		{
+			Name:         "Next event of aggregate",
+			Sequence:     5,
+			LastSequence: 4,
+		},
+		{
+			Name:         "Missing events of aggregate",
+			Sequence:     7,
+			LastSequence: 4,
+			ExpectedGaps: 1,
+		},
+		{
+			Name:             "Event of aggregate out of order",
+			Sequence:         3,
+			LastSequence:     4,
+			ExpectedReorders: 1,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			log, _ := zap.NewProduction()
+			defer log.Sync()
+
+			channel := "verify-" + tc.Name
+			eventLogger := NewEventLogger(log, &Config{}, nil, nil, nil)
+			eventLogger.verifySequence(channel, &entities.Event{Sequence: tc.Sequence}, tc.LastSequence)
+
+			assert.Equal(t, tc.ExpectedGaps, counterValue(sequenceGaps, channel))
+			assert.Equal(t, tc.ExpectedReorders, counterValue(reorderedEvents, channel))
+		})
+	}
+}
+
+func counterValue(counters *prometheus.CounterVec, channel string) int64 {
+	return int64(testutil.ToFloat64(counters.WithLabelValues(channel)))
+}
+
+func TestUniqueDeadLetters(t *testing.T) {
+	letters := []*pb.DeadLetter{
+		{Channel: "employees", Sequence: 1, Error: "first"},
+		{Channel: "employees", Sequence: 2},
+		{Channel: "employees", Sequence: 1, Error: "second"},
+		{Channel: "roles", Sequence: 1},
+	}
+
+	assert.Equal(t, []*pb.DeadLetter{
+		{Channel: "employees", Sequence: 1, Error: "second"},
+		{Channel: "employees", Sequence: 2},
+		{Channel: "roles", Sequence: 1},
+	}, uniqueDeadLetters(letters))
+}
@@ -0,0 +1,32 @@
+package eventlogger
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/migotom/cell-centre-services/pkg/helpers/metrics"
+)
+
+// Metrics of logged events, counted per channel.
+var (
+	duplicateEvents = newEventsCounter("duplicate_events_total", "Events already logged, skipped per channel.")
+	sequenceGaps    = newEventsCounter("sequence_gaps_total", "Gaps in sequences of aggregates per channel, approximate with several concurrent loggers.")
+	reorderedEvents = newEventsCounter("reordered_events_total", "Events of aggregates logged out of order per channel, approximate with several concurrent loggers.")
+	chainedEvents   = newEventsCounter("chained_events_total", "Logged events linked into hash chain per channel.")
+
+	loggingLag = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "eventlogger",
+		Name:      "lag_seconds",
+		Help:      "Time between storing of the last logged event by message bus and its logging, per channel.",
+	}, []string{"channel"})
+)
+
+func newEventsCounter(name, help string) *prometheus.CounterVec {
+	return promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "eventlogger",
+		Name:      name,
+		Help:      help,
+	}, []string{"channel"})
+}
@@ -0,0 +1,191 @@
+package eventlogger
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"go.uber.org/zap"
+	"golang.org/x/time/rate"
+
+	"github.com/migotom/cell-centre-services/pkg/components/event"
+	"github.com/migotom/cell-centre-services/pkg/pb"
+)
+
+const (
+	defaultReplayIdle     = 5 * time.Second
+	defaultReplayProgress = 10 * time.Second
+	replayBuffer          = 64
+)
+
+// ReplayHandler handles replayed message of channel, e.g. EventLogger.LogEvent or projection of events.
+type ReplayHandler func(channel string, data []byte) error
+
+// ReplayOptions of channel replay. Replay starts at given sequence, at given time or at the first
+// message of channel when neither is set, and ends once no message arrives for idle time.
+type ReplayOptions struct {
+	Channel       string
+	Handler       string
+	StartSequence uint64
+	StartTime     time.Time
+	// Rate limits number of messages handled per second, zero disables limit.
+	Rate float64
+	// DryRun decodes replayed messages without passing them to handler.
+	DryRun   bool
+	Idle     time.Duration
+	Progress time.Duration
+}
+
+// ReplayStats summarizes replay of channel.
+type ReplayStats struct {
+	Replayed      int
+	Failed        int
+	LastSequence  uint64
+	LastTimestamp time.Time
+}
+
+// Replayer feeds history of events channel into registered handlers.
+type Replayer struct {
+	log             *zap.Logger
+	eventsStreaming event.Streaming
+	handlers        map[string]ReplayHandler
+}
+
+// NewReplayer returns new replayer without registered handlers.
+func NewReplayer(log *zap.Logger, eventsStreaming event.Streaming) *Replayer {
+	return &Replayer{
+		log:             log,
+		eventsStreaming: eventsStreaming,
+		handlers:        make(map[string]ReplayHandler),
+	}
+}
+
+// Register handler under given name.
+func (replayer *Replayer) Register(name string, handler ReplayHandler) {
+	replayer.handlers[name] = handler
+}
+
+// Handlers returns sorted names of registered handlers.
+func (replayer *Replayer) Handlers() []string {
+	names := make([]string, 0, len(replayer.handlers))
+	for name := range replayer.handlers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Replay reads channel from requested position and passes its messages to handler. Messages that handler
+// failed to process are reported and skipped, replay continues with following ones.
+func (replayer *Replayer) Replay(ctx context.Context, options ReplayOptions) (ReplayStats, error) {
+	var stats ReplayStats
+
+	handler, ok := replayer.handlers[options.Handler]
+	if !ok {
+		return stats, fmt.Errorf("unknown replay handler %q", options.Handler)
+	}
+	if options.Idle == 0 {
+		options.Idle = defaultReplayIdle
+	}
+	if options.Progress == 0 {
+		options.Progress = defaultReplayProgress
+	}
+
+	limit := rate.Inf
+	if options.Rate > 0 {
+		limit = rate.Limit(options.Rate)
+	}
+	limiter := rate.NewLimiter(limit, 1)
+
+	var start event.SubscribeOption
+	switch {
+	case options.StartSequence != 0:
+		start = event.StartAtSequence(options.StartSequence)
+	case !options.StartTime.IsZero():
+		start = event.StartAtTime(options.StartTime)
+	default:
+		start = event.DeliverAllAvailable()
+	}
+
+	done := make(chan struct{})
+	defer close(done)
+
+	messages := make(chan event.Message, replayBuffer)
+	subscription, err := replayer.eventsStreaming.Subscribe(options.Channel, func(msg event.Message) {
+		select {
+		case messages <- msg:
+		case <-done:
+		}
+	}, start)
+	if err != nil {
+		return stats, fmt.Errorf("can't subscribe channel %s: %v", options.Channel, err)
+	}
+	defer subscription.Close()
+
+	log := replayer.log.With(zap.String("channel", options.Channel), zap.String("handler", options.Handler), zap.Bool("dryRun", options.DryRun))
+	started := time.Now()
+
+	progress := time.NewTicker(options.Progress)
+	defer progress.Stop()
+
+	idle := time.NewTimer(options.Idle)
+	defer idle.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return stats, ctx.Err()
+		case <-idle.C:
+			return stats, nil
+		case <-progress.C:
+			log.Info("Replay progress",
+				zap.Int("replayed", stats.Replayed),
+				zap.Int("failed", stats.Failed),
+				zap.Uint64("lastSequence", stats.LastSequence),
+				zap.Time("lastTimestamp", stats.LastTimestamp),
+				zap.Float64("perSecond", float64(stats.Replayed+stats.Failed)/time.Since(started).Seconds()),
+			)
+		case msg := <-messages:
+			if !idle.Stop() {
+				select {
+				case <-idle.C:
+				default:
+				}
+			}
+			idle.Reset(options.Idle)
+
+			// message redelivered by message bus was already handled
+			if msg.Sequence() <= stats.LastSequence {
+				continue
+			}
+
+			if err := limiter.Wait(ctx); err != nil {
+				return stats, err
+			}
+
+			stats.LastSequence = msg.Sequence()
+			stats.LastTimestamp = msg.Timestamp()
+
+			if err := replayMessage(options, handler, msg); err != nil {
+				stats.Failed++
+				log.Error("Failed to replay message", zap.Uint64("sequence", msg.Sequence()), zap.Error(err))
+				continue
+			}
+			stats.Replayed++
+		}
+	}
+}
+
+// replayMessage passes message to handler, in dry run message is only decoded.
+func replayMessage(options ReplayOptions, handler ReplayHandler, msg event.Message) error {
+	if options.DryRun {
+		var e pb.Event
+		if err := proto.Unmarshal(msg.Data(), &e); err != nil {
+			return fmt.Errorf("can't decode event: %v", err)
+		}
+		return nil
+	}
+	return handler(options.Channel, msg.Data())
+}
@@ -0,0 +1,118 @@
+package eventlogger
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+
+	"github.com/migotom/cell-centre-services/pkg/components/event"
+	"github.com/migotom/cell-centre-services/pkg/helpers"
+	"github.com/migotom/cell-centre-services/pkg/helpers/mocks"
+)
+
+func TestReplay(t *testing.T) {
+	cases := []struct {
+		Name             string
+		Options          ReplayOptions
+		Messages         []*mocks.MessageMock
+		HandlerErr       error
+		ExpectedHandled  int
+		ExpectedStats    ReplayStats
+		ExpectedStartSeq uint64
+		ExpectedErr      string
+	}{
+		{
+			Name:    "Replay from sequence",
+			Options: ReplayOptions{Channel: "employees", Handler: "logger", StartSequence: 2},
+			Messages: []*mocks.MessageMock{
+				{MsgData: validEventData(), MsgSequence: 2},
+				{MsgData: validEventData(), MsgSequence: 3},
+			},
+			ExpectedHandled:  2,
+			ExpectedStats:    ReplayStats{Replayed: 2, LastSequence: 3},
+			ExpectedStartSeq: 2,
+		},
+		{
+			Name:    "Redelivered messages are skipped",
+			Options: ReplayOptions{Channel: "employees", Handler: "logger"},
+			Messages: []*mocks.MessageMock{
+				{MsgData: validEventData(), MsgSequence: 1},
+				{MsgData: validEventData(), MsgSequence: 2},
+				{MsgData: validEventData(), MsgSequence: 1},
+			},
+			ExpectedHandled: 2,
+			ExpectedStats:   ReplayStats{Replayed: 2, LastSequence: 2},
+		},
+		{
+			Name:    "Failed messages are counted",
+			Options: ReplayOptions{Channel: "employees", Handler: "logger"},
+			Messages: []*mocks.MessageMock{
+				{MsgData: validEventData(), MsgSequence: 1},
+			},
+			HandlerErr:      errors.New("database down"),
+			ExpectedHandled: 1,
+			ExpectedStats:   ReplayStats{Failed: 1, LastSequence: 1},
+		},
+		{
+			Name:    "Dry run only decodes messages",
+			Options: ReplayOptions{Channel: "employees", Handler: "logger", DryRun: true},
+			Messages: []*mocks.MessageMock{
+				{MsgData: validEventData(), MsgSequence: 1},
+				{MsgData: []byte("not an event"), MsgSequence: 2},
+			},
+			ExpectedStats: ReplayStats{Replayed: 1, Failed: 1, LastSequence: 2},
+		},
+		{
+			Name:        "Unknown handler",
+			Options:     ReplayOptions{Channel: "employees", Handler: "directory"},
+			ExpectedErr: `unknown replay handler "directory"`,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			log, _ := zap.NewProduction()
+			defer log.Sync()
+
+			streamingMock := mocks.StreamingMock{}
+			subscriptionMock := mocks.SubscriptionMock{}
+
+			if tc.ExpectedErr == "" {
+				streamingMock.On("Subscribe", tc.Options.Channel, mock.Anything, mock.MatchedBy(func(options []event.SubscribeOption) bool {
+					return event.NewSubscribeOptions(options...).StartAtSequence == tc.ExpectedStartSeq
+				})).Run(func(args mock.Arguments) {
+					handler := args.Get(1).(event.MessageHandler)
+					go func() {
+						for _, msg := range tc.Messages {
+							handler(msg)
+						}
+					}()
+				}).Return(&subscriptionMock, nil)
+				subscriptionMock.On("Close").Return(nil)
+			}
+
+			var handled int
+			replayer := NewReplayer(log, &streamingMock)
+			replayer.Register("logger", func(channel string, data []byte) error {
+				handled++
+				return tc.HandlerErr
+			})
+
+			tc.Options.Idle = 100 * time.Millisecond
+			stats, err := replayer.Replay(context.Background(), tc.Options)
+			helpers.AssertErrors(t, tc.ExpectedErr, err)
+
+			assert.Equal(t, tc.ExpectedStats.Replayed, stats.Replayed)
+			assert.Equal(t, tc.ExpectedStats.Failed, stats.Failed)
+			assert.Equal(t, tc.ExpectedStats.LastSequence, stats.LastSequence)
+			assert.Equal(t, tc.ExpectedHandled, handled)
+
+			streamingMock.AssertExpectations(t)
+			subscriptionMock.AssertExpectations(t)
+		})
+	}
+}
@@ -1,20 +1,30 @@
 package eventstore
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"net"
 
-	grpc_middleware "github.com/grpc-ecosystem/go-grpc-middleware"
-	grpc_auth "github.com/grpc-ecosystem/go-grpc-middleware/auth"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/testdata"
 
 	authDelivery "github.com/migotom/cell-centre-services/pkg/components/auth/delivery/grpc"
 	"github.com/migotom/cell-centre-services/pkg/components/employee"
 	employeeDelivery "github.com/migotom/cell-centre-services/pkg/components/employee/delivery/grpc"
 	"github.com/migotom/cell-centre-services/pkg/components/event"
+	eventDelivery "github.com/migotom/cell-centre-services/pkg/components/event/delivery/grpc"
+	"github.com/migotom/cell-centre-services/pkg/components/event/streaming"
+	"github.com/migotom/cell-centre-services/pkg/components/health"
+	healthDelivery "github.com/migotom/cell-centre-services/pkg/components/health/delivery/grpc"
 	"github.com/migotom/cell-centre-services/pkg/components/role"
+	"github.com/migotom/cell-centre-services/pkg/helpers"
+	"github.com/migotom/cell-centre-services/pkg/helpers/interceptors"
+	"github.com/migotom/cell-centre-services/pkg/helpers/metrics"
+	"github.com/migotom/cell-centre-services/pkg/helpers/tracing"
 	"github.com/migotom/cell-centre-services/pkg/pb"
 )
 
@@ -24,27 +34,40 @@ type EventStore struct {
 	config           *Config
 	authDelivery     *authDelivery.AuthenticateDelivery
 	employeeDelivery *employeeDelivery.EmployeeDelivery
+	eventDelivery    *eventDelivery.EventDelivery
+	healthDelivery   *healthDelivery.HealthDelivery
 }
 
-// NewEventStore returns new event store service.
+// NewEventStore returns new event store service, given health delivery is served as grpc.health.v1 service.
+// Health isn't served without it.
 func NewEventStore(
 	log *zap.Logger,
 	config *Config,
-	eventsStreaming *event.EventsStreaming,
+	eventsStreaming event.Streaming,
+	eventRegistry *event.Registry,
 	authDelivery *authDelivery.AuthenticateDelivery,
 	employeeRepository employee.Repository,
 	roleRepository role.Repository,
+	employeeOptions employeeDelivery.EmployeeDeliveryOptions,
+	healthDelivery *healthDelivery.HealthDelivery,
 ) *EventStore {
+	var eventCipher event.PayloadCipher
+	if employeeOptions.PayloadCipher != nil {
+		eventCipher = employeeOptions.PayloadCipher
+	}
+
 	return &EventStore{
 		log:              log,
 		config:           config,
 		authDelivery:     authDelivery,
-		employeeDelivery: employeeDelivery.NewEmployeeDelivery(log, employeeRepository, roleRepository, eventsStreaming),
+		employeeDelivery: employeeDelivery.NewEmployeeDelivery(log, employeeRepository, roleRepository, eventsStreaming, eventRegistry, employeeOptions),
+		eventDelivery:    eventDelivery.NewEventDelivery(log, eventsStreaming, eventRegistry, eventCipher),
+		healthDelivery:   healthDelivery,
 	}
 }
 
-// Listen to gRPC calls.
-func (eventStore *EventStore) Listen() {
+// Listen to gRPC calls until given context is done, pending calls and publishes of events are finished before return.
+func (eventStore *EventStore) Listen(ctx context.Context) {
 	listener, err := net.Listen("tcp", eventStore.config.ListenAddress)
 	if err != nil {
 		eventStore.log.Fatal("Failed to listen", zap.Error(err))
@@ -61,22 +84,102 @@ func (eventStore *EventStore) Listen() {
 		opts = []grpc.ServerOption{grpc.Creds(creds)}
 	}
 
-	opts = append(opts, grpc.UnaryInterceptor(grpc_middleware.ChainUnaryServer(
-		grpc_auth.UnaryServerInterceptor(eventStore.authDelivery.DefaultInterceptor),
-	)))
+	opts = append(opts, interceptors.ServerOptions(eventStore.log, eventStore.authDelivery.DefaultInterceptor)...)
 
 	grpcServer := grpc.NewServer(opts...)
 	pb.RegisterEmployeeServiceServer(grpcServer, eventStore.employeeDelivery)
-	grpcServer.Serve(listener)
+	pb.RegisterEmployeeExportServiceServer(grpcServer, eventStore.employeeDelivery)
+	pb.RegisterEventServiceServer(grpcServer, eventStore.eventDelivery)
+	metrics.RegisterServer(grpcServer)
+	if eventStore.healthDelivery != nil {
+		healthpb.RegisterHealthServer(grpcServer, eventStore.healthDelivery)
+		go eventStore.healthDelivery.Run(ctx)
+	}
+
+	go func() {
+		// subscriptions of events would hold graceful stop until timeout
+		<-ctx.Done()
+		eventStore.eventDelivery.Close()
+	}()
+
+	serve := func() error { return grpcServer.Serve(listener) }
+	if err := helpers.ServeGRPC(ctx, grpcServer, serve, eventStore.config.ShutdownTimeout.Duration); err != nil {
+		eventStore.log.Error("gRPC server stopped", zap.Error(err))
+	}
+
+	drainCtx, cancel := context.WithTimeout(context.Background(), eventStore.config.ShutdownTimeout.Duration)
+	defer cancel()
+	if err := eventStore.employeeDelivery.Drain(drainCtx); err != nil {
+		eventStore.log.Error("Pending events weren't published", zap.Error(err))
+	}
+}
+
+// ExportEmployeeData returns data held about employee matching given filter.
+func (eventStore *EventStore) ExportEmployeeData(ctx context.Context, filter *pb.EmployeeFilter) (*pb.EmployeeDataExport, error) {
+	return eventStore.employeeDelivery.ExportEmployeeData(ctx, filter)
 }
 
+// Write models of employees.
+const (
+	// StateWriteModel keeps employees repository as the source of truth, events are published after its changes.
+	StateWriteModel = "state"
+	// EventsWriteModel keeps event log as the source of truth, employees repository is projection of its events.
+	EventsWriteModel = "events"
+)
+
+const defaultSnapshotEvery = 100
+
 // Config of EventStore service.
 type Config struct {
 	ListenAddress          string `toml:"listen_address"`
 	DatabaseAddress        string `toml:"database_address"`
 	DatabaseName           string `toml:"database_name"`
-	NATSClusterID          string `toml:"nats_cluster_id"`
-	NATSURL                string `toml:"nats_url"`
 	GRPCTLSCertificateFile string `toml:"grpc_tls_certificate_file"`
 	GRPCTLSKeyFile         string `toml:"grpc_tls_key_file"`
+	MetricsAddress         string `toml:"metrics_address"`
+	WriteModel             string `toml:"write_model"`
+	SnapshotEvery          int    `toml:"snapshot_every"`
+	ArchiveDirectory       string `toml:"archive_directory"`
+
+	ShutdownTimeout     helpers.Duration `toml:"shutdown_timeout"`
+	HealthCheckInterval helpers.Duration `toml:"health_check_interval"`
+	Tracing             tracing.Config   `toml:"tracing"`
+	streaming.NATSConfig
+}
+
+// SetDefaults sets default values of not configured options.
+func (config *Config) SetDefaults() {
+	if config.WriteModel == "" {
+		config.WriteModel = StateWriteModel
+	}
+	if config.SnapshotEvery <= 0 {
+		config.SnapshotEvery = defaultSnapshotEvery
+	}
+	if config.ShutdownTimeout.Duration == 0 {
+		config.ShutdownTimeout.Duration = helpers.DefaultShutdownTimeout
+	}
+	if config.HealthCheckInterval.Duration == 0 {
+		config.HealthCheckInterval.Duration = health.DefaultCheckInterval
+	}
+	config.Tracing.SetDefaults()
+}
+
+// Validate returns errors of missing or invalid options.
+func (config *Config) Validate() error {
+	errs := []error{
+		helpers.Required("listen_address", config.ListenAddress),
+		helpers.Required("database_address", config.DatabaseAddress),
+		helpers.Required("database_name", config.DatabaseName),
+		config.Tracing.Validate(),
+		config.NATSConfig.Validate(),
+	}
+	if (config.GRPCTLSCertificateFile == "") != (config.GRPCTLSKeyFile == "") {
+		errs = append(errs, errors.New("grpc_tls_certificate_file and grpc_tls_key_file have to be set together"))
+	}
+	switch config.WriteModel {
+	case StateWriteModel, EventsWriteModel:
+	default:
+		errs = append(errs, fmt.Errorf("write_model %q is unknown, expected %q or %q", config.WriteModel, StateWriteModel, EventsWriteModel))
+	}
+	return errors.Join(errs...)
 }
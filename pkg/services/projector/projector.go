@@ -0,0 +1,474 @@
+package projector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/zap"
+
+	"github.com/migotom/cell-centre-services/pkg/components/directory"
+	"github.com/migotom/cell-centre-services/pkg/components/employee"
+	"github.com/migotom/cell-centre-services/pkg/components/event"
+	eventFactory "github.com/migotom/cell-centre-services/pkg/components/event/factory"
+	"github.com/migotom/cell-centre-services/pkg/components/event/streaming"
+	"github.com/migotom/cell-centre-services/pkg/components/keystore"
+	"github.com/migotom/cell-centre-services/pkg/components/role"
+	"github.com/migotom/cell-centre-services/pkg/entities"
+	"github.com/migotom/cell-centre-services/pkg/helpers"
+	"github.com/migotom/cell-centre-services/pkg/helpers/correlation"
+	"github.com/migotom/cell-centre-services/pkg/helpers/tracing"
+	"github.com/migotom/cell-centre-services/pkg/pb"
+	"github.com/migotom/cell-centre-services/pkg/services/eventlogger"
+)
+
+const (
+	// ReplayHandler is name under which projection is registered in replayer.
+	ReplayHandler = "directory"
+
+	// subscriber of projection named in its dead letters
+	subscriber = "projector"
+
+	defaultAckWait           = 30 * time.Second
+	defaultRebuildIdle       = 5 * time.Second
+	defaultMaxDeliveries     = 5
+	defaultDeadLetterChannel = "deadletters"
+)
+
+// Projector keeps employee directory read model up to date with employee and role events.
+type Projector struct {
+	log                 *zap.Logger
+	config              *Config
+	eventsStreaming     event.Streaming
+	directoryRepository directory.Repository
+	roleRepository      role.Repository
+	eventFactory        *eventFactory.EventEntityFactory
+	payloadCipher       event.PayloadCipher
+
+	sync.Mutex
+	checkpoints map[string]uint64
+	// sequences of messages waiting for redelivery, checkpoint isn't moved past them
+	failed map[string]uint64
+
+	// messages being projected
+	handling sync.WaitGroup
+}
+
+// NewProjector returns new employee directory projector, personal data of events is decrypted by given cipher.
+// Nil cipher projects personal data as it was published.
+func NewProjector(log *zap.Logger, config *Config, eventsStreaming event.Streaming, directoryRepository directory.Repository, roleRepository role.Repository, eventRegistry *event.Registry, payloadCipher event.PayloadCipher) *Projector {
+	return &Projector{
+		log:                 log,
+		config:              config,
+		eventsStreaming:     eventsStreaming,
+		directoryRepository: directoryRepository,
+		roleRepository:      roleRepository,
+		eventFactory:        eventFactory.NewEventEntityFactory(eventRegistry),
+		payloadCipher:       payloadCipher,
+		checkpoints:         make(map[string]uint64),
+		failed:              make(map[string]uint64),
+	}
+}
+
+// Listen projects events of subscribed channels until given context is done, each channel is read from message
+// following its checkpoint one message at a time. Events being projected are finished before return.
+func (projector *Projector) Listen(ctx context.Context) {
+	var subscriptions []event.Subscription
+	for _, channel := range projector.config.Subscribes {
+		channel := channel
+
+		checkpoint, err := projector.directoryRepository.Checkpoint(context.Background(), channel)
+		if err != nil {
+			projector.log.Fatal("Can't read checkpoint of projection", zap.String("channel", channel), zap.Error(err))
+		}
+		projector.checkpoints[channel] = checkpoint
+
+		start := event.DeliverAllAvailable()
+		if checkpoint > 0 {
+			start = event.StartAtSequence(checkpoint + 1)
+		}
+
+		subscription, err := projector.eventsStreaming.Subscribe(channel, func(msg event.Message) {
+			projector.handling.Add(1)
+			defer projector.handling.Done()
+			projector.handleMessage(channel, msg)
+		}, start, event.ManualAck(), event.AckWait(projector.config.AckWait.Duration), event.MaxInFlight(1))
+		if err != nil {
+			projector.log.Fatal("Failed to subscribe events", zap.String("channel", channel), zap.Error(err))
+		}
+		subscriptions = append(subscriptions, subscription)
+	}
+
+	<-ctx.Done()
+	for _, subscription := range subscriptions {
+		if err := subscription.Close(); err != nil {
+			projector.log.Error("Failed to close subscription", zap.Error(err))
+		}
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), projector.config.ShutdownTimeout.Duration)
+	defer cancel()
+	if err := helpers.Wait(shutdownCtx, &projector.handling); err != nil {
+		projector.log.Warn("Projection in progress abandoned, events are redelivered after restart", zap.Error(err))
+	}
+}
+
+// handleMessage projects event and advances checkpoint of channel. Message that failed to be projected is
+// redelivered until limit of deliveries is reached, afterwards it's moved into dead letter channel.
+func (projector *Projector) handleMessage(channel string, msg event.Message) {
+	log := projector.log.With(zap.String("channel", channel), zap.Uint64("sequence", msg.Sequence()), zap.Int("deliveries", msg.Deliveries()))
+
+	if err := projector.Project(channel, msg.Data()); err != nil {
+		if msg.Deliveries() < projector.config.MaxDeliveries {
+			log.Warn("Error while projecting event, message will be redelivered", zap.Error(err))
+			projector.redeliver(log, channel, msg)
+			return
+		}
+		if deadLetterErr := projector.deadLetter(channel, msg, err); deadLetterErr != nil {
+			log.Error("Failed to move message into dead letter channel", zap.NamedError("cause", err), zap.Error(deadLetterErr))
+			projector.redeliver(log, channel, msg)
+			return
+		}
+		log.Error("Error while projecting event, message moved into dead letter channel", zap.Error(err))
+	}
+
+	if err := projector.advance(channel, msg.Sequence()); err != nil {
+		log.Error("Can't store checkpoint of projection", zap.Error(err))
+	}
+	if err := msg.Ack(); err != nil {
+		log.Error("Failed to acknowledge message", zap.Error(err))
+	}
+}
+
+// redeliver keeps checkpoint of channel behind message until it's settled and asks for its redelivery.
+func (projector *Projector) redeliver(log *zap.Logger, channel string, msg event.Message) {
+	projector.Lock()
+	if failed, ok := projector.failed[channel]; !ok || msg.Sequence() < failed {
+		projector.failed[channel] = msg.Sequence()
+	}
+	projector.Unlock()
+
+	if err := msg.Nak(); err != nil {
+		log.Error("Failed to negatively acknowledge message", zap.Error(err))
+	}
+}
+
+func (projector *Projector) deadLetter(channel string, msg event.Message, cause error) error {
+	data, err := eventlogger.NewDeadLetter(channel, subscriber, msg, cause)
+	if err != nil {
+		return err
+	}
+
+	id := fmt.Sprintf("%s-%s-%d", subscriber, channel, msg.Sequence())
+	return projector.eventsStreaming.PublishData(projector.config.DeadLetterChannel, id, data)
+}
+
+// advance stores checkpoint of channel once message of given sequence is projected or dead lettered. Checkpoint
+// isn't moved past message waiting for redelivery, nor back by redelivered message.
+func (projector *Projector) advance(channel string, sequence uint64) error {
+	projector.Lock()
+	defer projector.Unlock()
+
+	if failed, ok := projector.failed[channel]; ok {
+		if sequence > failed {
+			return nil
+		}
+		if sequence == failed {
+			delete(projector.failed, channel)
+		}
+	}
+	if sequence <= projector.checkpoints[channel] {
+		return nil
+	}
+	if err := projector.directoryRepository.SetCheckpoint(context.Background(), channel, sequence); err != nil {
+		return err
+	}
+	projector.checkpoints[channel] = sequence
+	return nil
+}
+
+// Rebuild drops employee directory and projects whole history of subscribed channels in their order, afterwards
+// checkpoints of channels point to their last replayed messages. Projector shouldn't listen during rebuild.
+func (projector *Projector) Rebuild(ctx context.Context) error {
+	if err := projector.directoryRepository.Drop(ctx); err != nil {
+		return fmt.Errorf("can't drop employee directory: %v", err)
+	}
+
+	replayer := eventlogger.NewReplayer(projector.log, projector.eventsStreaming)
+	replayer.Register(ReplayHandler, projector.Project)
+
+	for _, channel := range projector.config.Subscribes {
+		stats, err := replayer.Replay(ctx, eventlogger.ReplayOptions{
+			Channel: channel,
+			Handler: ReplayHandler,
+			Idle:    projector.config.RebuildIdle.Duration,
+		})
+		projector.log.Info("Channel projected",
+			zap.String("channel", channel),
+			zap.Int("projected", stats.Replayed),
+			zap.Int("failed", stats.Failed),
+			zap.Uint64("lastSequence", stats.LastSequence),
+		)
+		if err != nil {
+			return fmt.Errorf("can't replay channel %s: %v", channel, err)
+		}
+		if stats.LastSequence == 0 {
+			continue
+		}
+		if err := projector.directoryRepository.SetCheckpoint(ctx, channel, stats.LastSequence); err != nil {
+			return fmt.Errorf("can't store checkpoint of channel %s: %v", channel, err)
+		}
+	}
+	return nil
+}
+
+// Project applies event of channel to employee directory, events of other types are skipped.
+func (projector *Projector) Project(channel string, data []byte) (err error) {
+	var e pb.Event
+	if err := proto.Unmarshal(data, &e); err != nil {
+		return fmt.Errorf("can't decode event: %v", err)
+	}
+
+	ctx, span := tracing.StartConsume(correlation.NewContextFromEvent(context.Background(), &e), channel, &e)
+	defer func() { tracing.End(span, err) }()
+
+	if projector.payloadCipher != nil {
+		if err := projector.payloadCipher.Decrypt(ctx, &e); err != nil {
+			return err
+		}
+	}
+	entity, err := projector.eventFactory.NewFromEvent(e)
+	if err != nil {
+		return err
+	}
+
+	switch entity.Type {
+	case entities.NewEmployeeEvent, entities.UpdateEmployeeEvent, entities.DeleteEmployeeEvent, entities.EraseEmployeeEvent:
+		err = projector.projectEmployee(ctx, entity)
+	case entities.UpdateRoleEvent, entities.DeleteRoleEvent:
+		err = projector.projectRole(ctx, entity)
+	default:
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("can't project event %s: %v", entity.EventID, err)
+	}
+
+	correlation.Logger(ctx, projector.log).Debug("Event projected", zap.String("channel", channel), zap.String("eventID", entity.EventID))
+	return nil
+}
+
+func (projector *Projector) projectEmployee(ctx context.Context, e *entities.Event) error {
+	id, err := employeeID(e)
+	if err != nil {
+		return err
+	}
+
+	entry, err := projector.directoryRepository.Get(ctx, id)
+	switch {
+	case err == directory.ErrEntryNotFound:
+		entry = nil
+	case err != nil:
+		return err
+	case e.Sequence != 0 && e.Sequence <= entry.Sequence:
+		// event of employee was already projected
+		return nil
+	}
+
+	switch e.Type {
+	case entities.NewEmployeeEvent:
+		created, ok := e.Data.(*entities.Employee)
+		if !ok {
+			return errors.New("missing employee")
+		}
+		entry = newEntry(created)
+		entry.ID = id
+		if entry.CreatedAt == nil {
+			entry.CreatedAt = eventTime(e)
+		}
+	case entities.UpdateEmployeeEvent:
+		if entry == nil {
+			return fmt.Errorf("%v: %s", employee.ErrEmployeeNotFound, id.Hex())
+		}
+		changes, ok := e.Data.(*entities.EmployeeChanges)
+		if !ok {
+			return errors.New("missing employee changes")
+		}
+		if err := projector.applyChanges(ctx, entry, changes.Changes); err != nil {
+			return err
+		}
+		entry.UpdatedAt = eventTime(e)
+	case entities.DeleteEmployeeEvent:
+		if entry == nil {
+			return nil
+		}
+		entry.Status = entities.DeletedEmployee
+		entry.UpdatedAt = eventTime(e)
+	case entities.EraseEmployeeEvent:
+		if entry == nil {
+			return nil
+		}
+		entry.Email, entry.Name, entry.Phone = keystore.Erased, keystore.Erased, keystore.Erased
+		entry.UpdatedAt = eventTime(e)
+	}
+
+	entry.Sequence = e.Sequence
+	return projector.directoryRepository.Save(ctx, entry)
+}
+
+// applyChanges sets new values of changed fields of entry, roles are matched by their names.
+func (projector *Projector) applyChanges(ctx context.Context, entry *entities.DirectoryEntry, changes []entities.FieldChange) error {
+	for _, change := range changes {
+		if change.Redacted {
+			continue
+		}
+		switch change.Path {
+		case "email":
+			entry.Email = change.NewValue
+		case "name":
+			entry.Name = change.NewValue
+		case "phone":
+			entry.Phone = change.NewValue
+		case "team":
+			entry.Team = change.NewValue
+		case "roles":
+			roles, err := projector.changedRoles(ctx, entry.Roles, change.NewValue)
+			if err != nil {
+				return err
+			}
+			entry.Roles = roles
+		}
+	}
+	return nil
+}
+
+// changedRoles returns roles of given comma separated names, roles not held yet are looked up by name.
+func (projector *Projector) changedRoles(ctx context.Context, roles []entities.Role, names string) ([]entities.Role, error) {
+	held := make(map[string]entities.Role, len(roles))
+	for _, role := range roles {
+		held[role.Name] = role
+	}
+
+	var changed []entities.Role
+	for _, name := range strings.Split(names, ",") {
+		if name == "" {
+			continue
+		}
+		role, ok := held[name]
+		if !ok {
+			found, err := projector.roleRepository.Get(ctx, &pb.RoleFilter{Name: name})
+			if err != nil {
+				return nil, fmt.Errorf("can't get role %s: %v", name, err)
+			}
+			role = *found
+		}
+		changed = append(changed, role)
+	}
+	return changed, nil
+}
+
+func (projector *Projector) projectRole(ctx context.Context, e *entities.Event) error {
+	role, ok := e.Data.(*entities.Role)
+	if !ok || role.ID.IsZero() {
+		return errors.New("missing role ID")
+	}
+
+	if e.Type == entities.DeleteRoleEvent {
+		return projector.directoryRepository.RemoveRole(ctx, *role)
+	}
+	return projector.directoryRepository.RenameRole(ctx, *role)
+}
+
+// employeeID returns ID of employee aggregate, events logged before aggregate IDs were set carry it in data only.
+func employeeID(e *entities.Event) (primitive.ObjectID, error) {
+	if e.AggregateID != "" {
+		id, err := primitive.ObjectIDFromHex(e.AggregateID)
+		if err != nil {
+			return id, fmt.Errorf("invalid employee ID %q", e.AggregateID)
+		}
+		return id, nil
+	}
+
+	switch data := e.Data.(type) {
+	case *entities.Employee:
+		return data.ID, nil
+	case *entities.EmployeeChanges:
+		return data.ID, nil
+	}
+	return primitive.NilObjectID, errors.New("missing employee ID")
+}
+
+func newEntry(employee *entities.Employee) *entities.DirectoryEntry {
+	return &entities.DirectoryEntry{
+		ID:        employee.ID,
+		Email:     employee.Email,
+		Name:      employee.Name,
+		Phone:     employee.Phone,
+		Team:      employee.Team,
+		Status:    entities.ActiveEmployee,
+		Roles:     employee.Roles,
+		CreatedAt: employee.CreatedAt,
+		UpdatedAt: employee.UpdatedAt,
+	}
+}
+
+func eventTime(e *entities.Event) *time.Time {
+	if e.CreatedAt.IsZero() {
+		return nil
+	}
+	createdAt := e.CreatedAt
+	return &createdAt
+}
+
+// Config of Projector service.
+type Config struct {
+	DatabaseAddress   string           `toml:"database_address"`
+	DatabaseName      string           `toml:"database_name"`
+	Subscribes        []string         `toml:"subscribes"`
+	MaxDeliveries     int              `toml:"max_deliveries"`
+	AckWait           helpers.Duration `toml:"ack_wait"`
+	DeadLetterChannel string           `toml:"dead_letter_channel"`
+	RebuildIdle       helpers.Duration `toml:"rebuild_idle"`
+	MetricsAddress    string           `toml:"metrics_address"`
+	ShutdownTimeout   helpers.Duration `toml:"shutdown_timeout"`
+	Tracing           tracing.Config   `toml:"tracing"`
+	streaming.NATSConfig
+}
+
+// SetDefaults sets default values of not configured options.
+func (config *Config) SetDefaults() {
+	if len(config.Subscribes) == 0 {
+		config.Subscribes = []string{employee.EventChannel, role.EventChannel}
+	}
+	if config.MaxDeliveries <= 0 {
+		config.MaxDeliveries = defaultMaxDeliveries
+	}
+	if config.AckWait.Duration == 0 {
+		config.AckWait.Duration = defaultAckWait
+	}
+	if config.DeadLetterChannel == "" {
+		config.DeadLetterChannel = defaultDeadLetterChannel
+	}
+	if config.RebuildIdle.Duration == 0 {
+		config.RebuildIdle.Duration = defaultRebuildIdle
+	}
+	if config.ShutdownTimeout.Duration == 0 {
+		config.ShutdownTimeout.Duration = helpers.DefaultShutdownTimeout
+	}
+	config.Tracing.SetDefaults()
+}
+
+// Validate returns errors of missing or invalid options.
+func (config *Config) Validate() error {
+	return errors.Join(
+		helpers.Required("database_address", config.DatabaseAddress),
+		helpers.Required("database_name", config.DatabaseName),
+		config.Tracing.Validate(),
+		config.NATSConfig.Validate(),
+	)
+}
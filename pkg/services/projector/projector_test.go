@@ -0,0 +1,328 @@
+package projector
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/zap"
+
+	"github.com/migotom/cell-centre-services/pkg/components/directory"
+	"github.com/migotom/cell-centre-services/pkg/components/employee"
+	"github.com/migotom/cell-centre-services/pkg/components/event"
+	"github.com/migotom/cell-centre-services/pkg/components/keystore"
+	"github.com/migotom/cell-centre-services/pkg/components/role"
+	"github.com/migotom/cell-centre-services/pkg/entities"
+	"github.com/migotom/cell-centre-services/pkg/helpers"
+	"github.com/migotom/cell-centre-services/pkg/helpers/mocks"
+	"github.com/migotom/cell-centre-services/pkg/pb"
+)
+
+var (
+	johnID, _    = primitive.ObjectIDFromHex("5d3783ee28ae9468bc528907")
+	adminRole    = entities.Role{ID: primitive.NewObjectID(), Name: "admin"}
+	servicemanID = primitive.NewObjectID()
+	createdAt    = time.Date(2019, 7, 24, 20, 26, 40, 0, time.UTC)
+)
+
+func eventData(eventType entities.EventType, channel, aggregateID string, sequence uint64, payload proto.Message) []byte {
+	any, _ := ptypes.MarshalAny(payload)
+	timestamp, _ := ptypes.TimestampProto(createdAt)
+	data, _ := proto.Marshal(&pb.Event{
+		EventId:       "e1",
+		Channel:       channel,
+		Type:          string(eventType),
+		AggregateId:   aggregateID,
+		AggregateType: channel,
+		Sequence:      sequence,
+		Payload:       any,
+		SchemaVersion: event.SchemaVersion,
+		CreatedAt:     timestamp,
+	})
+	return data
+}
+
+func existingEntry() *entities.DirectoryEntry {
+	return &entities.DirectoryEntry{
+		ID:       johnID,
+		Email:    "john@page.com",
+		Name:     "John",
+		Status:   entities.ActiveEmployee,
+		Roles:    []entities.Role{adminRole},
+		Sequence: 1,
+	}
+}
+
+func newEventRegistry(t *testing.T) *event.Registry {
+	registry := event.NewRegistry()
+	assert.NoError(t, employee.RegisterEvents(registry))
+	assert.NoError(t, role.RegisterEvents(registry))
+	return registry
+}
+
+func TestProject(t *testing.T) {
+	cases := []struct {
+		Name              string
+		Channel           string
+		Data              []byte
+		ExpectedMockCalls func(*mocks.DirectoryRepositoryMock, *mocks.RoleRepositoryMock)
+		ExpectedErr       string
+	}{
+		{
+			Name:    "Created employee is listed",
+			Channel: employee.EventChannel,
+			Data: eventData(entities.NewEmployeeEvent, employee.EventChannel, johnID.Hex(), 1, &pb.Employee{
+				Id:    johnID.Hex(),
+				Email: "john@page.com",
+				Name:  "John",
+				Team:  "field",
+				Roles: []*pb.Role{{Id: adminRole.ID.Hex(), Name: "admin"}},
+			}),
+			ExpectedMockCalls: func(d *mocks.DirectoryRepositoryMock, r *mocks.RoleRepositoryMock) {
+				d.On("Get", mock.Anything, johnID).Return((*entities.DirectoryEntry)(nil), directory.ErrEntryNotFound)
+				d.On("Save", mock.Anything, &entities.DirectoryEntry{
+					ID:        johnID,
+					Email:     "john@page.com",
+					Name:      "John",
+					Team:      "field",
+					Status:    entities.ActiveEmployee,
+					Roles:     []entities.Role{adminRole},
+					CreatedAt: &createdAt,
+					Sequence:  1,
+				}).Return(nil)
+			},
+		},
+		{
+			Name:    "Updated employee gets names of new roles",
+			Channel: employee.EventChannel,
+			Data: eventData(entities.UpdateEmployeeEvent, employee.EventChannel, johnID.Hex(), 2, &pb.EmployeeChanges{
+				Id: johnID.Hex(),
+				Changes: []*pb.FieldChange{
+					{Path: "team", NewValue: "office"},
+					{Path: "roles", OldValue: "admin", NewValue: "admin,serviceman"},
+					{Path: "password", Redacted: true},
+				},
+			}),
+			ExpectedMockCalls: func(d *mocks.DirectoryRepositoryMock, r *mocks.RoleRepositoryMock) {
+				d.On("Get", mock.Anything, johnID).Return(existingEntry(), nil)
+				r.On("Get", mock.Anything, &pb.RoleFilter{Name: "serviceman"}).Return(&entities.Role{ID: servicemanID, Name: "serviceman"}, nil)
+				d.On("Save", mock.Anything, &entities.DirectoryEntry{
+					ID:        johnID,
+					Email:     "john@page.com",
+					Name:      "John",
+					Team:      "office",
+					Status:    entities.ActiveEmployee,
+					Roles:     []entities.Role{adminRole, {ID: servicemanID, Name: "serviceman"}},
+					UpdatedAt: &createdAt,
+					Sequence:  2,
+				}).Return(nil)
+			},
+		},
+		{
+			Name:    "Already projected event is skipped",
+			Channel: employee.EventChannel,
+			Data:    eventData(entities.DeleteEmployeeEvent, employee.EventChannel, johnID.Hex(), 1, &pb.EmployeeFilter{Id: johnID.Hex()}),
+			ExpectedMockCalls: func(d *mocks.DirectoryRepositoryMock, r *mocks.RoleRepositoryMock) {
+				d.On("Get", mock.Anything, johnID).Return(existingEntry(), nil)
+			},
+		},
+		{
+			Name:    "Deleted employee stays listed as deleted",
+			Channel: employee.EventChannel,
+			Data:    eventData(entities.DeleteEmployeeEvent, employee.EventChannel, johnID.Hex(), 2, &pb.EmployeeFilter{Id: johnID.Hex()}),
+			ExpectedMockCalls: func(d *mocks.DirectoryRepositoryMock, r *mocks.RoleRepositoryMock) {
+				d.On("Get", mock.Anything, johnID).Return(existingEntry(), nil)
+				d.On("Save", mock.Anything, mock.MatchedBy(func(entry *entities.DirectoryEntry) bool {
+					return entry.Status == entities.DeletedEmployee && entry.Sequence == 2
+				})).Return(nil)
+			},
+		},
+		{
+			Name:    "Erased employee loses personal data",
+			Channel: employee.EventChannel,
+			Data:    eventData(entities.EraseEmployeeEvent, employee.EventChannel, johnID.Hex(), 2, &pb.EmployeeFilter{Id: johnID.Hex()}),
+			ExpectedMockCalls: func(d *mocks.DirectoryRepositoryMock, r *mocks.RoleRepositoryMock) {
+				d.On("Get", mock.Anything, johnID).Return(existingEntry(), nil)
+				d.On("Save", mock.Anything, mock.MatchedBy(func(entry *entities.DirectoryEntry) bool {
+					return entry.Email == keystore.Erased && entry.Name == keystore.Erased && entry.Phone == keystore.Erased && entry.Sequence == 2
+				})).Return(nil)
+			},
+		},
+		{
+			Name:    "Update of employee missing in directory",
+			Channel: employee.EventChannel,
+			Data:    eventData(entities.UpdateEmployeeEvent, employee.EventChannel, johnID.Hex(), 2, &pb.EmployeeChanges{Id: johnID.Hex()}),
+			ExpectedMockCalls: func(d *mocks.DirectoryRepositoryMock, r *mocks.RoleRepositoryMock) {
+				d.On("Get", mock.Anything, johnID).Return((*entities.DirectoryEntry)(nil), directory.ErrEntryNotFound)
+			},
+			ExpectedErr: "can't project event e1: employee doesn't exist: 5d3783ee28ae9468bc528907",
+		},
+		{
+			Name:    "Renamed role",
+			Channel: role.EventChannel,
+			Data:    eventData(entities.UpdateRoleEvent, role.EventChannel, adminRole.ID.Hex(), 2, &pb.Role{Id: adminRole.ID.Hex(), Name: "administrator"}),
+			ExpectedMockCalls: func(d *mocks.DirectoryRepositoryMock, r *mocks.RoleRepositoryMock) {
+				d.On("RenameRole", mock.Anything, entities.Role{ID: adminRole.ID, Name: "administrator"}).Return(nil)
+			},
+		},
+		{
+			Name:    "Deleted role",
+			Channel: role.EventChannel,
+			Data:    eventData(entities.DeleteRoleEvent, role.EventChannel, adminRole.ID.Hex(), 3, &pb.RoleFilter{Id: adminRole.ID.Hex()}),
+			ExpectedMockCalls: func(d *mocks.DirectoryRepositoryMock, r *mocks.RoleRepositoryMock) {
+				d.On("RemoveRole", mock.Anything, entities.Role{ID: adminRole.ID}).Return(errors.New("database down"))
+			},
+			ExpectedErr: "can't project event e1: database down",
+		},
+		{
+			Name:              "Created role isn't projected",
+			Channel:           role.EventChannel,
+			Data:              eventData(entities.NewRoleEvent, role.EventChannel, adminRole.ID.Hex(), 1, &pb.Role{Id: adminRole.ID.Hex(), Name: "admin"}),
+			ExpectedMockCalls: func(d *mocks.DirectoryRepositoryMock, r *mocks.RoleRepositoryMock) {},
+		},
+		{
+			Name:              "Poison message",
+			Channel:           employee.EventChannel,
+			Data:              []byte("not an event"),
+			ExpectedMockCalls: func(d *mocks.DirectoryRepositoryMock, r *mocks.RoleRepositoryMock) {},
+			ExpectedErr:       "can't decode event: proto: cannot parse invalid wire-format data",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			directoryRepositoryMock := mocks.DirectoryRepositoryMock{}
+			roleRepositoryMock := mocks.RoleRepositoryMock{}
+
+			tc.ExpectedMockCalls(&directoryRepositoryMock, &roleRepositoryMock)
+
+			projector := NewProjector(zap.NewNop(), &Config{}, nil, &directoryRepositoryMock, &roleRepositoryMock, newEventRegistry(t), nil)
+			err := projector.Project(tc.Channel, tc.Data)
+			helpers.AssertErrors(t, tc.ExpectedErr, err)
+
+			directoryRepositoryMock.AssertExpectations(t)
+			roleRepositoryMock.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandleMessage(t *testing.T) {
+	data := eventData(entities.UpdateRoleEvent, role.EventChannel, adminRole.ID.Hex(), 2, &pb.Role{Id: adminRole.ID.Hex(), Name: "administrator"})
+
+	cases := []struct {
+		Name               string
+		Checkpoint         uint64
+		Failed             uint64
+		Message            *mocks.MessageMock
+		ExpectedMockCalls  func(*mocks.DirectoryRepositoryMock, *mocks.StreamingMock, *mocks.MessageMock)
+		ExpectedCheckpoint uint64
+		ExpectedFailed     uint64
+	}{
+		{
+			Name:    "Projected message advances checkpoint",
+			Message: &mocks.MessageMock{MsgData: data, MsgSequence: 7, MsgDeliveries: 1},
+			ExpectedMockCalls: func(d *mocks.DirectoryRepositoryMock, s *mocks.StreamingMock, m *mocks.MessageMock) {
+				d.On("RenameRole", mock.Anything, mock.Anything).Return(nil)
+				d.On("SetCheckpoint", mock.Anything, role.EventChannel, uint64(7)).Return(nil)
+				m.On("Ack").Return(nil)
+			},
+			ExpectedCheckpoint: 7,
+		},
+		{
+			Name:       "Redelivered message doesn't move checkpoint back",
+			Checkpoint: 9,
+			Message:    &mocks.MessageMock{MsgData: data, MsgSequence: 7, MsgDeliveries: 2},
+			ExpectedMockCalls: func(d *mocks.DirectoryRepositoryMock, s *mocks.StreamingMock, m *mocks.MessageMock) {
+				d.On("RenameRole", mock.Anything, mock.Anything).Return(nil)
+				m.On("Ack").Return(nil)
+			},
+			ExpectedCheckpoint: 9,
+		},
+		{
+			Name:       "Failed message waits for redelivery",
+			Checkpoint: 6,
+			Message:    &mocks.MessageMock{MsgData: data, MsgSequence: 7, MsgDeliveries: 1},
+			ExpectedMockCalls: func(d *mocks.DirectoryRepositoryMock, s *mocks.StreamingMock, m *mocks.MessageMock) {
+				d.On("RenameRole", mock.Anything, mock.Anything).Return(errors.New("database down"))
+				m.On("Nak").Return(nil)
+			},
+			ExpectedCheckpoint: 6,
+			ExpectedFailed:     7,
+		},
+		{
+			Name:       "Checkpoint isn't moved past message waiting for redelivery",
+			Checkpoint: 6,
+			Failed:     7,
+			Message:    &mocks.MessageMock{MsgData: data, MsgSequence: 8, MsgDeliveries: 1},
+			ExpectedMockCalls: func(d *mocks.DirectoryRepositoryMock, s *mocks.StreamingMock, m *mocks.MessageMock) {
+				d.On("RenameRole", mock.Anything, mock.Anything).Return(nil)
+				m.On("Ack").Return(nil)
+			},
+			ExpectedCheckpoint: 6,
+			ExpectedFailed:     7,
+		},
+		{
+			Name:       "Projected redelivery advances checkpoint",
+			Checkpoint: 6,
+			Failed:     7,
+			Message:    &mocks.MessageMock{MsgData: data, MsgSequence: 7, MsgDeliveries: 2},
+			ExpectedMockCalls: func(d *mocks.DirectoryRepositoryMock, s *mocks.StreamingMock, m *mocks.MessageMock) {
+				d.On("RenameRole", mock.Anything, mock.Anything).Return(nil)
+				d.On("SetCheckpoint", mock.Anything, role.EventChannel, uint64(7)).Return(nil)
+				m.On("Ack").Return(nil)
+			},
+			ExpectedCheckpoint: 7,
+		},
+		{
+			Name:       "Failed message at last delivery is dead lettered",
+			Checkpoint: 6,
+			Failed:     7,
+			Message:    &mocks.MessageMock{MsgData: []byte("not an event"), MsgSequence: 7, MsgDeliveries: 3},
+			ExpectedMockCalls: func(d *mocks.DirectoryRepositoryMock, s *mocks.StreamingMock, m *mocks.MessageMock) {
+				s.On("PublishData", "deadletters", "projector-roles-7", mock.MatchedBy(func(data []byte) bool {
+					var deadLetter pb.DeadLetter
+					return proto.Unmarshal(data, &deadLetter) == nil && deadLetter.Subscriber == "projector" && deadLetter.Sequence == 7
+				})).Return(nil)
+				d.On("SetCheckpoint", mock.Anything, role.EventChannel, uint64(7)).Return(nil)
+				m.On("Ack").Return(nil)
+			},
+			ExpectedCheckpoint: 7,
+		},
+		{
+			Name:       "Message which can't be dead lettered waits for redelivery",
+			Checkpoint: 6,
+			Message:    &mocks.MessageMock{MsgData: []byte("not an event"), MsgSequence: 7, MsgDeliveries: 3},
+			ExpectedMockCalls: func(d *mocks.DirectoryRepositoryMock, s *mocks.StreamingMock, m *mocks.MessageMock) {
+				s.On("PublishData", "deadletters", "projector-roles-7", mock.Anything).Return(errors.New("nats down"))
+				m.On("Nak").Return(nil)
+			},
+			ExpectedCheckpoint: 6,
+			ExpectedFailed:     7,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			directoryRepositoryMock := mocks.DirectoryRepositoryMock{}
+			streamingMock := mocks.StreamingMock{}
+			tc.ExpectedMockCalls(&directoryRepositoryMock, &streamingMock, tc.Message)
+
+			config := Config{MaxDeliveries: 3, DeadLetterChannel: "deadletters"}
+			projector := NewProjector(zap.NewNop(), &config, &streamingMock, &directoryRepositoryMock, &mocks.RoleRepositoryMock{}, newEventRegistry(t), nil)
+			projector.checkpoints[role.EventChannel] = tc.Checkpoint
+			if tc.Failed != 0 {
+				projector.failed[role.EventChannel] = tc.Failed
+			}
+			projector.handleMessage(role.EventChannel, tc.Message)
+
+			assert.Equal(t, tc.ExpectedCheckpoint, projector.checkpoints[role.EventChannel])
+			assert.Equal(t, tc.ExpectedFailed, projector.failed[role.EventChannel])
+			directoryRepositoryMock.AssertExpectations(t)
+			streamingMock.AssertExpectations(t)
+			tc.Message.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,171 @@
+package restapi
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/grpc-ecosystem/grpc-gateway/runtime"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/migotom/cell-centre-services/pkg/helpers/correlation"
+	"github.com/migotom/cell-centre-services/pkg/pb"
+)
+
+const (
+	eventsStreamPath         = "/v1/events/stream"
+	defaultHeartbeatInterval = 15 * time.Second
+)
+
+// eventsStreamHandler exposes SubscribeEvents gRPC stream as Server-Sent Events. ID of every sent event is its
+// sequence in channel, reconnecting clients resume subscription after sequence given by Last-Event-ID header.
+type eventsStreamHandler struct {
+	log       *zap.Logger
+	client    pb.EventServiceClient
+	heartbeat time.Duration
+
+	closeOnce sync.Once
+	closing   chan struct{}
+}
+
+func newEventsStreamHandler(log *zap.Logger, client pb.EventServiceClient) *eventsStreamHandler {
+	return &eventsStreamHandler{
+		log:       log,
+		client:    client,
+		heartbeat: defaultHeartbeatInterval,
+		closing:   make(chan struct{}),
+	}
+}
+
+// close ends open streams, clients are expected to reconnect and resume their subscriptions.
+func (handler *eventsStreamHandler) close() {
+	handler.closeOnce.Do(func() {
+		close(handler.closing)
+	})
+}
+
+func (handler *eventsStreamHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	request, err := subscribeEventsRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ctx := metadata.AppendToOutgoingContext(r.Context(), "authorization", r.Header.Get("Authorization"))
+	stream, err := handler.client.SubscribeEvents(ctx, request)
+	if err == nil {
+		var header metadata.MD
+		if header, err = stream.Header(); err == nil && header == nil {
+			// subscription failed before it was established, its error is reported by stream
+			_, err = stream.Recv()
+		}
+	}
+	if err != nil {
+		s := status.Convert(err)
+		http.Error(w, s.Message(), runtime.HTTPStatusFromCode(s.Code()))
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	events := make(chan *pb.StreamedEvent)
+	errs := make(chan error, 1)
+	go func() {
+		for {
+			streamed, err := stream.Recv()
+			if err != nil {
+				errs <- err
+				return
+			}
+			select {
+			case events <- streamed:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	heartbeat := time.NewTicker(handler.heartbeat)
+	defer heartbeat.Stop()
+
+	marshaler := jsonpb.Marshaler{OrigName: true}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-handler.closing:
+			return
+		case err := <-errs:
+			if err != io.EOF && ctx.Err() == nil {
+				correlation.Logger(r.Context(), handler.log).Error("Events stream failed", zap.Error(err))
+				fmt.Fprintf(w, "event: error\ndata: %s\n\n", status.Convert(err).Message())
+				flusher.Flush()
+			}
+			return
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": heartbeat\n\n")
+			flusher.Flush()
+		case streamed := <-events:
+			data, err := marshaler.MarshalToString(streamed.Event)
+			if err != nil {
+				correlation.Logger(r.Context(), handler.log).Error("Can't encode event", zap.Uint64("sequence", streamed.Sequence), zap.Error(err))
+				continue
+			}
+			fmt.Fprintf(w, "id: %d\ndata: %s\n\n", streamed.Sequence, data)
+			flusher.Flush()
+		}
+	}
+}
+
+// subscribeEventsRequest reads subscription filters from query parameters.
+func subscribeEventsRequest(r *http.Request) (*pb.SubscribeEventsRequest, error) {
+	query := r.URL.Query()
+
+	request := pb.SubscribeEventsRequest{
+		Channel:       query.Get("channel"),
+		AggregateType: query.Get("aggregate_type"),
+		AggregateId:   query.Get("aggregate_id"),
+		Types:         query["type"],
+	}
+	if request.Channel == "" {
+		return nil, fmt.Errorf("missing channel")
+	}
+
+	if fromSequence := query.Get("from_sequence"); fromSequence != "" {
+		sequence, err := strconv.ParseUint(fromSequence, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid from_sequence: %v", err)
+		}
+		request.FromSequence = sequence
+	}
+
+	if lastEventID := r.Header.Get("Last-Event-ID"); lastEventID != "" {
+		sequence, err := strconv.ParseUint(lastEventID, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid Last-Event-ID: %v", err)
+		}
+		request.FromSequence = sequence + 1
+	}
+	return &request, nil
+}
@@ -0,0 +1,137 @@
+package restapi
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+	grpc_auth "github.com/grpc-ecosystem/go-grpc-middleware/auth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+
+	"github.com/migotom/cell-centre-services/pkg/components/auth"
+	authDelivery "github.com/migotom/cell-centre-services/pkg/components/auth/delivery/grpc"
+	"github.com/migotom/cell-centre-services/pkg/components/employee"
+	"github.com/migotom/cell-centre-services/pkg/components/event"
+	eventDelivery "github.com/migotom/cell-centre-services/pkg/components/event/delivery/grpc"
+	"github.com/migotom/cell-centre-services/pkg/entities"
+	"github.com/migotom/cell-centre-services/pkg/helpers/mocks"
+	"github.com/migotom/cell-centre-services/pkg/pb"
+)
+
+func runEventService(t *testing.T, log *zap.Logger, streaming event.Streaming) pb.EventServiceClient {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	registry := event.NewRegistry()
+	require.NoError(t, employee.RegisterEvents(registry))
+
+	authenticate := authDelivery.NewAuthenticateDelivery(log, nil)
+	server := grpc.NewServer(grpc.StreamInterceptor(grpc_auth.StreamServerInterceptor(authenticate.DefaultInterceptor)))
+	pb.RegisterEventServiceServer(server, eventDelivery.NewEventDelivery(log, streaming, registry, nil))
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.Dial(listener.Addr().String(), grpc.WithInsecure())
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	return pb.NewEventServiceClient(conn)
+}
+
+func TestEventsStream(t *testing.T) {
+	admin := &entities.Employee{ID: primitive.NewObjectID(), Email: "admin@page.com", Roles: []entities.Role{{Name: "admin"}}}
+	token, err := auth.NewToken(admin)
+	require.NoError(t, err)
+
+	payload, _ := ptypes.MarshalAny(&pb.Employee{Id: admin.ID.Hex(), Email: admin.Email})
+	data, _ := proto.Marshal(&pb.Event{EventId: "1", Channel: "employees", Type: "NewEmployee", AggregateId: admin.ID.Hex(), Payload: payload, SchemaVersion: event.SchemaVersion})
+
+	cases := []struct {
+		Name             string
+		Query            string
+		Headers          map[string]string
+		ExpectedStatus   int
+		ExpectedSequence uint64
+		ExpectedBody     string
+	}{
+		{
+			Name:           "Events streamed",
+			Query:          "channel=employees&type=NewEmployee",
+			Headers:        map[string]string{"Authorization": token},
+			ExpectedStatus: http.StatusOK,
+			ExpectedBody: `id: 12` + "\n" + `data: {"event_id":"1","channel":"employees","type":"NewEmployee","aggregate_id":"` + admin.ID.Hex() + `",` +
+				`"payload":{"@type":"type.googleapis.com/pb.Employee","id":"` + admin.ID.Hex() + `","email":"admin@page.com"},"schema_version":2}`,
+		},
+		{
+			Name:             "Events resumed after last event",
+			Query:            "channel=employees",
+			Headers:          map[string]string{"Authorization": token, "Last-Event-ID": "11"},
+			ExpectedStatus:   http.StatusOK,
+			ExpectedSequence: 12,
+			ExpectedBody:     `id: 12`,
+		},
+		{
+			Name:           "Unauthenticated",
+			Query:          "channel=employees",
+			ExpectedStatus: http.StatusUnauthorized,
+		},
+		{
+			Name:           "Missing channel",
+			Headers:        map[string]string{"Authorization": token},
+			ExpectedStatus: http.StatusBadRequest,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			log, _ := zap.NewProduction()
+			defer log.Sync()
+
+			streamingMock := mocks.StreamingMock{}
+			subscriptionMock := mocks.SubscriptionMock{}
+			streamingMock.On("Subscribe", "employees", mock.Anything, mock.MatchedBy(func(options []event.SubscribeOption) bool {
+				return event.NewSubscribeOptions(options...).StartAtSequence == tc.ExpectedSequence
+			})).Run(func(args mock.Arguments) {
+				go args.Get(1).(event.MessageHandler)(&mocks.MessageMock{MsgData: data, MsgSequence: 12})
+			}).Return(&subscriptionMock, nil).Maybe()
+			subscriptionMock.On("Close").Return(nil).Maybe()
+
+			server := httptest.NewServer(newEventsStreamHandler(log, runEventService(t, log, &streamingMock)))
+			defer server.Close()
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			request, _ := http.NewRequest(http.MethodGet, server.URL+eventsStreamPath+"?"+tc.Query, nil)
+			for name, value := range tc.Headers {
+				request.Header.Set(name, value)
+			}
+			response, err := http.DefaultClient.Do(request.WithContext(ctx))
+			require.NoError(t, err)
+			defer response.Body.Close()
+
+			assert.Equal(t, tc.ExpectedStatus, response.StatusCode)
+			if tc.ExpectedStatus != http.StatusOK {
+				return
+			}
+			assert.Equal(t, "text/event-stream", response.Header.Get("Content-Type"))
+
+			var lines []string
+			scanner := bufio.NewScanner(response.Body)
+			for scanner.Scan() && scanner.Text() != "" {
+				lines = append(lines, scanner.Text())
+			}
+			assert.True(t, strings.HasPrefix(strings.Join(lines, "\n"), tc.ExpectedBody), strings.Join(lines, "\n"))
+		})
+	}
+}
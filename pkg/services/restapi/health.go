@@ -0,0 +1,96 @@
+package restapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	livenessPath            = "/healthz"
+	readinessPath           = "/readyz"
+	defaultReadinessTimeout = 2 * time.Second
+)
+
+// upstream is gRPC endpoint serving REST API calls.
+type upstream struct {
+	name   string
+	client healthpb.HealthClient
+}
+
+// healthReport is response of health endpoints.
+type healthReport struct {
+	Status    string            `json:"status"`
+	Upstreams map[string]string `json:"upstreams,omitempty"`
+}
+
+// livenessHandler reports that REST API is running.
+func livenessHandler(w http.ResponseWriter, r *http.Request) {
+	writeHealthReport(w, http.StatusOK, healthReport{Status: "ok"})
+}
+
+// readinessHandler reports REST API ready once all upstream gRPC endpoints report serving status of grpc.health.v1
+// service.
+type readinessHandler struct {
+	log       *zap.Logger
+	upstreams []upstream
+	timeout   time.Duration
+}
+
+func newReadinessHandler(log *zap.Logger, upstreams ...upstream) *readinessHandler {
+	return &readinessHandler{
+		log:       log,
+		upstreams: upstreams,
+		timeout:   defaultReadinessTimeout,
+	}
+}
+
+func (handler *readinessHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), handler.timeout)
+	defer cancel()
+
+	statuses := make([]string, len(handler.upstreams))
+	var wg sync.WaitGroup
+	for i := range handler.upstreams {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			statuses[i] = handler.check(ctx, handler.upstreams[i])
+		}(i)
+	}
+	wg.Wait()
+
+	report := healthReport{Status: "ready", Upstreams: make(map[string]string, len(statuses))}
+	code := http.StatusOK
+	for i, upstream := range handler.upstreams {
+		report.Upstreams[upstream.name] = statuses[i]
+		if statuses[i] != healthpb.HealthCheckResponse_SERVING.String() {
+			report.Status = "not ready"
+			code = http.StatusServiceUnavailable
+		}
+	}
+	writeHealthReport(w, code, report)
+}
+
+// check returns health status of upstream, or reason why it can't be checked.
+func (handler *readinessHandler) check(ctx context.Context, upstream upstream) string {
+	response, err := upstream.client.Check(ctx, &healthpb.HealthCheckRequest{})
+	if err != nil {
+		handler.log.Warn("Can't check health of upstream", zap.String("upstream", upstream.name), zap.Error(err))
+		return status.Convert(err).Code().String()
+	}
+	return response.GetStatus().String()
+}
+
+func writeHealthReport(w http.ResponseWriter, code int, report healthReport) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(report)
+}
@@ -0,0 +1,106 @@
+package restapi
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	grpcHealth "google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func runHealthService(t *testing.T, status healthpb.HealthCheckResponse_ServingStatus) healthpb.HealthClient {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	health := grpcHealth.NewServer()
+	health.SetServingStatus("", status)
+
+	server := grpc.NewServer()
+	healthpb.RegisterHealthServer(server, health)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.Dial(listener.Addr().String(), grpc.WithInsecure())
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	return healthpb.NewHealthClient(conn)
+}
+
+func TestReadiness(t *testing.T) {
+	cases := []struct {
+		Name           string
+		Upstreams      func(t *testing.T) []upstream
+		ExpectedStatus int
+		ExpectedReport healthReport
+	}{
+		{
+			Name: "All upstreams serving",
+			Upstreams: func(t *testing.T) []upstream {
+				return []upstream{
+					{name: "eventstore", client: runHealthService(t, healthpb.HealthCheckResponse_SERVING)},
+					{name: "authenticator", client: runHealthService(t, healthpb.HealthCheckResponse_SERVING)},
+				}
+			},
+			ExpectedStatus: http.StatusOK,
+			ExpectedReport: healthReport{Status: "ready", Upstreams: map[string]string{"eventstore": "SERVING", "authenticator": "SERVING"}},
+		},
+		{
+			Name: "Upstream not serving",
+			Upstreams: func(t *testing.T) []upstream {
+				return []upstream{
+					{name: "eventstore", client: runHealthService(t, healthpb.HealthCheckResponse_NOT_SERVING)},
+					{name: "authenticator", client: runHealthService(t, healthpb.HealthCheckResponse_SERVING)},
+				}
+			},
+			ExpectedStatus: http.StatusServiceUnavailable,
+			ExpectedReport: healthReport{Status: "not ready", Upstreams: map[string]string{"eventstore": "NOT_SERVING", "authenticator": "SERVING"}},
+		},
+		{
+			Name: "Upstream without health service",
+			Upstreams: func(t *testing.T) []upstream {
+				listener, err := net.Listen("tcp", "127.0.0.1:0")
+				require.NoError(t, err)
+				server := grpc.NewServer()
+				go server.Serve(listener)
+				t.Cleanup(server.Stop)
+
+				conn, err := grpc.Dial(listener.Addr().String(), grpc.WithInsecure())
+				require.NoError(t, err)
+				t.Cleanup(func() { conn.Close() })
+
+				return []upstream{{name: "webhooks", client: healthpb.NewHealthClient(conn)}}
+			},
+			ExpectedStatus: http.StatusServiceUnavailable,
+			ExpectedReport: healthReport{Status: "not ready", Upstreams: map[string]string{"webhooks": "Unimplemented"}},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			handler := newReadinessHandler(zap.NewNop(), tc.Upstreams(t)...)
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, readinessPath, nil))
+
+			var report healthReport
+			require.NoError(t, json.NewDecoder(recorder.Body).Decode(&report))
+			assert.Equal(t, tc.ExpectedStatus, recorder.Code)
+			assert.Equal(t, tc.ExpectedReport, report)
+		})
+	}
+}
+
+func TestLiveness(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	livenessHandler(recorder, httptest.NewRequest(http.MethodGet, livenessPath, nil))
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.JSONEq(t, `{"status":"ok"}`, recorder.Body.String())
+}
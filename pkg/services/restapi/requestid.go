@@ -0,0 +1,34 @@
+package restapi
+
+import (
+	"context"
+	"net/http"
+
+	grpc_zap "github.com/grpc-ecosystem/go-grpc-middleware/logging/zap"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+
+	"github.com/migotom/cell-centre-services/pkg/helpers/correlation"
+)
+
+// requestIDHandler accepts ID of request given by caller in X-Request-ID header or generates new one,
+// returns it in response headers and passes it in context of request to upstream gRPC calls.
+func requestIDHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID := r.Header.Get(correlation.RequestIDHeader)
+		if !correlation.ValidRequestID(requestID) {
+			requestID = correlation.NewRequestID()
+		}
+		w.Header().Set(correlation.RequestIDHeader, requestID)
+
+		next.ServeHTTP(w, r.WithContext(correlation.NewContext(r.Context(), requestID, "")))
+	})
+}
+
+// correlatedLoggingInterceptor logs gRPC client calls with IDs of request which made them.
+func correlatedLoggingInterceptor(log *zap.Logger, opts ...grpc_zap.Option) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, callOpts ...grpc.CallOption) error {
+		interceptor := grpc_zap.UnaryClientInterceptor(correlation.Logger(ctx, log), opts...)
+		return interceptor(ctx, method, req, reply, cc, invoker, callOpts...)
+	}
+}
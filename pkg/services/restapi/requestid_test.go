@@ -0,0 +1,56 @@
+package restapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/migotom/cell-centre-services/pkg/helpers/correlation"
+)
+
+func TestRequestIDHandler(t *testing.T) {
+	cases := []struct {
+		Name              string
+		RequestID         string
+		ExpectedRequestID string
+	}{
+		{
+			Name:              "Request ID given by caller",
+			RequestID:         "request-1",
+			ExpectedRequestID: "request-1",
+		},
+		{
+			Name: "Missing request ID",
+		},
+		{
+			Name:      "Invalid request ID",
+			RequestID: "request 1",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			var correlationID string
+			handler := requestIDHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				correlationID, _ = correlation.FromContext(r.Context())
+			}))
+
+			request := httptest.NewRequest(http.MethodGet, "/v1/employee", nil)
+			if tc.RequestID != "" {
+				request.Header.Set(correlation.RequestIDHeader, tc.RequestID)
+			}
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, request)
+
+			requestID := recorder.Header().Get(correlation.RequestIDHeader)
+			if tc.ExpectedRequestID != "" {
+				assert.Equal(t, tc.ExpectedRequestID, requestID)
+			} else {
+				assert.True(t, correlation.ValidRequestID(requestID))
+				assert.NotEqual(t, tc.RequestID, requestID)
+			}
+			assert.Equal(t, requestID, correlationID)
+		})
+	}
+}
@@ -2,6 +2,7 @@ package restapi
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"time"
 
@@ -12,7 +13,12 @@ import (
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 	"google.golang.org/grpc"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 
+	"github.com/migotom/cell-centre-services/pkg/helpers"
+	"github.com/migotom/cell-centre-services/pkg/helpers/correlation"
+	"github.com/migotom/cell-centre-services/pkg/helpers/metrics"
+	"github.com/migotom/cell-centre-services/pkg/helpers/tracing"
 	gw "github.com/migotom/cell-centre-services/pkg/pb"
 )
 
@@ -30,15 +36,14 @@ func NewRESTAPI(log *zap.Logger, config *Config) *RESTAPI {
 	}
 }
 
-// Listen to REST API calls.
-func (restAPI *RESTAPI) Listen() {
-	ctx := context.Background()
+// Listen to REST API calls until given context is done, pending requests are finished before return.
+func (restAPI *RESTAPI) Listen(ctx context.Context) {
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
 	grpc_zap.ReplaceGrpcLogger(restAPI.log)
 
-	mux := runtime.NewServeMux()
+	gwMux := runtime.NewServeMux()
 	grpcOpts := []grpc_retry.CallOption{
 		grpc_retry.WithBackoff(grpc_retry.BackoffLinear(100 * time.Millisecond)),
 	}
@@ -49,27 +54,83 @@ func (restAPI *RESTAPI) Listen() {
 	}
 
 	opts := []grpc.DialOption{
-		grpc.WithInsecure(), grpc.WithUnaryInterceptor(grpc_middleware.ChainUnaryClient(
+		grpc.WithInsecure(), tracing.DialOption(), grpc.WithUnaryInterceptor(grpc_middleware.ChainUnaryClient(
+			metrics.UnaryClientInterceptor(),
+			correlation.UnaryClientInterceptor(),
 			grpc_retry.UnaryClientInterceptor(grpcOpts...),
-			grpc_zap.UnaryClientInterceptor(restAPI.log, zapOpts...),
+			correlatedLoggingInterceptor(restAPI.log, zapOpts...),
+		)),
+		grpc.WithStreamInterceptor(grpc_middleware.ChainStreamClient(
+			metrics.StreamClientInterceptor(),
+			correlation.StreamClientInterceptor(),
 		)),
 	}
 
 	var err error
-	err = gw.RegisterEmployeeServiceHandlerFromEndpoint(ctx, mux, restAPI.config.EndpointEventStoreURL, opts)
+	err = gw.RegisterEmployeeServiceHandlerFromEndpoint(ctx, gwMux, restAPI.config.EndpointEventStoreURL, opts)
 	if err != nil {
 		restAPI.log.Error("Unable to register employee service handler", zap.Error(err))
 		return
 	}
 
-	err = gw.RegisterAuthServiceHandlerFromEndpoint(ctx, mux, restAPI.config.EndpointAuthenticatorURL, opts)
+	err = gw.RegisterEmployeeExportServiceHandlerFromEndpoint(ctx, gwMux, restAPI.config.EndpointEventStoreURL, opts)
+	if err != nil {
+		restAPI.log.Error("Unable to register employee export service handler", zap.Error(err))
+		return
+	}
+
+	err = gw.RegisterAuthServiceHandlerFromEndpoint(ctx, gwMux, restAPI.config.EndpointAuthenticatorURL, opts)
 	if err != nil {
 		restAPI.log.Error("Unable to register authenticator service handler", zap.Error(err))
 		return
 	}
 
-	err = http.ListenAndServe(restAPI.config.ListenAddress, mux)
+	err = gw.RegisterWebhookServiceHandlerFromEndpoint(ctx, gwMux, restAPI.config.EndpointWebhooksURL, opts)
+	if err != nil {
+		restAPI.log.Error("Unable to register webhook service handler", zap.Error(err))
+		return
+	}
+
+	eventStoreConn, err := grpc.DialContext(ctx, restAPI.config.EndpointEventStoreURL, opts...)
 	if err != nil {
+		restAPI.log.Error("Unable to connect event store", zap.Error(err))
+		return
+	}
+	defer eventStoreConn.Close()
+
+	eventsStream := newEventsStreamHandler(restAPI.log, gw.NewEventServiceClient(eventStoreConn))
+
+	// health of upstreams is checked without retries and logging of every call
+	var upstreams []upstream
+	for name, endpoint := range map[string]string{
+		"eventstore":    restAPI.config.EndpointEventStoreURL,
+		"authenticator": restAPI.config.EndpointAuthenticatorURL,
+		"webhooks":      restAPI.config.EndpointWebhooksURL,
+	} {
+		conn, err := grpc.DialContext(ctx, endpoint, grpc.WithInsecure())
+		if err != nil {
+			restAPI.log.Error("Unable to connect upstream", zap.String("upstream", name), zap.Error(err))
+			return
+		}
+		defer conn.Close()
+		upstreams = append(upstreams, upstream{name: name, client: healthpb.NewHealthClient(conn)})
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(eventsStreamPath, eventsStream)
+	mux.HandleFunc(livenessPath, livenessHandler)
+	mux.Handle(readinessPath, newReadinessHandler(restAPI.log, upstreams...))
+	mux.Handle("/", tracing.Handler(gwMux, "grpc-gateway"))
+
+	server := &http.Server{
+		Addr:    restAPI.config.ListenAddress,
+		Handler: requestIDHandler(mux),
+	}
+	// open events streams would hold shutdown until timeout
+	server.RegisterOnShutdown(eventsStream.close)
+
+	err = helpers.ServeHTTP(ctx, server, restAPI.config.ShutdownTimeout.Duration)
+	if err != nil && err != http.ErrServerClosed {
 		restAPI.log.Error("Can't listen", zap.Error(err))
 		return
 	}
@@ -80,4 +141,28 @@ type Config struct {
 	ListenAddress            string `toml:"listen_address"`
 	EndpointEventStoreURL    string `toml:"endpoint_event_store_url"`
 	EndpointAuthenticatorURL string `toml:"endpoint_authenticator_url"`
+	EndpointWebhooksURL      string `toml:"endpoint_webhooks_url"`
+	MetricsAddress           string `toml:"metrics_address"`
+
+	ShutdownTimeout helpers.Duration `toml:"shutdown_timeout"`
+	Tracing         tracing.Config   `toml:"tracing"`
+}
+
+// SetDefaults sets default values of not configured options.
+func (config *Config) SetDefaults() {
+	if config.ShutdownTimeout.Duration == 0 {
+		config.ShutdownTimeout.Duration = helpers.DefaultShutdownTimeout
+	}
+	config.Tracing.SetDefaults()
+}
+
+// Validate returns errors of missing or invalid options.
+func (config *Config) Validate() error {
+	return errors.Join(
+		helpers.Required("listen_address", config.ListenAddress),
+		helpers.Required("endpoint_event_store_url", config.EndpointEventStoreURL),
+		helpers.Required("endpoint_authenticator_url", config.EndpointAuthenticatorURL),
+		helpers.Required("endpoint_webhooks_url", config.EndpointWebhooksURL),
+		config.Tracing.Validate(),
+	)
 }
@@ -0,0 +1,70 @@
+package webhooks
+
+import (
+	"sync"
+	"time"
+)
+
+// circuitBreaker stops deliveries to endpoint after threshold of consecutive failures. Once cooldown passes
+// a single trial delivery is allowed, its success closes the breaker and failure keeps it open for another cooldown.
+type circuitBreaker struct {
+	sync.Mutex
+
+	threshold int
+	cooldown  time.Duration
+	now       func() time.Time
+
+	failures int
+	openedAt time.Time
+}
+
+func newCircuitBreaker(threshold int, cooldown time.Duration) *circuitBreaker {
+	return &circuitBreaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+		now:       time.Now,
+	}
+}
+
+// Allow reports whether delivery to endpoint may be attempted.
+func (breaker *circuitBreaker) Allow() bool {
+	breaker.Lock()
+	defer breaker.Unlock()
+
+	if breaker.failures < breaker.threshold {
+		return true
+	}
+	if breaker.now().Sub(breaker.openedAt) < breaker.cooldown {
+		return false
+	}
+	// half open, further deliveries wait for result of trial one
+	breaker.openedAt = breaker.now()
+	return true
+}
+
+// Open reports whether breaker stops deliveries, unlike Allow it doesn't start trial delivery.
+func (breaker *circuitBreaker) Open() bool {
+	breaker.Lock()
+	defer breaker.Unlock()
+
+	return breaker.failures >= breaker.threshold && breaker.now().Sub(breaker.openedAt) < breaker.cooldown
+}
+
+// Success of delivery closes breaker.
+func (breaker *circuitBreaker) Success() {
+	breaker.Lock()
+	defer breaker.Unlock()
+
+	breaker.failures = 0
+}
+
+// Failure of delivery opens breaker once threshold is reached.
+func (breaker *circuitBreaker) Failure() {
+	breaker.Lock()
+	defer breaker.Unlock()
+
+	breaker.failures++
+	if breaker.failures >= breaker.threshold {
+		breaker.openedAt = breaker.now()
+	}
+}
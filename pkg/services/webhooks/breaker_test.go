@@ -0,0 +1,38 @@
+package webhooks
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	now := time.Now()
+	breaker := newCircuitBreaker(2, time.Minute)
+	breaker.now = func() time.Time { return now }
+
+	breaker.Failure()
+	assert.True(t, breaker.Allow(), "closed below threshold")
+
+	breaker.Failure()
+	assert.True(t, breaker.Open())
+	assert.False(t, breaker.Allow(), "open once threshold is reached")
+
+	now = now.Add(time.Minute)
+	assert.False(t, breaker.Open(), "cooldown passed")
+	assert.True(t, breaker.Allow(), "trial delivery after cooldown")
+	assert.False(t, breaker.Allow(), "single trial delivery")
+
+	breaker.Failure()
+	now = now.Add(30 * time.Second)
+	assert.False(t, breaker.Allow(), "failed trial keeps breaker open")
+
+	now = now.Add(30 * time.Second)
+	assert.True(t, breaker.Allow(), "trial delivery after another cooldown")
+
+	breaker.Success()
+	assert.False(t, breaker.Open())
+	assert.True(t, breaker.Allow(), "closed after successful trial")
+	assert.True(t, breaker.Allow())
+}
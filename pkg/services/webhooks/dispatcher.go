@@ -0,0 +1,197 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/migotom/cell-centre-services/pkg/components/webhook"
+	"github.com/migotom/cell-centre-services/pkg/entities"
+)
+
+const errCircuitOpen = "circuit breaker open"
+
+// Dispatcher delivers events to webhooks with retries and per endpoint circuit breaking,
+// every attempt is stored in delivery log.
+type Dispatcher struct {
+	log               *zap.Logger
+	config            *Config
+	client            *http.Client
+	attemptRepository webhook.AttemptRepository
+
+	mu       sync.Mutex
+	breakers map[string]*circuitBreaker
+}
+
+// NewDispatcher returns new webhooks dispatcher.
+func NewDispatcher(log *zap.Logger, config *Config, attemptRepository webhook.AttemptRepository) *Dispatcher {
+	return &Dispatcher{
+		log:               log,
+		config:            config,
+		client:            &http.Client{Timeout: config.RequestTimeout.Duration},
+		attemptRepository: attemptRepository,
+		breakers:          make(map[string]*circuitBreaker),
+	}
+}
+
+// Delivery of event to webhook.
+type Delivery struct {
+	Webhook   *entities.Webhook
+	EventID   string
+	EventType string
+	// Header of encoded event, sent along with headers of webhook
+	Header http.Header
+	Body   []byte
+}
+
+// Result of dispatch.
+type Result int
+
+const (
+	// Delivered event was accepted by webhook.
+	Delivered Result = iota
+	// Rejected event was refused by reachable webhook, redelivery won't help.
+	Rejected
+	// Failed delivery exhausted its attempts, was skipped by open circuit breaker or interrupted.
+	Failed
+)
+
+// Dispatch delivers event to webhook, failed attempts are retried with exponential backoff until limit of attempts
+// is reached.
+func (dispatcher *Dispatcher) Dispatch(ctx context.Context, delivery Delivery) Result {
+	log := dispatcher.log.With(
+		zap.String("webhookID", delivery.Webhook.ID.Hex()),
+		zap.String("url", delivery.Webhook.URL),
+		zap.String("eventID", delivery.EventID),
+	)
+	breaker := dispatcher.breaker(delivery.Webhook.URL)
+
+	for attempt := 1; attempt <= dispatcher.config.MaxAttempts; attempt++ {
+		record := entities.WebhookAttempt{
+			WebhookID: delivery.Webhook.ID,
+			EventID:   delivery.EventID,
+			EventType: delivery.EventType,
+			Attempt:   attempt,
+			CreatedAt: time.Now(),
+		}
+
+		if !breaker.Allow() {
+			record.Error = errCircuitOpen
+			dispatcher.record(log, &record)
+			log.Warn("Webhook delivery skipped, circuit breaker open", zap.Int("attempt", attempt))
+			return Failed
+		}
+
+		statusCode, err := dispatcher.send(ctx, delivery)
+		record.StatusCode = statusCode
+		record.Duration = time.Since(record.CreatedAt)
+		if err == nil {
+			record.Delivered = true
+			dispatcher.record(log, &record)
+			breaker.Success()
+			return Delivered
+		}
+		record.Error = err.Error()
+		dispatcher.record(log, &record)
+
+		if permanentFailure(statusCode) {
+			// endpoint is reachable but rejects event, retries won't help
+			log.Error("Webhook rejected event", zap.Int("statusCode", statusCode))
+			return Rejected
+		}
+		breaker.Failure()
+
+		log.Warn("Webhook delivery failed", zap.Int("attempt", attempt), zap.Error(err))
+		if attempt == dispatcher.config.MaxAttempts {
+			break
+		}
+
+		select {
+		case <-time.After(dispatcher.backoff(attempt)):
+		case <-ctx.Done():
+			return Failed
+		}
+	}
+
+	log.Error("Webhook delivery failed, attempts exhausted", zap.Int("attempts", dispatcher.config.MaxAttempts))
+	return Failed
+}
+
+// Available reports whether circuit breaker of endpoint lets deliveries through.
+func (dispatcher *Dispatcher) Available(endpoint string) bool {
+	return !dispatcher.breaker(endpoint).Open()
+}
+
+// send posts event to webhook, signed along with its CloudEvents attribute headers, and returns status code of response.
+func (dispatcher *Dispatcher) send(ctx context.Context, delivery Delivery) (int, error) {
+	request, err := http.NewRequest(http.MethodPost, delivery.Webhook.URL, bytes.NewReader(delivery.Body))
+	if err != nil {
+		return 0, err
+	}
+	request = request.WithContext(ctx)
+	for name, values := range delivery.Header {
+		request.Header[name] = values
+	}
+	if request.Header.Get("Content-Type") == "" {
+		request.Header.Set("Content-Type", "application/json")
+	}
+	request.Header.Set(webhook.SignatureHeader, webhook.Sign(delivery.Webhook.Secret, delivery.Header, delivery.Body))
+	request.Header.Set(webhook.EventIDHeader, delivery.EventID)
+	request.Header.Set(webhook.EventTypeHeader, delivery.EventType)
+
+	response, err := dispatcher.client.Do(request)
+	if err != nil {
+		return 0, err
+	}
+	defer response.Body.Close()
+	io.Copy(ioutil.Discard, response.Body)
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return response.StatusCode, fmt.Errorf("unexpected status %s", response.Status)
+	}
+	return response.StatusCode, nil
+}
+
+// backoff returns delay before next attempt, doubled with every attempt up to configured maximum.
+func (dispatcher *Dispatcher) backoff(attempt int) time.Duration {
+	delay := dispatcher.config.InitialBackoff.Duration
+	for i := 1; i < attempt && delay < dispatcher.config.MaxBackoff.Duration; i++ {
+		delay *= 2
+	}
+	if delay > dispatcher.config.MaxBackoff.Duration {
+		delay = dispatcher.config.MaxBackoff.Duration
+	}
+	return delay
+}
+
+// breaker returns circuit breaker of endpoint.
+func (dispatcher *Dispatcher) breaker(endpoint string) *circuitBreaker {
+	dispatcher.mu.Lock()
+	defer dispatcher.mu.Unlock()
+
+	breaker, ok := dispatcher.breakers[endpoint]
+	if !ok {
+		breaker = newCircuitBreaker(dispatcher.config.BreakerThreshold, dispatcher.config.BreakerCooldown.Duration)
+		dispatcher.breakers[endpoint] = breaker
+	}
+	return breaker
+}
+
+func (dispatcher *Dispatcher) record(log *zap.Logger, attempt *entities.WebhookAttempt) {
+	if err := dispatcher.attemptRepository.New(context.Background(), attempt); err != nil {
+		log.Error("Can't store webhook attempt", zap.Error(err))
+	}
+}
+
+// permanentFailure reports whether status code rejects request regardless of retries.
+func permanentFailure(statusCode int) bool {
+	return statusCode >= 400 && statusCode < 500 &&
+		statusCode != http.StatusRequestTimeout && statusCode != http.StatusTooManyRequests
+}
@@ -0,0 +1,139 @@
+package webhooks
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/zap"
+
+	"github.com/migotom/cell-centre-services/pkg/components/webhook"
+	"github.com/migotom/cell-centre-services/pkg/entities"
+	"github.com/migotom/cell-centre-services/pkg/helpers"
+	"github.com/migotom/cell-centre-services/pkg/helpers/mocks"
+)
+
+func testConfig() *Config {
+	config := Config{
+		MaxAttempts:      3,
+		InitialBackoff:   helpers.Duration{Duration: time.Millisecond},
+		MaxBackoff:       helpers.Duration{Duration: 2 * time.Millisecond},
+		BreakerThreshold: 2,
+	}
+	config.SetDefaults()
+	return &config
+}
+
+func attempted(delivered bool, statusCode int, errMsg string) interface{} {
+	return mock.MatchedBy(func(attempt *entities.WebhookAttempt) bool {
+		return attempt.Delivered == delivered && attempt.StatusCode == statusCode && attempt.Error == errMsg
+	})
+}
+
+func TestDispatch(t *testing.T) {
+	cases := []struct {
+		Name              string
+		Responses         []int
+		ExpectedMockCalls func(*mocks.WebhookAttemptRepositoryMock)
+		ExpectedResult    Result
+		ExpectedRequests  int
+	}{
+		{
+			Name:      "Delivered at first attempt",
+			Responses: []int{http.StatusOK},
+			ExpectedMockCalls: func(r *mocks.WebhookAttemptRepositoryMock) {
+				r.On("New", mock.Anything, attempted(true, http.StatusOK, "")).Return(nil).Once()
+			},
+			ExpectedResult:   Delivered,
+			ExpectedRequests: 1,
+		},
+		{
+			Name:      "Delivered after retry",
+			Responses: []int{http.StatusServiceUnavailable, http.StatusNoContent},
+			ExpectedMockCalls: func(r *mocks.WebhookAttemptRepositoryMock) {
+				r.On("New", mock.Anything, attempted(false, http.StatusServiceUnavailable, "unexpected status 503 Service Unavailable")).Return(nil).Once()
+				r.On("New", mock.Anything, attempted(true, http.StatusNoContent, "")).Return(nil).Once()
+			},
+			ExpectedResult:   Delivered,
+			ExpectedRequests: 2,
+		},
+		{
+			Name:      "Rejected event is not retried",
+			Responses: []int{http.StatusBadRequest},
+			ExpectedMockCalls: func(r *mocks.WebhookAttemptRepositoryMock) {
+				r.On("New", mock.Anything, attempted(false, http.StatusBadRequest, "unexpected status 400 Bad Request")).Return(nil).Once()
+			},
+			ExpectedResult:   Rejected,
+			ExpectedRequests: 1,
+		},
+		{
+			Name:      "Circuit breaker opens after consecutive failures",
+			Responses: []int{http.StatusInternalServerError, http.StatusInternalServerError, http.StatusOK},
+			ExpectedMockCalls: func(r *mocks.WebhookAttemptRepositoryMock) {
+				r.On("New", mock.Anything, attempted(false, http.StatusInternalServerError, "unexpected status 500 Internal Server Error")).Return(nil).Twice()
+				r.On("New", mock.Anything, attempted(false, 0, errCircuitOpen)).Return(nil).Once()
+			},
+			ExpectedResult:   Failed,
+			ExpectedRequests: 2,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			log, _ := zap.NewProduction()
+			defer log.Sync()
+
+			secret := "secret"
+			body := []byte(`{"event_id":"1","type":"NewEmployee"}`)
+
+			var requests int
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				received, _ := ioutil.ReadAll(r.Body)
+				assert.Equal(t, body, received)
+				assert.Equal(t, "employee/2", r.Header.Get("Ce-Subject"))
+				assert.True(t, webhook.VerifySignature(secret, r.Header, received, r.Header.Get(webhook.SignatureHeader)))
+				assert.Equal(t, "1", r.Header.Get(webhook.EventIDHeader))
+				assert.Equal(t, "NewEmployee", r.Header.Get(webhook.EventTypeHeader))
+
+				w.WriteHeader(tc.Responses[requests])
+				requests++
+			}))
+			defer server.Close()
+
+			attemptRepositoryMock := mocks.WebhookAttemptRepositoryMock{}
+			tc.ExpectedMockCalls(&attemptRepositoryMock)
+
+			dispatcher := NewDispatcher(log, testConfig(), &attemptRepositoryMock)
+			result := dispatcher.Dispatch(context.Background(), Delivery{
+				Webhook:   &entities.Webhook{ID: primitive.NewObjectID(), URL: server.URL, Secret: secret},
+				EventID:   "1",
+				EventType: "NewEmployee",
+				Header:    http.Header{"Ce-Subject": {"employee/2"}},
+				Body:      body,
+			})
+
+			assert.Equal(t, tc.ExpectedResult, result)
+			assert.Equal(t, tc.ExpectedRequests, requests)
+			attemptRepositoryMock.AssertExpectations(t)
+		})
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	config := Config{
+		InitialBackoff: helpers.Duration{Duration: time.Second},
+		MaxBackoff:     helpers.Duration{Duration: 5 * time.Second},
+	}
+	dispatcher := NewDispatcher(nil, &config, nil)
+
+	var delays []time.Duration
+	for attempt := 1; attempt <= 5; attempt++ {
+		delays = append(delays, dispatcher.backoff(attempt))
+	}
+	assert.Equal(t, []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second}, delays)
+}
@@ -0,0 +1,470 @@
+package webhooks
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/testdata"
+
+	authDelivery "github.com/migotom/cell-centre-services/pkg/components/auth/delivery/grpc"
+	"github.com/migotom/cell-centre-services/pkg/components/event"
+	"github.com/migotom/cell-centre-services/pkg/components/event/encoding"
+	"github.com/migotom/cell-centre-services/pkg/components/event/streaming"
+	"github.com/migotom/cell-centre-services/pkg/components/health"
+	healthDelivery "github.com/migotom/cell-centre-services/pkg/components/health/delivery/grpc"
+	"github.com/migotom/cell-centre-services/pkg/components/webhook"
+	webhookDelivery "github.com/migotom/cell-centre-services/pkg/components/webhook/delivery/grpc"
+	"github.com/migotom/cell-centre-services/pkg/entities"
+	"github.com/migotom/cell-centre-services/pkg/helpers"
+	"github.com/migotom/cell-centre-services/pkg/helpers/interceptors"
+	"github.com/migotom/cell-centre-services/pkg/helpers/metrics"
+	"github.com/migotom/cell-centre-services/pkg/helpers/tracing"
+	"github.com/migotom/cell-centre-services/pkg/pb"
+)
+
+const (
+	defaultAckWait            = 5 * time.Minute
+	defaultRequestTimeout     = 10 * time.Second
+	defaultMaxAttempts        = 5
+	defaultInitialBackoff     = time.Second
+	defaultMaxBackoff         = time.Minute
+	defaultBreakerThreshold   = 5
+	defaultBreakerCooldown    = time.Minute
+	defaultRedispatchInterval = time.Minute
+)
+
+// Webhooks defines service delivering events of subscribed channels to registered webhooks,
+// with gRPC handler managing webhooks.
+type Webhooks struct {
+	log               *zap.Logger
+	config            *Config
+	eventsStreaming   event.Streaming
+	webhookRepository webhook.Repository
+	parkingRepository webhook.ParkingRepository
+	dispatcher        *Dispatcher
+	authDelivery      *authDelivery.AuthenticateDelivery
+	webhookDelivery   *webhookDelivery.WebhookDelivery
+	payloadCipher     event.PayloadCipher
+	healthDelivery    *healthDelivery.HealthDelivery
+
+	// messages being delivered and parked deliveries being redispatched
+	handling sync.WaitGroup
+}
+
+// NewWebhooks returns new webhooks service, personal data of events is decrypted by given cipher before delivery.
+// Nil cipher delivers events as they were published. Failed deliveries are kept by parking repository until
+// their webhooks are available. Given health delivery is served as grpc.health.v1 service,
+// health isn't served without it.
+func NewWebhooks(
+	log *zap.Logger,
+	config *Config,
+	eventsStreaming event.Streaming,
+	authDelivery *authDelivery.AuthenticateDelivery,
+	webhookRepository webhook.Repository,
+	attemptRepository webhook.AttemptRepository,
+	parkingRepository webhook.ParkingRepository,
+	payloadCipher event.PayloadCipher,
+	healthDelivery *healthDelivery.HealthDelivery,
+) *Webhooks {
+	return &Webhooks{
+		log:               log,
+		config:            config,
+		eventsStreaming:   eventsStreaming,
+		webhookRepository: webhookRepository,
+		parkingRepository: parkingRepository,
+		dispatcher:        NewDispatcher(log, config, attemptRepository),
+		authDelivery:      authDelivery,
+		webhookDelivery:   webhookDelivery.NewWebhookDelivery(log, webhookRepository, attemptRepository),
+		payloadCipher:     payloadCipher,
+		healthDelivery:    healthDelivery,
+	}
+}
+
+// Listen for events to deliver and gRPC calls until given context is done. Pending gRPC calls and deliveries in
+// progress are finished before return, unacknowledged events are redelivered after restart.
+func (webhooks *Webhooks) Listen(ctx context.Context) {
+	var subscriptions []event.Subscription
+	for _, channel := range webhooks.config.Subscribes {
+		channel := channel
+		queueGroup := channel + "-webhooks-group"
+		durableName := "webhooks-" + webhooks.config.NATSClientID + "-durable"
+
+		subscription, err := webhooks.eventsStreaming.Subscribe(channel, func(msg event.Message) {
+			webhooks.handling.Add(1)
+			defer webhooks.handling.Done()
+			webhooks.handleMessage(channel, msg)
+		}, event.QueueGroup(queueGroup), event.DurableName(durableName),
+			event.ManualAck(), event.AckWait(webhooks.config.AckWait.Duration),
+		)
+		if err != nil {
+			webhooks.log.Fatal("Failed to subscribe events", zap.String("channel", channel), zap.Error(err))
+		}
+		subscriptions = append(subscriptions, subscription)
+	}
+
+	webhooks.handling.Add(1)
+	go func() {
+		defer webhooks.handling.Done()
+		webhooks.redispatchParked(ctx)
+	}()
+
+	listener, err := net.Listen("tcp", webhooks.config.ListenAddress)
+	if err != nil {
+		webhooks.log.Fatal("Failed to listen", zap.Error(err))
+	}
+
+	var opts []grpc.ServerOption
+	if webhooks.config.GRPCTLSKeyFile != "" && webhooks.config.GRPCTLSCertificateFile != "" {
+		certFile := testdata.Path(webhooks.config.GRPCTLSCertificateFile)
+		keyFile := testdata.Path(webhooks.config.GRPCTLSKeyFile)
+		creds, err := credentials.NewServerTLSFromFile(certFile, keyFile)
+		if err != nil {
+			webhooks.log.Fatal("Failed to generate credentials", zap.Error(err))
+		}
+		opts = []grpc.ServerOption{grpc.Creds(creds)}
+	}
+
+	opts = append(opts, interceptors.ServerOptions(webhooks.log, webhooks.authDelivery.DefaultInterceptor)...)
+
+	grpcServer := grpc.NewServer(opts...)
+	pb.RegisterWebhookServiceServer(grpcServer, webhooks.webhookDelivery)
+	metrics.RegisterServer(grpcServer)
+	if webhooks.healthDelivery != nil {
+		healthpb.RegisterHealthServer(grpcServer, webhooks.healthDelivery)
+		go webhooks.healthDelivery.Run(ctx)
+	}
+
+	serve := func() error { return grpcServer.Serve(listener) }
+	if err := helpers.ServeGRPC(ctx, grpcServer, serve, webhooks.config.ShutdownTimeout.Duration); err != nil {
+		webhooks.log.Error("gRPC server stopped", zap.Error(err))
+	}
+	webhooks.close(subscriptions)
+}
+
+// close stops receiving of events and waits for deliveries in progress up to shutdown timeout.
+func (webhooks *Webhooks) close(subscriptions []event.Subscription) {
+	for _, subscription := range subscriptions {
+		if err := subscription.Close(); err != nil {
+			webhooks.log.Error("Failed to close subscription", zap.Error(err))
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), webhooks.config.ShutdownTimeout.Duration)
+	defer cancel()
+	if err := helpers.Wait(ctx, &webhooks.handling); err != nil {
+		webhooks.log.Warn("Deliveries in progress abandoned, events are redelivered after restart", zap.Error(err))
+	}
+}
+
+// handleMessage delivers event to all webhooks accepting it. Deliveries which failed are parked to be redispatched
+// once their webhooks are available, message is acknowledged when deliveries are finished and failed ones parked.
+// Event of webhook with parked deliveries is parked behind them. Events handled concurrently may still reach webhook
+// in other order than they were published.
+// Message which can't be handled, e.g. when webhooks can't be listed, is negatively acknowledged to be redelivered.
+func (webhooks *Webhooks) handleMessage(channel string, msg event.Message) {
+	log := webhooks.log.With(zap.String("channel", channel), zap.Uint64("sequence", msg.Sequence()))
+
+	var e pb.Event
+	if err := proto.Unmarshal(msg.Data(), &e); err != nil {
+		log.Error("Invalid event, skipped", zap.Error(err))
+		webhooks.ack(log, msg)
+		return
+	}
+
+	ctx, span := tracing.StartConsume(context.Background(), channel, &e)
+	defer span.End()
+
+	if webhooks.payloadCipher != nil {
+		if err := webhooks.payloadCipher.Decrypt(ctx, &e); err != nil {
+			log.Error("Can't decrypt event", zap.Error(err))
+			webhooks.nak(log, msg)
+			return
+		}
+	}
+
+	registered, err := webhooks.webhookRepository.List(ctx)
+	if err != nil {
+		log.Error("Can't list webhooks", zap.Error(err))
+		webhooks.nak(log, msg)
+		return
+	}
+
+	// event is encoded once per wire format used by webhooks
+	encoded := make(map[encoding.Format]*encoding.Message)
+	var (
+		deliveries []Delivery
+		failed     []*entities.Webhook
+	)
+	for _, webhook := range registered {
+		if !webhook.Accepts(e.Channel, e.Type) {
+			continue
+		}
+
+		// event follows parked deliveries of webhook, so they are delivered in order once webhook is available
+		parked, err := webhooks.parkingRepository.Parked(ctx, webhook.ID)
+		if err != nil {
+			log.Error("Can't check parked deliveries", zap.String("webhookID", webhook.ID.Hex()), zap.Error(err))
+			webhooks.nak(log, msg)
+			return
+		}
+		if parked {
+			failed = append(failed, webhook)
+			continue
+		}
+
+		delivery, err := encode(webhook, &e, encoded)
+		if err != nil {
+			log.Error("Can't encode event, skipped", zap.String("webhookID", webhook.ID.Hex()), zap.String("format", webhook.Format), zap.Error(err))
+			continue
+		}
+		deliveries = append(deliveries, delivery)
+	}
+
+	var (
+		wg sync.WaitGroup
+		mu sync.Mutex
+	)
+	for _, delivery := range deliveries {
+		wg.Add(1)
+		go func(delivery Delivery) {
+			defer wg.Done()
+			if webhooks.dispatcher.Dispatch(ctx, delivery) == Failed {
+				mu.Lock()
+				failed = append(failed, delivery.Webhook)
+				mu.Unlock()
+			}
+		}(delivery)
+	}
+	wg.Wait()
+
+	for _, webhook := range failed {
+		parked := entities.ParkedDelivery{
+			WebhookID: webhook.ID,
+			Channel:   channel,
+			EventID:   e.EventId,
+			Data:      msg.Data(),
+		}
+		if err := webhooks.parkingRepository.Park(ctx, &parked); err != nil {
+			// webhooks which received event get it again with the same ID
+			log.Error("Can't park failed delivery", zap.String("webhookID", webhook.ID.Hex()), zap.Error(err))
+			webhooks.nak(log, msg)
+			return
+		}
+	}
+
+	webhooks.ack(log, msg)
+}
+
+// redispatchParked redispatches parked deliveries every redispatch interval until given context is done.
+func (webhooks *Webhooks) redispatchParked(ctx context.Context) {
+	ticker := time.NewTicker(webhooks.config.RedispatchInterval.Duration)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			webhooks.redispatch(ctx)
+		}
+	}
+}
+
+// redispatch delivers parked events to webhooks whose circuit breakers let deliveries through, in order they were
+// parked. Redispatch to webhook stops at the first delivery which fails again.
+func (webhooks *Webhooks) redispatch(ctx context.Context) {
+	registered, err := webhooks.webhookRepository.List(ctx)
+	if err != nil {
+		webhooks.log.Error("Can't list webhooks", zap.Error(err))
+		return
+	}
+
+	for _, webhook := range registered {
+		if !webhooks.dispatcher.Available(webhook.URL) {
+			continue
+		}
+
+		parked, err := webhooks.parkingRepository.List(ctx, webhook.ID)
+		if err != nil {
+			webhooks.log.Error("Can't list parked deliveries", zap.String("webhookID", webhook.ID.Hex()), zap.Error(err))
+			continue
+		}
+		for _, delivery := range parked {
+			if ctx.Err() != nil {
+				return
+			}
+			if !webhooks.redispatchDelivery(webhook, delivery) {
+				break
+			}
+		}
+	}
+}
+
+// redispatchDelivery dispatches parked delivery and removes it once event is delivered or can't be delivered at all.
+// It returns false if delivery failed again and stays parked.
+func (webhooks *Webhooks) redispatchDelivery(webhook *entities.Webhook, parked *entities.ParkedDelivery) bool {
+	log := webhooks.log.With(
+		zap.String("webhookID", webhook.ID.Hex()),
+		zap.String("channel", parked.Channel),
+		zap.String("eventID", parked.EventID),
+	)
+
+	var e pb.Event
+	if err := proto.Unmarshal(parked.Data, &e); err != nil {
+		log.Error("Invalid parked event, removed", zap.Error(err))
+		return webhooks.unpark(log, parked)
+	}
+	if !webhook.Accepts(e.Channel, e.Type) {
+		return webhooks.unpark(log, parked)
+	}
+
+	ctx, span := tracing.StartConsume(context.Background(), parked.Channel, &e)
+	defer span.End()
+
+	if webhooks.payloadCipher != nil {
+		if err := webhooks.payloadCipher.Decrypt(ctx, &e); err != nil {
+			log.Error("Can't decrypt parked event", zap.Error(err))
+			return false
+		}
+	}
+
+	delivery, err := encode(webhook, &e, make(map[encoding.Format]*encoding.Message))
+	if err != nil {
+		log.Error("Can't encode parked event, removed", zap.String("format", webhook.Format), zap.Error(err))
+		return webhooks.unpark(log, parked)
+	}
+	if webhooks.dispatcher.Dispatch(ctx, delivery) == Failed {
+		return false
+	}
+	return webhooks.unpark(log, parked)
+}
+
+// unpark removes parked delivery, it returns false if delivery couldn't be removed.
+func (webhooks *Webhooks) unpark(log *zap.Logger, parked *entities.ParkedDelivery) bool {
+	if err := webhooks.parkingRepository.Remove(context.Background(), parked.ID); err != nil {
+		log.Error("Can't remove parked delivery", zap.Error(err))
+		return false
+	}
+	return true
+}
+
+// encode returns delivery of event to webhook in its format, encoded messages are reused for webhooks of
+// the same format.
+func encode(webhook *entities.Webhook, e *pb.Event, encoded map[encoding.Format]*encoding.Message) (Delivery, error) {
+	format := encoding.Format(webhook.Format)
+	message, ok := encoded[format]
+	if !ok {
+		var err error
+		if message, err = encoding.Encode(format, e); err != nil {
+			return Delivery{}, err
+		}
+		encoded[format] = message
+	}
+
+	return Delivery{
+		Webhook:   webhook,
+		EventID:   e.EventId,
+		EventType: e.Type,
+		Header:    message.Header,
+		Body:      message.Body,
+	}, nil
+}
+
+func (webhooks *Webhooks) ack(log *zap.Logger, msg event.Message) {
+	if err := msg.Ack(); err != nil {
+		log.Error("Failed to acknowledge message", zap.Error(err))
+	}
+}
+
+// nak asks for redelivery of message which failed to be handled.
+func (webhooks *Webhooks) nak(log *zap.Logger, msg event.Message) {
+	if err := msg.Nak(); err != nil {
+		log.Error("Failed to negatively acknowledge message", zap.Error(err))
+	}
+}
+
+// Config of Webhooks service.
+type Config struct {
+	ListenAddress          string           `toml:"listen_address"`
+	DatabaseAddress        string           `toml:"database_address"`
+	DatabaseName           string           `toml:"database_name"`
+	GRPCTLSCertificateFile string           `toml:"grpc_tls_certificate_file"`
+	GRPCTLSKeyFile         string           `toml:"grpc_tls_key_file"`
+	MetricsAddress         string           `toml:"metrics_address"`
+	NATSClientID           string           `toml:"nats_client_id"`
+	Subscribes             []string         `toml:"subscribes"`
+	AckWait                helpers.Duration `toml:"ack_wait"`
+	RequestTimeout         helpers.Duration `toml:"request_timeout"`
+	MaxAttempts            int              `toml:"max_attempts"`
+	InitialBackoff         helpers.Duration `toml:"initial_backoff"`
+	MaxBackoff             helpers.Duration `toml:"max_backoff"`
+	BreakerThreshold       int              `toml:"breaker_threshold"`
+	BreakerCooldown        helpers.Duration `toml:"breaker_cooldown"`
+	RedispatchInterval     helpers.Duration `toml:"redispatch_interval"`
+	ShutdownTimeout        helpers.Duration `toml:"shutdown_timeout"`
+	HealthCheckInterval    helpers.Duration `toml:"health_check_interval"`
+	Tracing                tracing.Config   `toml:"tracing"`
+	streaming.NATSConfig
+}
+
+// SetDefaults sets default values of not configured options.
+func (config *Config) SetDefaults() {
+	if config.AckWait.Duration == 0 {
+		config.AckWait.Duration = defaultAckWait
+	}
+	if config.RequestTimeout.Duration == 0 {
+		config.RequestTimeout.Duration = defaultRequestTimeout
+	}
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = defaultMaxAttempts
+	}
+	if config.InitialBackoff.Duration == 0 {
+		config.InitialBackoff.Duration = defaultInitialBackoff
+	}
+	if config.MaxBackoff.Duration == 0 {
+		config.MaxBackoff.Duration = defaultMaxBackoff
+	}
+	if config.BreakerThreshold <= 0 {
+		config.BreakerThreshold = defaultBreakerThreshold
+	}
+	if config.BreakerCooldown.Duration == 0 {
+		config.BreakerCooldown.Duration = defaultBreakerCooldown
+	}
+	if config.RedispatchInterval.Duration == 0 {
+		config.RedispatchInterval.Duration = defaultRedispatchInterval
+	}
+	if config.ShutdownTimeout.Duration == 0 {
+		config.ShutdownTimeout.Duration = helpers.DefaultShutdownTimeout
+	}
+	if config.HealthCheckInterval.Duration == 0 {
+		config.HealthCheckInterval.Duration = health.DefaultCheckInterval
+	}
+	config.Tracing.SetDefaults()
+}
+
+// Validate returns errors of missing or invalid options.
+func (config *Config) Validate() error {
+	errs := []error{
+		helpers.Required("listen_address", config.ListenAddress),
+		helpers.Required("database_address", config.DatabaseAddress),
+		helpers.Required("database_name", config.DatabaseName),
+		helpers.Required("nats_client_id", config.NATSClientID),
+		config.Tracing.Validate(),
+		config.NATSConfig.Validate(),
+	}
+	if (config.GRPCTLSCertificateFile == "") != (config.GRPCTLSKeyFile == "") {
+		errs = append(errs, errors.New("grpc_tls_certificate_file and grpc_tls_key_file have to be set together"))
+	}
+	if len(config.Subscribes) == 0 {
+		errs = append(errs, errors.New("subscribes is required"))
+	}
+	return errors.Join(errs...)
+}
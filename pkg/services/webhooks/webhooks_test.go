@@ -0,0 +1,305 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/zap"
+
+	"github.com/migotom/cell-centre-services/pkg/components/event/encoding"
+	"github.com/migotom/cell-centre-services/pkg/components/webhook"
+	"github.com/migotom/cell-centre-services/pkg/entities"
+	"github.com/migotom/cell-centre-services/pkg/helpers/mocks"
+	"github.com/migotom/cell-centre-services/pkg/pb"
+)
+
+func TestHandleMessage(t *testing.T) {
+	payload, _ := ptypes.MarshalAny(&pb.EmployeeFilter{Email: "admin@page.com"})
+	eventData, _ := proto.Marshal(&pb.Event{
+		EventId:       "1",
+		Channel:       "employees",
+		Type:          "DeleteEmployee",
+		Payload:       payload,
+		SchemaVersion: 2,
+	})
+
+	webhookID := primitive.NewObjectID()
+
+	cases := []struct {
+		Name              string
+		Message           *mocks.MessageMock
+		Status            int
+		ExpectedMockCalls func(*mocks.WebhookRepositoryMock, *mocks.WebhookAttemptRepositoryMock, *mocks.WebhookParkingRepositoryMock, *mocks.MessageMock, string)
+		ExpectedRequests  int32
+	}{
+		{
+			Name:    "Event is delivered to accepting webhooks",
+			Message: &mocks.MessageMock{MsgData: eventData, MsgSequence: 1},
+			ExpectedMockCalls: func(w *mocks.WebhookRepositoryMock, a *mocks.WebhookAttemptRepositoryMock, p *mocks.WebhookParkingRepositoryMock, m *mocks.MessageMock, url string) {
+				w.On("List", mock.Anything).Return([]*entities.Webhook{
+					{ID: primitive.NewObjectID(), URL: url, Channels: []string{"employees"}},
+					{ID: primitive.NewObjectID(), URL: url, Channels: []string{"employees"}, EventTypes: []string{"DeleteEmployee"}},
+					{ID: primitive.NewObjectID(), URL: url, Channels: []string{"employees"}, EventTypes: []string{"NewEmployee"}},
+					{ID: primitive.NewObjectID(), URL: url, Channels: []string{"roles"}},
+				}, nil)
+				p.On("Parked", mock.Anything, mock.Anything).Return(false, nil)
+				a.On("New", mock.Anything, mock.Anything).Return(nil).Twice()
+				m.On("Ack").Return(nil)
+			},
+			ExpectedRequests: 2,
+		},
+		{
+			Name:    "Event is delivered in format of webhook",
+			Message: &mocks.MessageMock{MsgData: eventData, MsgSequence: 1},
+			ExpectedMockCalls: func(w *mocks.WebhookRepositoryMock, a *mocks.WebhookAttemptRepositoryMock, p *mocks.WebhookParkingRepositoryMock, m *mocks.MessageMock, url string) {
+				w.On("List", mock.Anything).Return([]*entities.Webhook{
+					{ID: primitive.NewObjectID(), URL: url, Channels: []string{"employees"}, Format: "cloudevents"},
+					{ID: primitive.NewObjectID(), URL: url, Channels: []string{"employees"}, Format: "cloudevents-binary"},
+					{ID: primitive.NewObjectID(), URL: url, Channels: []string{"employees"}, Format: "xml"},
+				}, nil)
+				p.On("Parked", mock.Anything, mock.Anything).Return(false, nil)
+				a.On("New", mock.Anything, mock.Anything).Return(nil).Twice()
+				m.On("Ack").Return(nil)
+			},
+			ExpectedRequests: 2,
+		},
+		{
+			Name:    "Failed delivery is parked",
+			Message: &mocks.MessageMock{MsgData: eventData, MsgSequence: 1},
+			Status:  http.StatusServiceUnavailable,
+			ExpectedMockCalls: func(w *mocks.WebhookRepositoryMock, a *mocks.WebhookAttemptRepositoryMock, p *mocks.WebhookParkingRepositoryMock, m *mocks.MessageMock, url string) {
+				w.On("List", mock.Anything).Return([]*entities.Webhook{
+					{ID: webhookID, URL: url, Channels: []string{"employees"}},
+				}, nil)
+				p.On("Parked", mock.Anything, webhookID).Return(false, nil)
+				a.On("New", mock.Anything, mock.Anything).Return(nil)
+				p.On("Park", mock.Anything, mock.MatchedBy(func(delivery *entities.ParkedDelivery) bool {
+					return delivery.WebhookID == webhookID && delivery.EventID == "1" && delivery.Channel == "employees" &&
+						bytes.Equal(delivery.Data, eventData)
+				})).Return(nil).Once()
+				m.On("Ack").Return(nil)
+			},
+			ExpectedRequests: 2,
+		},
+		{
+			Name:    "Rejected event is not parked",
+			Message: &mocks.MessageMock{MsgData: eventData, MsgSequence: 1},
+			Status:  http.StatusBadRequest,
+			ExpectedMockCalls: func(w *mocks.WebhookRepositoryMock, a *mocks.WebhookAttemptRepositoryMock, p *mocks.WebhookParkingRepositoryMock, m *mocks.MessageMock, url string) {
+				w.On("List", mock.Anything).Return([]*entities.Webhook{
+					{ID: webhookID, URL: url, Channels: []string{"employees"}},
+				}, nil)
+				p.On("Parked", mock.Anything, webhookID).Return(false, nil)
+				a.On("New", mock.Anything, mock.Anything).Return(nil).Once()
+				m.On("Ack").Return(nil)
+			},
+			ExpectedRequests: 1,
+		},
+		{
+			Name:    "Event waits for redelivery when failed delivery can't be parked",
+			Message: &mocks.MessageMock{MsgData: eventData, MsgSequence: 1},
+			Status:  http.StatusServiceUnavailable,
+			ExpectedMockCalls: func(w *mocks.WebhookRepositoryMock, a *mocks.WebhookAttemptRepositoryMock, p *mocks.WebhookParkingRepositoryMock, m *mocks.MessageMock, url string) {
+				w.On("List", mock.Anything).Return([]*entities.Webhook{
+					{ID: webhookID, URL: url, Channels: []string{"employees"}},
+				}, nil)
+				p.On("Parked", mock.Anything, webhookID).Return(false, nil)
+				a.On("New", mock.Anything, mock.Anything).Return(nil)
+				p.On("Park", mock.Anything, mock.Anything).Return(errors.New("database down")).Once()
+				m.On("Nak").Return(nil)
+			},
+			ExpectedRequests: 2,
+		},
+		{
+			Name:    "Event of webhook with parked deliveries is parked behind them",
+			Message: &mocks.MessageMock{MsgData: eventData, MsgSequence: 1},
+			ExpectedMockCalls: func(w *mocks.WebhookRepositoryMock, a *mocks.WebhookAttemptRepositoryMock, p *mocks.WebhookParkingRepositoryMock, m *mocks.MessageMock, url string) {
+				w.On("List", mock.Anything).Return([]*entities.Webhook{
+					{ID: webhookID, URL: url, Channels: []string{"employees"}},
+				}, nil)
+				p.On("Parked", mock.Anything, webhookID).Return(true, nil)
+				p.On("Park", mock.Anything, mock.MatchedBy(func(delivery *entities.ParkedDelivery) bool {
+					return delivery.WebhookID == webhookID && delivery.EventID == "1"
+				})).Return(nil).Once()
+				m.On("Ack").Return(nil)
+			},
+		},
+		{
+			Name:    "Event waits for redelivery when parked deliveries can't be checked",
+			Message: &mocks.MessageMock{MsgData: eventData, MsgSequence: 1},
+			ExpectedMockCalls: func(w *mocks.WebhookRepositoryMock, a *mocks.WebhookAttemptRepositoryMock, p *mocks.WebhookParkingRepositoryMock, m *mocks.MessageMock, url string) {
+				w.On("List", mock.Anything).Return([]*entities.Webhook{
+					{ID: webhookID, URL: url, Channels: []string{"employees"}},
+				}, nil)
+				p.On("Parked", mock.Anything, webhookID).Return(false, errors.New("database down"))
+				m.On("Nak").Return(nil)
+			},
+		},
+		{
+			Name:    "Invalid event is acknowledged",
+			Message: &mocks.MessageMock{MsgData: []byte("not an event"), MsgSequence: 2},
+			ExpectedMockCalls: func(w *mocks.WebhookRepositoryMock, a *mocks.WebhookAttemptRepositoryMock, p *mocks.WebhookParkingRepositoryMock, m *mocks.MessageMock, url string) {
+				m.On("Ack").Return(nil)
+			},
+		},
+		{
+			Name:    "Event waits for redelivery when webhooks can't be listed",
+			Message: &mocks.MessageMock{MsgData: eventData, MsgSequence: 3},
+			ExpectedMockCalls: func(w *mocks.WebhookRepositoryMock, a *mocks.WebhookAttemptRepositoryMock, p *mocks.WebhookParkingRepositoryMock, m *mocks.MessageMock, url string) {
+				w.On("List", mock.Anything).Return([]*entities.Webhook(nil), errors.New("database down"))
+				m.On("Nak").Return(nil)
+			},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			log, _ := zap.NewProduction()
+			defer log.Sync()
+
+			var requests int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := ioutil.ReadAll(r.Body)
+
+				format := encoding.JSON
+				switch {
+				case r.Header.Get("Ce-Id") != "":
+					format = encoding.CloudEventsBinary
+				case r.Header.Get("Content-Type") == encoding.StructuredContentType:
+					format = encoding.CloudEventsStructured
+				}
+				received, err := encoding.Decode(format, &encoding.Message{Header: r.Header, Body: body})
+				assert.NoError(t, err)
+				assert.Equal(t, "1", received.GetEventId())
+
+				atomic.AddInt32(&requests, 1)
+				if tc.Status != 0 {
+					w.WriteHeader(tc.Status)
+				}
+			}))
+			defer server.Close()
+
+			webhookRepositoryMock := mocks.WebhookRepositoryMock{}
+			attemptRepositoryMock := mocks.WebhookAttemptRepositoryMock{}
+			parkingRepositoryMock := mocks.WebhookParkingRepositoryMock{}
+			tc.ExpectedMockCalls(&webhookRepositoryMock, &attemptRepositoryMock, &parkingRepositoryMock, tc.Message, server.URL)
+
+			webhooks := NewWebhooks(log, testConfig(), nil, nil, &webhookRepositoryMock, &attemptRepositoryMock, &parkingRepositoryMock, nil, nil)
+			webhooks.handleMessage("employees", tc.Message)
+
+			assert.Equal(t, tc.ExpectedRequests, atomic.LoadInt32(&requests))
+			webhookRepositoryMock.AssertExpectations(t)
+			attemptRepositoryMock.AssertExpectations(t)
+			parkingRepositoryMock.AssertExpectations(t)
+			tc.Message.AssertExpectations(t)
+		})
+	}
+}
+
+func TestRedispatch(t *testing.T) {
+	parked := func(eventID, eventType string) *entities.ParkedDelivery {
+		payload, _ := ptypes.MarshalAny(&pb.EmployeeFilter{Id: eventID})
+		data, _ := proto.Marshal(&pb.Event{EventId: eventID, Channel: "employees", Type: eventType, Payload: payload})
+		return &entities.ParkedDelivery{ID: primitive.NewObjectID(), Channel: "employees", EventID: eventID, Data: data}
+	}
+	first, second := parked("1", "NewEmployee"), parked("2", "NewEmployee")
+	webhookID := primitive.NewObjectID()
+
+	cases := []struct {
+		Name              string
+		Status            int
+		EventTypes        []string
+		OpenBreaker       bool
+		ExpectedMockCalls func(*mocks.WebhookAttemptRepositoryMock, *mocks.WebhookParkingRepositoryMock)
+		ExpectedEvents    []string
+	}{
+		{
+			Name: "Parked deliveries are redispatched in order",
+			ExpectedMockCalls: func(a *mocks.WebhookAttemptRepositoryMock, p *mocks.WebhookParkingRepositoryMock) {
+				p.On("List", mock.Anything, webhookID).Return([]*entities.ParkedDelivery{first, second}, nil)
+				a.On("New", mock.Anything, mock.Anything).Return(nil).Twice()
+				p.On("Remove", mock.Anything, first.ID).Return(nil).Once()
+				p.On("Remove", mock.Anything, second.ID).Return(nil).Once()
+			},
+			ExpectedEvents: []string{"1", "2"},
+		},
+		{
+			Name:   "Redispatch stops at delivery which failed again",
+			Status: http.StatusServiceUnavailable,
+			ExpectedMockCalls: func(a *mocks.WebhookAttemptRepositoryMock, p *mocks.WebhookParkingRepositoryMock) {
+				p.On("List", mock.Anything, webhookID).Return([]*entities.ParkedDelivery{first, second}, nil)
+				a.On("New", mock.Anything, mock.Anything).Return(nil)
+			},
+			ExpectedEvents: []string{"1", "1"},
+		},
+		{
+			Name:   "Rejected event is removed",
+			Status: http.StatusBadRequest,
+			ExpectedMockCalls: func(a *mocks.WebhookAttemptRepositoryMock, p *mocks.WebhookParkingRepositoryMock) {
+				p.On("List", mock.Anything, webhookID).Return([]*entities.ParkedDelivery{first}, nil)
+				a.On("New", mock.Anything, mock.Anything).Return(nil).Once()
+				p.On("Remove", mock.Anything, first.ID).Return(nil).Once()
+			},
+			ExpectedEvents: []string{"1"},
+		},
+		{
+			Name:       "Event no longer accepted by webhook is removed",
+			EventTypes: []string{"DeleteEmployee"},
+			ExpectedMockCalls: func(a *mocks.WebhookAttemptRepositoryMock, p *mocks.WebhookParkingRepositoryMock) {
+				p.On("List", mock.Anything, webhookID).Return([]*entities.ParkedDelivery{first}, nil)
+				p.On("Remove", mock.Anything, first.ID).Return(nil).Once()
+			},
+		},
+		{
+			Name:              "Webhook with open circuit breaker is skipped",
+			OpenBreaker:       true,
+			ExpectedMockCalls: func(a *mocks.WebhookAttemptRepositoryMock, p *mocks.WebhookParkingRepositoryMock) {},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			log, _ := zap.NewProduction()
+			defer log.Sync()
+
+			var events []string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				events = append(events, r.Header.Get(webhook.EventIDHeader))
+				if tc.Status != 0 {
+					w.WriteHeader(tc.Status)
+				}
+			}))
+			defer server.Close()
+
+			webhookRepositoryMock := mocks.WebhookRepositoryMock{}
+			webhookRepositoryMock.On("List", mock.Anything).Return([]*entities.Webhook{
+				{ID: webhookID, URL: server.URL, Channels: []string{"employees"}, EventTypes: tc.EventTypes},
+			}, nil)
+			attemptRepositoryMock := mocks.WebhookAttemptRepositoryMock{}
+			parkingRepositoryMock := mocks.WebhookParkingRepositoryMock{}
+			tc.ExpectedMockCalls(&attemptRepositoryMock, &parkingRepositoryMock)
+
+			webhooks := NewWebhooks(log, testConfig(), nil, nil, &webhookRepositoryMock, &attemptRepositoryMock, &parkingRepositoryMock, nil, nil)
+			if tc.OpenBreaker {
+				for i := 0; i < webhooks.config.BreakerThreshold; i++ {
+					webhooks.dispatcher.breaker(server.URL).Failure()
+				}
+			}
+			webhooks.redispatch(context.Background())
+
+			assert.Equal(t, tc.ExpectedEvents, events)
+			webhookRepositoryMock.AssertExpectations(t)
+			attemptRepositoryMock.AssertExpectations(t)
+			parkingRepositoryMock.AssertExpectations(t)
+		})
+	}
+}